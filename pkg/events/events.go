@@ -0,0 +1,53 @@
+// Package events defines the envelope services use to announce domain
+// events and the port they publish them through.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event is a domain event. IDs are time-ordered, so sorting by ID gives
+// publication order.
+type Event struct {
+	ID         string      `json:"id" bson:"_id"`
+	Type       string      `json:"type" bson:"type"`       // e.g. "cart.abandoned"
+	Source     string      `json:"source" bson:"source"`   // emitting service, e.g. "cart-ms"
+	Subject    string      `json:"subject" bson:"subject"` // id of the entity the event is about
+	OccurredAt time.Time   `json:"occurred_at" bson:"occurred_at"`
+	Data       interface{} `json:"data" bson:"data"`
+}
+
+// New builds an event with a fresh ID.
+func New(eventType, source, subject string, data interface{}) Event {
+	return Event{
+		ID:         primitive.NewObjectID().Hex(),
+		Type:       eventType,
+		Source:     source,
+		Subject:    subject,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Publisher delivers events to whoever consumes them.
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// LogPublisher writes events to the standard logger. It is meant for
+// local development.
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(_ context.Context, e Event) error {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	log.Printf("event %s", raw)
+	return nil
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// OutboxCollection is the collection events are written to.
+const OutboxCollection = "events"
+
+// MongoOutbox publishes events by appending them to an outbox collection
+// in the emitting service's database. Consumers read the collection in ID
+// order.
+type MongoOutbox struct {
+	collection *mongo.Collection
+}
+
+func NewMongoOutbox(db *mongo.Database) *MongoOutbox {
+	return &MongoOutbox{collection: db.Collection(OutboxCollection)}
+}
+
+func (o *MongoOutbox) Publish(ctx context.Context, e Event) error {
+	if _, err := o.collection.InsertOne(ctx, e); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", e.Type, err)
+	}
+	return nil
+}
@@ -59,6 +59,27 @@ func AdminOnly(next http.Handler) http.Handler {
 
 
 
+// RequireRole lets requests through whose role is one of roles. Admins
+// are always let through.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            _, role := FromContext(r.Context())
+            if role == "admin" {
+                next.ServeHTTP(w, r)
+                return
+            }
+            for _, allowed := range roles {
+                if role == allowed {
+                    next.ServeHTTP(w, r)
+                    return
+                }
+            }
+            http.Error(w, "Forbidden: requires role "+strings.Join(roles, " or "), http.StatusForbidden)
+        })
+    }
+}
+
 // retrive userID from request context
 func FromContext(ctx context.Context) (string, string) {
     uid, _ := ctx.Value(userCtxKey).(string)
@@ -0,0 +1,156 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+var tMoney = reflect.TypeOf(Money{})
+
+// Registry returns a BSON registry that stores Money as
+// {amount: <int64 minor units>, currency: <code>}. Legacy numeric values
+// written before the money type existed are read as major units of
+// legacyCurrency, so services keep working while data is migrated.
+func Registry(legacyCurrency string) *bsoncodec.Registry {
+	reg := bson.NewRegistry()
+	Register(reg, legacyCurrency)
+	return reg
+}
+
+// Register adds the Money codec to an existing registry.
+func Register(reg *bsoncodec.Registry, legacyCurrency string) {
+	if legacyCurrency == "" {
+		legacyCurrency = DefaultCurrency
+	}
+	reg.RegisterTypeEncoder(tMoney, bsoncodec.ValueEncoderFunc(encodeMoney))
+	reg.RegisterTypeDecoder(tMoney, moneyDecoder(normalize(legacyCurrency)))
+}
+
+func encodeMoney(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != tMoney {
+		return bsoncodec.ValueEncoderError{Name: "MoneyEncodeValue", Types: []reflect.Type{tMoney}, Received: val}
+	}
+	m := val.Interface().(Money)
+
+	dw, err := vw.WriteDocument()
+	if err != nil {
+		return err
+	}
+
+	ew, err := dw.WriteDocumentElement("amount")
+	if err != nil {
+		return err
+	}
+	if err := ew.WriteInt64(m.Amount); err != nil {
+		return err
+	}
+
+	ew, err = dw.WriteDocumentElement("currency")
+	if err != nil {
+		return err
+	}
+	if err := ew.WriteString(m.Currency); err != nil {
+		return err
+	}
+
+	return dw.WriteDocumentEnd()
+}
+
+func moneyDecoder(legacyCurrency string) bsoncodec.ValueDecoderFunc {
+	return func(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+		if !val.CanSet() || val.Type() != tMoney {
+			return bsoncodec.ValueDecoderError{Name: "MoneyDecodeValue", Types: []reflect.Type{tMoney}, Received: val}
+		}
+
+		var m Money
+		switch vr.Type() {
+		case bsontype.EmbeddedDocument:
+			dr, err := vr.ReadDocument()
+			if err != nil {
+				return err
+			}
+			for {
+				key, evr, err := dr.ReadElement()
+				if errors.Is(err, bsonrw.ErrEOD) {
+					break
+				}
+				if err != nil {
+					return err
+				}
+
+				switch key {
+				case "amount":
+					if m.Amount, err = readMinor(evr); err != nil {
+						return err
+					}
+				case "currency":
+					if m.Currency, err = evr.ReadString(); err != nil {
+						return err
+					}
+				default:
+					if err := evr.Skip(); err != nil {
+						return err
+					}
+				}
+			}
+
+		case bsontype.Double:
+			f, err := vr.ReadDouble()
+			if err != nil {
+				return err
+			}
+			m = FromMajor(f, legacyCurrency)
+
+		case bsontype.Int32:
+			i, err := vr.ReadInt32()
+			if err != nil {
+				return err
+			}
+			m = FromMajor(float64(i), legacyCurrency)
+
+		case bsontype.Int64:
+			i, err := vr.ReadInt64()
+			if err != nil {
+				return err
+			}
+			m = FromMajor(float64(i), legacyCurrency)
+
+		case bsontype.Null:
+			if err := vr.ReadNull(); err != nil {
+				return err
+			}
+
+		case bsontype.Undefined:
+			if err := vr.ReadUndefined(); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("cannot decode %v into money.Money", vr.Type())
+		}
+
+		val.Set(reflect.ValueOf(m))
+		return nil
+	}
+}
+
+func readMinor(vr bsonrw.ValueReader) (int64, error) {
+	switch vr.Type() {
+	case bsontype.Int64:
+		return vr.ReadInt64()
+	case bsontype.Int32:
+		i, err := vr.ReadInt32()
+		return int64(i), err
+	case bsontype.Double:
+		f, err := vr.ReadDouble()
+		return int64(math.Round(f)), err
+	}
+	return 0, fmt.Errorf("cannot decode %v as a money amount", vr.Type())
+}
@@ -0,0 +1,56 @@
+// Command migrate-money rewrites legacy float amounts into {amount,
+// currency} documents for every collection of the database named by
+// MONGO_URI and MONGO_DB_NAME. Amounts are read as major units of
+// DEFAULT_CURRENCY (USD when unset). It is safe to run more than once.
+package main
+
+import (
+	"context"
+	"ecom-api/pkg/money"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// table lists the money fields each service stored as floats.
+var table = []money.FloatFields{
+	{Collection: "carts", Paths: []string{"items.price"}},
+	{Collection: "orders", Paths: []string{"total", "subtotal", "tax_total", "shipping_cost", "items.price", "items.tax", "items.line_total"}},
+	{Collection: "payments", Paths: []string{"amount"}},
+	{Collection: "products", Paths: []string{"price"}},
+}
+
+func main() {
+	mongoURI := os.Getenv("MONGO_URI")
+	dbName := os.Getenv("MONGO_DB_NAME")
+	if mongoURI == "" || dbName == "" {
+		log.Fatal("Missing MONGO_URI or MONGO_DB_NAME in environment")
+	}
+
+	currency := os.Getenv("DEFAULT_CURRENCY")
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Disconnect(ctx)
+
+	migrated, err := money.MigrateCollections(ctx, client.Database(dbName), currency, table)
+	for _, t := range table {
+		if n := migrated[t.Collection]; n > 0 {
+			log.Printf("migrated %d %s documents to %s", n, t.Collection, currency)
+		}
+	}
+	if err != nil {
+		log.Fatalf("migration failed: %v", err)
+	}
+}
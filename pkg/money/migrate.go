@@ -0,0 +1,139 @@
+package money
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MigrateFloatFields rewrites legacy numeric money fields of a collection
+// into the Money document shape. Paths use dot notation and may cross
+// arrays, e.g. "items.price". Fields that already hold a Money document
+// are left alone, so the migration can be re-run safely. It returns the
+// number of documents that were rewritten.
+func MigrateFloatFields(ctx context.Context, coll *mongo.Collection, currency string, paths ...string) (int, error) {
+	currency = normalize(currency)
+	if !ValidCurrency(currency) {
+		return 0, ErrInvalidCurrency
+	}
+
+	or := make(bson.A, 0, len(paths))
+	for _, p := range paths {
+		or = append(or, bson.M{p: bson.M{"$type": "number"}})
+	}
+
+	cur, err := coll.Find(ctx, bson.M{"$or": or})
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan %s: %w", coll.Name(), err)
+	}
+	defer cur.Close(ctx)
+
+	migrated := 0
+	for cur.Next(ctx) {
+		var doc bson.M
+		if err := cur.Decode(&doc); err != nil {
+			return migrated, err
+		}
+
+		changed := false
+		for _, p := range paths {
+			if convertPath(doc, strings.Split(p, "."), currency) {
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		if _, err := coll.ReplaceOne(ctx, bson.M{"_id": doc["_id"]}, doc); err != nil {
+			return migrated, fmt.Errorf("failed to migrate %s %v: %w", coll.Name(), doc["_id"], err)
+		}
+		migrated++
+	}
+
+	return migrated, cur.Err()
+}
+
+// FloatFields names the legacy numeric money fields of one collection.
+type FloatFields struct {
+	Collection string
+	Paths      []string
+}
+
+// MigrateCollections runs MigrateFloatFields over every collection of the
+// table and returns how many documents of each were rewritten. Collections
+// missing from db are simply empty, so one table can serve every service.
+func MigrateCollections(ctx context.Context, db *mongo.Database, currency string, table []FloatFields) (map[string]int, error) {
+	migrated := make(map[string]int, len(table))
+	for _, t := range table {
+		n, err := MigrateFloatFields(ctx, db.Collection(t.Collection), currency, t.Paths...)
+		migrated[t.Collection] = n
+		if err != nil {
+			return migrated, err
+		}
+	}
+	return migrated, nil
+}
+
+// convertPath walks a dotted path through documents and arrays and
+// replaces numeric leaves with Money documents.
+func convertPath(node interface{}, path []string, currency string) bool {
+	switch n := node.(type) {
+	case bson.M:
+		v, ok := n[path[0]]
+		if !ok {
+			return false
+		}
+		if len(path) == 1 {
+			m, ok := legacyToMoney(v, currency)
+			if !ok {
+				return false
+			}
+			n[path[0]] = bson.M{"amount": m.Amount, "currency": m.Currency}
+			return true
+		}
+		return convertPath(v, path[1:], currency)
+
+	case bson.D:
+		m := n.Map()
+		if !convertPath(m, path, currency) {
+			return false
+		}
+		for i := range n {
+			n[i].Value = m[n[i].Key]
+		}
+		return true
+
+	case bson.A:
+		changed := false
+		for _, el := range n {
+			if convertPath(el, path, currency) {
+				changed = true
+			}
+		}
+		return changed
+	}
+	return false
+}
+
+func legacyToMoney(v interface{}, currency string) (Money, bool) {
+	switch n := v.(type) {
+	case float64:
+		return FromMajor(n, currency), true
+	case int32:
+		return FromMajor(float64(n), currency), true
+	case int64:
+		return FromMajor(float64(n), currency), true
+	case primitive.Decimal128:
+		m, err := Parse(n.String(), currency)
+		if err != nil {
+			return Money{}, false
+		}
+		return m, true
+	}
+	return Money{}, false
+}
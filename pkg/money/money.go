@@ -0,0 +1,286 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is assumed for legacy amounts stored without a currency.
+const DefaultCurrency = "USD"
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidCurrency  = errors.New("invalid currency code")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// exponents lists ISO 4217 currencies whose minor unit is not 1/100.
+var exponents = map[string]int{
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+}
+
+// Money is an exact amount in the minor unit of an ISO 4217 currency.
+// The zero value has no currency and adopts the currency of the other
+// operand in arithmetic, so it can be used as an accumulator.
+type Money struct {
+	Amount   int64  `json:"amount" bson:"amount"`     // minor units, e.g. cents
+	Currency string `json:"currency" bson:"currency"` // ISO 4217 code
+}
+
+// New returns an amount given in minor units.
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: normalize(currency)}
+}
+
+// Zero returns a zero amount in currency.
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// FromMajor converts a decimal amount in major units, rounding half away
+// from zero to the currency's minor unit. It exists for configuration
+// files and for migrating legacy float values; do not use it for sums.
+func FromMajor(v float64, currency string) Money {
+	currency = normalize(currency)
+	return Money{Amount: roundRat(new(big.Rat).Mul(exact(v), pow10(Exponent(currency)))), Currency: currency}
+}
+
+// Parse reads a decimal string such as "12.34" exactly.
+func Parse(s, currency string) (Money, error) {
+	currency = normalize(currency)
+	if !ValidCurrency(currency) {
+		return Money{}, ErrInvalidCurrency
+	}
+
+	in := strings.TrimSpace(s)
+	s = in
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	// only digits may follow the one optional sign, so "+-5" is refused
+	whole, frac, _ := strings.Cut(s, ".")
+	exp := Exponent(currency)
+	if !isDigits(whole) || (frac != "" && !isDigits(frac)) || len(frac) > exp {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, in)
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	amount, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, in)
+	}
+	if neg {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Exponent is the number of minor-unit digits of the currency.
+func Exponent(currency string) int {
+	if e, ok := exponents[normalize(currency)]; ok {
+		return e
+	}
+	return 2
+}
+
+// ValidCurrency reports whether code looks like an ISO 4217 code.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Add returns m + o.
+func (m Money) Add(o Money) (Money, error) {
+	cur, err := m.common(o)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: cur}, nil
+}
+
+// Sub returns m - o.
+func (m Money) Sub(o Money) (Money, error) {
+	cur, err := m.common(o)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: cur}, nil
+}
+
+// Mul multiplies by an integer quantity.
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+// Share returns n of parts equal parts of m, rounding half away from
+// zero. Splitting m by the differences of growing shares, ending with the
+// share of all parts, never loses or adds a unit.
+func (m Money) Share(n, parts int64) Money {
+	if parts == 0 {
+		return Money{Currency: m.Currency}
+	}
+	v := big.NewRat(m.Amount, 1)
+	v.Mul(v, big.NewRat(n, parts))
+	return Money{Amount: roundRat(v), Currency: m.Currency}
+}
+
+// MulRate multiplies by a fractional rate, rounding half away from zero.
+func (m Money) MulRate(rate float64) Money {
+	v := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), exact(rate))
+	return Money{Amount: roundRat(v), Currency: m.Currency}
+}
+
+// DivRate divides by a fractional factor, rounding half away from zero.
+func (m Money) DivRate(factor float64) Money {
+	f := exact(factor)
+	if f.Sign() == 0 {
+		return Money{Amount: int64(math.Round(float64(m.Amount) / factor)), Currency: m.Currency}
+	}
+	v := new(big.Rat).Quo(new(big.Rat).SetInt64(m.Amount), f)
+	return Money{Amount: roundRat(v), Currency: m.Currency}
+}
+
+// Convert changes m into currency at rate, the number of major units of
+// currency per major unit of m's currency. The result is rounded half away
+// from zero to the minor unit of the target currency.
+func (m Money) Convert(currency string, rate float64) Money {
+	currency = normalize(currency)
+	v := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), exact(rate))
+	v.Mul(v, pow10(Exponent(currency)))
+	v.Quo(v, pow10(Exponent(m.Currency)))
+	return Money{Amount: roundRat(v), Currency: currency}
+}
+
+// Cmp compares m and o, returning -1, 0 or +1.
+func (m Money) Cmp(o Money) (int, error) {
+	if _, err := m.common(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+
+// Major returns the amount in major units for display. Do not compute with it.
+func (m Money) Major() float64 {
+	return float64(m.Amount) / scale(m.Currency)
+}
+
+// String formats the amount as "12.34 USD".
+func (m Money) String() string {
+	exp := Exponent(m.Currency)
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if exp > 0 {
+		if len(digits) <= exp {
+			digits = strings.Repeat("0", exp-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+	}
+
+	return strings.TrimSpace(sign + digits + " " + m.Currency)
+}
+
+// Sum adds amounts that share one currency.
+func Sum(items ...Money) (Money, error) {
+	var total Money
+	for _, it := range items {
+		var err error
+		if total, err = total.Add(it); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// common resolves the currency of a binary operation.
+func (m Money) common(o Money) (string, error) {
+	switch {
+	case m.Currency == o.Currency:
+		return m.Currency, nil
+	case m.Currency == "" && m.Amount == 0:
+		return o.Currency, nil
+	case o.Currency == "" && o.Amount == 0:
+		return m.Currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// exact reads a float as the decimal it prints as, so a rate of 0.0725
+// is taken as 725/10000 rather than the nearest binary fraction, which
+// would round half-unit results down.
+func exact(f float64) *big.Rat {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// roundRat rounds half away from zero to an integer.
+func roundRat(r *big.Rat) int64 {
+	num := new(big.Int).Abs(r.Num())
+	q, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
+
+func pow10(exp int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+}
+
+func scale(currency string) float64 {
+	return math.Pow10(Exponent(currency))
+}
+
+func normalize(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
@@ -0,0 +1,149 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     Money
+		err      error
+	}{
+		{in: "12.34", currency: "USD", want: New(1234, "USD")},
+		{in: " 12.3 ", currency: "usd", want: New(1230, "USD")},
+		{in: "12", currency: "USD", want: New(1200, "USD")},
+		{in: "12.", currency: "USD", want: New(1200, "USD")},
+		{in: "0.01", currency: "USD", want: New(1, "USD")},
+		{in: "-5.5", currency: "EUR", want: New(-550, "EUR")},
+		{in: "+5.5", currency: "EUR", want: New(550, "EUR")},
+		{in: "1500", currency: "JPY", want: New(1500, "JPY")},
+		{in: "1.234", currency: "KWD", want: New(1234, "KWD")},
+		{in: "12.345", currency: "USD", err: ErrInvalidAmount},
+		{in: "1.5", currency: "JPY", err: ErrInvalidAmount},
+		{in: "+-5", currency: "USD", err: ErrInvalidAmount},
+		{in: "-+5", currency: "USD", err: ErrInvalidAmount},
+		{in: "--5", currency: "USD", err: ErrInvalidAmount},
+		{in: "5.-1", currency: "USD", err: ErrInvalidAmount},
+		{in: "", currency: "USD", err: ErrInvalidAmount},
+		{in: "-", currency: "USD", err: ErrInvalidAmount},
+		{in: ".5", currency: "USD", err: ErrInvalidAmount},
+		{in: "1e3", currency: "USD", err: ErrInvalidAmount},
+		{in: "1,50", currency: "USD", err: ErrInvalidAmount},
+		{in: "99999999999999999999", currency: "USD", err: ErrInvalidAmount},
+		{in: "1.00", currency: "US", err: ErrInvalidCurrency},
+		{in: "1.00", currency: "", err: ErrInvalidCurrency},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in, tt.currency)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Parse(%q, %q) error = %v, want %v", tt.in, tt.currency, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q, %q) unexpected error: %v", tt.in, tt.currency, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q, %q) = %v, want %v", tt.in, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Money
+		want Money
+		err  error
+	}{
+		{name: "same currency", a: New(150, "USD"), b: New(275, "USD"), want: New(425, "USD")},
+		{name: "negative", a: New(150, "USD"), b: New(-200, "USD"), want: New(-50, "USD")},
+		{name: "zero value adopts other", a: Money{}, b: New(99, "EUR"), want: New(99, "EUR")},
+		{name: "other zero value", a: New(99, "EUR"), b: Money{}, want: New(99, "EUR")},
+		{name: "mismatch", a: New(1, "USD"), b: New(1, "EUR"), err: ErrCurrencyMismatch},
+		{name: "currency-less non-zero", a: Money{Amount: 5}, b: New(1, "EUR"), err: ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.a.Add(tt.b)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: error = %v, want %v", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCmp(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Money
+		want int
+		err  error
+	}{
+		{name: "less", a: New(1, "USD"), b: New(2, "USD"), want: -1},
+		{name: "equal", a: New(2, "USD"), b: New(2, "USD"), want: 0},
+		{name: "greater", a: New(3, "USD"), b: New(2, "USD"), want: 1},
+		{name: "negative", a: New(-3, "USD"), b: New(2, "USD"), want: -1},
+		{name: "zero value", a: Money{}, b: New(2, "USD"), want: -1},
+		{name: "mismatch", a: New(2, "USD"), b: New(2, "EUR"), err: ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.a.Cmp(tt.b)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: error = %v, want %v", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRateRounding(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Money
+		want Money
+	}{
+		{name: "MulRate half cent rounds up", got: New(3000, "USD").MulRate(0.0725), want: New(218, "USD")},
+		{name: "MulRate negative rounds away from zero", got: New(-3000, "USD").MulRate(0.0725), want: New(-218, "USD")},
+		{name: "MulRate below half", got: New(1001, "USD").MulRate(0.19), want: New(190, "USD")},
+		{name: "DivRate", got: New(1190, "EUR").DivRate(1.19), want: New(1000, "EUR")},
+		{name: "DivRate half", got: New(107, "EUR").DivRate(2), want: New(54, "EUR")},
+		{name: "FromMajor", got: FromMajor(1.005, "USD"), want: New(101, "USD")},
+		{name: "FromMajor zero exponent", got: FromMajor(1499.5, "JPY"), want: New(1500, "JPY")},
+		{name: "Convert", got: New(1000, "USD").Convert("EUR", 0.9215), want: New(922, "EUR")},
+		{name: "Convert across exponents", got: New(1050, "USD").Convert("JPY", 150), want: New(1575, "JPY")},
+		{name: "Share rounds", got: New(1000, "USD").Share(2, 3), want: New(667, "USD")},
+		{name: "Share of all parts", got: New(1000, "USD").Share(3, 3), want: New(1000, "USD")},
+		{name: "Share of no parts", got: New(1000, "USD").Share(0, 0), want: New(0, "USD")},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
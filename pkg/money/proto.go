@@ -0,0 +1,16 @@
+package money
+
+import "ecom-api/pkg/money/moneypb"
+
+// ToProto converts m to its wire representation.
+func ToProto(m Money) *moneypb.Money {
+	return &moneypb.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+// FromProto converts a wire value; nil yields the zero Money.
+func FromProto(p *moneypb.Money) Money {
+	if p == nil {
+		return Money{}
+	}
+	return New(p.GetAmount(), p.GetCurrency())
+}
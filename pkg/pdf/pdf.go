@@ -0,0 +1,158 @@
+// Package pdf writes simple text documents as PDF. Text is set in the
+// standard Courier font, so columns laid out with spaces stay aligned and
+// no font files are needed. The output only depends on the input, which
+// keeps stored documents reproducible.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Page geometry in points (A4).
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	marginLeft   = 50
+	marginTop    = 60
+	fontSize     = 9
+	lineHeight   = 11
+	linesPerPage = (pageHeight - 2*marginTop) / lineHeight
+)
+
+// BoldPrefix marks a line to be set in bold. It is removed from the output.
+const BoldPrefix = "# "
+
+// Render lays out text one line per row, breaking pages as needed. Lines
+// starting with BoldPrefix are set in bold and a form feed starts a new
+// page.
+func Render(title, text string) []byte {
+	var pages [][]string
+	for _, chunk := range strings.Split(strings.TrimRight(text, "\n"), "\f") {
+		lines := strings.Split(strings.Trim(chunk, "\n"), "\n")
+		for len(lines) > linesPerPage {
+			pages = append(pages, lines[:linesPerPage])
+			lines = lines[linesPerPage:]
+		}
+		pages = append(pages, lines)
+	}
+
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// objects 1-5 are fixed, pages and their contents follow in pairs
+	pageIDs := make([]string, len(pages))
+	for i := range pages {
+		pageIDs[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+
+	w.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	w.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageIDs, " "), len(pages)))
+	w.object(3, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	w.object(4, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	w.object(5, fmt.Sprintf("<< /Title %s /Producer (ecom-api) >>", literal(title)))
+
+	for i, lines := range pages {
+		id := 6 + 2*i
+		w.object(id, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, id+1))
+
+		content := pageContent(lines)
+		w.object(id+1, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	w.trailer()
+	return w.buf.Bytes()
+}
+
+func pageContent(lines []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, marginLeft, pageHeight-marginTop)
+
+	bold := false
+	for _, line := range lines {
+		isBold := strings.HasPrefix(line, BoldPrefix)
+		if isBold {
+			line = strings.TrimPrefix(line, BoldPrefix)
+		}
+		if isBold != bold {
+			font := "/F1"
+			if isBold {
+				font = "/F2"
+			}
+			fmt.Fprintf(&b, "%s %d Tf\n", font, fontSize)
+			bold = isBold
+		}
+		fmt.Fprintf(&b, "%s Tj T*\n", literal(line))
+	}
+	b.WriteString("ET")
+	return b.String()
+}
+
+// literal encodes s as a PDF string in WinAnsiEncoding. Characters the
+// encoding lacks are replaced by '?'.
+func literal(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		c, ok := winAnsi(r)
+		if !ok {
+			c = '?'
+		}
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			if c < 0x20 || c > 0x7e {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// winAnsiExtra maps the characters WinAnsiEncoding places in 0x80-0x9f.
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+func winAnsi(r rune) (byte, bool) {
+	if r == '\t' {
+		return ' ', true
+	}
+	if (r >= 0x20 && r < 0x7f) || (r >= 0xa0 && r <= 0xff) {
+		return byte(r), true
+	}
+	c, ok := winAnsiExtra[r]
+	return c, ok
+}
+
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *writer) object(id int, body string) {
+	for len(w.offsets) < id {
+		w.offsets = append(w.offsets, 0)
+	}
+	w.offsets[id-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+func (w *writer) trailer() {
+	start := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, off := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, start)
+}
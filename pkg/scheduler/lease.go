@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LeaseCollection holds one document per lease.
+const LeaseCollection = "leases"
+
+// Lease elects a single leader among the replicas of a service. The holder
+// renews the lease before it expires; when it stops, another replica takes
+// over once the lease ran out. Expiry is judged by each replica's clock,
+// so the TTL should be well above the clock skew between them.
+type Lease struct {
+	collection *mongo.Collection
+	name       string
+	holder     string
+	ttl        time.Duration
+}
+
+// NewLease returns a lease on name held as holder; an empty holder is
+// replaced by the host name and a random suffix.
+func NewLease(db *mongo.Database, name, holder string, ttl time.Duration) *Lease {
+	if holder == "" {
+		host, _ := os.Hostname()
+		holder = host + "-" + primitive.NewObjectID().Hex()
+	}
+	return &Lease{
+		collection: db.Collection(LeaseCollection),
+		name:       name,
+		holder:     holder,
+		ttl:        ttl,
+	}
+}
+
+// Holder identifies this replica.
+func (l *Lease) Holder() string { return l.holder }
+
+// Acquire takes the lease if it is free or expired, or renews it if this
+// replica already holds it. It returns until when the lease is held, or
+// the zero time if another replica holds it.
+func (l *Lease) Acquire(ctx context.Context) (time.Time, error) {
+	now := time.Now()
+	until := now.Add(l.ttl)
+
+	filter := bson.M{
+		"_id": l.name,
+		"$or": []bson.M{
+			{"holder": l.holder},
+			{"expires_at": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"holder": l.holder, "expires_at": until, "renewed_at": now}}
+
+	// held by someone else, the filter misses and the upsert hits the _id
+	_, err := l.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to acquire lease %s: %w", l.name, err)
+	}
+	return until, nil
+}
+
+// Release gives the lease up so another replica can take over right away.
+func (l *Lease) Release(ctx context.Context) error {
+	_, err := l.collection.UpdateOne(ctx,
+		bson.M{"_id": l.name, "holder": l.holder},
+		bson.M{"$set": bson.M{"expires_at": time.Now()}},
+	)
+	return err
+}
@@ -0,0 +1,242 @@
+// Package scheduler runs background jobs on one replica of a service at a
+// time. Delayed jobs are stored in Mongo and run once when due; periodic
+// jobs run at a fixed interval. Only the replica holding the service's
+// lease runs jobs, so scaling a service out does not run them twice.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// JobCollection holds the delayed jobs waiting to run.
+const JobCollection = "scheduled_jobs"
+
+const (
+	dueBatchSize = 100
+	maxBackoff   = time.Hour
+)
+
+// Handler runs a delayed job for subject, e.g. an order ID. A job whose
+// handler fails is retried with a growing delay, and a replica that loses
+// the lease mid-run leaves the job to the next leader, so handlers must be
+// safe to run again.
+type Handler func(ctx context.Context, subject string) error
+
+// Job is a delayed job. A subject has at most one pending job of a kind.
+type Job struct {
+	ID        string    `bson:"_id"` // kind/subject
+	Kind      string    `bson:"kind"`
+	Subject   string    `bson:"subject"`
+	RunAt     time.Time `bson:"run_at"`
+	Attempts  int       `bson:"attempts"`
+	LastError string    `bson:"last_error,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+type periodicJob struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+	next     time.Time
+}
+
+// Scheduler polls for due jobs while it holds the lease.
+type Scheduler struct {
+	lease    *Lease
+	jobs     *mongo.Collection
+	poll     time.Duration
+	handlers map[string]Handler
+	periodic []*periodicJob
+}
+
+// New returns a scheduler for service that checks for due jobs every poll
+// interval and holds its lease for leaseTTL.
+func New(db *mongo.Database, service string, poll, leaseTTL time.Duration) *Scheduler {
+	return &Scheduler{
+		lease:    NewLease(db, service+"-scheduler", "", leaseTTL),
+		jobs:     db.Collection(JobCollection),
+		poll:     poll,
+		handlers: map[string]Handler{},
+	}
+}
+
+// FromEnv configures a scheduler from SCHEDULER_POLL_INTERVAL (default
+// 10s) and SCHEDULER_LEASE_TTL (default 30s).
+func FromEnv(db *mongo.Database, service string) (*Scheduler, error) {
+	poll, ttl := 10*time.Second, 30*time.Second
+	if v := os.Getenv("SCHEDULER_POLL_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SCHEDULER_POLL_INTERVAL: %w", err)
+		}
+		poll = d
+	}
+	if v := os.Getenv("SCHEDULER_LEASE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SCHEDULER_LEASE_TTL: %w", err)
+		}
+		ttl = d
+	}
+	if poll <= 0 || ttl <= poll {
+		return nil, errors.New("SCHEDULER_LEASE_TTL must be longer than SCHEDULER_POLL_INTERVAL")
+	}
+	return New(db, service, poll, ttl), nil
+}
+
+// Handle registers the handler of a kind of delayed job. Register handlers
+// before Run.
+func (s *Scheduler) Handle(kind string, h Handler) {
+	s.handlers[kind] = h
+}
+
+// Every registers a job that runs every interval, starting as soon as a
+// replica becomes leader. Register jobs before Run.
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.periodic = append(s.periodic, &periodicJob{name: name, interval: interval, run: run})
+}
+
+// Schedule runs a job of kind for subject at at. Scheduling it again
+// before it ran moves it to the new time.
+func (s *Scheduler) Schedule(ctx context.Context, kind, subject string, at time.Time) error {
+	id := kind + "/" + subject
+	_, err := s.jobs.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set":         bson.M{"kind": kind, "subject": subject, "run_at": at, "attempts": 0},
+		"$unset":       bson.M{"last_error": ""},
+		"$setOnInsert": bson.M{"created_at": time.Now()},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to schedule %s: %w", id, err)
+	}
+	return nil
+}
+
+// Unschedule drops the pending job of kind for subject, if any.
+func (s *Scheduler) Unschedule(ctx context.Context, kind, subject string) error {
+	_, err := s.jobs.DeleteOne(ctx, bson.M{"_id": kind + "/" + subject})
+	return err
+}
+
+// Run campaigns for the lease every poll interval and runs due jobs while
+// it is held, until ctx is cancelled. The lease is released on the way out.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if s.poll <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(s.poll)
+	defer ticker.Stop()
+
+	leader := false
+	for {
+		until, err := s.lease.Acquire(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("scheduler: %v", err)
+		}
+		switch held := !until.IsZero(); {
+		case held && !leader:
+			log.Printf("scheduler: %s is now leader", s.lease.Holder())
+		case !held && leader:
+			log.Printf("scheduler: %s lost leadership", s.lease.Holder())
+		}
+		leader = !until.IsZero()
+
+		if leader {
+			// stop short of the lease end so a new leader never overlaps
+			jobCtx, cancel := context.WithDeadline(ctx, until.Add(-s.poll/2))
+			s.runPeriodic(jobCtx)
+			s.runDue(jobCtx)
+			cancel()
+		}
+
+		select {
+		case <-ctx.Done():
+			if leader {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := s.lease.Release(releaseCtx); err != nil {
+					log.Printf("scheduler: failed to release lease: %v", err)
+				}
+				cancel()
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runPeriodic(ctx context.Context) {
+	now := time.Now()
+	for _, p := range s.periodic {
+		if now.Before(p.next) {
+			continue
+		}
+		p.next = now.Add(p.interval)
+		if err := p.run(ctx); err != nil {
+			log.Printf("scheduler: job %s failed: %v", p.name, err)
+		}
+	}
+}
+
+// runDue runs the jobs that are due, oldest first, until none are left or
+// the lease is about to run out.
+func (s *Scheduler) runDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		opts := options.Find().SetSort(bson.M{"run_at": 1}).SetLimit(dueBatchSize)
+		cur, err := s.jobs.Find(ctx, bson.M{"run_at": bson.M{"$lte": time.Now()}}, opts)
+		if err != nil {
+			log.Printf("scheduler: failed to load due jobs: %v", err)
+			return
+		}
+		var due []Job
+		err = cur.All(ctx, &due)
+		if err != nil {
+			log.Printf("scheduler: failed to load due jobs: %v", err)
+			return
+		}
+
+		for i := range due {
+			if ctx.Err() != nil {
+				return
+			}
+			s.runJob(ctx, &due[i])
+		}
+		if len(due) < dueBatchSize {
+			return
+		}
+	}
+}
+
+// runJob runs a job and removes it, unless it was rescheduled while it
+// ran. A failed job is pushed back by a delay that doubles per attempt.
+func (s *Scheduler) runJob(ctx context.Context, job *Job) {
+	err := fmt.Errorf("no handler for %s jobs", job.Kind)
+	if h, ok := s.handlers[job.Kind]; ok {
+		err = h(ctx, job.Subject)
+	}
+
+	if err == nil {
+		if _, err := s.jobs.DeleteOne(ctx, bson.M{"_id": job.ID, "run_at": job.RunAt}); err != nil {
+			log.Printf("scheduler: failed to remove job %s: %v", job.ID, err)
+		}
+		return
+	}
+
+	backoff := min(s.poll<<min(job.Attempts, 16), maxBackoff)
+	log.Printf("scheduler: job %s failed (attempt %d), retrying in %s: %v", job.ID, job.Attempts+1, backoff, err)
+	_, uerr := s.jobs.UpdateOne(ctx, bson.M{"_id": job.ID, "run_at": job.RunAt}, bson.M{
+		"$set": bson.M{"run_at": time.Now().Add(backoff), "last_error": err.Error()},
+		"$inc": bson.M{"attempts": 1},
+	})
+	if uerr != nil {
+		log.Printf("scheduler: failed to reschedule job %s: %v", job.ID, uerr)
+	}
+}
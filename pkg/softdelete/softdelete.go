@@ -0,0 +1,117 @@
+// Package softdelete holds the filters and updates repositories use to
+// soft-delete records, and the job that purges them after a retention
+// period.
+package softdelete
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Fields set on a deleted record.
+const (
+	DeletedAtField = "deleted_at"
+	DeletedByField = "deleted_by"
+)
+
+// Active adds the condition that hides deleted records to filter.
+func Active(filter bson.M) bson.M {
+	if filter == nil {
+		filter = bson.M{}
+	}
+	filter[DeletedAtField] = bson.M{"$exists": false}
+	return filter
+}
+
+// Deleted adds the condition that matches only deleted records to filter.
+func Deleted(filter bson.M) bson.M {
+	if filter == nil {
+		filter = bson.M{}
+	}
+	filter[DeletedAtField] = bson.M{"$exists": true}
+	return filter
+}
+
+// MarkDeleted is the update that deletes a record on behalf of by.
+func MarkDeleted(by string, at time.Time) bson.M {
+	return bson.M{"$set": bson.M{DeletedAtField: at, DeletedByField: by}}
+}
+
+// Restore is the update that undeletes a record.
+func Restore() bson.M {
+	return bson.M{"$unset": bson.M{DeletedAtField: "", DeletedByField: ""}}
+}
+
+// Purge permanently removes the records of col deleted before before.
+func Purge(ctx context.Context, col *mongo.Collection, before time.Time) (int64, error) {
+	res, err := col.DeleteMany(ctx, bson.M{DeletedAtField: bson.M{"$lte": before}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// PurgeFunc purges the records deleted before before and returns how many.
+type PurgeFunc func(ctx context.Context, before time.Time) (int64, error)
+
+// Purger periodically purges records deleted longer than the retention
+// period ago. A zero retention keeps deleted records forever.
+type Purger struct {
+	Name      string // what is purged, for logging
+	Retention time.Duration
+	Interval  time.Duration
+	Purge     PurgeFunc
+}
+
+// Run purges every interval until ctx is cancelled.
+func (p *Purger) Run(ctx context.Context) error {
+	if p.Retention <= 0 || p.Interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := p.Purge(ctx, time.Now().Add(-p.Retention))
+		if err != nil {
+			log.Printf("purge of deleted %s failed: %v", p.Name, err)
+		} else if n > 0 {
+			log.Printf("purged %d deleted %s", n, p.Name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgerFromEnv configures a purger from SOFT_DELETE_RETENTION (e.g.
+// "2160h", empty disables purging) and PURGE_INTERVAL (default 24h).
+func PurgerFromEnv(name string, purge PurgeFunc) (*Purger, error) {
+	p := &Purger{Name: name, Interval: 24 * time.Hour, Purge: purge}
+
+	if v := os.Getenv("SOFT_DELETE_RETENTION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SOFT_DELETE_RETENTION: %w", err)
+		}
+		p.Retention = d
+	}
+	if v := os.Getenv("PURGE_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PURGE_INTERVAL: %w", err)
+		}
+		p.Interval = d
+	}
+	return p, nil
+}
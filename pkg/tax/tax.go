@@ -0,0 +1,197 @@
+package tax
+
+import (
+	"ecom-api/pkg/money"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Wildcard matches any region or any category in a rule.
+const Wildcard = "*"
+
+// DefaultCategory is used for lines that carry no tax category.
+const DefaultCategory = "standard"
+
+// Rule is a single row of the tax table.
+type Rule struct {
+	Region    string  `json:"region"`    // e.g. "US-CA", "DE" or "*"
+	Category  string  `json:"category"`  // product tax category or "*"
+	Rate      float64 `json:"rate"`      // fractional rate, 0.19 for 19%
+	Inclusive bool    `json:"inclusive"` // catalog prices already contain this tax
+}
+
+// Line is a priced line to be taxed.
+type Line struct {
+	Category  string
+	UnitPrice money.Money
+	Quantity  int
+}
+
+// LineTax is the tax breakdown of a single line.
+type LineTax struct {
+	Category  string      `json:"category"`
+	Rate      float64     `json:"rate"`
+	Inclusive bool        `json:"inclusive"`
+	Net       money.Money `json:"net"`   // line amount excluding tax
+	Tax       money.Money `json:"tax"`   // tax amount of the line
+	Gross     money.Money `json:"gross"` // line amount including tax
+}
+
+// Result is the outcome of taxing a set of lines for one region.
+type Result struct {
+	Region           string      `json:"region"`
+	PricesIncludeTax bool        `json:"prices_include_tax"`
+	Lines            []LineTax   `json:"lines"`
+	Subtotal         money.Money `json:"subtotal"` // sum of net amounts
+	TaxTotal         money.Money `json:"tax_total"`
+	Total            money.Money `json:"total"` // sum of gross amounts
+}
+
+// Table is a rule-table tax calculator keyed on region and category.
+type Table struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+}
+
+// NewTable builds a calculator from a list of rules.
+func NewTable(rules []Rule) *Table {
+	t := &Table{}
+	t.SetRules(rules)
+	return t
+}
+
+// LoadTable reads a JSON rule file of the form {"rules": [...]}.
+// An empty path yields an empty table, which taxes everything at zero.
+func LoadTable(path string) (*Table, error) {
+	if path == "" {
+		return NewTable(nil), nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tax rules %s: %w", path, err)
+	}
+
+	var file struct {
+		Rules []Rule `json:"rules"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse tax rules %s: %w", path, err)
+	}
+
+	for _, r := range file.Rules {
+		if r.Rate < 0 {
+			return nil, fmt.Errorf("tax rule %s/%s has negative rate", r.Region, r.Category)
+		}
+	}
+
+	return NewTable(file.Rules), nil
+}
+
+// SetRules replaces the rule set.
+func (t *Table) SetRules(rules []Rule) {
+	m := make(map[string]Rule, len(rules))
+	for _, r := range rules {
+		r.Region = normalize(r.Region)
+		r.Category = normalize(r.Category)
+		m[key(r.Region, r.Category)] = r
+	}
+
+	t.mu.Lock()
+	t.rules = m
+	t.mu.Unlock()
+}
+
+// Calculate taxes the given lines for a shipping region. Tax is rounded
+// per line to the currency's minor unit and all lines must share one
+// currency.
+func (t *Table) Calculate(region string, lines []Line) (*Result, error) {
+	region = strings.ToUpper(strings.TrimSpace(region))
+
+	res := &Result{Region: region, Lines: make([]LineTax, 0, len(lines))}
+	for _, l := range lines {
+		if l.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity must be greater than 0")
+		}
+
+		category := l.Category
+		if category == "" {
+			category = DefaultCategory
+		}
+
+		rule := t.lookup(region, normalize(category))
+		amount := l.UnitPrice.Mul(int64(l.Quantity))
+
+		lt := LineTax{Category: category, Rate: rule.Rate, Inclusive: rule.Inclusive}
+		if rule.Inclusive {
+			lt.Gross = amount
+			lt.Net = amount.DivRate(1 + rule.Rate)
+			lt.Tax = money.New(lt.Gross.Amount-lt.Net.Amount, amount.Currency)
+			res.PricesIncludeTax = true
+		} else {
+			lt.Net = amount
+			lt.Tax = amount.MulRate(rule.Rate)
+			lt.Gross = money.New(lt.Net.Amount+lt.Tax.Amount, amount.Currency)
+		}
+
+		var err error
+		if res.Subtotal, err = res.Subtotal.Add(lt.Net); err != nil {
+			return nil, err
+		}
+		if res.TaxTotal, err = res.TaxTotal.Add(lt.Tax); err != nil {
+			return nil, err
+		}
+		if res.Total, err = res.Total.Add(lt.Gross); err != nil {
+			return nil, err
+		}
+		res.Lines = append(res.Lines, lt)
+	}
+
+	return res, nil
+}
+
+// lookup finds the most specific rule for a region and category.
+// Regions are matched from the most to the least specific part,
+// so "US-CA" falls back to "US" and then to the wildcard.
+func (t *Table) lookup(region, category string) Rule {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, r := range regionChain(region) {
+		if rule, ok := t.rules[key(r, category)]; ok {
+			return rule
+		}
+		if rule, ok := t.rules[key(r, Wildcard)]; ok {
+			return rule
+		}
+	}
+	return Rule{Region: Wildcard, Category: Wildcard}
+}
+
+func regionChain(region string) []string {
+	var chain []string
+	for region != "" {
+		chain = append(chain, region)
+		i := strings.LastIndex(region, "-")
+		if i < 0 {
+			break
+		}
+		region = region[:i]
+	}
+	return append(chain, Wildcard)
+}
+
+func normalize(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Wildcard
+	}
+	return strings.ToUpper(s)
+}
+
+func key(region, category string) string {
+	return region + "|" + category
+}
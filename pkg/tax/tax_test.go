@@ -0,0 +1,102 @@
+package tax
+
+import (
+	"ecom-api/pkg/money"
+	"testing"
+)
+
+func TestCalculateRegionFallback(t *testing.T) {
+	table := NewTable([]Rule{
+		{Region: "*", Category: "*", Rate: 0.05},
+		{Region: "US", Category: "*", Rate: 0.06},
+		{Region: "US-CA", Category: "standard", Rate: 0.0725},
+		{Region: "US-CA", Category: "food", Rate: 0},
+		{Region: "DE", Category: "*", Rate: 0.19, Inclusive: true},
+		{Region: "DE", Category: "books", Rate: 0.07, Inclusive: true},
+		{Region: "*", Category: "digital", Rate: 0.2},
+	})
+
+	tests := []struct {
+		name      string
+		region    string
+		category  string
+		rate      float64
+		inclusive bool
+	}{
+		{name: "exact region and category", region: "US-CA", category: "standard", rate: 0.0725},
+		{name: "empty category is standard", region: "US-CA", category: "", rate: 0.0725},
+		{name: "category match wins over region wildcard", region: "us-ca", category: "food", rate: 0},
+		{name: "subregion falls back to country", region: "US-CA", category: "toys", rate: 0.06},
+		{name: "unknown state falls back to country", region: "US-NY", category: "standard", rate: 0.06},
+		{name: "country wildcard category", region: "DE", category: "standard", rate: 0.19, inclusive: true},
+		{name: "country category", region: "DE", category: "books", rate: 0.07, inclusive: true},
+		{name: "unknown region falls back to wildcard", region: "FR", category: "standard", rate: 0.05},
+		{name: "country wildcard beats global category", region: "US", category: "digital", rate: 0.06},
+		{name: "global category for unknown region", region: "FR", category: "digital", rate: 0.2},
+		{name: "empty region", region: "", category: "standard", rate: 0.05},
+	}
+
+	for _, tt := range tests {
+		res, err := table.Calculate(tt.region, []Line{{Category: tt.category, UnitPrice: money.New(1000, "USD"), Quantity: 1}})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		lt := res.Lines[0]
+		if lt.Rate != tt.rate || lt.Inclusive != tt.inclusive {
+			t.Errorf("%s: rate %v inclusive %v, want %v %v", tt.name, lt.Rate, lt.Inclusive, tt.rate, tt.inclusive)
+		}
+	}
+}
+
+func TestCalculateEmptyTable(t *testing.T) {
+	res, err := NewTable(nil).Calculate("US-CA", []Line{{UnitPrice: money.New(999, "USD"), Quantity: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TaxTotal.Amount != 0 || res.Total != money.New(1998, "USD") {
+		t.Errorf("got tax %v total %v, want no tax on 19.98 USD", res.TaxTotal, res.Total)
+	}
+}
+
+func TestCalculateAmounts(t *testing.T) {
+	table := NewTable([]Rule{
+		{Region: "US", Category: "*", Rate: 0.0725},
+		{Region: "DE", Category: "*", Rate: 0.19, Inclusive: true},
+	})
+
+	tests := []struct {
+		name            string
+		region          string
+		unit            money.Money
+		qty             int
+		net, tax, gross int64
+	}{
+		{name: "exclusive rounds half away from zero", region: "US", unit: money.New(1000, "USD"), qty: 3, net: 3000, tax: 218, gross: 3218},
+		{name: "inclusive splits the gross", region: "DE", unit: money.New(1190, "EUR"), qty: 1, net: 1000, tax: 190, gross: 1190},
+	}
+
+	for _, tt := range tests {
+		res, err := table.Calculate(tt.region, []Line{{UnitPrice: tt.unit, Quantity: tt.qty}})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if res.Subtotal.Amount != tt.net || res.TaxTotal.Amount != tt.tax || res.Total.Amount != tt.gross {
+			t.Errorf("%s: got %v/%v/%v, want %d/%d/%d", tt.name, res.Subtotal, res.TaxTotal, res.Total, tt.net, tt.tax, tt.gross)
+		}
+	}
+}
+
+func TestCalculateRejects(t *testing.T) {
+	table := NewTable(nil)
+	if _, err := table.Calculate("US", []Line{{UnitPrice: money.New(100, "USD"), Quantity: 0}}); err == nil {
+		t.Error("zero quantity was accepted")
+	}
+	if _, err := table.Calculate("US", []Line{
+		{UnitPrice: money.New(100, "USD"), Quantity: 1},
+		{UnitPrice: money.New(100, "EUR"), Quantity: 1},
+	}); err == nil {
+		t.Error("mixed currencies were accepted")
+	}
+}
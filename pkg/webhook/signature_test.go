@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"evt_1","type":"payment.succeeded"}`)
+	signedAt := time.Unix(1700000000, 0)
+	header := Sign("new", signedAt, body)
+	sig := header[strings.Index(header, "v1="):]
+
+	tests := []struct {
+		name    string
+		header  string
+		body    []byte
+		secrets []string
+		now     time.Time
+		err     error
+	}{
+		{name: "valid", header: header, body: body, secrets: []string{"new"}, now: signedAt},
+		{name: "within tolerance late", header: header, body: body, secrets: []string{"new"}, now: signedAt.Add(DefaultTolerance)},
+		{name: "within tolerance early", header: header, body: body, secrets: []string{"new"}, now: signedAt.Add(-DefaultTolerance)},
+		{name: "too old", header: header, body: body, secrets: []string{"new"}, now: signedAt.Add(DefaultTolerance + time.Second), err: ErrStaleTimestamp},
+		{name: "from the future", header: header, body: body, secrets: []string{"new"}, now: signedAt.Add(-DefaultTolerance - time.Second), err: ErrStaleTimestamp},
+		{name: "rotated, new secret second", header: header, body: body, secrets: []string{"old", "new"}, now: signedAt},
+		{name: "old secret only", header: header, body: body, secrets: []string{"old"}, now: signedAt, err: ErrBadSignature},
+		{name: "no secrets", header: header, body: body, now: signedAt, err: ErrBadSignature},
+		{name: "several signatures, one matching", header: "t=1700000000,v1=deadbeef," + sig, body: body, secrets: []string{"new"}, now: signedAt},
+		{name: "spaces around parts", header: "t=1700000000, " + sig, body: body, secrets: []string{"new"}, now: signedAt},
+		{name: "tampered body", header: header, body: []byte(`{"id":"evt_1","type":"payment.failed"}`), secrets: []string{"new"}, now: signedAt, err: ErrBadSignature},
+		{name: "timestamp swapped", header: "t=1700000001," + sig, body: body, secrets: []string{"new"}, now: signedAt, err: ErrBadSignature},
+		{name: "missing header", header: "", body: body, secrets: []string{"new"}, now: signedAt, err: ErrNoSignature},
+		{name: "no timestamp", header: sig, body: body, secrets: []string{"new"}, now: signedAt, err: ErrBadSignature},
+		{name: "bad timestamp", header: "t=yesterday," + sig, body: body, secrets: []string{"new"}, now: signedAt, err: ErrBadSignature},
+		{name: "no signature", header: "t=1700000000", body: body, secrets: []string{"new"}, now: signedAt, err: ErrBadSignature},
+		{name: "garbage", header: "not a signature", body: body, secrets: []string{"new"}, now: signedAt, err: ErrBadSignature},
+		{name: "unknown scheme only", header: "t=1700000000,v0=" + sig[3:], body: body, secrets: []string{"new"}, now: signedAt, err: ErrBadSignature},
+	}
+
+	for _, tt := range tests {
+		err := Verify(tt.header, tt.body, tt.secrets, DefaultTolerance, tt.now)
+		if tt.err == nil && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if tt.err != nil && !errors.Is(err, tt.err) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte("payload")
+	at := time.Unix(1700000000, 0)
+
+	got := Sign("secret", at, body)
+	if !strings.HasPrefix(got, "t=1700000000,v1=") || len(got) != len("t=1700000000,v1=")+64 {
+		t.Fatalf("Sign = %q, want t=<unix>,v1=<64 hex digits>", got)
+	}
+	if again := Sign("secret", at, body); again != got {
+		t.Errorf("Sign is not deterministic: %q then %q", got, again)
+	}
+	if other := Sign("other", at, body); other == got {
+		t.Error("different secrets produced the same signature")
+	}
+}
+
+func TestParseSecrets(t *testing.T) {
+	got, err := ParseSecrets(" sim=one, acme=two|three ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || len(got["sim"]) != 1 || got["sim"][0] != "one" || strings.Join(got["acme"], "|") != "two|three" {
+		t.Errorf("ParseSecrets = %v", got)
+	}
+
+	for _, bad := range []string{"sim", "=secret", "sim="} {
+		if _, err := ParseSecrets(bad); err == nil {
+			t.Errorf("ParseSecrets(%q) accepted a malformed entry", bad)
+		}
+	}
+}
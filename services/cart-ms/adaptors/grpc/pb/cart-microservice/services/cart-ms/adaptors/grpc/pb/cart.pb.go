@@ -7,6 +7,7 @@
 package pb
 
 import (
+	moneypb "ecom-api/pkg/money/moneypb"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -26,6 +27,7 @@ type AddItemRequest struct {
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	ProductId     string                 `protobuf:"bytes,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
 	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"` // cart currency, only honoured while the cart is empty
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -81,6 +83,13 @@ func (x *AddItemRequest) GetQuantity() int32 {
 	return 0
 }
 
+func (x *AddItemRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
 type AddItemResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
@@ -128,6 +137,7 @@ func (x *AddItemResponse) GetMessage() string {
 type GetCartRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Region        string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"` // shipping region used to price tax
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -169,12 +179,24 @@ func (x *GetCartRequest) GetUserId() string {
 	return ""
 }
 
+func (x *GetCartRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
 type GetCartResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Items         []*CartItem            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
-	Total         float64                `protobuf:"fixed64,2,opt,name=total,proto3" json:"total,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Items            []*CartItem            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Total            *moneypb.Money         `protobuf:"bytes,7,opt,name=total,proto3" json:"total,omitempty"`
+	Subtotal         *moneypb.Money         `protobuf:"bytes,8,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	TaxTotal         *moneypb.Money         `protobuf:"bytes,9,opt,name=tax_total,json=taxTotal,proto3" json:"tax_total,omitempty"`
+	Region           string                 `protobuf:"bytes,5,opt,name=region,proto3" json:"region,omitempty"`
+	PricesIncludeTax bool                   `protobuf:"varint,6,opt,name=prices_include_tax,json=pricesIncludeTax,proto3" json:"prices_include_tax,omitempty"`
+	Currency         string                 `protobuf:"bytes,10,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *GetCartResponse) Reset() {
@@ -214,18 +236,61 @@ func (x *GetCartResponse) GetItems() []*CartItem {
 	return nil
 }
 
-func (x *GetCartResponse) GetTotal() float64 {
+func (x *GetCartResponse) GetTotal() *moneypb.Money {
 	if x != nil {
 		return x.Total
 	}
-	return 0
+	return nil
+}
+
+func (x *GetCartResponse) GetSubtotal() *moneypb.Money {
+	if x != nil {
+		return x.Subtotal
+	}
+	return nil
+}
+
+func (x *GetCartResponse) GetTaxTotal() *moneypb.Money {
+	if x != nil {
+		return x.TaxTotal
+	}
+	return nil
+}
+
+func (x *GetCartResponse) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *GetCartResponse) GetPricesIncludeTax() bool {
+	if x != nil {
+		return x.PricesIncludeTax
+	}
+	return false
+}
+
+func (x *GetCartResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
 }
 
 type CartItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
 	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
-	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
+	Price         *moneypb.Money         `protobuf:"bytes,10,opt,name=price,proto3" json:"price,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	TaxCategory   string                 `protobuf:"bytes,5,opt,name=tax_category,json=taxCategory,proto3" json:"tax_category,omitempty"`
+	TaxRate       float64                `protobuf:"fixed64,6,opt,name=tax_rate,json=taxRate,proto3" json:"tax_rate,omitempty"`
+	Tax           *moneypb.Money         `protobuf:"bytes,11,opt,name=tax,proto3" json:"tax,omitempty"`
+	LineTotal     *moneypb.Money         `protobuf:"bytes,12,opt,name=line_total,json=lineTotal,proto3" json:"line_total,omitempty"`
+	Weight        float64                `protobuf:"fixed64,9,opt,name=weight,proto3" json:"weight,omitempty"`
+	PriceSource   string                 `protobuf:"bytes,13,opt,name=price_source,json=priceSource,proto3" json:"price_source,omitempty"`      // base, list or converted
+	ExchangeRate  float64                `protobuf:"fixed64,14,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"` // rate used to convert the price, 1 if not converted
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -274,10 +339,66 @@ func (x *CartItem) GetQuantity() int32 {
 	return 0
 }
 
-func (x *CartItem) GetPrice() float64 {
+func (x *CartItem) GetPrice() *moneypb.Money {
 	if x != nil {
 		return x.Price
 	}
+	return nil
+}
+
+func (x *CartItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CartItem) GetTaxCategory() string {
+	if x != nil {
+		return x.TaxCategory
+	}
+	return ""
+}
+
+func (x *CartItem) GetTaxRate() float64 {
+	if x != nil {
+		return x.TaxRate
+	}
+	return 0
+}
+
+func (x *CartItem) GetTax() *moneypb.Money {
+	if x != nil {
+		return x.Tax
+	}
+	return nil
+}
+
+func (x *CartItem) GetLineTotal() *moneypb.Money {
+	if x != nil {
+		return x.LineTotal
+	}
+	return nil
+}
+
+func (x *CartItem) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *CartItem) GetPriceSource() string {
+	if x != nil {
+		return x.PriceSource
+	}
+	return ""
+}
+
+func (x *CartItem) GetExchangeRate() float64 {
+	if x != nil {
+		return x.ExchangeRate
+	}
 	return 0
 }
 
@@ -380,6 +501,7 @@ func (x *RemoveFromCartResponse) GetMessage() string {
 type ClearCartRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CheckedOut    bool                   `protobuf:"varint,2,opt,name=checked_out,json=checkedOut,proto3" json:"checked_out,omitempty"` // the cart was turned into an order
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -421,6 +543,13 @@ func (x *ClearCartRequest) GetUserId() string {
 	return ""
 }
 
+func (x *ClearCartRequest) GetCheckedOut() bool {
+	if x != nil {
+		return x.CheckedOut
+	}
+	return false
+}
+
 type ClearCartResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
@@ -465,44 +594,390 @@ func (x *ClearCartResponse) GetMessage() string {
 	return ""
 }
 
+type Address struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Line1         string                 `protobuf:"bytes,2,opt,name=line1,proto3" json:"line1,omitempty"`
+	Line2         string                 `protobuf:"bytes,3,opt,name=line2,proto3" json:"line2,omitempty"`
+	City          string                 `protobuf:"bytes,4,opt,name=city,proto3" json:"city,omitempty"`
+	State         string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	PostalCode    string                 `protobuf:"bytes,6,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	Country       string                 `protobuf:"bytes,7,opt,name=country,proto3" json:"country,omitempty"` // ISO 3166-1 alpha-2
+	Phone         string                 `protobuf:"bytes,8,opt,name=phone,proto3" json:"phone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Address) Reset() {
+	*x = Address{}
+	mi := &file_cart_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Address) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Address) ProtoMessage() {}
+
+func (x *Address) ProtoReflect() protoreflect.Message {
+	mi := &file_cart_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Address.ProtoReflect.Descriptor instead.
+func (*Address) Descriptor() ([]byte, []int) {
+	return file_cart_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Address) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Address) GetLine1() string {
+	if x != nil {
+		return x.Line1
+	}
+	return ""
+}
+
+func (x *Address) GetLine2() string {
+	if x != nil {
+		return x.Line2
+	}
+	return ""
+}
+
+func (x *Address) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Address) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Address) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *Address) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Address) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+type ShippingQuote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MethodCode    string                 `protobuf:"bytes,1,opt,name=method_code,json=methodCode,proto3" json:"method_code,omitempty"`
+	MethodName    string                 `protobuf:"bytes,2,opt,name=method_name,json=methodName,proto3" json:"method_name,omitempty"`
+	Zone          string                 `protobuf:"bytes,3,opt,name=zone,proto3" json:"zone,omitempty"`
+	Cost          *moneypb.Money         `protobuf:"bytes,7,opt,name=cost,proto3" json:"cost,omitempty"`
+	MinDays       int32                  `protobuf:"varint,5,opt,name=min_days,json=minDays,proto3" json:"min_days,omitempty"`
+	MaxDays       int32                  `protobuf:"varint,6,opt,name=max_days,json=maxDays,proto3" json:"max_days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShippingQuote) Reset() {
+	*x = ShippingQuote{}
+	mi := &file_cart_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShippingQuote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShippingQuote) ProtoMessage() {}
+
+func (x *ShippingQuote) ProtoReflect() protoreflect.Message {
+	mi := &file_cart_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShippingQuote.ProtoReflect.Descriptor instead.
+func (*ShippingQuote) Descriptor() ([]byte, []int) {
+	return file_cart_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ShippingQuote) GetMethodCode() string {
+	if x != nil {
+		return x.MethodCode
+	}
+	return ""
+}
+
+func (x *ShippingQuote) GetMethodName() string {
+	if x != nil {
+		return x.MethodName
+	}
+	return ""
+}
+
+func (x *ShippingQuote) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *ShippingQuote) GetCost() *moneypb.Money {
+	if x != nil {
+		return x.Cost
+	}
+	return nil
+}
+
+func (x *ShippingQuote) GetMinDays() int32 {
+	if x != nil {
+		return x.MinDays
+	}
+	return 0
+}
+
+func (x *ShippingQuote) GetMaxDays() int32 {
+	if x != nil {
+		return x.MaxDays
+	}
+	return 0
+}
+
+// When user_id is set the user's cart weight and value are used,
+// otherwise weight and order_value are taken from the request.
+type GetShippingQuotesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Address       *Address               `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Weight        float64                `protobuf:"fixed64,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	OrderValue    *moneypb.Money         `protobuf:"bytes,5,opt,name=order_value,json=orderValue,proto3" json:"order_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetShippingQuotesRequest) Reset() {
+	*x = GetShippingQuotesRequest{}
+	mi := &file_cart_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetShippingQuotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetShippingQuotesRequest) ProtoMessage() {}
+
+func (x *GetShippingQuotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cart_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetShippingQuotesRequest.ProtoReflect.Descriptor instead.
+func (*GetShippingQuotesRequest) Descriptor() ([]byte, []int) {
+	return file_cart_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetShippingQuotesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetShippingQuotesRequest) GetAddress() *Address {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *GetShippingQuotesRequest) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *GetShippingQuotesRequest) GetOrderValue() *moneypb.Money {
+	if x != nil {
+		return x.OrderValue
+	}
+	return nil
+}
+
+type GetShippingQuotesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Quotes        []*ShippingQuote       `protobuf:"bytes,1,rep,name=quotes,proto3" json:"quotes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetShippingQuotesResponse) Reset() {
+	*x = GetShippingQuotesResponse{}
+	mi := &file_cart_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetShippingQuotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetShippingQuotesResponse) ProtoMessage() {}
+
+func (x *GetShippingQuotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cart_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetShippingQuotesResponse.ProtoReflect.Descriptor instead.
+func (*GetShippingQuotesResponse) Descriptor() ([]byte, []int) {
+	return file_cart_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetShippingQuotesResponse) GetQuotes() []*ShippingQuote {
+	if x != nil {
+		return x.Quotes
+	}
+	return nil
+}
+
 var File_cart_proto protoreflect.FileDescriptor
 
 const file_cart_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"cart.proto\x12\x04cart\"d\n" +
+	"cart.proto\x12\x04cart\x1a\vmoney.proto\"\x80\x01\n" +
 	"\x0eAddItemRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x02 \x01(\tR\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x03 \x01(\x05R\bquantity\"+\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\"+\n" +
 	"\x0fAddItemResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\")\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"A\n" +
 	"\x0eGetCartRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"M\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\"\xa4\x02\n" +
 	"\x0fGetCartResponse\x12$\n" +
-	"\x05items\x18\x01 \x03(\v2\x0e.cart.CartItemR\x05items\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x01R\x05total\"[\n" +
+	"\x05items\x18\x01 \x03(\v2\x0e.cart.CartItemR\x05items\x12\"\n" +
+	"\x05total\x18\a \x01(\v2\f.money.MoneyR\x05total\x12(\n" +
+	"\bsubtotal\x18\b \x01(\v2\f.money.MoneyR\bsubtotal\x12)\n" +
+	"\ttax_total\x18\t \x01(\v2\f.money.MoneyR\btaxTotal\x12\x16\n" +
+	"\x06region\x18\x05 \x01(\tR\x06region\x12,\n" +
+	"\x12prices_include_tax\x18\x06 \x01(\bR\x10pricesIncludeTax\x12\x1a\n" +
+	"\bcurrency\x18\n" +
+	" \x01(\tR\bcurrencyJ\x04\b\x02\x10\x03J\x04\b\x03\x10\x04J\x04\b\x04\x10\x05\"\xfa\x02\n" +
 	"\bCartItem\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x14\n" +
-	"\x05price\x18\x03 \x01(\x01R\x05price\"O\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\"\n" +
+	"\x05price\x18\n" +
+	" \x01(\v2\f.money.MoneyR\x05price\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12!\n" +
+	"\ftax_category\x18\x05 \x01(\tR\vtaxCategory\x12\x19\n" +
+	"\btax_rate\x18\x06 \x01(\x01R\ataxRate\x12\x1e\n" +
+	"\x03tax\x18\v \x01(\v2\f.money.MoneyR\x03tax\x12+\n" +
+	"\n" +
+	"line_total\x18\f \x01(\v2\f.money.MoneyR\tlineTotal\x12\x16\n" +
+	"\x06weight\x18\t \x01(\x01R\x06weight\x12!\n" +
+	"\fprice_source\x18\r \x01(\tR\vpriceSource\x12#\n" +
+	"\rexchange_rate\x18\x0e \x01(\x01R\fexchangeRateJ\x04\b\x03\x10\x04J\x04\b\a\x10\bJ\x04\b\b\x10\t\"O\n" +
 	"\x15RemoveFromCartRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x02 \x01(\tR\tproductId\"2\n" +
 	"\x16RemoveFromCartResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"+\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"L\n" +
 	"\x10ClearCartRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"-\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1f\n" +
+	"\vchecked_out\x18\x02 \x01(\bR\n" +
+	"checkedOut\"-\n" +
 	"\x11ClearCartResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage2\x88\x02\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\xc4\x01\n" +
+	"\aAddress\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05line1\x18\x02 \x01(\tR\x05line1\x12\x14\n" +
+	"\x05line2\x18\x03 \x01(\tR\x05line2\x12\x12\n" +
+	"\x04city\x18\x04 \x01(\tR\x04city\x12\x14\n" +
+	"\x05state\x18\x05 \x01(\tR\x05state\x12\x1f\n" +
+	"\vpostal_code\x18\x06 \x01(\tR\n" +
+	"postalCode\x12\x18\n" +
+	"\acountry\x18\a \x01(\tR\acountry\x12\x14\n" +
+	"\x05phone\x18\b \x01(\tR\x05phone\"\xc3\x01\n" +
+	"\rShippingQuote\x12\x1f\n" +
+	"\vmethod_code\x18\x01 \x01(\tR\n" +
+	"methodCode\x12\x1f\n" +
+	"\vmethod_name\x18\x02 \x01(\tR\n" +
+	"methodName\x12\x12\n" +
+	"\x04zone\x18\x03 \x01(\tR\x04zone\x12 \n" +
+	"\x04cost\x18\a \x01(\v2\f.money.MoneyR\x04cost\x12\x19\n" +
+	"\bmin_days\x18\x05 \x01(\x05R\aminDays\x12\x19\n" +
+	"\bmax_days\x18\x06 \x01(\x05R\amaxDaysJ\x04\b\x04\x10\x05\"\xa9\x01\n" +
+	"\x18GetShippingQuotesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12'\n" +
+	"\aaddress\x18\x02 \x01(\v2\r.cart.AddressR\aaddress\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x01R\x06weight\x12-\n" +
+	"\vorder_value\x18\x05 \x01(\v2\f.money.MoneyR\n" +
+	"orderValueJ\x04\b\x04\x10\x05\"H\n" +
+	"\x19GetShippingQuotesResponse\x12+\n" +
+	"\x06quotes\x18\x01 \x03(\v2\x13.cart.ShippingQuoteR\x06quotes2\xde\x02\n" +
 	"\vCartService\x126\n" +
 	"\aAddItem\x12\x14.cart.AddItemRequest\x1a\x15.cart.AddItemResponse\x126\n" +
 	"\aGetCart\x12\x14.cart.GetCartRequest\x1a\x15.cart.GetCartResponse\x12K\n" +
 	"\x0eRemoveFromCart\x12\x1b.cart.RemoveFromCartRequest\x1a\x1c.cart.RemoveFromCartResponse\x12<\n" +
-	"\tClearCart\x12\x16.cart.ClearCartRequest\x1a\x17.cart.ClearCartResponseB8Z6cart-microservice/services/cart-ms/adaptors/grpc/pb;pbb\x06proto3"
+	"\tClearCart\x12\x16.cart.ClearCartRequest\x1a\x17.cart.ClearCartResponse\x12T\n" +
+	"\x11GetShippingQuotes\x12\x1e.cart.GetShippingQuotesRequest\x1a\x1f.cart.GetShippingQuotesResponseB8Z6cart-microservice/services/cart-ms/adaptors/grpc/pb;pbb\x06proto3"
 
 var (
 	file_cart_proto_rawDescOnce sync.Once
@@ -516,33 +991,50 @@ func file_cart_proto_rawDescGZIP() []byte {
 	return file_cart_proto_rawDescData
 }
 
-var file_cart_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_cart_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_cart_proto_goTypes = []any{
-	(*AddItemRequest)(nil),         // 0: cart.AddItemRequest
-	(*AddItemResponse)(nil),        // 1: cart.AddItemResponse
-	(*GetCartRequest)(nil),         // 2: cart.GetCartRequest
-	(*GetCartResponse)(nil),        // 3: cart.GetCartResponse
-	(*CartItem)(nil),               // 4: cart.CartItem
-	(*RemoveFromCartRequest)(nil),  // 5: cart.RemoveFromCartRequest
-	(*RemoveFromCartResponse)(nil), // 6: cart.RemoveFromCartResponse
-	(*ClearCartRequest)(nil),       // 7: cart.ClearCartRequest
-	(*ClearCartResponse)(nil),      // 8: cart.ClearCartResponse
+	(*AddItemRequest)(nil),            // 0: cart.AddItemRequest
+	(*AddItemResponse)(nil),           // 1: cart.AddItemResponse
+	(*GetCartRequest)(nil),            // 2: cart.GetCartRequest
+	(*GetCartResponse)(nil),           // 3: cart.GetCartResponse
+	(*CartItem)(nil),                  // 4: cart.CartItem
+	(*RemoveFromCartRequest)(nil),     // 5: cart.RemoveFromCartRequest
+	(*RemoveFromCartResponse)(nil),    // 6: cart.RemoveFromCartResponse
+	(*ClearCartRequest)(nil),          // 7: cart.ClearCartRequest
+	(*ClearCartResponse)(nil),         // 8: cart.ClearCartResponse
+	(*Address)(nil),                   // 9: cart.Address
+	(*ShippingQuote)(nil),             // 10: cart.ShippingQuote
+	(*GetShippingQuotesRequest)(nil),  // 11: cart.GetShippingQuotesRequest
+	(*GetShippingQuotesResponse)(nil), // 12: cart.GetShippingQuotesResponse
+	(*moneypb.Money)(nil),             // 13: money.Money
 }
 var file_cart_proto_depIdxs = []int32{
-	4, // 0: cart.GetCartResponse.items:type_name -> cart.CartItem
-	0, // 1: cart.CartService.AddItem:input_type -> cart.AddItemRequest
-	2, // 2: cart.CartService.GetCart:input_type -> cart.GetCartRequest
-	5, // 3: cart.CartService.RemoveFromCart:input_type -> cart.RemoveFromCartRequest
-	7, // 4: cart.CartService.ClearCart:input_type -> cart.ClearCartRequest
-	1, // 5: cart.CartService.AddItem:output_type -> cart.AddItemResponse
-	3, // 6: cart.CartService.GetCart:output_type -> cart.GetCartResponse
-	6, // 7: cart.CartService.RemoveFromCart:output_type -> cart.RemoveFromCartResponse
-	8, // 8: cart.CartService.ClearCart:output_type -> cart.ClearCartResponse
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	4,  // 0: cart.GetCartResponse.items:type_name -> cart.CartItem
+	13, // 1: cart.GetCartResponse.total:type_name -> money.Money
+	13, // 2: cart.GetCartResponse.subtotal:type_name -> money.Money
+	13, // 3: cart.GetCartResponse.tax_total:type_name -> money.Money
+	13, // 4: cart.CartItem.price:type_name -> money.Money
+	13, // 5: cart.CartItem.tax:type_name -> money.Money
+	13, // 6: cart.CartItem.line_total:type_name -> money.Money
+	13, // 7: cart.ShippingQuote.cost:type_name -> money.Money
+	9,  // 8: cart.GetShippingQuotesRequest.address:type_name -> cart.Address
+	13, // 9: cart.GetShippingQuotesRequest.order_value:type_name -> money.Money
+	10, // 10: cart.GetShippingQuotesResponse.quotes:type_name -> cart.ShippingQuote
+	0,  // 11: cart.CartService.AddItem:input_type -> cart.AddItemRequest
+	2,  // 12: cart.CartService.GetCart:input_type -> cart.GetCartRequest
+	5,  // 13: cart.CartService.RemoveFromCart:input_type -> cart.RemoveFromCartRequest
+	7,  // 14: cart.CartService.ClearCart:input_type -> cart.ClearCartRequest
+	11, // 15: cart.CartService.GetShippingQuotes:input_type -> cart.GetShippingQuotesRequest
+	1,  // 16: cart.CartService.AddItem:output_type -> cart.AddItemResponse
+	3,  // 17: cart.CartService.GetCart:output_type -> cart.GetCartResponse
+	6,  // 18: cart.CartService.RemoveFromCart:output_type -> cart.RemoveFromCartResponse
+	8,  // 19: cart.CartService.ClearCart:output_type -> cart.ClearCartResponse
+	12, // 20: cart.CartService.GetShippingQuotes:output_type -> cart.GetShippingQuotesResponse
+	16, // [16:21] is the sub-list for method output_type
+	11, // [11:16] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_cart_proto_init() }
@@ -556,7 +1048,7 @@ func file_cart_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cart_proto_rawDesc), len(file_cart_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
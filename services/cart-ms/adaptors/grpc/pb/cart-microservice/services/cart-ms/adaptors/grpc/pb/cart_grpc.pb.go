@@ -19,10 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CartService_AddItem_FullMethodName        = "/cart.CartService/AddItem"
-	CartService_GetCart_FullMethodName        = "/cart.CartService/GetCart"
-	CartService_RemoveFromCart_FullMethodName = "/cart.CartService/RemoveFromCart"
-	CartService_ClearCart_FullMethodName      = "/cart.CartService/ClearCart"
+	CartService_AddItem_FullMethodName           = "/cart.CartService/AddItem"
+	CartService_GetCart_FullMethodName           = "/cart.CartService/GetCart"
+	CartService_RemoveFromCart_FullMethodName    = "/cart.CartService/RemoveFromCart"
+	CartService_ClearCart_FullMethodName         = "/cart.CartService/ClearCart"
+	CartService_GetShippingQuotes_FullMethodName = "/cart.CartService/GetShippingQuotes"
 )
 
 // CartServiceClient is the client API for CartService service.
@@ -33,6 +34,7 @@ type CartServiceClient interface {
 	GetCart(ctx context.Context, in *GetCartRequest, opts ...grpc.CallOption) (*GetCartResponse, error)
 	RemoveFromCart(ctx context.Context, in *RemoveFromCartRequest, opts ...grpc.CallOption) (*RemoveFromCartResponse, error)
 	ClearCart(ctx context.Context, in *ClearCartRequest, opts ...grpc.CallOption) (*ClearCartResponse, error)
+	GetShippingQuotes(ctx context.Context, in *GetShippingQuotesRequest, opts ...grpc.CallOption) (*GetShippingQuotesResponse, error)
 }
 
 type cartServiceClient struct {
@@ -83,6 +85,16 @@ func (c *cartServiceClient) ClearCart(ctx context.Context, in *ClearCartRequest,
 	return out, nil
 }
 
+func (c *cartServiceClient) GetShippingQuotes(ctx context.Context, in *GetShippingQuotesRequest, opts ...grpc.CallOption) (*GetShippingQuotesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetShippingQuotesResponse)
+	err := c.cc.Invoke(ctx, CartService_GetShippingQuotes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CartServiceServer is the server API for CartService service.
 // All implementations must embed UnimplementedCartServiceServer
 // for forward compatibility.
@@ -91,6 +103,7 @@ type CartServiceServer interface {
 	GetCart(context.Context, *GetCartRequest) (*GetCartResponse, error)
 	RemoveFromCart(context.Context, *RemoveFromCartRequest) (*RemoveFromCartResponse, error)
 	ClearCart(context.Context, *ClearCartRequest) (*ClearCartResponse, error)
+	GetShippingQuotes(context.Context, *GetShippingQuotesRequest) (*GetShippingQuotesResponse, error)
 	mustEmbedUnimplementedCartServiceServer()
 }
 
@@ -113,6 +126,9 @@ func (UnimplementedCartServiceServer) RemoveFromCart(context.Context, *RemoveFro
 func (UnimplementedCartServiceServer) ClearCart(context.Context, *ClearCartRequest) (*ClearCartResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ClearCart not implemented")
 }
+func (UnimplementedCartServiceServer) GetShippingQuotes(context.Context, *GetShippingQuotesRequest) (*GetShippingQuotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShippingQuotes not implemented")
+}
 func (UnimplementedCartServiceServer) mustEmbedUnimplementedCartServiceServer() {}
 func (UnimplementedCartServiceServer) testEmbeddedByValue()                     {}
 
@@ -206,6 +222,24 @@ func _CartService_ClearCart_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CartService_GetShippingQuotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetShippingQuotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServiceServer).GetShippingQuotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CartService_GetShippingQuotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServiceServer).GetShippingQuotes(ctx, req.(*GetShippingQuotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CartService_ServiceDesc is the grpc.ServiceDesc for CartService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,6 +263,10 @@ var CartService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ClearCart",
 			Handler:    _CartService_ClearCart_Handler,
 		},
+		{
+			MethodName: "GetShippingQuotes",
+			Handler:    _CartService_GetShippingQuotes_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "cart.proto",
@@ -3,18 +3,23 @@ package main
 import (
 	"context"
 	"ecom-api/pkg/auth"
+	"ecom-api/pkg/events"
+	"ecom-api/pkg/money"
+	"ecom-api/pkg/tax"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"time"
 
 	"cart-microservice/adaptors/grpc/pb/cart-microservice/services/cart-ms/adaptors/grpc/pb"
 	"cart-microservice/internal/adaptors/db"
 	grpcAdapter "cart-microservice/internal/adaptors/grpc"
 	httpAdapter "cart-microservice/internal/adaptors/http"
+	"cart-microservice/internal/adaptors/shipping"
 	"cart-microservice/internal/application"
 
 	"github.com/joho/godotenv"
@@ -66,7 +71,7 @@ auth.InitJWT()
 	}
 
 	// --- MongoDB ---
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetRegistry(money.Registry(os.Getenv("DEFAULT_CURRENCY"))))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -88,15 +93,59 @@ auth.InitJWT()
 
 	productClient := grpcAdapter.NewProductClient(productConn)
 
+	// --- tax rules ---
+	taxTable, err := tax.LoadTable(os.Getenv("TAX_RULES_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// --- shipping rates ---
+	rateTable, err := shipping.LoadRateTable(os.Getenv("SHIPPING_RATES_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// --- abandoned cart reminders ---
+	thresholds, err := parseDurations(os.Getenv("CART_ABANDON_THRESHOLDS"))
+	if err != nil {
+		log.Fatalf("invalid CART_ABANDON_THRESHOLDS: %v", err)
+	}
+	scanInterval := 5 * time.Minute
+	if v := os.Getenv("CART_ABANDON_SCAN_INTERVAL"); v != "" {
+		if scanInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid CART_ABANDON_SCAN_INTERVAL: %v", err)
+		}
+	}
+
+	// --- cart expiry ---
+	var cartTTL time.Duration
+	if v := os.Getenv("CART_TTL"); v != "" {
+		if cartTTL, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid CART_TTL: %v", err)
+		}
+	}
+	expiryInterval := 10 * time.Minute
+	if v := os.Getenv("CART_EXPIRY_INTERVAL"); v != "" {
+		if expiryInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid CART_EXPIRY_INTERVAL: %v", err)
+		}
+	}
+
 	// --- wiring ---
+	publisher := events.NewMongoOutbox(dbConn)
 	repo := db.NewMongoCartRepo(dbConn)
-	service := application.NewCartService(repo, productClient)
+	service := application.NewCartService(repo, productClient, taxTable, rateTable, publisher, os.Getenv("DEFAULT_CURRENCY"))
+	scanner := application.NewAbandonedCartScanner(repo, publisher, thresholds, scanInterval)
+	expirer := application.NewCartExpirer(repo, productClient, cartTTL, expiryInterval)
+	wishlistRepo := db.NewMongoWishlistRepo(dbConn)
+	wishlistService := application.NewWishlistService(wishlistRepo, repo, service, productClient)
 
 	// HTTP server
 	handler := httpAdapter.NewCartHandler(service)
+	wishlistHandler := httpAdapter.NewWishlistHandler(wishlistService)
 	httpServer := &http.Server{
 		Addr:    httpPort,
-		Handler: httpAdapter.NewRouter(handler),
+		Handler: httpAdapter.NewRouter(handler, wishlistHandler),
 	}
 
 	// gRPC server
@@ -110,6 +159,8 @@ auth.InitJWT()
 	}
 
 	g := new(errgroup.Group)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
 
 	// HTTP
 	g.Go(func() error {
@@ -123,6 +174,16 @@ auth.InitJWT()
 		return grpcServer.Serve(lis)
 	})
 
+	// abandoned cart scanner
+	g.Go(func() error {
+		return scanner.Run(workerCtx)
+	})
+
+	// cart expiry
+	g.Go(func() error {
+		return expirer.Run(workerCtx)
+	})
+
 	// --- Graceful shutdown ---
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, os.Kill)
@@ -130,6 +191,7 @@ auth.InitJWT()
 	go func() {
 		<-stop
 		fmt.Println("\n🛑 Shutting down Cart service...")
+		stopWorkers()
 
 		// shutdown HTTP
 		ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -147,3 +209,23 @@ auth.InitJWT()
 		log.Fatal(err)
 	}
 }
+
+// parseDurations reads a comma-separated list such as "1h,24h,72h".
+func parseDurations(v string) ([]time.Duration, error) {
+	var out []time.Duration
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("duration %q must be positive", part)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
@@ -7,17 +7,20 @@ import (
 	"cart-microservice/internal/domain"
 	"cart-microservice/internal/ports"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MongoCartRepo struct {
-	col *mongo.Collection
+	col     *mongo.Collection
+	expired *mongo.Collection
 }
 
 func NewMongoCartRepo(db *mongo.Database) ports.CartRepository {
 	return &MongoCartRepo{
-		col: db.Collection("carts"),
+		col:     db.Collection("carts"),
+		expired: db.Collection("expired_carts"),
 	}
 }
 
@@ -30,12 +33,12 @@ func (r *MongoCartRepo) GetCart(userID string) (*domain.Cart, error) {
 	return &cart, err
 }
 
-func (r *MongoCartRepo) AddItem(userID string, item domain.CartItem) error {
+func (r *MongoCartRepo) AddItem(userID, currency string, item domain.CartItem) error {
 	item.AddedAt = time.Now()
 	filter := bson.M{"user_id": userID}
 	update := bson.M{
 		"$push": bson.M{"items": item},
-		"$set":  bson.M{"updated_at": time.Now()},
+		"$set":  bson.M{"updated_at": time.Now(), "currency": currency, "abandon_stage": 0},
 	}
 	opts := options.Update().SetUpsert(true)
 	_, err := r.col.UpdateOne(context.TODO(), filter, update, opts)
@@ -46,7 +49,7 @@ func (r *MongoCartRepo) RemoveItem(userID, productID string) error {
 	filter := bson.M{"user_id": userID}
 	update := bson.M{
 		"$pull": bson.M{"items": bson.M{"product_id": productID}},
-		"$set":  bson.M{"updated_at": time.Now()},
+		"$set":  bson.M{"updated_at": time.Now(), "abandon_stage": 0},
 	}
 	_, err := r.col.UpdateOne(context.TODO(), filter, update)
 	return err
@@ -56,3 +59,122 @@ func (r *MongoCartRepo) ClearCart(userID string) error {
 	_, err := r.col.DeleteOne(context.TODO(), bson.M{"user_id": userID})
 	return err
 }
+
+func (r *MongoCartRepo) FindIdle(ctx context.Context, stage int, idleSince, remindedBefore time.Time, limit int64) ([]domain.Cart, error) {
+	filter := bson.M{
+		"abandon_stage": stageFilter(stage),
+		"updated_at":    bson.M{"$lte": idleSince},
+		"items.0":       bson.M{"$exists": true},
+	}
+	if !remindedBefore.IsZero() {
+		filter["last_reminded_at"] = bson.M{"$lte": remindedBefore}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}}).SetLimit(limit)
+
+	cursor, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var carts []domain.Cart
+	for cursor.Next(ctx) {
+		var c domain.Cart
+		if err := cursor.Decode(&c); err != nil {
+			return nil, err
+		}
+		carts = append(carts, c)
+	}
+	return carts, cursor.Err()
+}
+
+func (r *MongoCartRepo) AdvanceAbandonStage(ctx context.Context, cart *domain.Cart, at time.Time) (bool, error) {
+	filter := bson.M{
+		"user_id":       cart.UserID,
+		"abandon_stage": stageFilter(cart.AbandonStage),
+		"updated_at":    cart.UpdatedAt,
+	}
+	set := bson.M{"abandon_stage": cart.AbandonStage + 1, "last_reminded_at": at}
+	if cart.AbandonStage+1 > cart.ReminderStageReached {
+		set["reminder_stage_reached"] = cart.AbandonStage + 1
+	}
+
+	res, err := r.col.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *MongoCartRepo) RevertAbandonStage(ctx context.Context, cart *domain.Cart) error {
+	set := bson.M{
+		"abandon_stage":          cart.AbandonStage,
+		"reminder_stage_reached": cart.ReminderStageReached,
+	}
+	update := bson.M{"$set": set}
+	if cart.LastRemindedAt != nil {
+		set["last_reminded_at"] = cart.LastRemindedAt
+	} else {
+		update["$unset"] = bson.M{"last_reminded_at": ""}
+	}
+
+	_, err := r.col.UpdateOne(ctx, bson.M{"user_id": cart.UserID, "abandon_stage": cart.AbandonStage + 1}, update)
+	return err
+}
+
+func (r *MongoCartRepo) FindExpired(ctx context.Context, before time.Time, limit int64) ([]domain.Cart, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}}).SetLimit(limit)
+
+	cursor, err := r.col.Find(ctx, bson.M{"updated_at": bson.M{"$lte": before}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var carts []domain.Cart
+	for cursor.Next(ctx) {
+		var c domain.Cart
+		if err := cursor.Decode(&c); err != nil {
+			return nil, err
+		}
+		carts = append(carts, c)
+	}
+	return carts, cursor.Err()
+}
+
+func (r *MongoCartRepo) ExpireCart(ctx context.Context, archive *domain.ExpiredCart) (bool, error) {
+	// archive first so an expired cart is never lost; the copy is dropped
+	// again if the cart changed after it was picked up
+	objID := primitive.NewObjectID()
+	if _, err := r.expired.InsertOne(ctx, bson.M{
+		"_id":        objID,
+		"user_id":    archive.UserID,
+		"cart":       archive.Cart,
+		"item_count": archive.ItemCount,
+		"subtotal":   archive.Subtotal,
+		"idle_for":   archive.IdleFor,
+		"expired_at": archive.ExpiredAt,
+	}); err != nil {
+		return false, err
+	}
+	archive.ID = objID.Hex()
+
+	res, err := r.col.DeleteOne(ctx, bson.M{"user_id": archive.UserID, "updated_at": archive.Cart.UpdatedAt})
+	if err == nil && res.DeletedCount == 1 {
+		return true, nil
+	}
+
+	if _, derr := r.expired.DeleteOne(ctx, bson.M{"_id": objID}); derr != nil && err == nil {
+		err = derr
+	}
+	return false, err
+}
+
+// stageFilter matches a stage; carts saved before abandonment tracking
+// existed have no stage and count as stage 0.
+func stageFilter(stage int) interface{} {
+	if stage == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return stage
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cart-microservice/internal/domain"
+	"cart-microservice/internal/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoWishlistRepo struct {
+	col *mongo.Collection
+}
+
+func NewMongoWishlistRepo(db *mongo.Database) ports.WishlistRepository {
+	return &MongoWishlistRepo{
+		col: db.Collection("wishlists"),
+	}
+}
+
+func (r *MongoWishlistRepo) Create(ctx context.Context, w *domain.Wishlist) (*domain.Wishlist, error) {
+	objID := primitive.NewObjectID()
+
+	_, err := r.col.InsertOne(ctx, bson.M{
+		"_id":         objID,
+		"user_id":     w.UserID,
+		"name":        w.Name,
+		"kind":        w.Kind,
+		"items":       w.Items,
+		"share_token": w.ShareToken,
+		"created_at":  w.CreatedAt,
+		"updated_at":  w.UpdatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w.ID = objID.Hex()
+	return w, nil
+}
+
+func (r *MongoWishlistRepo) FindByID(ctx context.Context, id string) (*domain.Wishlist, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid id", domain.ErrWishlistNotFound)
+	}
+	return r.findOne(ctx, bson.M{"_id": objID})
+}
+
+func (r *MongoWishlistRepo) FindByUser(ctx context.Context, userID string) ([]domain.Wishlist, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.col.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	lists := []domain.Wishlist{}
+	for cursor.Next(ctx) {
+		var w domain.Wishlist
+		if err := cursor.Decode(&w); err != nil {
+			return nil, err
+		}
+		lists = append(lists, w)
+	}
+	return lists, cursor.Err()
+}
+
+func (r *MongoWishlistRepo) FindByKind(ctx context.Context, userID, kind string) (*domain.Wishlist, error) {
+	return r.findOne(ctx, bson.M{"user_id": userID, "kind": kind})
+}
+
+func (r *MongoWishlistRepo) FindByShareToken(ctx context.Context, token string) (*domain.Wishlist, error) {
+	if token == "" {
+		return nil, domain.ErrWishlistNotFound
+	}
+	return r.findOne(ctx, bson.M{"share_token": token})
+}
+
+func (r *MongoWishlistRepo) Update(ctx context.Context, w *domain.Wishlist) error {
+	objID, err := primitive.ObjectIDFromHex(w.ID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid id", domain.ErrWishlistNotFound)
+	}
+
+	res, err := r.col.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{
+		"$set": bson.M{
+			"name":        w.Name,
+			"items":       w.Items,
+			"share_token": w.ShareToken,
+			"updated_at":  w.UpdatedAt,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrWishlistNotFound
+	}
+	return nil
+}
+
+func (r *MongoWishlistRepo) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid id", domain.ErrWishlistNotFound)
+	}
+	_, err = r.col.DeleteOne(ctx, bson.M{"_id": objID})
+	return err
+}
+
+func (r *MongoWishlistRepo) findOne(ctx context.Context, filter bson.M) (*domain.Wishlist, error) {
+	var w domain.Wishlist
+	err := r.col.FindOne(ctx, filter).Decode(&w)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrWishlistNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if w.Items == nil {
+		w.Items = []domain.WishlistItem{}
+	}
+	return &w, nil
+}
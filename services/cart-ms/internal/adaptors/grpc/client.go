@@ -19,11 +19,35 @@ func NewProductClient(conn *grpc.ClientConn ) *ProdctClient {
   }
 }
 
-func (c *ProdctClient) GetProduct(id string) (*pb.GetProductResponse, error) {
-	res, err := c.client.GetProduct(context.Background(), &pb.GetProductRequest{Id: id})
+// GetProduct fetches a product with its price quoted in currency.
+func (c *ProdctClient) GetProduct(id, currency string) (*pb.GetProductResponse, error) {
+	res, err := c.client.GetProduct(context.Background(), &pb.GetProductRequest{Id: id, Currency: currency})
 	if err != nil {
 		return  nil, err
 	}
 
 	return res, nil
-}
\ No newline at end of file
+}
+
+// GetExchangeRate returns the rate in force for converting base into quote.
+func (c *ProdctClient) GetExchangeRate(base, quote string) (*pb.GetExchangeRateResponse, error) {
+	return c.client.GetExchangeRate(context.Background(), &pb.GetExchangeRateRequest{Base: base, Quote: quote})
+}
+
+// ReserveStock sets the quantity of a product held for holder.
+func (c *ProdctClient) ReserveStock(holder, productID string, quantity int) (*pb.ReserveStockResponse, error) {
+	return c.client.ReserveStock(context.Background(), &pb.ReserveStockRequest{Holder: holder, ProductId: productID, Quantity: int32(quantity)})
+}
+
+// ReleaseStock frees the holder's reservation of a product, or all of
+// them when productID is empty.
+func (c *ProdctClient) ReleaseStock(holder, productID string) error {
+	_, err := c.client.ReleaseStock(context.Background(), &pb.ReleaseStockRequest{Holder: holder, ProductId: productID})
+	return err
+}
+
+// CommitStock takes the holder's reserved units off the stock.
+func (c *ProdctClient) CommitStock(holder string) error {
+	_, err := c.client.CommitStock(context.Background(), &pb.CommitStockRequest{Holder: holder})
+	return err
+}
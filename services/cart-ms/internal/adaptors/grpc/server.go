@@ -6,6 +6,7 @@ import (
 	"cart-microservice/internal/domain"
 	"cart-microservice/internal/ports"
 	"context"
+	"ecom-api/pkg/money"
 	//"product-microservice/adaptors/grpc/pb/product-microservice/services/product-ms/adaptors/grpc/pb"
 )
 
@@ -25,7 +26,7 @@ func (s *CartGrpcServer) AddItem(ctx context.Context, req *pb.AddItemRequest)(*p
    }
 
    //call service to add item
-   err := s.service.AddItem(req.GetUserId(), &item)
+   err := s.service.AddItem(req.GetUserId(), req.GetCurrency(), &item)
    if err != nil {
 	return  nil, err
    }
@@ -36,7 +37,7 @@ func (s *CartGrpcServer) AddItem(ctx context.Context, req *pb.AddItemRequest)(*p
 }
 
 func (s *CartGrpcServer) GetCart(ctx context.Context, req *pb.GetCartRequest) (*pb.GetCartResponse, error) {
-	cart, err := s.service.GetCart(req.GetUserId())
+	cart, err := s.service.GetCart(req.GetUserId(), req.GetRegion())
 	if err != nil {
 		return nil, err
 	}
@@ -44,15 +45,28 @@ func (s *CartGrpcServer) GetCart(ctx context.Context, req *pb.GetCartRequest) (*
 	var pbItems []*pb.CartItem
 	for _, item := range cart.Items {
 		pbItems = append(pbItems, &pb.CartItem{
-			ProductId: item.ProductID,
-			Quantity:  int32(item.Quantity),
-			Price:     item.Price,
+			ProductId:   item.ProductID,
+			Quantity:    int32(item.Quantity),
+			Price:       money.ToProto(item.Price),
+			Name:        item.Name,
+			TaxCategory: item.TaxCategory,
+			TaxRate:     item.TaxRate,
+			Tax:         money.ToProto(item.Tax),
+			LineTotal:   money.ToProto(item.LineTotal),
+			Weight:      item.Weight,
+			PriceSource:  item.PriceSource,
+			ExchangeRate: item.ExchangeRate,
 		})
 	}
 
 	return &pb.GetCartResponse{
-		Items: pbItems,
-		Total: cart.Total,
+		Items:            pbItems,
+		Total:            money.ToProto(cart.Total),
+		Subtotal:         money.ToProto(cart.Subtotal),
+		TaxTotal:         money.ToProto(cart.TaxTotal),
+		Region:           cart.Region,
+		PricesIncludeTax: cart.PricesIncludeTax,
+		Currency:         cart.Currency,
 	}, nil
 }
 
@@ -65,10 +79,54 @@ func (s *CartGrpcServer) RemoveFromCart(ctx context.Context, req *pb.RemoveFromC
 }
 
 func (s *CartGrpcServer) ClearCart(ctx context.Context, req *pb.ClearCartRequest) (*pb.ClearCartResponse, error) {
-	if err := s.service.ClearCart(req.GetUserId()); err != nil {
+	clearCart := s.service.ClearCart
+	if req.GetCheckedOut() {
+		clearCart = func(userID string) error { return s.service.CheckoutCart(ctx, userID) }
+	}
+	if err := clearCart(req.GetUserId()); err != nil {
 		return nil, err
 	}
 
 	return &pb.ClearCartResponse{Message: "Cart cleared successfully"}, nil
 }
 
+func (s *CartGrpcServer) GetShippingQuotes(ctx context.Context, req *pb.GetShippingQuotesRequest) (*pb.GetShippingQuotesResponse, error) {
+	dest := addressFromProto(req.GetAddress())
+
+	var quotes []domain.ShippingQuote
+	var err error
+	if req.GetUserId() != "" {
+		quotes, err = s.service.GetShippingQuotes(req.GetUserId(), dest)
+	} else {
+		quotes, err = s.service.QuoteShipping(dest, req.GetWeight(), money.FromProto(req.GetOrderValue()))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetShippingQuotesResponse{}
+	for _, q := range quotes {
+		resp.Quotes = append(resp.Quotes, &pb.ShippingQuote{
+			MethodCode: q.MethodCode,
+			MethodName: q.MethodName,
+			Zone:       q.Zone,
+			Cost:       money.ToProto(q.Cost),
+			MinDays:    int32(q.MinDays),
+			MaxDays:    int32(q.MaxDays),
+		})
+	}
+	return resp, nil
+}
+
+func addressFromProto(a *pb.Address) domain.Address {
+	return domain.Address{
+		Name:       a.GetName(),
+		Line1:      a.GetLine1(),
+		Line2:      a.GetLine2(),
+		City:       a.GetCity(),
+		State:      a.GetState(),
+		PostalCode: a.GetPostalCode(),
+		Country:    a.GetCountry(),
+		Phone:      a.GetPhone(),
+	}
+}
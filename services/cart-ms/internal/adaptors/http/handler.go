@@ -2,7 +2,9 @@ package http
 
 import (
 	"ecom-api/pkg/middleware"
+	"ecom-api/pkg/money"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -27,6 +29,7 @@ type CartHandler struct {
 type AddItemRequest struct {
     ProductID string `json:"product_id" validate:"required"`
     Quantity  int    `json:"quantity" validate:"required,min=1"`
+    Currency  string `json:"currency,omitempty" example:"EUR"` // fixes the cart currency when the cart is empty
 }
 
 
@@ -35,10 +38,11 @@ func NewCartHandler(service ports.CartService) *CartHandler {
 }
 
 // @Summary      Get Cart
-// @Description  Get the authenticated user's cart
+// @Description  Get the authenticated user's cart priced with tax for a shipping region
 // @Tags         Cart
 // @Produce      json
 // @Security     BearerAuth
+// @Param        region  query     string  false  "Shipping region, e.g. US-CA or DE"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
@@ -50,48 +54,67 @@ func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cart, err := h.service.GetCart(userID)
+	cart, err := h.service.GetCart(userID, r.URL.Query().Get("region"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	type CartItemResponse struct {
-		ProductID string  `json:"productId"`
-		Name      string  `json:"name"`
-		Price     float64 `json:"price"`
-		Quantity  int     `json:"quantity"`
-		Subtotal  float64 `json:"subtotal"`
-		AddedAt   string  `json:"addedAt"`
+		ProductID   string  `json:"productId"`
+		Name        string  `json:"name"`
+		Price       money.Money `json:"price"`
+		Quantity    int         `json:"quantity"`
+		Subtotal    money.Money `json:"subtotal"`
+		TaxCategory string      `json:"taxCategory"`
+		TaxRate     float64     `json:"taxRate"`
+		Tax         money.Money `json:"tax"`
+		LineTotal   money.Money `json:"lineTotal"`
+		PriceSource  string     `json:"priceSource"`
+		ExchangeRate float64    `json:"exchangeRate"`
+		AddedAt     string      `json:"addedAt"`
 	}
 
 	type CartResponse struct {
-		UserID    string             `json:"userId"`
-		Items     []CartItemResponse `json:"items"`
-		Total     float64            `json:"total"`
-		UpdatedAt string             `json:"updatedAt"`
+		UserID           string             `json:"userId"`
+		Items            []CartItemResponse `json:"items"`
+		Currency         string             `json:"currency"`
+		Region           string             `json:"region"`
+		PricesIncludeTax bool               `json:"pricesIncludeTax"`
+		Subtotal         money.Money        `json:"subtotal"`
+		TaxTotal         money.Money        `json:"taxTotal"`
+		Total            money.Money        `json:"total"`
+		UpdatedAt        string             `json:"updatedAt"`
 	}
 
 	var items []CartItemResponse
-	var total float64
 	for _, it := range cart.Items {
-		subtotal := it.Price * float64(it.Quantity)
 		items = append(items, CartItemResponse{
-			ProductID: it.ProductID,
-			Name:      it.Name,
-			Price:     it.Price,
-			Quantity:  it.Quantity,
-			Subtotal:  subtotal,
-			AddedAt:   it.AddedAt.Format("2006-01-02 15:04"),
+			ProductID:   it.ProductID,
+			Name:        it.Name,
+			Price:       it.Price,
+			Quantity:    it.Quantity,
+			Subtotal:    it.Price.Mul(int64(it.Quantity)),
+			TaxCategory: it.TaxCategory,
+			TaxRate:     it.TaxRate,
+			Tax:         it.Tax,
+			LineTotal:   it.LineTotal,
+			PriceSource:  it.PriceSource,
+			ExchangeRate: it.ExchangeRate,
+			AddedAt:     it.AddedAt.Format("2006-01-02 15:04"),
 		})
-		total += subtotal
 	}
 
 	resp := CartResponse{
-		UserID:    cart.UserID,
-		Items:     items,
-		Total:     total,
-		UpdatedAt: cart.UpdatedAt.Format("2006-01-02 15:04"),
+		UserID:           cart.UserID,
+		Items:            items,
+		Currency:         cart.Currency,
+		Region:           cart.Region,
+		PricesIncludeTax: cart.PricesIncludeTax,
+		Subtotal:         cart.Subtotal,
+		TaxTotal:         cart.TaxTotal,
+		Total:            cart.Total,
+		UpdatedAt:        cart.UpdatedAt.Format("2006-01-02 15:04"),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -128,8 +151,8 @@ func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
         Quantity:  req.Quantity,
     }
 
-    if err := h.service.AddItem(userID, item); err != nil {
-        if strings.Contains(err.Error(), "quantity") || strings.Contains(err.Error(), "stock") {
+    if err := h.service.AddItem(userID, req.Currency, item); err != nil {
+        if strings.Contains(err.Error(), "quantity") || strings.Contains(err.Error(), "stock") || strings.Contains(err.Error(), "currency") {
             http.Error(w, err.Error(), http.StatusBadRequest)
             return
         }
@@ -191,3 +214,50 @@ func (h *CartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
 	}
 	json.NewEncoder(w).Encode(map[string]string{"message": "cart cleared"})
 }
+
+// @Summary      Get Shipping Quotes
+// @Description  Price the available shipping methods for the user's cart to a destination
+// @Tags         Cart
+// @Produce      json
+// @Security     BearerAuth
+// @Param        country      query     string  true   "Destination country (ISO 3166-1 alpha-2)"
+// @Param        state        query     string  false  "Destination state or province"
+// @Param        city         query     string  false  "Destination city"
+// @Param        postal_code  query     string  false  "Destination postal code"
+// @Success      200   {array}   domain.ShippingQuote
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /carts/shipping-quotes [get]
+func (h *CartHandler) GetShippingQuotes(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized: userID missing in context", http.StatusUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	dest := domain.Address{
+		Country:    q.Get("country"),
+		State:      q.Get("state"),
+		City:       q.Get("city"),
+		PostalCode: q.Get("postal_code"),
+	}
+	if dest.Country == "" {
+		http.Error(w, "country required", http.StatusBadRequest)
+		return
+	}
+
+	quotes, err := h.service.GetShippingQuotes(userID, dest)
+	if err != nil {
+		if errors.Is(err, domain.ErrCartEmpty) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quotes)
+}
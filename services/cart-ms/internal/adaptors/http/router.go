@@ -29,7 +29,7 @@ import (
 // @description Type "Bearer" followed by a space and JWT token.
 
 // NewRouter sets up routes for order-ms
-func NewRouter(handler *CartHandler) http.Handler {
+func NewRouter(handler *CartHandler, wishlists *WishlistHandler) http.Handler {
 	r := chi.NewRouter()
 
 	// Swagger UI
@@ -40,6 +40,28 @@ func NewRouter(handler *CartHandler) http.Handler {
 		r.With(middleware.AuthMiddleware).Post("/add", handler.AddItem)
 		r.With(middleware.AuthMiddleware).Delete("/remove", handler.RemoveItem)
 		r.With(middleware.AuthMiddleware).Delete("/clear", handler.ClearCart)
+		r.With(middleware.AuthMiddleware).Get("/shipping-quotes", handler.GetShippingQuotes)
+		r.With(middleware.AuthMiddleware).Post("/save-for-later", wishlists.SaveForLater)
+	})
+
+	r.Route("/wishlists", func(r chi.Router) {
+		// read-only share links need no account
+		r.Get("/shared/{token}", wishlists.GetSharedWishlist)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.AuthMiddleware)
+
+			r.Get("/", wishlists.ListWishlists)
+			r.Post("/", wishlists.CreateWishlist)
+			r.Get("/{id}", wishlists.GetWishlist)
+			r.Patch("/{id}", wishlists.RenameWishlist)
+			r.Delete("/{id}", wishlists.DeleteWishlist)
+			r.Post("/{id}/items", wishlists.AddItem)
+			r.Delete("/{id}/items/{product_id}", wishlists.RemoveItem)
+			r.Post("/{id}/items/{product_id}/move-to-cart", wishlists.MoveToCart)
+			r.Post("/{id}/share", wishlists.ShareWishlist)
+			r.Delete("/{id}/share", wishlists.UnshareWishlist)
+		})
 	})
 
 	return r
@@ -0,0 +1,382 @@
+package http
+
+import (
+	"ecom-api/pkg/middleware"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"cart-microservice/internal/domain"
+	"cart-microservice/internal/ports"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WishlistHandler struct {
+	service ports.WishlistService
+}
+
+func NewWishlistHandler(service ports.WishlistService) *WishlistHandler {
+	return &WishlistHandler{service: service}
+}
+
+type WishlistRequest struct {
+	Name string `json:"name" example:"Birthday ideas"`
+}
+
+type WishlistItemRequest struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity" example:"1"`
+}
+
+type SaveForLaterRequest struct {
+	ProductID string `json:"product_id"`
+}
+
+// SharedWishlistResponse is the read-only view of a shared list.
+type SharedWishlistResponse struct {
+	Name      string                `json:"name"`
+	Items     []domain.WishlistItem `json:"items"`
+	UpdatedAt time.Time             `json:"updated_at"`
+}
+
+// @Summary      List Wishlists
+// @Description  List the authenticated user's wishlists, including the save-for-later list
+// @Tags         Wishlist
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   domain.Wishlist
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /wishlists [get]
+func (h *WishlistHandler) ListWishlists(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	lists, err := h.service.ListWishlists(r.Context(), userID)
+	if err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, lists)
+}
+
+// @Summary      Create Wishlist
+// @Description  Create a named wishlist
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        wishlist  body      WishlistRequest  true  "Wishlist"
+// @Success      201  {object}  domain.Wishlist
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Router       /wishlists [post]
+func (h *WishlistHandler) CreateWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req WishlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.service.CreateWishlist(r.Context(), userID, req.Name)
+	if err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, list)
+}
+
+// @Summary      Get Wishlist
+// @Description  Get a wishlist with price-drop and back-in-stock flags
+// @Tags         Wishlist
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Wishlist ID"
+// @Success      200  {object}  domain.Wishlist
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /wishlists/{id} [get]
+func (h *WishlistHandler) GetWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.GetWishlist(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// @Summary      Rename Wishlist
+// @Description  Rename a wishlist
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      string           true  "Wishlist ID"
+// @Param        wishlist  body      WishlistRequest  true  "New name"
+// @Success      200  {object}  domain.Wishlist
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /wishlists/{id} [patch]
+func (h *WishlistHandler) RenameWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req WishlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.service.RenameWishlist(r.Context(), userID, chi.URLParam(r, "id"), req.Name)
+	if err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// @Summary      Delete Wishlist
+// @Description  Delete a wishlist and its items
+// @Tags         Wishlist
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Wishlist ID"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /wishlists/{id} [delete]
+func (h *WishlistHandler) DeleteWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWishlist(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "wishlist deleted"})
+}
+
+// @Summary      Add Wishlist Item
+// @Description  Save a product to a wishlist at its current catalog price
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string               true  "Wishlist ID"
+// @Param        item  body      WishlistItemRequest  true  "Item"
+// @Success      200  {object}  domain.Wishlist
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /wishlists/{id}/items [post]
+func (h *WishlistHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req WishlistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ProductID == "" {
+		http.Error(w, "product_id required", http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.service.AddItem(r.Context(), userID, chi.URLParam(r, "id"), req.ProductID, req.Quantity)
+	if err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// @Summary      Remove Wishlist Item
+// @Description  Remove a product from a wishlist
+// @Tags         Wishlist
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Wishlist ID"
+// @Param        product_id  path      string  true  "Product ID"
+// @Success      200  {object}  domain.Wishlist
+// @Failure      404  {object}  map[string]string
+// @Router       /wishlists/{id}/items/{product_id} [delete]
+func (h *WishlistHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.RemoveItem(r.Context(), userID, chi.URLParam(r, "id"), chi.URLParam(r, "product_id"))
+	if err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// @Summary      Move to Cart
+// @Description  Add a wishlist line to the cart; lines of the save-for-later list are moved out of it
+// @Tags         Wishlist
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Wishlist ID"
+// @Param        product_id  path      string  true  "Product ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /wishlists/{id}/items/{product_id}/move-to-cart [post]
+func (h *WishlistHandler) MoveToCart(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.MoveToCart(r.Context(), userID, chi.URLParam(r, "id"), chi.URLParam(r, "product_id")); err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "item moved to cart"})
+}
+
+// @Summary      Save for Later
+// @Description  Move a cart line into the user's save-for-later list
+// @Tags         Cart
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        item  body      SaveForLaterRequest  true  "Cart line"
+// @Success      200  {object}  domain.Wishlist
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /carts/save-for-later [post]
+func (h *WishlistHandler) SaveForLater(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req SaveForLaterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ProductID == "" {
+		http.Error(w, "product_id required", http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.service.SaveForLater(r.Context(), userID, req.ProductID)
+	if err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// @Summary      Share Wishlist
+// @Description  Create a read-only share link token for a wishlist
+// @Tags         Wishlist
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Wishlist ID"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /wishlists/{id}/share [post]
+func (h *WishlistHandler) ShareWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.ShareWishlist(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"share_token": list.ShareToken,
+		"url":         "/wishlists/shared/" + list.ShareToken,
+	})
+}
+
+// @Summary      Unshare Wishlist
+// @Description  Revoke the share link of a wishlist
+// @Tags         Wishlist
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Wishlist ID"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /wishlists/{id}/share [delete]
+func (h *WishlistHandler) UnshareWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnshareWishlist(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "wishlist is no longer shared"})
+}
+
+// @Summary      Get Shared Wishlist
+// @Description  Read-only view of a shared wishlist; no authentication required
+// @Tags         Wishlist
+// @Produce      json
+// @Param        token  path      string  true  "Share token"
+// @Success      200  {object}  SharedWishlistResponse
+// @Failure      404  {object}  map[string]string
+// @Router       /wishlists/shared/{token} [get]
+func (h *WishlistHandler) GetSharedWishlist(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.GetSharedWishlist(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		wishlistError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, SharedWishlistResponse{
+		Name:      list.Name,
+		Items:     list.Items,
+		UpdatedAt: list.UpdatedAt,
+	})
+}
+
+func requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, _ := middleware.FromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized: userID missing in context", http.StatusUnauthorized)
+		return "", false
+	}
+	return userID, true
+}
+
+func wishlistError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrWishlistNotFound), errors.Is(err, domain.ErrItemNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidWishlist),
+		strings.Contains(err.Error(), "quantity"), strings.Contains(err.Error(), "stock"), strings.Contains(err.Error(), "currency"):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
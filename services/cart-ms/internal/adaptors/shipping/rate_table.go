@@ -0,0 +1,167 @@
+package shipping
+
+import (
+	"cart-microservice/internal/domain"
+	"ecom-api/pkg/money"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// wildcard matches any destination country in a zone.
+const wildcard = "*"
+
+// Zone groups destination countries that share the same rates.
+type Zone struct {
+	Code      string   `json:"code"`
+	Countries []string `json:"countries"`
+}
+
+// Rate is one row of a method's rate table. A rate applies when the
+// parcel weighs at most MaxWeight (0 means no limit) and the order value
+// is at least MinOrderValue. Amounts are decimal major units of the
+// table currency.
+type Rate struct {
+	Zone          string  `json:"zone"`
+	MaxWeight     float64 `json:"max_weight"`
+	MinOrderValue float64 `json:"min_order_value"`
+	Price         float64 `json:"price"`
+}
+
+// Method is a shipping method with its rate table.
+type Method struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	MinDays int    `json:"min_days"`
+	MaxDays int    `json:"max_days"`
+	Rates   []Rate `json:"rates"`
+}
+
+// RateTable quotes shipping from zones and per-method rate tables.
+type RateTable struct {
+	Currency string   `json:"currency"` // defaults to money.DefaultCurrency
+	Zones    []Zone   `json:"zones"`
+	Methods  []Method `json:"methods"`
+}
+
+// LoadRateTable reads the JSON rate table at path.
+// An empty path yields a table with no shipping methods.
+func LoadRateTable(path string) (*RateTable, error) {
+	if path == "" {
+		return &RateTable{Currency: money.DefaultCurrency}, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shipping rates %s: %w", path, err)
+	}
+
+	var t RateTable
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse shipping rates %s: %w", path, err)
+	}
+
+	if t.Currency == "" {
+		t.Currency = money.DefaultCurrency
+	}
+	t.Currency = strings.ToUpper(t.Currency)
+	if !money.ValidCurrency(t.Currency) {
+		return nil, fmt.Errorf("shipping rates %s: %w %q", path, money.ErrInvalidCurrency, t.Currency)
+	}
+
+	for _, m := range t.Methods {
+		if m.Code == "" {
+			return nil, fmt.Errorf("shipping method without code in %s", path)
+		}
+		for _, r := range m.Rates {
+			if r.Price < 0 || r.MaxWeight < 0 || r.MinOrderValue < 0 {
+				return nil, fmt.Errorf("shipping method %s has a negative rate value", m.Code)
+			}
+		}
+	}
+
+	return &t, nil
+}
+
+// Quote returns the cheapest applicable rate of every method that ships
+// to the destination, cheapest method first.
+func (t *RateTable) Quote(dest domain.Address, weight float64, orderValue money.Money) ([]domain.ShippingQuote, error) {
+	if dest.Country == "" {
+		return nil, fmt.Errorf("destination country is required")
+	}
+	if weight < 0 || orderValue.IsNegative() {
+		return nil, fmt.Errorf("weight and order value cannot be negative")
+	}
+	if orderValue.Currency != "" && orderValue.Currency != t.Currency {
+		return nil, fmt.Errorf("%w: shipping rates are in %s, order is in %s",
+			money.ErrCurrencyMismatch, t.Currency, orderValue.Currency)
+	}
+
+	zone := t.zoneFor(dest.Country)
+	if zone == "" {
+		return []domain.ShippingQuote{}, nil
+	}
+
+	quotes := []domain.ShippingQuote{}
+	for _, m := range t.Methods {
+		var best money.Money
+		found := false
+		for _, r := range m.Rates {
+			if r.Zone != zone {
+				continue
+			}
+			if r.MaxWeight > 0 && weight > r.MaxWeight {
+				continue
+			}
+			if orderValue.Amount < money.FromMajor(r.MinOrderValue, t.Currency).Amount {
+				continue
+			}
+			price := money.FromMajor(r.Price, t.Currency)
+			if !found || price.Amount < best.Amount {
+				best, found = price, true
+			}
+		}
+		if !found {
+			continue
+		}
+
+		quotes = append(quotes, domain.ShippingQuote{
+			MethodCode: m.Code,
+			MethodName: m.Name,
+			Zone:       zone,
+			Cost:       best,
+			MinDays:    m.MinDays,
+			MaxDays:    m.MaxDays,
+		})
+	}
+
+	sort.SliceStable(quotes, func(i, j int) bool { return quotes[i].Cost.Amount < quotes[j].Cost.Amount })
+	return quotes, nil
+}
+
+// QuoteCurrency is the currency of the table's prices and order value thresholds.
+func (t *RateTable) QuoteCurrency() string {
+	return t.Currency
+}
+
+// zoneFor finds the zone listing the country, falling back to a zone
+// that lists the wildcard.
+func (t *RateTable) zoneFor(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+
+	fallback := ""
+	for _, z := range t.Zones {
+		for _, c := range z.Countries {
+			c = strings.ToUpper(strings.TrimSpace(c))
+			if c == country {
+				return z.Code
+			}
+			if c == wildcard && fallback == "" {
+				fallback = z.Code
+			}
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,138 @@
+package application
+
+import (
+	"cart-microservice/internal/domain"
+	"cart-microservice/internal/ports"
+	"context"
+	"ecom-api/pkg/events"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+const (
+	eventSource   = "cart-ms"
+	scanBatchSize = 100
+)
+
+// AbandonedCartScanner periodically looks for carts that have been idle
+// longer than the configured thresholds and emits a cart.abandoned event
+// for every threshold a cart passes. Threshold i (0-based) moves a cart
+// from stage i to stage i+1, and only once the gap to the previous
+// threshold has passed since the last reminder, so a cart found late still
+// gets its reminders spaced out. Any change to the cart resets it to stage 0.
+type AbandonedCartScanner struct {
+	repo       ports.CartRepository
+	publisher  ports.EventPublisher
+	thresholds []time.Duration
+	interval   time.Duration
+}
+
+func NewAbandonedCartScanner(repo ports.CartRepository, publisher ports.EventPublisher, thresholds []time.Duration, interval time.Duration) *AbandonedCartScanner {
+	sorted := append([]time.Duration(nil), thresholds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &AbandonedCartScanner{
+		repo:       repo,
+		publisher:  publisher,
+		thresholds: sorted,
+		interval:   interval,
+	}
+}
+
+// Run scans every interval until ctx is cancelled.
+func (s *AbandonedCartScanner) Run(ctx context.Context) error {
+	if len(s.thresholds) == 0 || s.interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.ScanOnce(ctx); err != nil {
+			log.Printf("abandoned cart scan failed: %v", err)
+		} else if n > 0 {
+			log.Printf("abandoned cart scan: %d reminders emitted", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScanOnce runs a single pass over all stages and returns the number of
+// events emitted. Later stages go first so a cart advances at most one
+// stage per pass.
+func (s *AbandonedCartScanner) ScanOnce(ctx context.Context) (int, error) {
+	emitted := 0
+	now := time.Now()
+
+	for stage := len(s.thresholds) - 1; stage >= 0; stage-- {
+		var remindedBefore time.Time
+		if stage > 0 {
+			remindedBefore = now.Add(-(s.thresholds[stage] - s.thresholds[stage-1]))
+		}
+
+		carts, err := s.repo.FindIdle(ctx, stage, now.Add(-s.thresholds[stage]), remindedBefore, scanBatchSize)
+		if err != nil {
+			return emitted, fmt.Errorf("failed to find idle carts at stage %d: %w", stage, err)
+		}
+
+		for i := range carts {
+			ok, err := s.remind(ctx, &carts[i], now)
+			if err != nil {
+				return emitted, err
+			}
+			if ok {
+				emitted++
+			}
+		}
+	}
+
+	return emitted, nil
+}
+
+func (s *AbandonedCartScanner) remind(ctx context.Context, cart *domain.Cart, now time.Time) (bool, error) {
+	// claim the stage first so concurrent scanners emit each stage once
+	claimed, err := s.repo.AdvanceAbandonStage(ctx, cart, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance cart %s: %w", cart.UserID, err)
+	}
+	if !claimed {
+		return false, nil
+	}
+
+	if err := s.publisher.Publish(ctx, abandonedEvent(cart)); err != nil {
+		if rerr := s.repo.RevertAbandonStage(ctx, cart); rerr != nil {
+			log.Printf("failed to revert abandon stage of cart %s: %v", cart.UserID, rerr)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func abandonedEvent(cart *domain.Cart) events.Event {
+	items := make([]domain.AbandonedItem, len(cart.Items))
+	for i, it := range cart.Items {
+		items[i] = domain.AbandonedItem{
+			ProductID: it.ProductID,
+			Name:      it.Name,
+			Quantity:  it.Quantity,
+			Price:     it.Price,
+		}
+	}
+
+	return events.New(domain.EventCartAbandoned, eventSource, cart.UserID, domain.AbandonedCartEvent{
+		UserID:    cart.UserID,
+		Stage:     cart.AbandonStage + 1,
+		IdleSince: cart.UpdatedAt,
+		Currency:  cart.Currency,
+		Subtotal:  cart.ItemsSubtotal(),
+		Items:     items,
+	})
+}
@@ -0,0 +1,110 @@
+package application
+
+import (
+	"cart-microservice/internal/adaptors/grpc"
+	"cart-microservice/internal/domain"
+	"cart-microservice/internal/ports"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const expiryBatchSize = 100
+
+// CartExpirer removes carts that have not changed for longer than the TTL.
+// Each expired cart is archived to expired_carts and the stock it holds in
+// product-ms is released.
+type CartExpirer struct {
+	repo          ports.CartRepository
+	productClient *grpc.ProdctClient
+	ttl           time.Duration
+	interval      time.Duration
+}
+
+func NewCartExpirer(repo ports.CartRepository, productClient *grpc.ProdctClient, ttl, interval time.Duration) *CartExpirer {
+	return &CartExpirer{
+		repo:          repo,
+		productClient: productClient,
+		ttl:           ttl,
+		interval:      interval,
+	}
+}
+
+// Run sweeps every interval until ctx is cancelled. A zero TTL keeps
+// carts forever.
+func (e *CartExpirer) Run(ctx context.Context) error {
+	if e.ttl <= 0 || e.interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := e.SweepOnce(ctx); err != nil {
+			log.Printf("cart expiry sweep failed: %v", err)
+		} else if n > 0 {
+			log.Printf("cart expiry sweep: %d carts expired", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// SweepOnce expires carts in batches until none are left and returns how
+// many were removed.
+func (e *CartExpirer) SweepOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	expired := 0
+
+	for {
+		carts, err := e.repo.FindExpired(ctx, now.Add(-e.ttl), expiryBatchSize)
+		if err != nil {
+			return expired, fmt.Errorf("failed to find expired carts: %w", err)
+		}
+
+		removed := 0
+		for i := range carts {
+			ok, err := e.expire(ctx, &carts[i], now)
+			if err != nil {
+				return expired, err
+			}
+			if ok {
+				removed++
+			}
+		}
+		expired += removed
+
+		// a batch where every cart was touched concurrently would repeat forever
+		if len(carts) < expiryBatchSize || removed == 0 {
+			return expired, nil
+		}
+	}
+}
+
+func (e *CartExpirer) expire(ctx context.Context, cart *domain.Cart, now time.Time) (bool, error) {
+	ok, err := e.repo.ExpireCart(ctx, &domain.ExpiredCart{
+		UserID:    cart.UserID,
+		Cart:      *cart,
+		ItemCount: len(cart.Items),
+		Subtotal:  cart.ItemsSubtotal(),
+		IdleFor:   now.Sub(cart.UpdatedAt).Round(time.Second).String(),
+		ExpiredAt: now,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to expire cart %s: %w", cart.UserID, err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	if err := e.productClient.ReleaseStock(domain.ReservationHolder(cart.UserID), ""); err != nil {
+		log.Printf("failed to release stock of expired cart %s: %v", cart.UserID, err)
+	}
+	return true, nil
+}
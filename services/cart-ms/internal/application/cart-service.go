@@ -4,47 +4,88 @@ import (
 	"cart-microservice/internal/adaptors/grpc"
 	"cart-microservice/internal/domain"
 	"cart-microservice/internal/ports"
+	"context"
+	"ecom-api/pkg/events"
+	"ecom-api/pkg/money"
+	"ecom-api/pkg/tax"
 	"fmt"
+	"log"
+	"strings"
 )
 
 type CartServiceImplement struct {
 	repo ports.CartRepository
 	productClient  *grpc.ProdctClient
-	
+	taxCalculator ports.TaxCalculator
+	shippingRater ports.ShippingRater
+	publisher ports.EventPublisher
+	defaultCurrency string
 }
 
-func NewCartService(r ports.CartRepository, productClient *grpc.ProdctClient) ports.CartService {
+func NewCartService(r ports.CartRepository, productClient *grpc.ProdctClient, taxCalculator ports.TaxCalculator, shippingRater ports.ShippingRater, publisher ports.EventPublisher, defaultCurrency string) ports.CartService {
+	if defaultCurrency == "" {
+		defaultCurrency = money.DefaultCurrency
+	}
 	return  &CartServiceImplement{
 		repo: r,
 		productClient: productClient ,
+		taxCalculator: taxCalculator,
+		shippingRater: shippingRater,
+		publisher: publisher,
+		defaultCurrency: strings.ToUpper(defaultCurrency),
 	}
 }
 
 
-func (s *CartServiceImplement) AddItem(userID string, item *domain.CartItem) error {
+// AddItem adds a product to the cart. The currency of the first item
+// fixes the cart currency; an empty currency keeps the current one.
+func (s *CartServiceImplement) AddItem(userID, currency string, item *domain.CartItem) error {
 	// 1. Validate quantity
 	if item.Quantity <= 0 {
 		return fmt.Errorf("quantity must be greater than 0")
 	}
 
+	cart, err := s.repo.GetCart(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get cart: %w", err)
+	}
+	currency, err = s.cartCurrency(cart, currency)
+	if err != nil {
+		return err
+	}
+
 	// 2. Call Product-MS via gRPC
-	product, err := s.productClient.GetProduct(item.ProductID)
+	product, err := s.productClient.GetProduct(item.ProductID, currency)
 	if err != nil {
 		return fmt.Errorf("failed to fetch product %s: %w", item.ProductID, err)
 	}
 
-	// 3. Check stock
-	if int32(item.Quantity) > product.Product.Stock {
-		return fmt.Errorf("requested quantity %d exceeds available stock %d",
-			item.Quantity, product.Product.Stock)
+	// 3. Reserve stock for every line of the product in the cart
+	held := 0
+	for _, it := range cart.Items {
+		if it.ProductID == item.ProductID {
+			held += it.Quantity
+		}
+	}
+	holder := domain.ReservationHolder(userID)
+	if _, err := s.productClient.ReserveStock(holder, item.ProductID, held+item.Quantity); err != nil {
+		return fmt.Errorf("failed to reserve stock for product %s: %w", item.ProductID, err)
 	}
 
-	// 4. Copy price & name from product
-	item.Price = product.Product.Price
+	// 4. Copy price in the cart currency & name from product
+	quote := product.Product.GetQuote()
+	item.Price = money.FromProto(quote.GetPrice())
+	item.PriceSource = quote.GetSource()
+	item.ExchangeRate = quote.GetExchangeRate()
 	item.Name = product.Product.Name
+	item.TaxCategory = product.Product.TaxCategory
+	item.Weight = product.Product.Weight
 
 	// 5. Save to repo
-	if err := s.repo.AddItem(userID, *item); err != nil {
+	if err := s.repo.AddItem(userID, currency, *item); err != nil {
+		if _, rerr := s.productClient.ReserveStock(holder, item.ProductID, held); rerr != nil {
+			log.Printf("failed to roll back reservation of %s for %s: %v", item.ProductID, userID, rerr)
+		}
 		return fmt.Errorf("failed to save cart item: %w", err)
 	}
 
@@ -52,18 +93,66 @@ func (s *CartServiceImplement) AddItem(userID string, item *domain.CartItem) err
 }
 
 
-func (s *CartServiceImplement) GetCart(userID string) (*domain.Cart, error) {
+// GetCart returns the user's cart priced as a quote for the given
+// shipping region, with per-line and total tax filled in. Item prices
+// are refreshed in the cart currency at the current exchange rates; an
+// order placed from the cart is priced again by order-ms when it is created.
+func (s *CartServiceImplement) GetCart(userID, region string) (*domain.Cart, error) {
 	cart, err :=  s.repo.GetCart(userID)
 	if err != nil {
 		return  nil, fmt.Errorf("failed to get cart: %w", err)
 	}
 
-	var total float64
+	if cart.Currency == "" {
+		cart.Currency = s.defaultCurrency
+	}
+	for i, item := range cart.Items {
+		product, err := s.productClient.GetProduct(item.ProductID, cart.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to price product %s: %w", item.ProductID, err)
+		}
+		quote := product.Product.GetQuote()
+		cart.Items[i].Price = money.FromProto(quote.GetPrice())
+		cart.Items[i].PriceSource = quote.GetSource()
+		cart.Items[i].ExchangeRate = quote.GetExchangeRate()
+	}
+
+	lines := make([]tax.Line, len(cart.Items))
+	for i, item := range cart.Items {
+		lines[i] = tax.Line{
+			Category:  item.TaxCategory,
+			UnitPrice: item.Price,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	quote, err := s.taxCalculator.Calculate(region, lines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate tax: %w", err)
+	}
+
+	for i, lt := range quote.Lines {
+		cart.Items[i].TaxRate = lt.Rate
+		cart.Items[i].Tax = lt.Tax
+		cart.Items[i].LineTotal = lt.Gross
+	}
+
+	var weight float64
 	for _, item := range cart.Items {
-		total += float64(item.Quantity) * item.Price
+		weight += item.Weight * float64(item.Quantity)
 	}
 
-	cart.Total = total
+	cart.Weight = weight
+	cart.Region = quote.Region
+	cart.Subtotal = quote.Subtotal
+	cart.TaxTotal = quote.TaxTotal
+	cart.Total = quote.Total
+	cart.PricesIncludeTax = quote.PricesIncludeTax
+	if cart.Total.Currency == "" {
+		cart.Subtotal = money.Zero(cart.Currency)
+		cart.TaxTotal = money.Zero(cart.Currency)
+		cart.Total = money.Zero(cart.Currency)
+	}
 
 	return  cart, nil
 }
@@ -72,7 +161,11 @@ func (s *CartServiceImplement) RemoveItem(userID, productID string) error {
 	if err := s.repo.RemoveItem(userID, productID); err != nil {
 		return  fmt.Errorf("failed to remove item: %w", err)
 	}
-    
+
+	// a leftover reservation is freed when the cart expires
+	if err := s.productClient.ReleaseStock(domain.ReservationHolder(userID), productID); err != nil {
+		log.Printf("failed to release stock of %s for %s: %v", productID, userID, err)
+	}
 	return  nil
 }
 
@@ -80,5 +173,107 @@ func (s *CartServiceImplement) ClearCart(userID string) error {
 	if err := s.repo.ClearCart(userID); err != nil {
 		return fmt.Errorf("failed to clear cart: %w", err)
 	}
+
+	if err := s.productClient.ReleaseStock(domain.ReservationHolder(userID), ""); err != nil {
+		log.Printf("failed to release stock for %s: %v", userID, err)
+	}
+	return nil
+}
+
+// CheckoutCart clears a cart that was turned into an order, taking its
+// reserved stock off the shelf. Carts that were sent abandonment reminders
+// are reported as recovered.
+func (s *CartServiceImplement) CheckoutCart(ctx context.Context, userID string) error {
+	cart, err := s.repo.GetCart(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	if err := s.productClient.CommitStock(domain.ReservationHolder(userID)); err != nil {
+		return fmt.Errorf("failed to commit reserved stock: %w", err)
+	}
+	if err := s.repo.ClearCart(userID); err != nil {
+		return fmt.Errorf("failed to clear cart: %w", err)
+	}
+
+	if cart.ReminderStageReached > 0 {
+		event := events.New(domain.EventCartRecovered, eventSource, userID, domain.CartRecoveredEvent{
+			UserID:       userID,
+			StageReached: cart.ReminderStageReached,
+			Subtotal:     cart.ItemsSubtotal(),
+		})
+		if err := s.publisher.Publish(ctx, event); err != nil {
+			log.Printf("failed to publish %s for %s: %v", event.Type, userID, err)
+		}
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// GetShippingQuotes prices the shipping methods for the user's cart
+// shipped to dest, using the cart weight and its value before tax.
+func (s *CartServiceImplement) GetShippingQuotes(userID string, dest domain.Address) ([]domain.ShippingQuote, error) {
+	cart, err := s.GetCart(userID, dest.Region())
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, domain.ErrCartEmpty
+	}
+
+	return s.QuoteShipping(dest, cart.Weight, cart.Subtotal)
+}
+
+// QuoteShipping prices the shipping methods for an arbitrary parcel.
+// Orders in another currency than the rate table are converted at the
+// current exchange rate and the costs are returned in the order currency.
+func (s *CartServiceImplement) QuoteShipping(dest domain.Address, weight float64, orderValue money.Money) ([]domain.ShippingQuote, error) {
+	tableCurrency := s.shippingRater.QuoteCurrency()
+	currency := orderValue.Currency
+
+	rate := 1.0
+	convert := currency != "" && currency != tableCurrency
+	if convert {
+		fx, err := s.productClient.GetExchangeRate(tableCurrency, currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s/%s exchange rate: %w", tableCurrency, currency, err)
+		}
+		rate = fx.GetRate()
+		orderValue = orderValue.Convert(tableCurrency, 1/rate)
+	}
+
+	quotes, err := s.shippingRater.Quote(dest, weight, orderValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote shipping: %w", err)
+	}
+
+	if convert {
+		for i := range quotes {
+			quotes[i].Cost = quotes[i].Cost.Convert(currency, rate)
+		}
+	}
+	return quotes, nil
+}
+
+// cartCurrency decides the currency of a cart an item is added to.
+func (s *CartServiceImplement) cartCurrency(cart *domain.Cart, requested string) (string, error) {
+	requested = strings.ToUpper(strings.TrimSpace(requested))
+	if requested != "" && !money.ValidCurrency(requested) {
+		return "", fmt.Errorf("currency must be an ISO 4217 code")
+	}
+
+	current := cart.Currency
+	if current == "" {
+		current = s.defaultCurrency
+	}
+
+	if len(cart.Items) == 0 {
+		if requested != "" {
+			return requested, nil
+		}
+		return current, nil
+	}
+	if requested != "" && requested != current {
+		return "", fmt.Errorf("cart currency is %s; clear the cart to switch to %s", current, requested)
+	}
+	return current, nil
+}
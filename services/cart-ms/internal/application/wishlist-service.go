@@ -0,0 +1,315 @@
+package application
+
+import (
+	"cart-microservice/internal/adaptors/grpc"
+	"cart-microservice/internal/domain"
+	"cart-microservice/internal/ports"
+	"context"
+	"crypto/rand"
+	"ecom-api/pkg/money"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const maxWishlistName = 100
+
+type WishlistServiceImplement struct {
+	repo          ports.WishlistRepository
+	cartRepo      ports.CartRepository
+	carts         ports.CartService
+	productClient *grpc.ProdctClient
+}
+
+func NewWishlistService(r ports.WishlistRepository, cartRepo ports.CartRepository, carts ports.CartService, productClient *grpc.ProdctClient) ports.WishlistService {
+	return &WishlistServiceImplement{
+		repo:          r,
+		cartRepo:      cartRepo,
+		carts:         carts,
+		productClient: productClient,
+	}
+}
+
+func (s *WishlistServiceImplement) CreateWishlist(ctx context.Context, userID, name string) (*domain.Wishlist, error) {
+	name, err := validateWishlistName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return s.repo.Create(ctx, &domain.Wishlist{
+		UserID:    userID,
+		Name:      name,
+		Kind:      domain.WishlistKindNamed,
+		Items:     []domain.WishlistItem{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func (s *WishlistServiceImplement) ListWishlists(ctx context.Context, userID string) ([]domain.Wishlist, error) {
+	return s.repo.FindByUser(ctx, userID)
+}
+
+func (s *WishlistServiceImplement) GetWishlist(ctx context.Context, userID, id string) (*domain.Wishlist, error) {
+	w, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.refresh(w)
+	return w, nil
+}
+
+func (s *WishlistServiceImplement) RenameWishlist(ctx context.Context, userID, id, name string) (*domain.Wishlist, error) {
+	name, err := validateWishlistName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if w.Kind == domain.WishlistKindSaveForLater {
+		return nil, fmt.Errorf("%w: the save-for-later list cannot be renamed", domain.ErrInvalidWishlist)
+	}
+
+	w.Name = name
+	return w, s.save(ctx, w)
+}
+
+func (s *WishlistServiceImplement) DeleteWishlist(ctx context.Context, userID, id string) error {
+	if _, err := s.owned(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// AddItem saves a product at its catalog price. Adding a product that is
+// already on the list increases its quantity and keeps the first snapshot.
+func (s *WishlistServiceImplement) AddItem(ctx context.Context, userID, id, productID string, quantity int) (*domain.Wishlist, error) {
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	w, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.productClient.GetProduct(productID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product %s: %w", productID, err)
+	}
+
+	addToList(w, domain.WishlistItem{
+		ProductID:    productID,
+		Name:         product.Product.GetName(),
+		Quantity:     quantity,
+		SavedPrice:   money.FromProto(product.Product.GetPrice()),
+		SavedInStock: product.Product.GetStock() > 0,
+	})
+	return w, s.save(ctx, w)
+}
+
+func (s *WishlistServiceImplement) RemoveItem(ctx context.Context, userID, id, productID string) (*domain.Wishlist, error) {
+	w, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	i := w.Item(productID)
+	if i < 0 {
+		return nil, domain.ErrItemNotFound
+	}
+	w.Items = append(w.Items[:i], w.Items[i+1:]...)
+	return w, s.save(ctx, w)
+}
+
+func (s *WishlistServiceImplement) SaveForLater(ctx context.Context, userID, productID string) (*domain.Wishlist, error) {
+	cart, err := s.cartRepo.GetCart(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	var line *domain.CartItem
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == productID {
+			line = &cart.Items[i]
+			break
+		}
+	}
+	if line == nil {
+		return nil, fmt.Errorf("%w: product %s is not in the cart", domain.ErrItemNotFound, productID)
+	}
+
+	w, err := s.saveForLaterList(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	inStock := true
+	if product, err := s.productClient.GetProduct(productID, ""); err == nil {
+		inStock = product.Product.GetStock() > 0
+	}
+
+	addToList(w, domain.WishlistItem{
+		ProductID:    line.ProductID,
+		Name:         line.Name,
+		Quantity:     line.Quantity,
+		SavedPrice:   line.Price,
+		SavedInStock: inStock,
+	})
+	if err := s.save(ctx, w); err != nil {
+		return nil, err
+	}
+
+	if err := s.carts.RemoveItem(userID, productID); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *WishlistServiceImplement) MoveToCart(ctx context.Context, userID, id, productID string) error {
+	w, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	i := w.Item(productID)
+	if i < 0 {
+		return domain.ErrItemNotFound
+	}
+
+	item := &domain.CartItem{ProductID: productID, Quantity: w.Items[i].Quantity}
+	if err := s.carts.AddItem(userID, "", item); err != nil {
+		return err
+	}
+
+	if w.Kind != domain.WishlistKindSaveForLater {
+		return nil
+	}
+	w.Items = append(w.Items[:i], w.Items[i+1:]...)
+	return s.save(ctx, w)
+}
+
+// ShareWishlist issues a read-only share token, reusing the current one.
+func (s *WishlistServiceImplement) ShareWishlist(ctx context.Context, userID, id string) (*domain.Wishlist, error) {
+	w, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if w.ShareToken != "" {
+		return w, nil
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate share token: %w", err)
+	}
+	w.ShareToken = hex.EncodeToString(token)
+	return w, s.save(ctx, w)
+}
+
+func (s *WishlistServiceImplement) UnshareWishlist(ctx context.Context, userID, id string) error {
+	w, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	w.ShareToken = ""
+	return s.save(ctx, w)
+}
+
+func (s *WishlistServiceImplement) GetSharedWishlist(ctx context.Context, token string) (*domain.Wishlist, error) {
+	w, err := s.repo.FindByShareToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	s.refresh(w)
+	return w, nil
+}
+
+// owned loads a list and hides lists of other users as not found.
+func (s *WishlistServiceImplement) owned(ctx context.Context, userID, id string) (*domain.Wishlist, error) {
+	w, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if w.UserID != userID {
+		return nil, domain.ErrWishlistNotFound
+	}
+	return w, nil
+}
+
+func (s *WishlistServiceImplement) saveForLaterList(ctx context.Context, userID string) (*domain.Wishlist, error) {
+	w, err := s.repo.FindByKind(ctx, userID, domain.WishlistKindSaveForLater)
+	if err == nil {
+		return w, nil
+	}
+	if !errors.Is(err, domain.ErrWishlistNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	return s.repo.Create(ctx, &domain.Wishlist{
+		UserID:    userID,
+		Name:      domain.SaveForLaterName,
+		Kind:      domain.WishlistKindSaveForLater,
+		Items:     []domain.WishlistItem{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func (s *WishlistServiceImplement) save(ctx context.Context, w *domain.Wishlist) error {
+	w.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, w); err != nil {
+		return fmt.Errorf("failed to save wishlist: %w", err)
+	}
+	return nil
+}
+
+// refresh fills the computed fields of every line from product-ms. Prices
+// are compared in the currency of the saved snapshot.
+func (s *WishlistServiceImplement) refresh(w *domain.Wishlist) {
+	for i := range w.Items {
+		it := &w.Items[i]
+
+		product, err := s.productClient.GetProduct(it.ProductID, it.SavedPrice.Currency)
+		if err != nil {
+			it.Available = false
+			continue
+		}
+
+		it.Available = true
+		it.Name = product.Product.GetName()
+		it.CurrentPrice = money.FromProto(product.Product.GetQuote().GetPrice())
+		it.InStock = product.Product.GetStock() > 0
+		it.BackInStock = it.InStock && !it.SavedInStock
+		if cmp, err := it.CurrentPrice.Cmp(it.SavedPrice); err == nil {
+			it.PriceDropped = cmp < 0
+		}
+	}
+}
+
+func addToList(w *domain.Wishlist, item domain.WishlistItem) {
+	if i := w.Item(item.ProductID); i >= 0 {
+		w.Items[i].Quantity += item.Quantity
+		return
+	}
+	item.AddedAt = time.Now()
+	w.Items = append(w.Items, item)
+}
+
+func validateWishlistName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", domain.ErrInvalidWishlist)
+	}
+	if len(name) > maxWishlistName {
+		return "", fmt.Errorf("%w: name must be at most %d characters", domain.ErrInvalidWishlist, maxWishlistName)
+	}
+	return name, nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"time"
+)
+
+// Event types emitted by cart-ms.
+const (
+	EventCartAbandoned = "cart.abandoned"
+	EventCartRecovered = "cart.recovered"
+)
+
+// AbandonedCartEvent is the payload of cart.abandoned. Stage starts at 1
+// and grows with every idle threshold the cart passes, so the notification
+// side can pick the matching reminder.
+type AbandonedCartEvent struct {
+	UserID    string          `json:"user_id" bson:"user_id"`
+	Stage     int             `json:"stage" bson:"stage"`
+	IdleSince time.Time       `json:"idle_since" bson:"idle_since"`
+	Currency  string          `json:"currency" bson:"currency"`
+	Subtotal  money.Money     `json:"subtotal" bson:"subtotal"`
+	Items     []AbandonedItem `json:"items" bson:"items"`
+}
+
+type AbandonedItem struct {
+	ProductID string      `json:"product_id" bson:"product_id"`
+	Name      string      `json:"name" bson:"name"`
+	Quantity  int         `json:"quantity" bson:"quantity"`
+	Price     money.Money `json:"price" bson:"price"`
+}
+
+// CartRecoveredEvent is the payload of cart.recovered, emitted when a cart
+// that was sent at least one reminder is checked out.
+type CartRecoveredEvent struct {
+	UserID       string      `json:"user_id" bson:"user_id"`
+	StageReached int         `json:"stage_reached" bson:"stage_reached"`
+	Subtotal     money.Money `json:"subtotal" bson:"subtotal"`
+}
+
+// ItemsSubtotal sums the stored item prices without tax.
+func (c *Cart) ItemsSubtotal() money.Money {
+	total := money.Zero(c.Currency)
+	for _, it := range c.Items {
+		if sum, err := total.Add(it.Price.Mul(int64(it.Quantity))); err == nil {
+			total = sum
+		}
+	}
+	return total
+}
@@ -1,18 +1,43 @@
 package domain
 
-import "time"
+import (
+	"ecom-api/pkg/money"
+	"time"
+)
 
 type CartItem struct {
 	ProductID string  `json:"product_id" bson:"product_id"`
 	Name      string  `json:"name" bson:"name"`
-	Price     float64 `json:"price" bson:"price"`
+	Price     money.Money `json:"price" bson:"price"`
 	Quantity  int     `json:"quantity" bson:"quantity"`
 	AddedAt   time.Time `json:"added_at" bson:"added_at"`
+	TaxCategory string `json:"tax_category" bson:"tax_category"`
+	Weight    float64 `json:"weight" bson:"weight"` // unit weight in kg
+	PriceSource  string  `json:"price_source" bson:"price_source"`   // base, list or converted
+	ExchangeRate float64 `json:"exchange_rate" bson:"exchange_rate"` // 1 unless the price was converted
+
+	// quote fields, computed on read
+	TaxRate   float64 `json:"tax_rate" bson:"-"`
+	Tax       money.Money `json:"tax" bson:"-"`
+	LineTotal money.Money `json:"line_total" bson:"-"`
 }
 
 type Cart struct {
 	UserID    string     `json:"user_id" bson:"user_id"`
 	Items     []CartItem `json:"items" bson:"items"`
-	Total     float64    `json:"total" bson:"-"`
+	Currency  string     `json:"currency" bson:"currency"` // set by the first item added
+	Total     money.Money `json:"total" bson:"-"`
 	UpdatedAt time.Time  `json:"updated_at" bson:"updated_at"`
+
+	// abandonment tracking, see AbandonedCartScanner
+	AbandonStage         int        `json:"abandon_stage" bson:"abandon_stage"`                   // reminders sent since the last activity
+	ReminderStageReached int        `json:"reminder_stage_reached" bson:"reminder_stage_reached"` // highest stage over the cart's life
+	LastRemindedAt       *time.Time `json:"last_reminded_at,omitempty" bson:"last_reminded_at,omitempty"`
+
+	// quote fields, computed on read
+	Region           string  `json:"region" bson:"-"`
+	Subtotal         money.Money `json:"subtotal" bson:"-"`
+	TaxTotal         money.Money `json:"tax_total" bson:"-"`
+	PricesIncludeTax bool    `json:"prices_include_tax" bson:"-"`
+	Weight           float64 `json:"weight" bson:"-"`
 }
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"time"
+)
+
+// ReservationHolder names the stock reservations a cart holds in product-ms.
+func ReservationHolder(userID string) string {
+	return "cart:" + userID
+}
+
+// ExpiredCart is the archived copy of a cart removed after its TTL, kept
+// for analytics.
+type ExpiredCart struct {
+	ID        string      `json:"id" bson:"_id,omitempty"`
+	UserID    string      `json:"user_id" bson:"user_id"`
+	Cart      Cart        `json:"cart" bson:"cart"`
+	ItemCount int         `json:"item_count" bson:"item_count"`
+	Subtotal  money.Money `json:"subtotal" bson:"subtotal"`
+	IdleFor   string      `json:"idle_for" bson:"idle_for"` // time since the last change, e.g. "720h0m0s"
+	ExpiredAt time.Time   `json:"expired_at" bson:"expired_at"`
+}
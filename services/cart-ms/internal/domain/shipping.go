@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"errors"
+	"strings"
+)
+
+// ErrCartEmpty is returned when an empty cart is quoted for shipping.
+var ErrCartEmpty = errors.New("cart is empty")
+
+// Address is a shipping destination.
+type Address struct {
+	Name       string `json:"name" bson:"name"`
+	Line1      string `json:"line1" bson:"line1"`
+	Line2      string `json:"line2,omitempty" bson:"line2,omitempty"`
+	City       string `json:"city" bson:"city"`
+	State      string `json:"state,omitempty" bson:"state,omitempty"`
+	PostalCode string `json:"postal_code" bson:"postal_code"`
+	Country    string `json:"country" bson:"country"` // ISO 3166-1 alpha-2
+	Phone      string `json:"phone,omitempty" bson:"phone,omitempty"`
+}
+
+// Region is the tax region of the address, e.g. "US-CA" or "DE".
+func (a Address) Region() string {
+	country := strings.ToUpper(strings.TrimSpace(a.Country))
+	state := strings.ToUpper(strings.TrimSpace(a.State))
+	if country == "" || state == "" {
+		return country
+	}
+	return country + "-" + state
+}
+
+// ShippingQuote is the price of one shipping method for a destination.
+type ShippingQuote struct {
+	MethodCode string      `json:"method_code"`
+	MethodName string      `json:"method_name"`
+	Zone       string      `json:"zone"`
+	Cost       money.Money `json:"cost"`
+	MinDays    int         `json:"min_days"`
+	MaxDays    int         `json:"max_days"`
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"errors"
+	"time"
+)
+
+// Wishlist kinds. Every user has at most one save-for-later list, created
+// on first use; named wishlists are created explicitly.
+const (
+	WishlistKindNamed        = "wishlist"
+	WishlistKindSaveForLater = "save_for_later"
+)
+
+const SaveForLaterName = "Saved for later"
+
+var (
+	ErrWishlistNotFound = errors.New("wishlist not found")
+	ErrItemNotFound     = errors.New("item not found")
+	ErrInvalidWishlist  = errors.New("invalid wishlist")
+)
+
+type Wishlist struct {
+	ID         string         `json:"id" bson:"_id,omitempty"`
+	UserID     string         `json:"user_id" bson:"user_id"`
+	Name       string         `json:"name" bson:"name"`
+	Kind       string         `json:"kind" bson:"kind"`
+	Items      []WishlistItem `json:"items" bson:"items"`
+	ShareToken string         `json:"share_token,omitempty" bson:"share_token,omitempty"` // set while the list is shared
+	CreatedAt  time.Time      `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at" bson:"updated_at"`
+}
+
+// WishlistItem keeps a snapshot of the product taken when it was saved, so
+// price drops and restocks can be detected when the list is fetched.
+type WishlistItem struct {
+	ProductID    string      `json:"product_id" bson:"product_id"`
+	Name         string      `json:"name" bson:"name"`
+	Quantity     int         `json:"quantity" bson:"quantity"`
+	SavedPrice   money.Money `json:"saved_price" bson:"saved_price"`
+	SavedInStock bool        `json:"saved_in_stock" bson:"saved_in_stock"`
+	AddedAt      time.Time   `json:"added_at" bson:"added_at"`
+
+	// computed against product-ms on read
+	CurrentPrice money.Money `json:"current_price" bson:"-"`
+	InStock      bool        `json:"in_stock" bson:"-"`
+	PriceDropped bool        `json:"price_dropped" bson:"-"`
+	BackInStock  bool        `json:"back_in_stock" bson:"-"`
+	Available    bool        `json:"available" bson:"-"` // false when the product no longer exists
+}
+
+// Item returns the index of the line for productID, or -1.
+func (w *Wishlist) Item(productID string) int {
+	for i, it := range w.Items {
+		if it.ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
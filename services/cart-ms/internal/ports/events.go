@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+	"ecom-api/pkg/events"
+)
+
+// EventPublisher announces domain events to other services.
+type EventPublisher interface {
+	Publish(ctx context.Context, e events.Event) error
+}
@@ -1,10 +1,30 @@
 package ports
 
-import "cart-microservice/internal/domain"
+import (
+	"cart-microservice/internal/domain"
+	"context"
+	"time"
+)
 
 type CartRepository interface {
 	GetCart(userID string) (*domain.Cart, error)
-	AddItem(userID string, item domain.CartItem) error
+	AddItem(userID, currency string, item domain.CartItem) error
 	RemoveItem(userID, productID string) error
 	ClearCart(userID string) error
+
+	// FindIdle returns non-empty carts at the given abandon stage that
+	// have not been updated since idleSince and, if remindedBefore is set,
+	// were last reminded no later than remindedBefore; oldest first.
+	FindIdle(ctx context.Context, stage int, idleSince, remindedBefore time.Time, limit int64) ([]domain.Cart, error)
+	// AdvanceAbandonStage moves a cart from stage to stage+1 unless it was
+	// touched or advanced concurrently; it reports whether it did.
+	AdvanceAbandonStage(ctx context.Context, cart *domain.Cart, at time.Time) (bool, error)
+	// RevertAbandonStage undoes AdvanceAbandonStage when publishing failed.
+	RevertAbandonStage(ctx context.Context, cart *domain.Cart) error
+
+	// FindExpired returns carts not updated since before, oldest first.
+	FindExpired(ctx context.Context, before time.Time, limit int64) ([]domain.Cart, error)
+	// ExpireCart archives a cart and deletes it unless it was touched in the
+	// meantime; it reports whether the cart was removed.
+	ExpireCart(ctx context.Context, archive *domain.ExpiredCart) (bool, error)
 }
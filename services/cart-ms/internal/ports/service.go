@@ -1,10 +1,17 @@
 package ports
 
-import "cart-microservice/internal/domain"
+import (
+	"cart-microservice/internal/domain"
+	"context"
+	"ecom-api/pkg/money"
+)
 
 type CartService interface {
-	GetCart(userID string) (*domain.Cart, error)
-	AddItem(userID string, item *domain.CartItem) error 
+	GetCart(userID, region string) (*domain.Cart, error)
+	AddItem(userID, currency string, item *domain.CartItem) error
 	RemoveItem(userID, productID string) error
 	ClearCart(userID string) error
+	CheckoutCart(ctx context.Context, userID string) error
+	GetShippingQuotes(userID string, dest domain.Address) ([]domain.ShippingQuote, error)
+	QuoteShipping(dest domain.Address, weight float64, orderValue money.Money) ([]domain.ShippingQuote, error)
 }
\ No newline at end of file
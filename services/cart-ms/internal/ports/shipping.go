@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"cart-microservice/internal/domain"
+	"ecom-api/pkg/money"
+)
+
+// ShippingRater prices the available shipping methods for a parcel.
+type ShippingRater interface {
+	// QuoteCurrency is the currency rates are configured in.
+	QuoteCurrency() string
+	Quote(dest domain.Address, weight float64, orderValue money.Money) ([]domain.ShippingQuote, error)
+}
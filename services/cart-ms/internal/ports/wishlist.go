@@ -0,0 +1,39 @@
+package ports
+
+import (
+	"cart-microservice/internal/domain"
+	"context"
+)
+
+type WishlistRepository interface {
+	Create(ctx context.Context, w *domain.Wishlist) (*domain.Wishlist, error)
+	FindByID(ctx context.Context, id string) (*domain.Wishlist, error)
+	FindByUser(ctx context.Context, userID string) ([]domain.Wishlist, error)
+	FindByKind(ctx context.Context, userID, kind string) (*domain.Wishlist, error)
+	FindByShareToken(ctx context.Context, token string) (*domain.Wishlist, error)
+	Update(ctx context.Context, w *domain.Wishlist) error
+	Delete(ctx context.Context, id string) error
+}
+
+type WishlistService interface {
+	CreateWishlist(ctx context.Context, userID, name string) (*domain.Wishlist, error)
+	ListWishlists(ctx context.Context, userID string) ([]domain.Wishlist, error)
+	// GetWishlist returns one of the user's lists with price-drop and
+	// back-in-stock flags computed against product-ms.
+	GetWishlist(ctx context.Context, userID, id string) (*domain.Wishlist, error)
+	RenameWishlist(ctx context.Context, userID, id, name string) (*domain.Wishlist, error)
+	DeleteWishlist(ctx context.Context, userID, id string) error
+
+	AddItem(ctx context.Context, userID, id, productID string, quantity int) (*domain.Wishlist, error)
+	RemoveItem(ctx context.Context, userID, id, productID string) (*domain.Wishlist, error)
+
+	// SaveForLater moves a cart line into the user's save-for-later list.
+	SaveForLater(ctx context.Context, userID, productID string) (*domain.Wishlist, error)
+	// MoveToCart adds a list line to the cart. Lines of the save-for-later
+	// list are removed from it, wishlist lines are kept.
+	MoveToCart(ctx context.Context, userID, id, productID string) error
+
+	ShareWishlist(ctx context.Context, userID, id string) (*domain.Wishlist, error)
+	UnshareWishlist(ctx context.Context, userID, id string) error
+	GetSharedWishlist(ctx context.Context, token string) (*domain.Wishlist, error)
+}
@@ -3,19 +3,27 @@ package main
 import (
 	"context"
 	"ecom-api/pkg/auth"
+	"ecom-api/pkg/events"
+	"ecom-api/pkg/money"
+	"ecom-api/pkg/scheduler"
+	"ecom-api/pkg/softdelete"
+	"ecom-api/pkg/tax"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"time"
 
 	"order-microservice/adaptors/grpc/pb/order-microservice/services/order-ms/adaptors/grpc/pb"
 	"order-microservice/internals/adaptors/db"
 	grpcAdapter "order-microservice/internals/adaptors/grpc"
 	httpAdapter "order-microservice/internals/adaptors/http"
+	"order-microservice/internals/adaptors/render"
 	"order-microservice/internals/application"
+	"order-microservice/internals/domain"
 
 	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -53,13 +61,15 @@ auth.InitJWT()
 	grpcPort := os.Getenv("ORDER_GRPC_PORT")
 	cartMsAddr := os.Getenv("CART_MS_GRPC_ADDR")
 	paymentMsAddr := os.Getenv("PAYMENT_MS_GRPC_ADDR")
+	productMsAddr := os.Getenv("PRODUCT_MS_GRPC_ADDR")
+	userMsAddr := os.Getenv("USER_MS_GRPC_ADDR")
 
 	if mongoURI == "" || dbName == "" || httpPort == "" || grpcPort == "" {
 		log.Fatal("❌ Missing required env vars: MONGO_URI, MONGO_DB_NAME, ORDER_HTTP_PORT, ORDER_GRPC_PORT")
 	}
 
 	// --- MongoDB ---
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetRegistry(money.Registry(os.Getenv("DEFAULT_CURRENCY"))))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -90,9 +100,118 @@ auth.InitJWT()
 	defer paymentConn.Close()
 	paymentClient := grpcAdapter.NewPaymentClient(paymentConn)
 
+	// Product-MS
+	productConn, err := grpc.Dial(productMsAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to connect to product-ms at %s: %v", productMsAddr, err)
+	}
+	defer productConn.Close()
+	productClient := grpcAdapter.NewProductClient(productConn)
+
+	// User-MS, asked for email verification when checkout requires it
+	var userClient *grpcAdapter.UserClient
+	if v := os.Getenv("REQUIRE_VERIFIED_EMAIL"); v != "" {
+		required, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Fatalf("invalid REQUIRE_VERIFIED_EMAIL: %v", err)
+		}
+		if required {
+			if userMsAddr == "" {
+				log.Fatal("REQUIRE_VERIFIED_EMAIL needs USER_MS_GRPC_ADDR")
+			}
+			userConn, err := grpc.Dial(userMsAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				log.Fatalf("failed to connect to user-ms at %s: %v", userMsAddr, err)
+			}
+			defer userConn.Close()
+			userClient = grpcAdapter.NewUserClient(userConn)
+		}
+	}
+
+	// --- Tax rules ---
+	taxTable, err := tax.LoadTable(os.Getenv("TAX_RULES_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// --- Invoicing ---
+	legalEntities, err := domain.LoadLegalEntities(os.Getenv("LEGAL_ENTITIES_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	invoiceRenderer, err := render.NewInvoiceRenderer(os.Getenv("INVOICE_TEMPLATE_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	invoices := db.NewMongoInvoiceRepository(dbConn)
+	if err := invoices.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create invoice indexes: %v", err)
+	}
+
+	// --- Scheduler and unpaid order expiry ---
+	jobs, err := scheduler.FromEnv(dbConn, "order-ms")
+	if err != nil {
+		log.Fatal(err)
+	}
+	var unpaidOrderTTL time.Duration
+	if v := os.Getenv("UNPAID_ORDER_TTL"); v != "" {
+		if unpaidOrderTTL, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid UNPAID_ORDER_TTL: %v", err)
+		}
+	}
+	sweepInterval := 15 * time.Minute
+	if v := os.Getenv("UNPAID_ORDER_SWEEP_INTERVAL"); v != "" {
+		if sweepInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid UNPAID_ORDER_SWEEP_INTERVAL: %v", err)
+		}
+	}
+
+	// --- Subscriptions ---
+	subscriptions := db.NewMongoSubscriptionRepository(dbConn)
+	if err := subscriptions.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create subscription indexes: %v", err)
+	}
+	subscriptionRetries, err := domain.ParseRetrySchedule(os.Getenv("SUBSCRIPTION_RETRY_SCHEDULE"))
+	if err != nil {
+		log.Fatalf("invalid SUBSCRIPTION_RETRY_SCHEDULE: %v", err)
+	}
+	billingInterval := 15 * time.Minute
+	if v := os.Getenv("SUBSCRIPTION_SWEEP_INTERVAL"); v != "" {
+		if billingInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid SUBSCRIPTION_SWEEP_INTERVAL: %v", err)
+		}
+	}
+
 	// --- Service ---
 	repo := db.NewMongoOrderRepository(dbConn)
-	service := application.NewOrderService(repo, cartClient, paymentClient)
+	service := application.NewOrderService(repo, db.NewMongoShipmentRepository(dbConn), db.NewMongoReturnRepository(dbConn), invoices, taxTable, legalEntities, invoiceRenderer, application.OrderServiceOptions{
+		CartClient:          cartClient,
+		PaymentClient:       paymentClient,
+		ProductClient:       productClient,
+		UserClient:          userClient,
+		Jobs:                jobs,
+		Publisher:           events.NewMongoOutbox(dbConn),
+		UnpaidOrderTTL:      unpaidOrderTTL,
+		Subscriptions:       subscriptions,
+		SubscriptionRetries: subscriptionRetries,
+	})
+
+	jobs.Handle(domain.JobExpireUnpaidOrder, service.ExpireUnpaidOrder)
+	jobs.Every("expire-unpaid-orders", sweepInterval, func(ctx context.Context) error {
+		_, err := service.ExpireUnpaidOrders(ctx)
+		return err
+	})
+	jobs.Handle(domain.JobBillSubscription, service.BillSubscription)
+	jobs.Every("bill-subscriptions", billingInterval, func(ctx context.Context) error {
+		_, err := service.BillDueSubscriptions(ctx)
+		return err
+	})
+
+	// --- Purge of deleted orders ---
+	purger, err := softdelete.PurgerFromEnv("orders", service.PurgeDeletedOrders)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// --- HTTP setup ---
 	handler := httpAdapter.NewOrderHandler(service)
@@ -112,6 +231,8 @@ auth.InitJWT()
 	}
 
 	g := new(errgroup.Group)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
 
 	// HTTP server
 	g.Go(func() error {
@@ -125,6 +246,16 @@ auth.InitJWT()
 		return grpcServer.Serve(lis)
 	})
 
+	// purge of deleted records
+	g.Go(func() error {
+		return purger.Run(workerCtx)
+	})
+
+	// scheduled jobs, run by one replica at a time
+	g.Go(func() error {
+		return jobs.Run(workerCtx)
+	})
+
 	// --- Graceful shutdown ---
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, os.Kill)
@@ -132,6 +263,7 @@ auth.InitJWT()
 	go func() {
 		<-stop
 		fmt.Println("\n🛑 Shutting down Order service...")
+		stopWorkers()
 
 		// shutdown HTTP
 		ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"order-microservice/internals/domain"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Unique indexes of the invoices collection. The first keeps each number
+// of a legal entity's sequence to a single document, the second keeps an
+// order from being invoiced or credited twice for the same thing.
+const (
+	invoiceNumberIndex    = "entity_kind_sequence"
+	invoiceReferenceIndex = "order_kind_reference"
+)
+
+type MongoInvoiceRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoInvoiceRepository(db *mongo.Database) *MongoInvoiceRepository {
+	return &MongoInvoiceRepository{
+		collection: db.Collection("invoices"),
+	}
+}
+
+// EnsureIndexes creates the unique indexes numbering relies on.
+func (r *MongoInvoiceRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "entity", Value: 1}, {Key: "kind", Value: 1}, {Key: "sequence", Value: -1}},
+			Options: options.Index().SetName(invoiceNumberIndex).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "order_id", Value: 1}, {Key: "kind", Value: 1}, {Key: "reference", Value: 1}},
+			Options: options.Index().SetName(invoiceReferenceIndex).SetUnique(true),
+		},
+	})
+	return err
+}
+
+// NextSequence returns the number after the highest one the entity issued
+// for kind. Numbers only exist once their document was inserted, so a
+// failed insert leaves no gap.
+func (r *MongoInvoiceRepository) NextSequence(ctx context.Context, entity, kind string) (int64, error) {
+	var last struct {
+		Sequence int64 `bson:"sequence"`
+	}
+	opts := options.FindOne().SetSort(bson.M{"sequence": -1}).SetProjection(bson.M{"sequence": 1})
+	err := r.collection.FindOne(ctx, bson.M{"entity": entity, "kind": kind}, opts).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return last.Sequence + 1, nil
+}
+
+func (r *MongoInvoiceRepository) Create(ctx context.Context, inv *domain.Invoice) (*domain.Invoice, error) {
+	oid := primitive.NewObjectID()
+
+	_, err := r.collection.InsertOne(ctx, bson.M{
+		"_id":            oid,
+		"kind":           inv.Kind,
+		"entity":         inv.Entity,
+		"sequence":       inv.Sequence,
+		"number":         inv.Number,
+		"order_id":       inv.OrderID,
+		"reference":      inv.Reference,
+		"invoice_number": inv.InvoiceNumber,
+		"issued_at":      inv.IssuedAt,
+		"currency":       inv.Currency,
+		"bill_to":        inv.BillTo,
+		"lines":          inv.Lines,
+		"subtotal":       inv.Subtotal,
+		"tax_total":      inv.TaxTotal,
+		"shipping":       inv.Shipping,
+		"total":          inv.Total,
+		"tax_summary":    inv.TaxSummary,
+		"pdf":            inv.PDF,
+		"pdf_sha256":     inv.PDFSHA256,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		if strings.Contains(err.Error(), invoiceReferenceIndex) {
+			return nil, domain.ErrInvoiceAlreadyIssued
+		}
+		return nil, domain.ErrInvoiceNumberTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	inv.ID = oid.Hex()
+	return inv, nil
+}
+
+func (r *MongoInvoiceRepository) FindByID(ctx context.Context, id string) (*domain.Invoice, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	return r.findOne(ctx, bson.M{"_id": oid})
+}
+
+func (r *MongoInvoiceRepository) FindByReference(ctx context.Context, orderID, kind, reference string) (*domain.Invoice, error) {
+	return r.findOne(ctx, bson.M{"order_id": orderID, "kind": kind, "reference": reference})
+}
+
+// FindByOrder lists the invoice and credit notes of an order without their
+// PDFs.
+func (r *MongoInvoiceRepository) FindByOrder(ctx context.Context, orderID string) ([]*domain.Invoice, error) {
+	opts := options.Find().SetSort(bson.M{"issued_at": 1}).SetProjection(bson.M{"pdf": 0})
+	cur, err := r.collection.Find(ctx, bson.M{"order_id": orderID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	invoices := []*domain.Invoice{}
+	for cur.Next(ctx) {
+		var inv domain.Invoice
+		if err := cur.Decode(&inv); err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, &inv)
+	}
+	return invoices, cur.Err()
+}
+
+func (r *MongoInvoiceRepository) findOne(ctx context.Context, filter bson.M) (*domain.Invoice, error) {
+	var inv domain.Invoice
+	err := r.collection.FindOne(ctx, filter).Decode(&inv)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
@@ -2,18 +2,116 @@ package db
 
 import (
 	"context"
+	"ecom-api/pkg/money"
+	"ecom-api/pkg/softdelete"
+	"errors"
 	"fmt"
 	"order-microservice/internals/domain"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MongoOrderRepository struct {
 	collection *mongo.Collection
 }
 
+// orderDocument is the stored shape of an order; _id is kept as ObjectID
+type orderDocument struct {
+	ID               primitive.ObjectID `bson:"_id"`
+	UserID           string             `bson:"user_id"`
+	Items            []domain.OrderItem `bson:"items"`
+	Subtotal         money.Money        `bson:"subtotal"`
+	TaxTotal         money.Money        `bson:"tax_total"`
+	Total            money.Money        `bson:"total"`
+	Currency         string             `bson:"currency"`
+	TaxRegion        string             `bson:"tax_region"`
+	PricesIncludeTax bool               `bson:"prices_include_tax"`
+	ShippingAddress  *domain.Address    `bson:"shipping_address,omitempty"`
+	ShippingMethod   string             `bson:"shipping_method,omitempty"`
+	ShippingCost     money.Money        `bson:"shipping_cost"`
+	Status           string             `bson:"status"`
+	StockCommitted   bool               `bson:"stock_committed"`
+	Cancellation     *domain.Cancellation `bson:"cancellation,omitempty"`
+	FulfillmentRev   int64              `bson:"fulfillment_rev"`
+	StatusHistory    []domain.StatusChange `bson:"status_history,omitempty"`
+	SubscriptionID    string            `bson:"subscription_id,omitempty"`
+	SubscriptionCycle int               `bson:"subscription_cycle,omitempty"`
+	CreatedAt        time.Time          `bson:"created_at"`
+	DeletedAt        *time.Time         `bson:"deleted_at,omitempty"`
+	DeletedBy        string             `bson:"deleted_by,omitempty"`
+}
+
+func newOrderDocument(oid primitive.ObjectID, o *domain.Order) *orderDocument {
+	return &orderDocument{
+		ID:               oid,
+		UserID:           o.UserID,
+		Items:            o.Items,
+		Subtotal:         o.Subtotal,
+		TaxTotal:         o.TaxTotal,
+		Total:            o.Total,
+		Currency:         o.Currency,
+		TaxRegion:        o.TaxRegion,
+		PricesIncludeTax: o.PricesIncludeTax,
+		ShippingAddress:  o.ShippingAddress,
+		ShippingMethod:   o.ShippingMethod,
+		ShippingCost:     o.ShippingCost,
+		Status:           o.Status,
+		StockCommitted:   o.StockCommitted,
+		Cancellation:     o.Cancellation,
+		FulfillmentRev:   o.FulfillmentRev,
+		StatusHistory:    o.StatusHistory,
+		SubscriptionID:    o.SubscriptionID,
+		SubscriptionCycle: o.SubscriptionCycle,
+		CreatedAt:        o.CreatedAt,
+	}
+}
+
+func (d *orderDocument) toDomain() *domain.Order {
+	// orders stored before created_at was recorded carry it in their _id
+	createdAt := d.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = d.ID.Timestamp()
+	}
+	// so do the status orders had before the history was kept; statusUpdate
+	// stores the same entry when such an order next changes
+	history := d.StatusHistory
+	if len(history) == 0 {
+		history = []domain.StatusChange{{Status: d.Status, At: createdAt}}
+	}
+	for i := range history {
+		history[i].Seq = int64(i + 1)
+	}
+
+	return &domain.Order{
+		ID:               d.ID.Hex(),
+		UserID:           d.UserID,
+		Items:            d.Items,
+		Subtotal:         d.Subtotal,
+		TaxTotal:         d.TaxTotal,
+		Total:            d.Total,
+		Currency:         d.Currency,
+		TaxRegion:        d.TaxRegion,
+		PricesIncludeTax: d.PricesIncludeTax,
+		ShippingAddress:  d.ShippingAddress,
+		ShippingMethod:   d.ShippingMethod,
+		ShippingCost:     d.ShippingCost,
+		Status:           d.Status,
+		StockCommitted:   d.StockCommitted,
+		Cancellation:     d.Cancellation,
+		FulfillmentRev:   d.FulfillmentRev,
+		StatusHistory:    history,
+		SubscriptionID:    d.SubscriptionID,
+		SubscriptionCycle: d.SubscriptionCycle,
+		CreatedAt:        createdAt,
+		DeletedAt:        d.DeletedAt,
+		DeletedBy:        d.DeletedBy,
+	}
+}
+
 func NewMongoOrderRepository(db *mongo.Database) *MongoOrderRepository {
 	return &MongoOrderRepository{
 		collection: db.Collection("orders"),
@@ -22,17 +120,10 @@ func NewMongoOrderRepository(db *mongo.Database) *MongoOrderRepository {
 
 func (r *MongoOrderRepository) Create(ctx context.Context, o *domain.Order) (*domain.Order, error) {
 	oid := primitive.NewObjectID()
+	o.CreatedAt = oid.Timestamp()
+	o.StatusHistory = []domain.StatusChange{{Seq: 1, Status: o.Status, At: o.CreatedAt}}
 
-	// build Mongo doc manually so _id is ObjectID
-	doc := bson.M{
-		"_id":     oid,
-		"user_id": o.UserID,
-		"items":   o.Items,
-		"total":   o.Total,
-		"status":  o.Status,
-	}
-
-	_, err := r.collection.InsertOne(ctx, doc)
+	_, err := r.collection.InsertOne(ctx, newOrderDocument(oid, o))
 	if err != nil {
 		return nil, err
 	}
@@ -48,30 +139,25 @@ func (r *MongoOrderRepository) FindByID(ctx context.Context, id string) (*domain
 		return nil, err
 	}
 
-	var result struct {
-		ID     primitive.ObjectID `bson:"_id"`
-		UserID string             `bson:"user_id"`
-		Items  []domain.OrderItem `bson:"items"`
-		Total  float64            `bson:"total"`
-		Status string             `bson:"status"`
+	var result orderDocument
+	err = r.collection.FindOne(ctx, softdelete.Active(bson.M{"_id": oid})).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrOrderNotFound
 	}
-
-	err = r.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&result)
 	if err != nil {
 		return nil, err
 	}
 
-	return &domain.Order{
-		ID:     result.ID.Hex(),
-		UserID: result.UserID,
-		Items:  result.Items,
-		Total:  result.Total,
-		Status: result.Status,
-	}, nil
+	return result.toDomain(), nil
 }
 
-func (r *MongoOrderRepository) List(ctx context.Context) ([]*domain.Order, error) {
-	cur, err := r.collection.Find(ctx, bson.M{})
+// List returns the orders of userID, or of every user when it is empty.
+func (r *MongoOrderRepository) List(ctx context.Context, userID string) ([]*domain.Order, error) {
+	filter := bson.M{}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+	cur, err := r.collection.Find(ctx, softdelete.Active(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -79,31 +165,50 @@ func (r *MongoOrderRepository) List(ctx context.Context) ([]*domain.Order, error
 
 	var orders []*domain.Order
 	for cur.Next(ctx) {
-		var result struct {
-			ID     primitive.ObjectID `bson:"_id"`
-			UserID string             `bson:"user_id"`
-			Items  []domain.OrderItem `bson:"items"`
-			Total  float64            `bson:"total"`
-			Status string             `bson:"status"`
-		}
-
+		var result orderDocument
 		if err := cur.Decode(&result); err != nil {
 			return nil, err
 		}
 
-		orders = append(orders, &domain.Order{
-			ID:     result.ID.Hex(),
-			UserID: result.UserID,
-			Items:  result.Items,
-			Total:  result.Total,
-			Status: result.Status,
-		})
+		orders = append(orders, result.toDomain())
 	}
 
 	return orders, nil
 }
 
-func (r *MongoOrderRepository) UpdateOrderStatus(ctx context.Context, id string, status string) (*domain.Order, error) {
+// FindUnpaid returns up to limit orders placed before before that are still
+// waiting for a successful payment, oldest first. Orders of subscriptions
+// are left out: subscriptions retry their own payments.
+func (r *MongoOrderRepository) FindUnpaid(ctx context.Context, before time.Time, limit int64) ([]*domain.Order, error) {
+	filter := softdelete.Active(bson.M{
+		"_id":    bson.M{"$lt": primitive.NewObjectIDFromTimestamp(before)},
+		"status": bson.M{"$in": []string{domain.StatusPending, domain.StatusFailed}},
+		"subscription_id": bson.M{"$exists": false},
+	})
+	cur, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var orders []*domain.Order
+	for cur.Next(ctx) {
+		var result orderDocument
+		if err := cur.Decode(&result); err != nil {
+			return nil, err
+		}
+		orders = append(orders, result.toDomain())
+	}
+	return orders, cur.Err()
+}
+
+// UpdateOrderStatus moves an order from one payment status to another. It
+// fails with ErrStatusChanged if the order is no longer in from, e.g.
+// because it was cancelled meanwhile.
+func (r *MongoOrderRepository) UpdateOrderStatus(ctx context.Context, id, from, status string) (*domain.Order, error) {
+	if !domain.CanChangePaymentStatus(from, status) {
+		return nil, fmt.Errorf("%w: %s to %s", domain.ErrInvalidStatus, from, status)
+	}
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, fmt.Errorf("invalid ObjectID %s: %w", id, err)
@@ -111,8 +216,8 @@ func (r *MongoOrderRepository) UpdateOrderStatus(ctx context.Context, id string,
 
 	res, err := r.collection.UpdateOne(
 		ctx,
-		bson.M{"_id": oid},
-		bson.M{"$set": bson.M{"status": status}},
+		softdelete.Active(bson.M{"_id": oid, "status": from}),
+		statusUpdate(status, nil),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("mongo update error: %w", err)
@@ -123,7 +228,10 @@ func (r *MongoOrderRepository) UpdateOrderStatus(ctx context.Context, id string,
 		id, status, res.MatchedCount, res.ModifiedCount)
 
 	if res.MatchedCount == 0 {
-		return nil, fmt.Errorf("no order found with id=%s", id)
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: order %s is no longer %s", domain.ErrStatusChanged, id, from)
 	}
 
 	// fetch updated doc
@@ -131,11 +239,212 @@ func (r *MongoOrderRepository) UpdateOrderStatus(ctx context.Context, id string,
 }
 
 
-func (r *MongoOrderRepository) Delete(ctx context.Context, id string) error {
+func (r *MongoOrderRepository) Delete(ctx context.Context, id, deletedBy string) error {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": oid})
+	res, err := r.collection.UpdateOne(ctx, softdelete.Active(bson.M{"_id": oid}), softdelete.MarkDeleted(deletedBy, time.Now()))
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrOrderNotFound
+	}
+	return nil
+}
+
+func (r *MongoOrderRepository) Restore(ctx context.Context, id string) (*domain.Order, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ObjectID %s: %w", id, err)
+	}
+
+	res, err := r.collection.UpdateOne(ctx, softdelete.Deleted(bson.M{"_id": oid}), softdelete.Restore())
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		return nil, domain.ErrOrderNotFound
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *MongoOrderRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return softdelete.Purge(ctx, r.collection, before)
+}
+
+func (r *MongoOrderRepository) Cancel(ctx context.Context, id, from string, c *domain.Cancellation) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid ObjectID %s: %w", id, err)
+	}
+
+	res, err := r.collection.UpdateOne(ctx,
+		softdelete.Active(bson.M{"_id": oid, "status": from}),
+		statusUpdate(domain.StatusCancelled, bson.M{"cancellation": c}),
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *MongoOrderRepository) UpdateCancellation(ctx context.Context, id string, c *domain.Cancellation) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ObjectID %s: %w", id, err)
+	}
+
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"_id": oid, "status": domain.StatusCancelled},
+		bson.M{"$set": bson.M{"cancellation": c}},
+	)
+	return err
+}
+
+func (r *MongoOrderRepository) FindBySubscriptionCycle(ctx context.Context, subscriptionID string, cycle int) (*domain.Order, error) {
+	var result orderDocument
+	err := r.collection.FindOne(ctx, softdelete.Active(bson.M{
+		"subscription_id":    subscriptionID,
+		"subscription_cycle": cycle,
+	})).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.toDomain(), nil
+}
+
+func (r *MongoOrderRepository) FindByUser(ctx context.Context, userID string, statuses []string) ([]*domain.Order, error) {
+	filter := softdelete.Active(bson.M{
+		"user_id": userID,
+		"status":  bson.M{"$in": statuses},
+	})
+	cur, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var orders []*domain.Order
+	for cur.Next(ctx) {
+		var result orderDocument
+		if err := cur.Decode(&result); err != nil {
+			return nil, err
+		}
+		orders = append(orders, result.toDomain())
+	}
+	return orders, cur.Err()
+}
+
+func (r *MongoOrderRepository) AnonymizeUser(ctx context.Context, userID, anonymousID string) (int64, error) {
+	res, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$set":   bson.M{"user_id": anonymousID},
+			"$unset": addressIdentifiers("shipping_address"),
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	// customers may have cancelled or deleted their orders themselves
+	for _, field := range []string{"cancellation.cancelled_by", "deleted_by"} {
+		if _, err := r.collection.UpdateMany(ctx, bson.M{field: userID}, bson.M{"$set": bson.M{field: anonymousID}}); err != nil {
+			return 0, err
+		}
+	}
+	return res.ModifiedCount, nil
+}
+
+// addressIdentifiers unsets the parts of the address at path that identify
+// a person. The country and state stay: tax regions rest on them.
+func addressIdentifiers(path string) bson.M {
+	unset := bson.M{}
+	for _, field := range []string{"name", "line1", "line2", "city", "postal_code", "phone"} {
+		unset[path+"."+field] = ""
+	}
+	return unset
+}
+
+func (r *MongoOrderRepository) MarkStockCommitted(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ObjectID %s: %w", id, err)
+	}
+
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": bson.M{"stock_committed": true}})
 	return err
 }
+
+func (r *MongoOrderRepository) AdvanceFulfillment(ctx context.Context, id string, rev int64) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid ObjectID %s: %w", id, err)
+	}
+
+	// orders created before shipments existed have no revision yet
+	filter := bson.M{"_id": oid, "fulfillment_rev": rev}
+	if rev == 0 {
+		filter = bson.M{"_id": oid, "fulfillment_rev": bson.M{"$in": bson.A{0, nil}}}
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"fulfillment_rev": rev + 1}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *MongoOrderRepository) SetFulfillmentStatus(ctx context.Context, id string, rev int64, status string) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid ObjectID %s: %w", id, err)
+	}
+
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{
+			"_id":             oid,
+			"fulfillment_rev": rev,
+			"status": bson.M{"$in": bson.A{
+				domain.StatusCompleted, domain.StatusPartiallyShipped, domain.StatusShipped, domain.StatusDelivered,
+			}},
+		},
+		statusUpdate(status, nil),
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// statusUpdate sets the status of an order, along with fields, and appends
+// the change to its status history in the same write. An order without a
+// history gets its previous status as the first entry, the one toDomain
+// reports for it. Setting the status an order already has adds nothing.
+func statusUpdate(status string, fields bson.M) mongo.Pipeline {
+	createdAt := bson.M{"$ifNull": bson.A{"$created_at", bson.M{"$toDate": "$_id"}}}
+	history := bson.M{"$ifNull": bson.A{
+		"$status_history",
+		bson.A{bson.M{"status": "$status", "at": createdAt}},
+	}}
+	change := bson.M{"status": bson.M{"$literal": status}, "at": time.Now().UTC()}
+
+	set := bson.M{
+		"status": bson.M{"$literal": status},
+		"status_history": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$status", bson.M{"$literal": status}}},
+			"$status_history",
+			bson.M{"$concatArrays": bson.A{history, bson.A{change}}},
+		}},
+	}
+	// values are stored as they are, not read as expressions
+	for k, v := range fields {
+		set[k] = bson.M{"$literal": v}
+	}
+	return mongo.Pipeline{{{Key: "$set", Value: set}}}
+}
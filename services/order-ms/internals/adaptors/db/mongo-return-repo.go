@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-microservice/internals/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoReturnRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoReturnRepository(db *mongo.Database) *MongoReturnRepository {
+	return &MongoReturnRepository{
+		collection: db.Collection("returns"),
+	}
+}
+
+func (r *MongoReturnRepository) Create(ctx context.Context, ret *domain.Return) (*domain.Return, error) {
+	oid := primitive.NewObjectID()
+
+	_, err := r.collection.InsertOne(ctx, bson.M{
+		"_id":            oid,
+		"order_id":       ret.OrderID,
+		"user_id":        ret.UserID,
+		"lines":          ret.Lines,
+		"status":         ret.Status,
+		"requested_at":   ret.RequestedAt,
+		"refund":         ret.Refund,
+		"refunded":       ret.Refunded,
+		"stock_returned": ret.StockReturned,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret.ID = oid.Hex()
+	return ret, nil
+}
+
+func (r *MongoReturnRepository) FindByID(ctx context.Context, id string) (*domain.Return, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrReturnNotFound
+	}
+
+	var ret domain.Return
+	err = r.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&ret)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrReturnNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (r *MongoReturnRepository) FindByOrder(ctx context.Context, orderID string) ([]*domain.Return, error) {
+	cur, err := r.collection.Find(ctx, bson.M{"order_id": orderID}, options.Find().SetSort(bson.M{"requested_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	returns := []*domain.Return{}
+	for cur.Next(ctx) {
+		var ret domain.Return
+		if err := cur.Decode(&ret); err != nil {
+			return nil, err
+		}
+		returns = append(returns, &ret)
+	}
+	return returns, cur.Err()
+}
+
+func (r *MongoReturnRepository) Update(ctx context.Context, ret *domain.Return, from string) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(ret.ID)
+	if err != nil {
+		return false, fmt.Errorf("invalid ObjectID %s: %w", ret.ID, err)
+	}
+
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": oid, "status": from},
+		bson.M{"$set": bson.M{
+			"lines":          ret.Lines,
+			"status":         ret.Status,
+			"review":         ret.Review,
+			"receipt":        ret.Receipt,
+			"refund":         ret.Refund,
+			"refunded":       ret.Refunded,
+			"stock_returned": ret.StockReturned,
+			"completed_at":   ret.CompletedAt,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (r *MongoReturnRepository) Delete(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ObjectID %s: %w", id, err)
+	}
+
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": oid})
+	return err
+}
+
+func (r *MongoReturnRepository) AnonymizeUser(ctx context.Context, userID, anonymousID string) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"user_id": userID}, bson.M{"$set": bson.M{"user_id": anonymousID}})
+	return err
+}
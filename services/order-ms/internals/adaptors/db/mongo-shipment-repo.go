@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-microservice/internals/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoShipmentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoShipmentRepository(db *mongo.Database) *MongoShipmentRepository {
+	return &MongoShipmentRepository{
+		collection: db.Collection("shipments"),
+	}
+}
+
+func (r *MongoShipmentRepository) Create(ctx context.Context, s *domain.Shipment) (*domain.Shipment, error) {
+	oid := primitive.NewObjectID()
+
+	_, err := r.collection.InsertOne(ctx, bson.M{
+		"_id":             oid,
+		"order_id":        s.OrderID,
+		"lines":           s.Lines,
+		"carrier":         s.Carrier,
+		"tracking_number": s.TrackingNumber,
+		"status":          s.Status,
+		"events":          s.Events,
+		"created_by":      s.CreatedBy,
+		"created_at":      s.CreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.ID = oid.Hex()
+	return s, nil
+}
+
+func (r *MongoShipmentRepository) FindByID(ctx context.Context, id string) (*domain.Shipment, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrShipmentNotFound
+	}
+
+	var s domain.Shipment
+	err = r.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrShipmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *MongoShipmentRepository) FindByOrder(ctx context.Context, orderID string) ([]*domain.Shipment, error) {
+	cur, err := r.collection.Find(ctx, bson.M{"order_id": orderID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	shipments := []*domain.Shipment{}
+	for cur.Next(ctx) {
+		var s domain.Shipment
+		if err := cur.Decode(&s); err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, &s)
+	}
+	return shipments, cur.Err()
+}
+
+func (r *MongoShipmentRepository) Update(ctx context.Context, s *domain.Shipment, from string) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(s.ID)
+	if err != nil {
+		return false, fmt.Errorf("invalid ObjectID %s: %w", s.ID, err)
+	}
+	if len(s.Events) == 0 {
+		return false, fmt.Errorf("shipment %s has no tracking events", s.ID)
+	}
+
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": oid, "status": from, "events": bson.M{"$size": len(s.Events) - 1}},
+		bson.M{"$set": bson.M{
+			"status":          s.Status,
+			"tracking_number": s.TrackingNumber,
+			"events":          s.Events,
+			"shipped_at":      s.ShippedAt,
+			"delivered_at":    s.DeliveredAt,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (r *MongoShipmentRepository) Delete(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ObjectID %s: %w", id, err)
+	}
+
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": oid})
+	return err
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-microservice/internals/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoSubscriptionRepository stores subscriptions with the time they are
+// next due, so the scheduler's sweep finds them without a scan.
+type MongoSubscriptionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoSubscriptionRepository(db *mongo.Database) *MongoSubscriptionRepository {
+	return &MongoSubscriptionRepository{
+		collection: db.Collection("subscriptions"),
+	}
+}
+
+func (r *MongoSubscriptionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "due_at", Value: 1}}, Options: options.Index().SetName("due_at").SetSparse(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}, Options: options.Index().SetName("user_id_created_at")},
+	})
+	return err
+}
+
+func (r *MongoSubscriptionRepository) Create(ctx context.Context, s *domain.Subscription) (*domain.Subscription, error) {
+	oid := primitive.NewObjectID()
+	s.ID = ""
+	s.Rev = 1
+
+	doc, err := subscriptionDocument(s)
+	if err != nil {
+		return nil, err
+	}
+	doc["_id"] = oid
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return nil, err
+	}
+
+	s.ID = oid.Hex()
+	return s, nil
+}
+
+func (r *MongoSubscriptionRepository) FindByID(ctx context.Context, id string) (*domain.Subscription, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	var s domain.Subscription
+	err = r.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *MongoSubscriptionRepository) List(ctx context.Context, userID, status string, limit int64) ([]*domain.Subscription, error) {
+	filter := bson.M{}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+	return r.find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit))
+}
+
+func (r *MongoSubscriptionRepository) Update(ctx context.Context, s *domain.Subscription) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(s.ID)
+	if err != nil {
+		return false, fmt.Errorf("invalid ObjectID %s: %w", s.ID, err)
+	}
+
+	doc, err := subscriptionDocument(s)
+	if err != nil {
+		return false, err
+	}
+	doc["rev"] = s.Rev + 1
+	update := bson.M{"$set": doc}
+	if _, ok := doc["due_at"]; !ok {
+		update["$unset"] = bson.M{"due_at": ""}
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": oid, "rev": s.Rev}, update)
+	if err != nil {
+		return false, err
+	}
+	if res.MatchedCount == 0 {
+		return false, nil
+	}
+	s.Rev++
+	return true, nil
+}
+
+func (r *MongoSubscriptionRepository) FindDue(ctx context.Context, before time.Time, limit int64) ([]*domain.Subscription, error) {
+	return r.find(ctx, bson.M{"due_at": bson.M{"$lte": before}}, options.Find().SetSort(bson.D{{Key: "due_at", Value: 1}}).SetLimit(limit))
+}
+
+func (r *MongoSubscriptionRepository) AnonymizeUser(ctx context.Context, userID, anonymousID string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$set":   bson.M{"user_id": anonymousID},
+			"$unset": addressIdentifiers("shipping_address"),
+			"$inc":   bson.M{"rev": 1},
+		},
+	)
+	return err
+}
+
+func (r *MongoSubscriptionRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*domain.Subscription, error) {
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	subs := []*domain.Subscription{}
+	for cur.Next(ctx) {
+		var s domain.Subscription
+		if err := cur.Decode(&s); err != nil {
+			return nil, err
+		}
+		subs = append(subs, &s)
+	}
+	return subs, cur.Err()
+}
+
+// subscriptionDocument is the stored shape of a subscription: its fields
+// and, unless nothing is due, when it is due next. The _id is left out.
+func subscriptionDocument(s *domain.Subscription) (bson.M, error) {
+	raw, err := bson.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	delete(doc, "_id")
+	if due := s.DueAt(); !due.IsZero() {
+		doc["due_at"] = due
+	}
+	return doc, nil
+}
@@ -3,6 +3,8 @@ package grpc
 import (
 	"cart-microservice/adaptors/grpc/pb/cart-microservice/services/cart-ms/adaptors/grpc/pb"
 	"context"
+	"ecom-api/pkg/money"
+	"order-microservice/internals/domain"
 
 	"google.golang.org/grpc"
 )
@@ -17,8 +19,8 @@ func NewCartClient(conn *grpc.ClientConn) *CartClient {
 	}
 }
 
-func (c *CartClient) GetCart(ctx context.Context, userID string) (*pb.GetCartResponse, error) {
-	res, err := c.client.GetCart(ctx, &pb.GetCartRequest{UserId: userID})
+func (c *CartClient) GetCart(ctx context.Context, userID, region string) (*pb.GetCartResponse, error) {
+	res, err := c.client.GetCart(ctx, &pb.GetCartRequest{UserId: userID, Region: region})
 	if err != nil {
 		return nil, err
 	}
@@ -28,7 +30,30 @@ func (c *CartClient) GetCart(ctx context.Context, userID string) (*pb.GetCartRes
 
 
 func (c *CartClient) ClearCart(ctx context.Context, userID string) (*pb.ClearCartResponse, error) {
-	return c.client.ClearCart(ctx, &pb.ClearCartRequest{UserId: userID})
+	return c.client.ClearCart(ctx, &pb.ClearCartRequest{UserId: userID, CheckedOut: true})
 }
 
+// GetShippingQuotes prices shipping to dest. With a userID the user's cart
+// is quoted, otherwise weight and orderValue describe the parcel.
+func (c *CartClient) GetShippingQuotes(ctx context.Context, userID string, dest domain.Address, weight float64, orderValue money.Money) ([]*pb.ShippingQuote, error) {
+	res, err := c.client.GetShippingQuotes(ctx, &pb.GetShippingQuotesRequest{
+		UserId: userID,
+		Address: &pb.Address{
+			Name:       dest.Name,
+			Line1:      dest.Line1,
+			Line2:      dest.Line2,
+			City:       dest.City,
+			State:      dest.State,
+			PostalCode: dest.PostalCode,
+			Country:    dest.Country,
+			Phone:      dest.Phone,
+		},
+		Weight:     weight,
+		OrderValue: money.ToProto(orderValue),
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	return res.Quotes, nil
+}
@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"context"
+	"ecom-api/pkg/money"
 	"log"
 	"payment-microservice/adaptors/grpc/pb/payment-microservice/services/payment-ms/adaptors/grpc/pb"
 
@@ -20,11 +21,14 @@ func NewPaymentClient(conn *grpc.ClientConn) *PaymentClient {
 	}
 }
 
-func (p *PaymentClient) ProcessPayment(ctx context.Context, orderID, userID string, amount float64) (*pb.Payment, error) {
+// ProcessPayment charges an order to the user's saved payment method
+// methodID, or to their default method if it is empty.
+func (p *PaymentClient) ProcessPayment(ctx context.Context, orderID, userID string, amount money.Money, methodID string) (*pb.Payment, error) {
 	resp, err := p.client.ProcessPayment(ctx, &pb.ProcessPaymentRequest{
-		OrderId: orderID,
-		UserId:  userID,
-		Amount:  amount,
+		OrderId:  orderID,
+		UserId:   userID,
+		Amount:   money.ToProto(amount),
+		MethodId: methodID,
 	})
 	if err != nil {
 		log.Printf("failed to process payment: %v", err)
@@ -42,3 +46,23 @@ func (p *PaymentClient) NotifyOrderCreated(ctx context.Context, orderID string)
 	}
 	return resp.GetMessage(), nil
 }
+
+// CancelOrderPayment voids or refunds the payments of a cancelled order.
+func (p *PaymentClient) CancelOrderPayment(ctx context.Context, orderID, reason string) (*pb.CancelOrderPaymentResponse, error) {
+	return p.client.CancelOrderPayment(ctx, &pb.CancelOrderPaymentRequest{
+		OrderId: orderID,
+		Reason:  reason,
+	})
+}
+
+// RefundOrderPayment refunds amount of an order's payments once per
+// reference, into the user's store credit if toStoreCredit is set.
+func (p *PaymentClient) RefundOrderPayment(ctx context.Context, orderID, reference string, amount money.Money, reason string, toStoreCredit bool) (*pb.RefundPaymentResponse, error) {
+	return p.client.RefundPayment(ctx, &pb.RefundPaymentRequest{
+		OrderId:       orderID,
+		Reference:     reference,
+		Amount:        money.ToProto(amount),
+		Reason:        reason,
+		ToStoreCredit: toStoreCredit,
+	})
+}
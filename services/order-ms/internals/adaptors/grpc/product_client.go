@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"order-microservice/internals/domain"
+	"product-microservice/adaptors/grpc/pb/product-microservice/services/product-ms/adaptors/grpc/pb"
+
+	"google.golang.org/grpc"
+)
+
+type ProductClient struct {
+	client pb.ProductServiceClient
+}
+
+func NewProductClient(conn *grpc.ClientConn) *ProductClient {
+	return &ProductClient{
+		client: pb.NewProductServiceClient(conn),
+	}
+}
+
+// RestockItems puts the quantities of the order lines back on the shelf.
+// product-ms applies a reference once, so the call is safe to repeat.
+func (c *ProductClient) RestockItems(ctx context.Context, reference string, items []domain.OrderItem) error {
+	req := &pb.RestockItemsRequest{Reference: reference}
+	for _, it := range items {
+		req.Items = append(req.Items, &pb.StockLine{ProductId: it.ProductID, Quantity: int32(it.Quantity)})
+	}
+	_, err := c.client.RestockItems(ctx, req)
+	return err
+}
+
+// GetProduct fetches a product with its price quoted in currency, or in
+// the product's base currency if currency is empty.
+func (c *ProductClient) GetProduct(ctx context.Context, id, currency string) (*pb.Product, error) {
+	res, err := c.client.GetProduct(ctx, &pb.GetProductRequest{Id: id, Currency: currency})
+	if err != nil {
+		return nil, err
+	}
+	return res.GetProduct(), nil
+}
+
+// ProductNames looks up the names of products for printing. Products that
+// cannot be found, e.g. because they were deleted since, are left out.
+func (c *ProductClient) ProductNames(ctx context.Context, ids []string) map[string]string {
+	names := make(map[string]string, len(ids))
+	for _, id := range ids {
+		if _, done := names[id]; done {
+			continue
+		}
+		res, err := c.client.GetProduct(ctx, &pb.GetProductRequest{Id: id})
+		if err != nil || res.GetProduct() == nil {
+			continue
+		}
+		names[id] = res.GetProduct().GetName()
+	}
+	return names
+}
@@ -2,9 +2,17 @@ package grpc
 
 import (
 	"context"
+	"ecom-api/pkg/money"
+	"errors"
+	"fmt"
 	"order-microservice/adaptors/grpc/pb/order-microservice/services/order-ms/adaptors/grpc/pb"
 	"order-microservice/internals/domain"
 	"order-microservice/internals/ports"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type OrderGrpcServer struct {
@@ -19,19 +27,34 @@ func NewOrderGrpcServer(s *ports.OrderService) *OrderGrpcServer {
 func (s *OrderGrpcServer) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
 	items := make([]domain.OrderItem, len(req.Items))
 	for i, item := range req.Items {
+		// prices are passed on only so the service can refuse them
 		items[i] = domain.OrderItem{
-			ProductID: item.ProductId,
-			Quantity:  int(item.Quantity),
+			ProductID:   item.ProductId,
+			Quantity:    int(item.Quantity),
+			Price:       money.FromProto(item.UnitPrice),
+			TaxCategory: item.TaxCategory,
+			Tax:         money.FromProto(item.Tax),
+			LineTotal:   money.FromProto(item.LineTotal),
 		}
 	}
 
 	order := &domain.Order{
-		UserID: req.UserId,
-		Items:  items,
-		Status: "PENDING",
+		UserID:         req.UserId,
+		Items:          items,
+		TaxRegion:      req.Region,
+		Currency:       strings.ToUpper(req.Currency),
+		ShippingMethod: req.ShippingMethod,
+		Status:         "PENDING",
+	}
+	if req.ShippingAddress != nil {
+		address := addressFromProto(req.ShippingAddress)
+		order.ShippingAddress = &address
 	}
 
 	created, err := s.service.CreateOrder(ctx, order)
+	if errors.Is(err, domain.ErrCartEmpty) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +73,7 @@ func (s *OrderGrpcServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest)
 }
 
 func (s *OrderGrpcServer) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
-	orders, err := s.service.ListOrders(ctx)
+	orders, err := s.service.ListOrders(ctx, "", domain.RoleSystem)
 	if err != nil {
 		return nil, err
 	}
@@ -65,6 +88,12 @@ func (s *OrderGrpcServer) ListOrders(ctx context.Context, req *pb.ListOrdersRequ
 func (s *OrderGrpcServer) UpdateOrderStatus(ctx context.Context, req *pb.UpdateOrderStatusRequest) (*pb.UpdateOrderStatusResponse, error) {
 	// Call service to update status
 	_, err := s.service.UpdateOrderStatus(ctx, req.Id, req.Status)
+	if errors.Is(err, domain.ErrStatusChanged) {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	if errors.Is(err, domain.ErrInvalidStatus) || errors.Is(err, domain.ErrNotCancellable) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -82,13 +111,157 @@ func (s *OrderGrpcServer) UpdateOrderStatus(ctx context.Context, req *pb.UpdateO
 
 
 func (s *OrderGrpcServer) DeleteOrder(ctx context.Context, req *pb.DeleteOrderRequest) (*pb.DeleteOrderResponse, error) {
-	err := s.service.DeleteOrder(ctx, req.Id)
+	err := s.service.DeleteOrder(ctx, req.Id, "", domain.RoleSystem)
 	if err != nil {
 		return nil, err
 	}
 	return &pb.DeleteOrderResponse{Message: "order deleted successfully"}, nil
 }
 
+func (s *OrderGrpcServer) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+	order, err := s.service.CancelOrder(ctx, req.GetId(), req.GetActorId(), req.GetRole(), req.GetReason(), req.GetNote())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CancelOrderResponse{Order: toProto(order)}, nil
+}
+
+// AnonymizeUser reports orders still on their way as FAILED_PRECONDITION,
+// so user-ms can tell the customer to wait for them.
+func (s *OrderGrpcServer) AnonymizeUser(ctx context.Context, req *pb.AnonymizeUserRequest) (*pb.AnonymizeUserResponse, error) {
+	n, err := s.service.AnonymizeUser(ctx, req.GetUserId(), req.GetAnonymousId())
+	if errors.Is(err, domain.ErrOrdersInProgress) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pb.AnonymizeUserResponse{Orders: n}, nil
+}
+
+func (s *OrderGrpcServer) CreateShipment(ctx context.Context, req *pb.CreateShipmentRequest) (*pb.CreateShipmentResponse, error) {
+	lines := make([]domain.ShipmentLine, len(req.GetLines()))
+	for i, l := range req.GetLines() {
+		lines[i] = domain.ShipmentLine{ProductID: l.GetProductId(), Quantity: int(l.GetQuantity())}
+	}
+
+	shipment, err := s.service.CreateShipment(ctx, req.GetOrderId(), req.GetActorId(), lines, req.GetCarrier(), req.GetTrackingNumber())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateShipmentResponse{Shipment: shipmentToProto(shipment)}, nil
+}
+
+func (s *OrderGrpcServer) ListShipments(ctx context.Context, req *pb.ListShipmentsRequest) (*pb.ListShipmentsResponse, error) {
+	shipments, err := s.service.ListShipments(ctx, req.GetOrderId(), req.GetActorId(), req.GetRole())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListShipmentsResponse{}
+	for _, sh := range shipments {
+		resp.Shipments = append(resp.Shipments, shipmentToProto(sh))
+	}
+	return resp, nil
+}
+
+func (s *OrderGrpcServer) UpdateShipment(ctx context.Context, req *pb.UpdateShipmentRequest) (*pb.UpdateShipmentResponse, error) {
+	update := domain.ShipmentUpdate{
+		Status:         req.GetStatus(),
+		Location:       req.GetLocation(),
+		Note:           req.GetNote(),
+		TrackingNumber: req.GetTrackingNumber(),
+	}
+	if req.GetAt() != "" {
+		at, err := time.Parse(time.RFC3339, req.GetAt())
+		if err != nil {
+			return nil, fmt.Errorf("invalid at %q: %w", req.GetAt(), err)
+		}
+		update.At = at
+	}
+
+	shipment, err := s.service.UpdateShipment(ctx, req.GetOrderId(), req.GetShipmentId(), update)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateShipmentResponse{Shipment: shipmentToProto(shipment)}, nil
+}
+
+func (s *OrderGrpcServer) RequestReturn(ctx context.Context, req *pb.RequestReturnRequest) (*pb.RequestReturnResponse, error) {
+	lines := make([]domain.ReturnLine, len(req.GetLines()))
+	for i, l := range req.GetLines() {
+		lines[i] = domain.ReturnLine{
+			ProductID: l.GetProductId(),
+			Quantity:  int(l.GetQuantity()),
+			Reason:    l.GetReason(),
+			Note:      l.GetNote(),
+		}
+	}
+
+	ret, err := s.service.RequestReturn(ctx, req.GetOrderId(), req.GetActorId(), req.GetRole(), lines, req.GetRefundToStoreCredit())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RequestReturnResponse{Return: returnToProto(ret)}, nil
+}
+
+func (s *OrderGrpcServer) ListReturns(ctx context.Context, req *pb.ListReturnsRequest) (*pb.ListReturnsResponse, error) {
+	returns, err := s.service.ListReturns(ctx, req.GetOrderId(), req.GetActorId(), req.GetRole())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListReturnsResponse{}
+	for _, r := range returns {
+		resp.Returns = append(resp.Returns, returnToProto(r))
+	}
+	return resp, nil
+}
+
+func (s *OrderGrpcServer) ReviewReturn(ctx context.Context, req *pb.ReviewReturnRequest) (*pb.ReviewReturnResponse, error) {
+	ret, err := s.service.ReviewReturn(ctx, req.GetOrderId(), req.GetReturnId(), req.GetReviewerId(), req.GetApprove(), req.GetNote())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ReviewReturnResponse{Return: returnToProto(ret)}, nil
+}
+
+func (s *OrderGrpcServer) ReceiveReturn(ctx context.Context, req *pb.ReceiveReturnRequest) (*pb.ReceiveReturnResponse, error) {
+	lines := make([]domain.ReceivedLine, len(req.GetLines()))
+	for i, l := range req.GetLines() {
+		lines[i] = domain.ReceivedLine{
+			ProductID: l.GetProductId(),
+			Quantity:  int(l.GetQuantity()),
+			Condition: l.GetCondition(),
+		}
+	}
+
+	ret, err := s.service.ReceiveReturn(ctx, req.GetOrderId(), req.GetReturnId(), req.GetReceiverId(), lines)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ReceiveReturnResponse{Return: returnToProto(ret)}, nil
+}
+
+// WatchOrder streams the order's status changes until the client goes away.
+// A client resumes after a reconnect by passing the seq it saw last.
+func (s *OrderGrpcServer) WatchOrder(req *pb.WatchOrderRequest, stream pb.OrderService_WatchOrderServer) error {
+	return s.service.WatchOrder(stream.Context(), req.GetId(), req.GetActorId(), req.GetRole(), req.GetAfterSeq(), func(changes []domain.StatusChange) error {
+		for _, c := range changes {
+			err := stream.Send(&pb.OrderStatusEvent{
+				OrderId: req.GetId(),
+				Seq:     c.Seq,
+				Status:  c.Status,
+				At:      c.At.Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // helper to convert domain → proto
 func toProto(order *domain.Order) *pb.Order {
 	if order == nil {
@@ -98,16 +271,152 @@ func toProto(order *domain.Order) *pb.Order {
 	items := make([]*pb.OrderItem, len(order.Items))
 	for i, item := range order.Items {
 		items[i] = &pb.OrderItem{
-			ProductId: item.ProductID,
-			Quantity:  int32(item.Quantity),
+			ProductId:   item.ProductID,
+			Name:        item.Name,
+			Sku:         item.SKU,
+			ImageUrl:    item.ImageURL,
+			Quantity:    int32(item.Quantity),
+			UnitPrice:   money.ToProto(item.Price),
+			TaxCategory: item.TaxCategory,
+			TaxRate:     item.TaxRate,
+			Tax:         money.ToProto(item.Tax),
+			LineTotal:   money.ToProto(item.LineTotal),
+			PriceSource:  item.PriceSource,
+			ExchangeRate: item.ExchangeRate,
 		}
 	}
 
-	return &pb.Order{
-		Id:     order.ID,
-		UserId: order.UserID,
-		Items:  items,
-		Total: order.Total,
-		Status: order.Status,
+	out := &pb.Order{
+		Id:               order.ID,
+		UserId:           order.UserID,
+		Items:            items,
+		Subtotal:         money.ToProto(order.Subtotal),
+		TaxTotal:         money.ToProto(order.TaxTotal),
+		Total:            money.ToProto(order.Total),
+		Currency:         order.Currency,
+		TaxRegion:        order.TaxRegion,
+		PricesIncludeTax: order.PricesIncludeTax,
+		ShippingAddress:  addressToProto(order.ShippingAddress),
+		ShippingMethod:   order.ShippingMethod,
+		ShippingCost:     money.ToProto(order.ShippingCost),
+		Status:           order.Status,
+	}
+
+	if c := order.Cancellation; c != nil {
+		out.Cancellation = &pb.Cancellation{
+			Reason:        c.Reason,
+			Note:          c.Note,
+			CancelledBy:   c.CancelledBy,
+			Role:          c.Role,
+			CancelledAt:   c.CancelledAt.Format(time.RFC3339),
+			PaymentAction: c.PaymentAction,
+			StockReleased: c.StockReleased,
+		}
+		if c.Refunded.Currency != "" {
+			out.Cancellation.Refunded = money.ToProto(c.Refunded)
+		}
+	}
+	return out
+}
+
+func shipmentToProto(s *domain.Shipment) *pb.Shipment {
+	out := &pb.Shipment{
+		Id:             s.ID,
+		OrderId:        s.OrderID,
+		Carrier:        s.Carrier,
+		TrackingNumber: s.TrackingNumber,
+		Status:         s.Status,
+		CreatedBy:      s.CreatedBy,
+		CreatedAt:      s.CreatedAt.Format(time.RFC3339),
+	}
+	for _, l := range s.Lines {
+		out.Lines = append(out.Lines, &pb.ShipmentLine{ProductId: l.ProductID, Quantity: int32(l.Quantity)})
+	}
+	for _, e := range s.Events {
+		out.Events = append(out.Events, &pb.TrackingEvent{
+			Status:   e.Status,
+			Location: e.Location,
+			Note:     e.Note,
+			At:       e.At.Format(time.RFC3339),
+		})
+	}
+	if s.ShippedAt != nil {
+		out.ShippedAt = s.ShippedAt.Format(time.RFC3339)
+	}
+	if s.DeliveredAt != nil {
+		out.DeliveredAt = s.DeliveredAt.Format(time.RFC3339)
+	}
+	return out
+}
+
+func returnToProto(r *domain.Return) *pb.Return {
+	out := &pb.Return{
+		Id:                  r.ID,
+		OrderId:             r.OrderID,
+		UserId:              r.UserID,
+		Status:              r.Status,
+		RequestedAt:         r.RequestedAt.Format(time.RFC3339),
+		Refunded:            r.Refunded,
+		StockReturned:       r.StockReturned,
+		RefundToStoreCredit: r.RefundToStoreCredit,
+	}
+	for _, l := range r.Lines {
+		out.Lines = append(out.Lines, &pb.ReturnLine{
+			ProductId: l.ProductID,
+			Quantity:  int32(l.Quantity),
+			Reason:    l.Reason,
+			Note:      l.Note,
+			Received:  int32(l.Received),
+			Condition: l.Condition,
+		})
+	}
+	if rv := r.Review; rv != nil {
+		out.Review = &pb.ReturnReview{
+			Approved:   rv.Approved,
+			Note:       rv.Note,
+			ReviewedBy: rv.ReviewedBy,
+			ReviewedAt: rv.ReviewedAt.Format(time.RFC3339),
+		}
+	}
+	if rc := r.Receipt; rc != nil {
+		out.ReceivedBy = rc.ReceivedBy
+		out.ReceivedAt = rc.ReceivedAt.Format(time.RFC3339)
+	}
+	if r.Refund.Currency != "" {
+		out.Refund = money.ToProto(r.Refund)
+	}
+	if r.CompletedAt != nil {
+		out.CompletedAt = r.CompletedAt.Format(time.RFC3339)
+	}
+	return out
+}
+
+func addressFromProto(a *pb.Address) domain.Address {
+	return domain.Address{
+		Name:       a.GetName(),
+		Line1:      a.GetLine1(),
+		Line2:      a.GetLine2(),
+		City:       a.GetCity(),
+		State:      a.GetState(),
+		PostalCode: a.GetPostalCode(),
+		Country:    a.GetCountry(),
+		Phone:      a.GetPhone(),
+	}
+}
+
+func addressToProto(a *domain.Address) *pb.Address {
+	if a == nil {
+		return nil
+	}
+
+	return &pb.Address{
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		State:      a.State,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Phone:      a.Phone,
 	}
 }
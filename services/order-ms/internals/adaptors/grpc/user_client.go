@@ -0,0 +1,27 @@
+package grpc
+
+import (
+	"context"
+	"user-microservice/adaptors/grpc/pb/user-microservice/services/user-ms/adaptors/grpc/pb"
+
+	"google.golang.org/grpc"
+)
+
+type UserClient struct {
+	client pb.UserServiceClient
+}
+
+func NewUserClient(conn *grpc.ClientConn) *UserClient {
+	return &UserClient{
+		client: pb.NewUserServiceClient(conn),
+	}
+}
+
+// EmailVerified reports whether the user has verified their email.
+func (c *UserClient) EmailVerified(ctx context.Context, userID string) (bool, error) {
+	res, err := c.client.GetUser(ctx, &pb.GetUserRequest{Id: userID})
+	if err != nil {
+		return false, err
+	}
+	return res.GetUser().GetEmailVerified(), nil
+}
@@ -2,10 +2,13 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"order-microservice/internals/domain"
 	"order-microservice/internals/ports"
 
 	"ecom-api/pkg/middleware"
+	"ecom-api/pkg/validation"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -22,10 +25,14 @@ func NewOrderHandler(s ports.OrderService) *OrderHandler {
 // @Summary      Create Order
 // @Description  Place a new order for the authenticated user from their cart
 // @Tags         Orders
+// @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        checkout  body  domain.Checkout  true  "Shipping address and method"
 // @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
 // @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /orders [post]
 // CreateOrder handles placing a new order from the user's cart
@@ -36,8 +43,28 @@ func (s *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var checkout domain.Checkout
+	if err := json.NewDecoder(r.Body).Decode(&checkout); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if errs := validation.ValidateStruct(checkout); len(errs) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": errs})
+		return
+	}
+
 	// Call service method to create order from cart
-	createdOrder, err := s.service.CreateOrderFromCart(r.Context(), userID)
+	createdOrder, err := s.service.CreateOrderFromCart(r.Context(), userID, checkout)
+	if errors.Is(err, domain.ErrInvalidOrder) || errors.Is(err, domain.ErrCartEmpty) {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrEmailNotVerified) {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
@@ -53,8 +80,9 @@ func (s *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 // GetOrder fetches a single order by ID
 func (s *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	userID, role := middleware.FromContext(r.Context())
 
-	order, err := s.service.GetOrder(r.Context(), id)
+	order, err := s.service.GetOwnOrder(r.Context(), id, userID, role)
 	if err != nil {
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusNotFound)
 		return
@@ -64,9 +92,10 @@ func (s *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(order)
 }
 
-// ListOrders returns all orders
+// ListOrders returns the caller's orders, or all orders to admins
 func (s *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
-	orders, err := s.service.ListOrders(r.Context())
+	userID, role := middleware.FromContext(r.Context())
+	orders, err := s.service.ListOrders(r.Context(), userID, role)
 	if err != nil {
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
@@ -76,7 +105,8 @@ func (s *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(orders)
 }
 
-// UpdateOrderStatus updates the status of an order and returns the updated order
+// UpdateOrderStatus updates the payment status of an order and returns the
+// updated order (admin only; payment-ms reports outcomes over gRPC)
 func (s *OrderHandler) UpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -95,6 +125,14 @@ func (s *OrderHandler) UpdateOrderStatus(w http.ResponseWriter, r *http.Request)
 	}
 
 	updatedOrder, err := s.service.UpdateOrderStatus(r.Context(), id, req.Status)
+	if errors.Is(err, domain.ErrInvalidStatus) || errors.Is(err, domain.ErrNotCancellable) {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrStatusChanged) {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusConflict)
+		return
+	}
 	if err != nil {
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
@@ -107,11 +145,20 @@ func (s *OrderHandler) UpdateOrderStatus(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// DeleteOrder deletes an order by ID
+// DeleteOrder soft-deletes an order by ID
 func (s *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-
-	if err := s.service.DeleteOrder(r.Context(), id); err != nil {
+	userID, role := middleware.FromContext(r.Context())
+
+	if err := s.service.DeleteOrder(r.Context(), id, userID, role); err != nil {
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrNotDeletable) {
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusConflict)
+			return
+		}
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -120,3 +167,89 @@ func (s *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"message": "Order deleted successfully"}`))
 }
+
+// CancelOrderRequest is the body of a cancellation.
+type CancelOrderRequest struct {
+	Reason string `json:"reason" example:"changed_mind"` // customer: changed_mind, ordered_by_mistake, found_cheaper, delivery_too_slow, other; admin: customer_request, out_of_stock, payment_failed, fraud_suspected, other
+	Note   string `json:"note,omitempty" example:"Ordered the wrong size"`
+}
+
+// @Summary      Cancel Order
+// @Description  Cancel an order, voiding or refunding its payment and releasing its stock. Customers can cancel their own unpaid orders; admins can also cancel paid ones. Cancelling an order whose reversal failed retries it.
+// @Tags         Orders
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  string              true  "Order ID"
+// @Param        request  body  CancelOrderRequest  true  "Reason code and note"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/cancel [post]
+// CancelOrder cancels an order and reverses its payment and stock
+func (s *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error": "unauthorized: missing userID"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var req CancelOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	order, err := s.service.CancelOrder(r.Context(), chi.URLParam(r, "id"), userID, role, req.Reason, req.Note)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrOrderNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, domain.ErrInvalidCancelReason):
+			status = http.StatusBadRequest
+		case errors.Is(err, domain.ErrNotCancellable):
+			status = http.StatusConflict
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error(), "order": order})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "order cancelled successfully",
+		"order":   order,
+	})
+}
+
+// @Summary      Restore Order
+// @Description  Restore a soft-deleted order (admin only)
+// @Tags         Orders
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Order ID"
+// @Success      200  {object}  domain.Order
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/restore [post]
+// RestoreOrder undeletes a soft-deleted order
+func (s *OrderHandler) RestoreOrder(w http.ResponseWriter, r *http.Request) {
+	order, err := s.service.RestoreOrder(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			http.Error(w, `{"error": "no deleted order with this id"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"order-microservice/internals/domain"
+	"strconv"
+
+	"ecom-api/pkg/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary      Download Invoice
+// @Description  Download the invoice of a paid order as PDF. The invoice is issued with the next number of the legal entity selling to the order's tax region the first time it is needed, and the same file is returned on every later download. Customers can only download their own invoices.
+// @Tags         Invoices
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Order ID"
+// @Success      200  {file}    file
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/invoice.pdf [get]
+// GetInvoice serves the invoice PDF of an order
+func (s *OrderHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	inv, err := s.service.GetInvoice(r.Context(), chi.URLParam(r, "id"), userID, role)
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+	writePDF(w, inv)
+}
+
+// @Summary      List Invoices
+// @Description  List the invoice and credit notes of an order. Customers can only see their own orders.
+// @Tags         Invoices
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Order ID"
+// @Success      200  {array}   domain.Invoice
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/invoices [get]
+// ListInvoices lists the invoicing documents of an order
+func (s *OrderHandler) ListInvoices(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	invoices, err := s.service.ListInvoices(r.Context(), chi.URLParam(r, "id"), userID, role)
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invoices)
+}
+
+// @Summary      Download Invoice Document
+// @Description  Download an invoice or credit note of an order as PDF. Customers can only download their own documents.
+// @Tags         Invoices
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        id         path  string  true  "Order ID"
+// @Param        invoiceId  path  string  true  "Invoice or credit note ID"
+// @Success      200  {file}    file
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/invoices/{invoiceId}.pdf [get]
+// GetInvoiceDocument serves an invoice or credit note PDF
+func (s *OrderHandler) GetInvoiceDocument(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	inv, err := s.service.GetInvoiceDocument(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "invoiceId"), userID, role)
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+	writePDF(w, inv)
+}
+
+func writePDF(w http.ResponseWriter, inv *domain.Invoice) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+inv.Number+`.pdf"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(inv.PDF)))
+	w.Header().Set("ETag", `"`+inv.PDFSHA256+`"`)
+	w.Write(inv.PDF)
+}
+
+func writeInvoiceError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrOrderNotFound), errors.Is(err, domain.ErrInvoiceNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrNotInvoiceable):
+		status = http.StatusConflict
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,212 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"order-microservice/internals/domain"
+
+	"ecom-api/pkg/middleware"
+	"ecom-api/pkg/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+// RequestReturnRequest is the body of a return request.
+type RequestReturnRequest struct {
+	Lines               []domain.ReturnLine `json:"lines" validate:"required,min=1,dive"` // reason: damaged, defective, wrong_item, not_as_described, no_longer_needed, other
+	RefundToStoreCredit bool                `json:"refund_to_store_credit,omitempty"`     // refund as store credit instead of to the original payment
+}
+
+// ReviewReturnRequest is the body of a return approval or rejection.
+type ReviewReturnRequest struct {
+	Note string `json:"note,omitempty" example:"Outside the return policy"`
+}
+
+// ReceiveReturnRequest is the warehouse's receipt of a return.
+type ReceiveReturnRequest struct {
+	Lines []domain.ReceivedLine `json:"lines" validate:"required,min=1,dive"`
+}
+
+// @Summary      Request Return
+// @Description  Request a return of delivered order lines, each with a reason code. Customers can only return their own orders. With refund_to_store_credit the refund is paid into the customer's wallet as store credit.
+// @Tags         Returns
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  string                true  "Order ID"
+// @Param        request  body  RequestReturnRequest  true  "Lines to return"
+// @Success      201  {object}  domain.Return
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/returns [post]
+// RequestReturn opens a return for an order
+func (s *OrderHandler) RequestReturn(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	var req RequestReturnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if errs := validation.ValidateStruct(req); len(errs) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": errs})
+		return
+	}
+
+	ret, err := s.service.RequestReturn(r.Context(), chi.URLParam(r, "id"), userID, role, req.Lines, req.RefundToStoreCredit)
+	if err != nil {
+		writeReturnError(w, err, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ret)
+}
+
+// @Summary      List Returns
+// @Description  List the returns of an order. Customers can only see their own orders.
+// @Tags         Returns
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Order ID"
+// @Success      200  {array}   domain.Return
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/returns [get]
+// ListReturns returns the returns of an order
+func (s *OrderHandler) ListReturns(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	returns, err := s.service.ListReturns(r.Context(), chi.URLParam(r, "id"), userID, role)
+	if err != nil {
+		writeReturnError(w, err, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(returns)
+}
+
+// @Summary      Approve Return
+// @Description  Approve a requested return (admin only). The refund and restock run once the warehouse received the goods.
+// @Tags         Returns
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path  string               true   "Order ID"
+// @Param        returnId  path  string               true   "Return ID"
+// @Param        request   body  ReviewReturnRequest  false  "Note"
+// @Success      200  {object}  domain.Return
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/returns/{returnId}/approve [post]
+// ApproveReturn approves a return
+func (s *OrderHandler) ApproveReturn(w http.ResponseWriter, r *http.Request) {
+	s.reviewReturn(w, r, true)
+}
+
+// @Summary      Reject Return
+// @Description  Reject a requested return (admin only)
+// @Tags         Returns
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path  string               true   "Order ID"
+// @Param        returnId  path  string               true   "Return ID"
+// @Param        request   body  ReviewReturnRequest  false  "Note"
+// @Success      200  {object}  domain.Return
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/returns/{returnId}/reject [post]
+// RejectReturn rejects a return
+func (s *OrderHandler) RejectReturn(w http.ResponseWriter, r *http.Request) {
+	s.reviewReturn(w, r, false)
+}
+
+func (s *OrderHandler) reviewReturn(w http.ResponseWriter, r *http.Request, approve bool) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	var req ReviewReturnRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	ret, err := s.service.ReviewReturn(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "returnId"), userID, approve, req.Note)
+	if err != nil {
+		writeReturnError(w, err, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// @Summary      Receive Return
+// @Description  Record the quantity and condition (new, opened, damaged) of the goods received for an approved return (warehouse staff or admin). The received goods are refunded and new or opened ones restocked. Receiving a return whose refund or restock failed retries it.
+// @Tags         Returns
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path  string                true  "Order ID"
+// @Param        returnId  path  string                true  "Return ID"
+// @Param        request   body  ReceiveReturnRequest  true  "Received lines"
+// @Success      200  {object}  domain.Return
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/returns/{returnId}/receive [post]
+// ReceiveReturn records the receipt of a return
+func (s *OrderHandler) ReceiveReturn(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	var req ReceiveReturnRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	ret, err := s.service.ReceiveReturn(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "returnId"), userID, req.Lines)
+	if err != nil {
+		writeReturnError(w, err, ret)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// writeReturnError maps return errors to a status; ret is included when
+// the return was saved but not fully processed.
+func writeReturnError(w http.ResponseWriter, err error, ret *domain.Return) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrOrderNotFound), errors.Is(err, domain.ErrReturnNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidReturn):
+		status = http.StatusBadRequest
+	case errors.Is(err, domain.ErrReturnNotAllowed), errors.Is(err, domain.ErrReturnStateChange), errors.Is(err, domain.ErrFulfillmentChanged):
+		status = http.StatusConflict
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error(), "return": ret})
+}
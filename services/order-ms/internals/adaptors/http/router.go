@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"order-microservice/internals/domain"
     "ecom-api/pkg/middleware"
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
@@ -32,11 +33,44 @@ func NewRouter(handler *OrderHandler) http.Handler {
 		r.Use(middleware.AuthMiddleware)
 
 		r.With(chiMiddleware.AllowContentType("application/json")).Post("/",handler.CreateOrder)
-		r.With(chiMiddleware.AllowContentType("application/json")).Put("/{id}",handler.UpdateOrderStatus)
+		r.With(middleware.AdminOnly, chiMiddleware.AllowContentType("application/json")).Put("/{id}",handler.UpdateOrderStatus)
+		r.With(chiMiddleware.AllowContentType("application/json")).Post("/{id}/cancel", handler.CancelOrder)
 		r.Get("/", handler.ListOrders)
 		r.Get("/{id}", handler.GetOrder)
+		r.Get("/{id}/events", handler.WatchOrder)
 		r.Delete("/{id}", handler.DeleteOrder)
+		r.With(middleware.AdminOnly).Post("/{id}/restore", handler.RestoreOrder)
 
+		// shipments
+		r.Get("/{id}/shipments", handler.ListShipments)
+		r.With(middleware.AdminOnly, chiMiddleware.AllowContentType("application/json")).Post("/{id}/shipments", handler.CreateShipment)
+		r.With(middleware.AdminOnly, chiMiddleware.AllowContentType("application/json")).Put("/{id}/shipments/{shipmentId}", handler.UpdateShipment)
+
+		// returns
+		r.Get("/{id}/returns", handler.ListReturns)
+		r.With(chiMiddleware.AllowContentType("application/json")).Post("/{id}/returns", handler.RequestReturn)
+		r.With(middleware.AdminOnly).Post("/{id}/returns/{returnId}/approve", handler.ApproveReturn)
+		r.With(middleware.AdminOnly).Post("/{id}/returns/{returnId}/reject", handler.RejectReturn)
+		r.With(middleware.RequireRole(domain.RoleWarehouse)).Post("/{id}/returns/{returnId}/receive", handler.ReceiveReturn)
+
+		// invoices
+		r.Get("/{id}/invoice.pdf", handler.GetInvoice)
+		r.Get("/{id}/invoices", handler.ListInvoices)
+		r.Get("/{id}/invoices/{invoiceId}.pdf", handler.GetInvoiceDocument)
+
+	})
+
+	r.Route("/subscriptions", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware)
+
+		r.With(chiMiddleware.AllowContentType("application/json")).Post("/", handler.CreateSubscription)
+		r.Get("/", handler.ListSubscriptions)
+		r.Get("/{id}", handler.GetSubscription)
+		r.With(chiMiddleware.AllowContentType("application/json")).Patch("/{id}", handler.UpdateSubscription)
+		r.Post("/{id}/pause", handler.PauseSubscription)
+		r.Post("/{id}/resume", handler.ResumeSubscription)
+		r.Post("/{id}/skip", handler.SkipSubscription)
+		r.Post("/{id}/cancel", handler.CancelSubscription)
 	})
 
 	return  r
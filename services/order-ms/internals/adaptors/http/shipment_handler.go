@@ -0,0 +1,147 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"order-microservice/internals/domain"
+
+	"ecom-api/pkg/middleware"
+	"ecom-api/pkg/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateShipmentRequest is the body of a new shipment.
+type CreateShipmentRequest struct {
+	Lines          []domain.ShipmentLine `json:"lines" validate:"required,min=1,dive"`
+	Carrier        string                `json:"carrier" validate:"required" example:"DHL"`
+	TrackingNumber string                `json:"tracking_number,omitempty" example:"JD014600006281234567"`
+}
+
+// @Summary      Create Shipment
+// @Description  Pack lines of a paid order into a shipment and create its label (admin only). An order can be split across several shipments and a line across several parcels.
+// @Tags         Shipments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  string                 true  "Order ID"
+// @Param        request  body  CreateShipmentRequest  true  "Lines, carrier and tracking number"
+// @Success      201  {object}  domain.Shipment
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/shipments [post]
+// CreateShipment creates a shipment for an order
+func (s *OrderHandler) CreateShipment(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	var req CreateShipmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if errs := validation.ValidateStruct(req); len(errs) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": errs})
+		return
+	}
+
+	shipment, err := s.service.CreateShipment(r.Context(), chi.URLParam(r, "id"), userID, req.Lines, req.Carrier, req.TrackingNumber)
+	if err != nil {
+		writeShipmentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shipment)
+}
+
+// @Summary      List Shipments
+// @Description  List the shipments of an order with their tracking history. Customers can only see their own orders.
+// @Tags         Shipments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Order ID"
+// @Success      200  {array}   domain.Shipment
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/shipments [get]
+// ListShipments returns the shipments of an order
+func (s *OrderHandler) ListShipments(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	shipments, err := s.service.ListShipments(r.Context(), chi.URLParam(r, "id"), userID, role)
+	if err != nil {
+		writeShipmentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shipments)
+}
+
+// @Summary      Update Shipment
+// @Description  Record a tracking update for a shipment (admin only): IN_TRANSIT, OUT_FOR_DELIVERY, DELIVERED, or CANCELLED to void a label before pickup. The order status follows: PARTIALLY_SHIPPED, SHIPPED and DELIVERED.
+// @Tags         Shipments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id           path  string                 true  "Order ID"
+// @Param        shipmentId   path  string                 true  "Shipment ID"
+// @Param        request      body  domain.ShipmentUpdate  true  "Tracking update"
+// @Success      200  {object}  domain.Shipment
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/shipments/{shipmentId} [put]
+// UpdateShipment records a tracking update for a shipment
+func (s *OrderHandler) UpdateShipment(w http.ResponseWriter, r *http.Request) {
+	var req domain.ShipmentUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if errs := validation.ValidateStruct(req); len(errs) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": errs})
+		return
+	}
+
+	shipment, err := s.service.UpdateShipment(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "shipmentId"), req)
+	if err != nil && shipment == nil {
+		writeShipmentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	resp := map[string]interface{}{"shipment": shipment}
+	if err != nil {
+		// the update was saved, only the order status lags behind
+		resp["warning"] = err.Error()
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func writeShipmentError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrOrderNotFound), errors.Is(err, domain.ErrShipmentNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidShipment), errors.Is(err, domain.ErrInvalidTransition):
+		status = http.StatusBadRequest
+	case errors.Is(err, domain.ErrNotFulfillable), errors.Is(err, domain.ErrFulfillmentChanged):
+		status = http.StatusConflict
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,308 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"order-microservice/internals/domain"
+	"strconv"
+	"time"
+
+	"ecom-api/pkg/middleware"
+	"ecom-api/pkg/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateSubscriptionRequest is the body of a new subscription. Without
+// start_at the first order is placed and charged right away.
+type CreateSubscriptionRequest struct {
+	Items           []domain.SubscriptionItem `json:"items" validate:"required,min=1,dive"`
+	Interval        domain.Interval           `json:"interval" validate:"required"`
+	Currency        string                    `json:"currency,omitempty" example:"USD"`
+	MethodID        string                    `json:"method_id" validate:"required"` // saved payment method in payment-ms
+	ShippingAddress domain.Address            `json:"shipping_address" validate:"required"`
+	ShippingMethod  string                    `json:"shipping_method" validate:"required" example:"standard"`
+	StartAt         *time.Time                `json:"start_at,omitempty" example:"2026-11-01T09:00:00Z"`
+}
+
+// UpdateSubscriptionRequest changes a subscription from its next order
+// on. Fields left out stay as they are.
+type UpdateSubscriptionRequest struct {
+	Items           []domain.SubscriptionItem `json:"items,omitempty" validate:"omitempty,min=1,dive"`
+	Interval        *domain.Interval          `json:"interval,omitempty"`
+	MethodID        *string                   `json:"method_id,omitempty"`
+	ShippingAddress *domain.Address           `json:"shipping_address,omitempty"`
+	ShippingMethod  *string                   `json:"shipping_method,omitempty" example:"express"`
+	NextRunAt       *time.Time                `json:"next_run_at,omitempty" example:"2026-11-15T09:00:00Z"`
+}
+
+// PauseSubscriptionRequest pauses a subscription until a time, or until
+// it is resumed without one.
+type PauseSubscriptionRequest struct {
+	Until *time.Time `json:"until,omitempty" example:"2027-01-01T00:00:00Z"`
+}
+
+// @Summary      Create Subscription
+// @Description  Subscribe to products ordered every interval and charged to a saved payment method. Failed payments are retried on a schedule; when the retries run out the subscription is cancelled.
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body  CreateSubscriptionRequest  true  "Subscription"
+// @Success      201  {object}  domain.Subscription
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /subscriptions [post]
+// CreateSubscription subscribes the caller to recurring orders
+func (s *OrderHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	var req CreateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if errs := validation.ValidateStruct(req); len(errs) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": errs})
+		return
+	}
+
+	sub, err := s.service.CreateSubscription(r.Context(), &domain.Subscription{
+		UserID:          userID,
+		Items:           req.Items,
+		Interval:        req.Interval,
+		Currency:        req.Currency,
+		MethodID:        req.MethodID,
+		ShippingAddress: req.ShippingAddress,
+		ShippingMethod:  req.ShippingMethod,
+	}, req.StartAt)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+// @Summary      List Subscriptions
+// @Description  List the caller's subscriptions, newest first. Admins see everyone's and can filter by user.
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id  query  string  false  "Only this user's subscriptions (admin only)"
+// @Param        status   query  string  false  "ACTIVE, PAUSED, PAST_DUE or CANCELLED"
+// @Param        limit    query  int     false  "Maximum number of subscriptions (default 50)"
+// @Success      200  {array}   domain.Subscription
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /subscriptions [get]
+// ListSubscriptions returns subscriptions
+func (s *OrderHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	limit := int64(50)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error": "invalid limit"}`, http.StatusBadRequest)
+			return
+		}
+		limit = min(n, 500)
+	}
+
+	subs, err := s.service.ListSubscriptions(r.Context(), userID, role, r.URL.Query().Get("user_id"), r.URL.Query().Get("status"), limit)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+}
+
+// @Summary      Get Subscription
+// @Description  Get a subscription with its open cycle and past cycles. Customers can only see their own.
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Subscription ID"
+// @Success      200  {object}  domain.Subscription
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /subscriptions/{id} [get]
+// GetSubscription returns a subscription
+func (s *OrderHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	sub, err := s.service.GetSubscription(r.Context(), chi.URLParam(r, "id"), userID, role)
+	writeSubscription(w, sub, err)
+}
+
+// @Summary      Update Subscription
+// @Description  Change the products, interval, payment method, shipping or next order date of a subscription. Changing the payment method of a past-due subscription retries its payment right away.
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  string                     true  "Subscription ID"
+// @Param        request  body  UpdateSubscriptionRequest  true  "Changes"
+// @Success      200  {object}  domain.Subscription
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /subscriptions/{id} [patch]
+// UpdateSubscription changes a subscription
+func (s *OrderHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	var req UpdateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if errs := validation.ValidateStruct(req); len(errs) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": errs})
+		return
+	}
+
+	sub, err := s.service.UpdateSubscription(r.Context(), chi.URLParam(r, "id"), userID, role, domain.SubscriptionChange{
+		Items:           req.Items,
+		Interval:        req.Interval,
+		MethodID:        req.MethodID,
+		ShippingAddress: req.ShippingAddress,
+		ShippingMethod:  req.ShippingMethod,
+		NextRunAt:       req.NextRunAt,
+	})
+	writeSubscription(w, sub, err)
+}
+
+// @Summary      Pause Subscription
+// @Description  Stop placing orders until the given time, or until the subscription is resumed. Only active subscriptions without an open payment can be paused.
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  string                    true   "Subscription ID"
+// @Param        request  body  PauseSubscriptionRequest  false  "Pause until"
+// @Success      200  {object}  domain.Subscription
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /subscriptions/{id}/pause [post]
+// PauseSubscription pauses a subscription
+func (s *OrderHandler) PauseSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	var req PauseSubscriptionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	sub, err := s.service.PauseSubscription(r.Context(), chi.URLParam(r, "id"), userID, role, req.Until)
+	writeSubscription(w, sub, err)
+}
+
+// @Summary      Resume Subscription
+// @Description  Resume a paused subscription. Orders missed during the pause are not placed.
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Subscription ID"
+// @Success      200  {object}  domain.Subscription
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /subscriptions/{id}/resume [post]
+// ResumeSubscription resumes a subscription
+func (s *OrderHandler) ResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	sub, err := s.service.ResumeSubscription(r.Context(), chi.URLParam(r, "id"), userID, role)
+	writeSubscription(w, sub, err)
+}
+
+// @Summary      Skip Subscription Order
+// @Description  Leave out the next order of a subscription. A cycle whose payment is being retried is skipped and its retries stop.
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Subscription ID"
+// @Success      200  {object}  domain.Subscription
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /subscriptions/{id}/skip [post]
+// SkipSubscription skips the next order of a subscription
+func (s *OrderHandler) SkipSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	sub, err := s.service.SkipSubscription(r.Context(), chi.URLParam(r, "id"), userID, role)
+	writeSubscription(w, sub, err)
+}
+
+// @Summary      Cancel Subscription
+// @Description  Cancel a subscription. An order it placed that is not paid yet is cancelled with it.
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Subscription ID"
+// @Success      200  {object}  domain.Subscription
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /subscriptions/{id}/cancel [post]
+// CancelSubscription cancels a subscription
+func (s *OrderHandler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+
+	sub, err := s.service.CancelSubscription(r.Context(), chi.URLParam(r, "id"), userID, role)
+	writeSubscription(w, sub, err)
+}
+
+func writeSubscription(w http.ResponseWriter, sub *domain.Subscription, err error) {
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sub)
+}
+
+func writeSubscriptionError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrSubscriptionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidSubscription):
+		status = http.StatusBadRequest
+	case errors.Is(err, domain.ErrSubscriptionStateChange):
+		status = http.StatusConflict
+	case errors.Is(err, domain.ErrEmailNotVerified):
+		status = http.StatusForbidden
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"order-microservice/internals/domain"
+	"strconv"
+	"time"
+
+	"ecom-api/pkg/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment, so
+// proxies do not close it.
+const keepAliveInterval = 15 * time.Second
+
+// OrderStatusEvent is the data of a status event on an order's event stream.
+type OrderStatusEvent struct {
+	OrderID string    `json:"order_id"`
+	Seq     int64     `json:"seq"`
+	Status  string    `json:"status"`
+	At      time.Time `json:"at"`
+}
+
+// @Summary      Watch Order
+// @Description  Stream the status changes of an order as Server-Sent Events. Every change is a "status" event whose id is its seq; the stream starts with the order's whole history. A client that reconnects sends the id it saw last in the Last-Event-ID header, or as the after query parameter, and receives only the changes after it. Customers can only watch their own orders.
+// @Tags         Orders
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Param        id             path    string  true   "Order ID"
+// @Param        after          query   int     false  "Seq of the last change received"
+// @Param        Last-Event-ID  header  string  false  "Seq of the last change received"
+// @Success      200  {object}  OrderStatusEvent
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/{id}/events [get]
+// WatchOrder streams the status changes of an order
+func (s *OrderHandler) WatchOrder(w http.ResponseWriter, r *http.Request) {
+	userID, role := middleware.FromContext(r.Context())
+	id := chi.URLParam(r, "id")
+
+	after := r.Header.Get("Last-Event-ID")
+	if after == "" {
+		after = r.URL.Query().Get("after")
+	}
+	var seq int64
+	if after != "" {
+		var err error
+		if seq, err = strconv.ParseInt(after, 10, 64); err != nil || seq < 0 {
+			http.Error(w, `{"error": "invalid last event id"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	started := false
+	lastWrite := time.Now()
+
+	err := s.service.WatchOrder(r.Context(), id, userID, role, seq, func(changes []domain.StatusChange) error {
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("X-Accel-Buffering", "no")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		} else if len(changes) == 0 && time.Since(lastWrite) < keepAliveInterval {
+			return nil
+		}
+
+		if len(changes) == 0 {
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		for _, c := range changes {
+			data, err := json.Marshal(OrderStatusEvent{OrderID: id, Seq: c.Seq, Status: c.Status, At: c.At})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "id: %d\nevent: status\ndata: %s\n\n", c.Seq, data)
+		}
+		lastWrite = time.Now()
+		return rc.Flush()
+	})
+	if err != nil && !started {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			status = http.StatusNotFound
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,108 @@
+// Package render lays out invoices and credit notes from a text template
+// and typesets them as PDF.
+package render
+
+import (
+	"bytes"
+	"ecom-api/pkg/money"
+	"ecom-api/pkg/pdf"
+	_ "embed"
+	"fmt"
+	"math"
+	"order-microservice/internals/domain"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+//go:embed invoice.tmpl
+var defaultTemplate string
+
+// InvoiceRenderer renders documents with a template that is parsed once
+// at startup, so every document is laid out the same way.
+type InvoiceRenderer struct {
+	tmpl *template.Template
+}
+
+// NewInvoiceRenderer parses the template at path, or the built-in one if
+// path is empty.
+func NewInvoiceRenderer(path string) (*InvoiceRenderer, error) {
+	text := defaultTemplate
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read invoice template %s: %w", path, err)
+		}
+		text = string(raw)
+	}
+
+	tmpl, err := template.New("invoice").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse invoice template: %w", err)
+	}
+	return &InvoiceRenderer{tmpl: tmpl}, nil
+}
+
+type document struct {
+	Invoice    *domain.Invoice
+	Seller     *domain.LegalEntity
+	CreditNote bool
+	TotalLabel string
+	Reason     string
+}
+
+func (r *InvoiceRenderer) Render(inv *domain.Invoice, seller *domain.LegalEntity) ([]byte, error) {
+	doc := document{Invoice: inv, Seller: seller, TotalLabel: "Total"}
+	if inv.Kind == domain.KindCreditNote {
+		doc.CreditNote = true
+		doc.TotalLabel = "Total credited"
+		doc.Reason = reason(inv.Reference)
+	}
+
+	var buf bytes.Buffer
+	if err := r.tmpl.Execute(&buf, doc); err != nil {
+		return nil, fmt.Errorf("failed to render %s %s: %w", inv.Kind, inv.Number, err)
+	}
+
+	title := "Invoice " + inv.Number
+	if doc.CreditNote {
+		title = "Credit note " + inv.Number
+	}
+	return pdf.Render(title, buf.String()), nil
+}
+
+// reason describes a credit note's reference for the reader.
+func reason(reference string) string {
+	switch {
+	case reference == domain.ReferenceCancellation:
+		return "order cancelled"
+	case strings.HasPrefix(reference, "return:"):
+		return "goods returned (return " + strings.TrimPrefix(reference, "return:") + ")"
+	}
+	return reference
+}
+
+var funcs = template.FuncMap{
+	"money":  func(m money.Money) string { return m.String() },
+	"amount": func(m money.Money) string { return strings.TrimSuffix(m.String(), " "+m.Currency) },
+	"rate":   func(r float64) string { return strconv.FormatFloat(math.Round(r*1e4)/100, 'f', -1, 64) + "%" },
+	"date":   func(t time.Time) string { return t.UTC().Format("2006-01-02") },
+	"left":   func(n int, v any) string { return pad(n, fmt.Sprint(v), false) },
+	"right":  func(n int, v any) string { return pad(n, fmt.Sprint(v), true) },
+	"rule":   func(n int) string { return strings.Repeat("-", n) },
+}
+
+// pad fits s into a column of n characters, cutting it short if needed.
+func pad(n int, s string, alignRight bool) string {
+	if utf8.RuneCountInString(s) > n {
+		s = string([]rune(s)[:n-1]) + "."
+	}
+	fill := strings.Repeat(" ", n-utf8.RuneCountInString(s))
+	if alignRight {
+		return fill + s
+	}
+	return s + fill
+}
@@ -0,0 +1,71 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"order-microservice/internals/domain"
+)
+
+// AnonymizeUser detaches the orders, returns and subscriptions of a
+// deleted account from the user, putting anonymousID in place of the
+// user's ID and removing what identifies them from shipping addresses.
+// Subscriptions still running and unpaid orders are cancelled first.
+// Paid orders still on their way need the address, so while there are any
+// it refuses with ErrOrdersInProgress. Invoices are kept as issued.
+// Calling it again finishes a call that failed part way.
+func (s *OrderServiceImplement) AnonymizeUser(ctx context.Context, userID, anonymousID string) (int64, error) {
+	if userID == "" || anonymousID == "" {
+		return 0, fmt.Errorf("user and anonymous ID are required")
+	}
+
+	inProgress, err := s.repo.FindByUser(ctx, userID, domain.InProgressStatuses)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load orders: %w", err)
+	}
+	if len(inProgress) > 0 {
+		return 0, fmt.Errorf("%w: %d paid orders have not been delivered yet", domain.ErrOrdersInProgress, len(inProgress))
+	}
+
+	subs, err := s.subscriptions.List(ctx, userID, "", 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+	for _, sub := range subs {
+		if sub.Status == domain.SubscriptionCancelled {
+			continue
+		}
+		if _, err := s.cancelSubscription(ctx, sub.ID, userID, domain.RoleSystem, domain.SubscriptionCancelledAccount, domain.ReasonAccountDeleted); err != nil {
+			return 0, fmt.Errorf("failed to cancel subscription %s: %w", sub.ID, err)
+		}
+	}
+
+	unpaid, err := s.repo.FindByUser(ctx, userID, []string{domain.StatusPending, domain.StatusFailed, domain.StatusCancelled})
+	if err != nil {
+		return 0, fmt.Errorf("failed to load orders: %w", err)
+	}
+	for _, order := range unpaid {
+		if order.Status == domain.StatusCancelled {
+			// an earlier call cancelled it but did not finish the reversal
+			if c := order.Cancellation; c == nil || c.Reason != domain.ReasonAccountDeleted || c.Complete() {
+				continue
+			}
+		} else if err := s.cancelAsSystem(ctx, order, domain.ReasonAccountDeleted, "account deleted"); err != nil {
+			return 0, fmt.Errorf("failed to cancel order %s: %w", order.ID, err)
+		}
+		if _, err := s.reverseCancelledOrder(ctx, order); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := s.repo.AnonymizeUser(ctx, userID, anonymousID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize orders: %w", err)
+	}
+	if err := s.returns.AnonymizeUser(ctx, userID, anonymousID); err != nil {
+		return 0, fmt.Errorf("failed to anonymize returns: %w", err)
+	}
+	if err := s.subscriptions.AnonymizeUser(ctx, userID, anonymousID); err != nil {
+		return 0, fmt.Errorf("failed to anonymize subscriptions: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,43 @@
+package application
+
+import (
+	"context"
+	"ecom-api/pkg/events"
+	"fmt"
+	"order-microservice/internals/domain"
+	"time"
+)
+
+// publish announces an event about an order. The change it reports is
+// saved already, so a failure is only logged.
+func (s *OrderServiceImplement) publish(ctx context.Context, eventType, orderID string, data interface{}) {
+	if err := s.publisher.Publish(ctx, events.New(eventType, eventSource, orderID, data)); err != nil {
+		fmt.Println("warning: failed to publish", eventType, "for order", orderID, err)
+	}
+}
+
+func (s *OrderServiceImplement) orderCreated(ctx context.Context, order *domain.Order) {
+	s.publish(ctx, domain.EventOrderCreated, order.ID, domain.OrderCreatedEvent{
+		OrderID:   order.ID,
+		UserID:    order.UserID,
+		Status:    order.Status,
+		Items:     order.Items,
+		Total:     order.Total,
+		CreatedAt: order.CreatedAt,
+	})
+}
+
+// statusChanged wakes the order's watchers and announces the new status.
+func (s *OrderServiceImplement) statusChanged(ctx context.Context, order *domain.Order, from, to string) {
+	s.watchers.notify(order.ID)
+	if from == to {
+		return
+	}
+	s.publish(ctx, domain.EventOrderStatusChanged, order.ID, domain.OrderStatusChangedEvent{
+		OrderID:   order.ID,
+		UserID:    order.UserID,
+		From:      from,
+		To:        to,
+		ChangedAt: time.Now().UTC(),
+	})
+}
@@ -0,0 +1,135 @@
+package application
+
+import (
+	"context"
+	"ecom-api/pkg/events"
+	"errors"
+	"fmt"
+	"log"
+	"order-microservice/internals/domain"
+	"time"
+)
+
+const (
+	eventSource     = "order-ms"
+	expiryBatchSize = 100
+)
+
+// scheduleExpiry arranges for an order to be cancelled if it is still
+// unpaid when the payment window closes. If scheduling fails the sweep in
+// ExpireUnpaidOrders catches the order instead. Subscription orders are
+// left to the subscription's retries.
+func (s *OrderServiceImplement) scheduleExpiry(ctx context.Context, order *domain.Order) {
+	if s.unpaidTTL <= 0 || order.SubscriptionID != "" {
+		return
+	}
+	if err := s.jobs.Schedule(ctx, domain.JobExpireUnpaidOrder, order.ID, order.CreatedAt.Add(s.unpaidTTL)); err != nil {
+		fmt.Println("warning: failed to schedule expiry of order", order.ID, err)
+	}
+}
+
+// ExpireUnpaidOrder cancels an order that is still unpaid once its payment
+// window closed, voiding its payment and putting its stock back, and
+// announces it with an order.expired event. Orders that were paid or
+// cancelled meanwhile are left alone. An error means the job should run
+// again: the order changed while it was being cancelled, or the reversal
+// did not finish.
+func (s *OrderServiceImplement) ExpireUnpaidOrder(ctx context.Context, id string) error {
+	order, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrOrderNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if order.Status == domain.StatusCancelled {
+		// an earlier run cancelled it but did not finish the reversal
+		if c := order.Cancellation; c != nil && c.Reason == domain.ReasonPaymentTimeout && !c.Complete() {
+			_, err := s.reverseCancelledOrder(ctx, order)
+			return err
+		}
+		return nil
+	}
+	if !order.Unpaid() || s.unpaidTTL <= 0 {
+		return nil
+	}
+	if deadline := order.CreatedAt.Add(s.unpaidTTL); time.Now().Before(deadline) {
+		// the window was widened after the job was scheduled
+		return s.jobs.Schedule(ctx, domain.JobExpireUnpaidOrder, id, deadline)
+	}
+
+	from := order.Status
+	if err := s.cancelAsSystem(ctx, order, domain.ReasonPaymentTimeout, fmt.Sprintf("not paid within %s", s.unpaidTTL)); err != nil {
+		return fmt.Errorf("failed to expire order %s: %w", id, err)
+	}
+	c := order.Cancellation
+
+	event := events.New(domain.EventOrderExpired, eventSource, id, domain.OrderExpiredEvent{
+		OrderID:   id,
+		UserID:    order.UserID,
+		Status:    from,
+		Total:     order.Total,
+		Items:     order.Items,
+		CreatedAt: order.CreatedAt,
+		ExpiredAt: c.CancelledAt,
+	})
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		fmt.Println("warning: failed to publish expiry of order", id, err)
+	}
+
+	_, err = s.reverseCancelledOrder(ctx, order)
+	return err
+}
+
+// cancelAsSystem cancels an unpaid order on the system's behalf. It fails
+// if the order changed since it was read; reversing it is left to the
+// caller.
+func (s *OrderServiceImplement) cancelAsSystem(ctx context.Context, order *domain.Order, reason, note string) error {
+	from := order.Status
+	c := &domain.Cancellation{
+		Reason:      reason,
+		Note:        note,
+		CancelledBy: domain.RoleSystem,
+		Role:        domain.RoleSystem,
+		CancelledAt: time.Now(),
+	}
+	ok, err := s.repo.Cancel(ctx, order.ID, from, c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("order %s changed while it was being cancelled", order.ID)
+	}
+	order.Status = domain.StatusCancelled
+	order.Cancellation = c
+	s.statusChanged(ctx, order, from, order.Status)
+	return nil
+}
+
+// ExpireUnpaidOrders expires a batch of orders whose payment window closed.
+// It backs up the per-order jobs, covering orders placed before expiry was
+// enabled or whose job could not be scheduled.
+func (s *OrderServiceImplement) ExpireUnpaidOrders(ctx context.Context) (int, error) {
+	if s.unpaidTTL <= 0 {
+		return 0, nil
+	}
+
+	orders, err := s.repo.FindUnpaid(ctx, time.Now().Add(-s.unpaidTTL), expiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find unpaid orders: %w", err)
+	}
+
+	expired := 0
+	for _, o := range orders {
+		if err := s.ExpireUnpaidOrder(ctx, o.ID); err != nil {
+			log.Printf("failed to expire order %s: %v", o.ID, err)
+			continue
+		}
+		expired++
+	}
+	if expired > 0 {
+		log.Printf("unpaid order sweep: %d orders expired", expired)
+	}
+	return expired, nil
+}
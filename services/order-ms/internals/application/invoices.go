@@ -0,0 +1,184 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"ecom-api/pkg/money"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"order-microservice/internals/domain"
+	"time"
+)
+
+// GetInvoice returns the order's invoice with its PDF, issuing it on first
+// request for an order paid before invoicing existed.
+func (s *OrderServiceImplement) GetInvoice(ctx context.Context, orderID, actorID, role string) (*domain.Invoice, error) {
+	order, err := s.findOwnOrder(ctx, orderID, actorID, role)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueInvoice(ctx, order)
+}
+
+// ListInvoices returns the invoice and credit notes of an order without
+// their PDFs. Documents missing for refunds that were made while invoicing
+// was unavailable are issued first.
+func (s *OrderServiceImplement) ListInvoices(ctx context.Context, orderID, actorID, role string) ([]*domain.Invoice, error) {
+	order, err := s.findOwnOrder(ctx, orderID, actorID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Invoiceable() {
+		if _, err := s.issueInvoice(ctx, order); err != nil {
+			return nil, err
+		}
+		if c := order.Cancellation; c != nil && c.PaymentAction == domain.ActionRefunded {
+			if err := s.issueCancellationCreditNote(ctx, order); err != nil {
+				return nil, err
+			}
+		}
+
+		returns, err := s.returns.FindByOrder(ctx, orderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load returns: %w", err)
+		}
+		for _, ret := range returns {
+			if ret.Refunded && ret.Refund.IsPositive() {
+				if err := s.issueReturnCreditNote(ctx, order, ret); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return s.invoices.FindByOrder(ctx, orderID)
+}
+
+// GetInvoiceDocument returns one invoice or credit note of an order with
+// its PDF.
+func (s *OrderServiceImplement) GetInvoiceDocument(ctx context.Context, orderID, invoiceID, actorID, role string) (*domain.Invoice, error) {
+	if _, err := s.findOwnOrder(ctx, orderID, actorID, role); err != nil {
+		return nil, err
+	}
+	inv, err := s.invoices.FindByID(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if inv.OrderID != orderID {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	return inv, nil
+}
+
+// findOwnOrder loads an order; customers only see their own orders.
+func (s *OrderServiceImplement) findOwnOrder(ctx context.Context, orderID, actorID, role string) (*domain.Order, error) {
+	order, err := s.repo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if role != domain.RoleAdmin && order.UserID != actorID {
+		return nil, domain.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// issueInvoice returns the order's invoice, issuing it if there is none yet.
+func (s *OrderServiceImplement) issueInvoice(ctx context.Context, order *domain.Order) (*domain.Invoice, error) {
+	inv, err := s.invoices.FindByReference(ctx, order.ID, domain.KindInvoice, domain.ReferenceOrder)
+	if !errors.Is(err, domain.ErrInvoiceNotFound) {
+		return inv, err
+	}
+
+	inv, err = order.NewInvoice(s.productNames(ctx, order))
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, order, inv)
+}
+
+// issueCancellationCreditNote credits the whole of a cancelled order whose
+// payment was refunded.
+func (s *OrderServiceImplement) issueCancellationCreditNote(ctx context.Context, order *domain.Order) error {
+	return s.issueCreditNote(ctx, order, domain.ReferenceCancellation, nil, order.ShippingCost)
+}
+
+// issueReturnCreditNote credits the units refunded for a return.
+func (s *OrderServiceImplement) issueReturnCreditNote(ctx context.Context, order *domain.Order, ret *domain.Return) error {
+	return s.issueCreditNote(ctx, order, "return:"+ret.ID, ret.Credited(), money.Zero(order.Currency))
+}
+
+// issueCreditNote issues a credit note against the order's invoice unless
+// one exists for reference already.
+func (s *OrderServiceImplement) issueCreditNote(ctx context.Context, order *domain.Order, reference string, qty map[string]int, shipping money.Money) error {
+	_, err := s.invoices.FindByReference(ctx, order.ID, domain.KindCreditNote, reference)
+	if !errors.Is(err, domain.ErrInvoiceNotFound) {
+		return err
+	}
+
+	invoice, err := s.issueInvoice(ctx, order)
+	if err != nil {
+		return err
+	}
+	cn, err := order.NewCreditNote(reference, qty, shipping, s.productNames(ctx, order))
+	if err != nil {
+		return err
+	}
+	cn.InvoiceNumber = invoice.Number
+	_, err = s.issue(ctx, order, cn)
+	return err
+}
+
+// issue numbers, renders and stores a document. The next number of the
+// legal entity's sequence is taken when the document is inserted, and a
+// number another document took meanwhile is retried with the one after.
+// If the document was issued concurrently, the stored one is returned.
+func (s *OrderServiceImplement) issue(ctx context.Context, order *domain.Order, inv *domain.Invoice) (*domain.Invoice, error) {
+	entity, err := s.legalEntities.For(order.TaxRegion)
+	if err != nil {
+		return nil, err
+	}
+	inv.Entity = entity.Code
+
+	for attempt := 0; attempt < 5; attempt++ {
+		seq, err := s.invoices.NextSequence(ctx, entity.Code, inv.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("failed to number %s: %w", inv.Kind, err)
+		}
+		inv.Sequence = seq
+		inv.Number = entity.Format(inv.Kind, seq)
+		inv.IssuedAt = time.Now().UTC()
+
+		inv.PDF, err = s.renderer.Render(inv, entity)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(inv.PDF)
+		inv.PDFSHA256 = hex.EncodeToString(sum[:])
+
+		created, err := s.invoices.Create(ctx, inv)
+		switch {
+		case err == nil:
+			return created, nil
+		case errors.Is(err, domain.ErrInvoiceNumberTaken):
+			continue
+		case errors.Is(err, domain.ErrInvoiceAlreadyIssued):
+			return s.invoices.FindByReference(ctx, order.ID, inv.Kind, inv.Reference)
+		default:
+			return nil, fmt.Errorf("failed to store %s: %w", inv.Kind, err)
+		}
+	}
+	return nil, fmt.Errorf("failed to number %s: %w", inv.Kind, domain.ErrInvoiceNumberTaken)
+}
+
+// productNames looks up the names of products on orders placed before
+// order lines recorded them.
+func (s *OrderServiceImplement) productNames(ctx context.Context, order *domain.Order) map[string]string {
+	var ids []string
+	for _, item := range order.Items {
+		if item.Name == "" {
+			ids = append(ids, item.ProductID)
+		}
+	}
+	return s.productClient.ProductNames(ctx, ids)
+}
@@ -0,0 +1,192 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-microservice/internals/domain"
+)
+
+// RequestReturn opens a return for delivered lines of an order. Like
+// shipments, returns advance the order's fulfillment revision, so two
+// returns requested at once cannot both claim the same units.
+func (s *OrderServiceImplement) RequestReturn(ctx context.Context, orderID, actorID, role string, lines []domain.ReturnLine, refundToStoreCredit bool) (*domain.Return, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		order, err := s.repo.FindByID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if role != domain.RoleAdmin && order.UserID != actorID {
+			return nil, domain.ErrOrderNotFound
+		}
+
+		shipments, err := s.shipments.FindByOrder(ctx, orderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load shipments: %w", err)
+		}
+		existing, err := s.returns.FindByOrder(ctx, orderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load returns: %w", err)
+		}
+
+		ret, err := order.NewReturn(shipments, existing, lines)
+		if err != nil {
+			return nil, err
+		}
+		ret.RefundToStoreCredit = refundToStoreCredit
+		created, err := s.returns.Create(ctx, ret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create return: %w", err)
+		}
+
+		ok, err := s.repo.AdvanceFulfillment(ctx, orderID, order.FulfillmentRev)
+		if err == nil && ok {
+			return created, nil
+		}
+		if delErr := s.returns.Delete(ctx, created.ID); delErr != nil {
+			fmt.Println("warning: failed to remove return", created.ID, delErr)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create return: %w", err)
+		}
+	}
+	return nil, domain.ErrFulfillmentChanged
+}
+
+// ListReturns returns the returns of an order; customers only see their
+// own orders.
+func (s *OrderServiceImplement) ListReturns(ctx context.Context, orderID, actorID, role string) ([]*domain.Return, error) {
+	order, err := s.repo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if role != domain.RoleAdmin && role != domain.RoleWarehouse && order.UserID != actorID {
+		return nil, domain.ErrOrderNotFound
+	}
+	return s.returns.FindByOrder(ctx, orderID)
+}
+
+func (s *OrderServiceImplement) ReviewReturn(ctx context.Context, orderID, returnID, reviewerID string, approve bool, note string) (*domain.Return, error) {
+	ret, err := s.findReturn(ctx, orderID, returnID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := ret.Status
+	if err := ret.Decide(approve, note, reviewerID); err != nil {
+		return nil, err
+	}
+	if err := s.saveReturn(ctx, ret, from); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ReceiveReturn records what the warehouse received for an approved
+// return and then refunds it and restocks what can be sold again. Calling
+// it again on a received return retries whichever of those steps failed.
+func (s *OrderServiceImplement) ReceiveReturn(ctx context.Context, orderID, returnID, receiverID string, lines []domain.ReceivedLine) (*domain.Return, error) {
+	ret, err := s.findReturn(ctx, orderID, returnID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ret.Status == domain.ReturnApproved {
+		order, err := s.repo.FindByID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		others, err := s.returns.FindByOrder(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if err := ret.Receive(order, others, lines, receiverID); err != nil {
+			return nil, err
+		}
+		if err := s.saveReturn(ctx, ret, domain.ReturnApproved); err != nil {
+			return nil, err
+		}
+	} else if ret.Status != domain.ReturnReceived {
+		return nil, fmt.Errorf("%w: return is %s", domain.ErrReturnStateChange, ret.Status)
+	}
+
+	return s.processReturn(ctx, ret)
+}
+
+// processReturn refunds a received return and puts its resellable goods
+// back in stock. Both calls are keyed on the return, so repeating them
+// never pays or restocks twice.
+func (s *OrderServiceImplement) processReturn(ctx context.Context, ret *domain.Return) (*domain.Return, error) {
+	reference := "return:" + ret.ID
+	var errs []error
+
+	if !ret.Refunded {
+		if !ret.Refund.IsPositive() {
+			ret.Refunded = true
+		} else if _, err := s.paymentClient.RefundOrderPayment(ctx, ret.OrderID, reference, ret.Refund, "return", ret.RefundToStoreCredit); err != nil {
+			errs = append(errs, fmt.Errorf("failed to refund: %w", err))
+		} else {
+			ret.Refunded = true
+		}
+	}
+
+	if !ret.StockReturned {
+		var items []domain.OrderItem
+		for _, l := range ret.Restock() {
+			items = append(items, domain.OrderItem{ProductID: l.ProductID, Quantity: l.Received})
+		}
+		if len(items) == 0 {
+			ret.StockReturned = true
+		} else if err := s.productClient.RestockItems(ctx, reference, items); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restock: %w", err))
+		} else {
+			ret.StockReturned = true
+		}
+	}
+
+	ret.Complete()
+	if err := s.saveReturn(ctx, ret, domain.ReturnReceived); err != nil {
+		errs = append(errs, err)
+	}
+	if ret.Refunded && ret.Refund.IsPositive() {
+		s.creditReturn(ctx, ret)
+	}
+	if len(errs) > 0 {
+		return ret, fmt.Errorf("return %s is received but not fully processed, receive again to retry: %w", ret.ID, errors.Join(errs...))
+	}
+	return ret, nil
+}
+
+// creditReturn issues the credit note of a refunded return. Failures are
+// only logged: listing the order's invoices issues it later.
+func (s *OrderServiceImplement) creditReturn(ctx context.Context, ret *domain.Return) {
+	order, err := s.repo.FindByID(ctx, ret.OrderID)
+	if err == nil {
+		err = s.issueReturnCreditNote(ctx, order, ret)
+	}
+	if err != nil {
+		fmt.Println("warning: failed to issue credit note for return", ret.ID, err)
+	}
+}
+
+func (s *OrderServiceImplement) findReturn(ctx context.Context, orderID, returnID string) (*domain.Return, error) {
+	ret, err := s.returns.FindByID(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if ret.OrderID != orderID {
+		return nil, domain.ErrReturnNotFound
+	}
+	return ret, nil
+}
+
+func (s *OrderServiceImplement) saveReturn(ctx context.Context, ret *domain.Return, from string) error {
+	ok, err := s.returns.Update(ctx, ret, from)
+	if err != nil {
+		return fmt.Errorf("failed to save return: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: return %s was updated meanwhile", domain.ErrReturnStateChange, ret.ID)
+	}
+	return nil
+}
@@ -2,57 +2,121 @@ package application
 
 import (
 	"context"
+	"ecom-api/pkg/money"
+	"ecom-api/pkg/tax"
+	"errors"
 	"fmt"
 	"order-microservice/internals/adaptors/grpc"
 	"order-microservice/internals/domain"
 	"order-microservice/internals/ports"
+	"strings"
+	"time"
 )
 
 type OrderServiceImplement struct {
 	repo          ports.OrderRepository
+	shipments     ports.ShipmentRepository
+	returns       ports.ReturnRepository
+	invoices      ports.InvoiceRepository
 	cartClient    *grpc.CartClient
 	paymentClient *grpc.PaymentClient
+	productClient *grpc.ProductClient
+	userClient    *grpc.UserClient
+	taxCalculator ports.TaxCalculator
+	legalEntities *domain.LegalEntities
+	renderer      ports.InvoiceRenderer
+	jobs          ports.JobScheduler
+	publisher     ports.EventPublisher
+	unpaidTTL     time.Duration
+	subscriptions ports.SubscriptionRepository
+	retries       []time.Duration
+	watchers      *watchHub
 }
 
-func NewOrderService(repo ports.OrderRepository, cartClient *grpc.CartClient, paymentClient *grpc.PaymentClient) ports.OrderService {
+// OrderServiceOptions holds what the order service works with besides its
+// own stores, tax rules and invoicing: the clients of the other services,
+// the scheduler and outbox, and the subscription settings.
+type OrderServiceOptions struct {
+	CartClient          *grpc.CartClient
+	PaymentClient       *grpc.PaymentClient
+	ProductClient       *grpc.ProductClient
+	UserClient          *grpc.UserClient // checks verified emails at checkout; nil lets unverified users check out
+	Jobs                ports.JobScheduler
+	Publisher           ports.EventPublisher
+	UnpaidOrderTTL      time.Duration // how long an order may wait for payment; zero keeps it forever
+	Subscriptions       ports.SubscriptionRepository
+	SubscriptionRetries []time.Duration // waits after a subscription's failed payments before trying again
+}
+
+func NewOrderService(repo ports.OrderRepository, shipments ports.ShipmentRepository, returns ports.ReturnRepository, invoices ports.InvoiceRepository, taxCalculator ports.TaxCalculator, legalEntities *domain.LegalEntities, renderer ports.InvoiceRenderer, opts OrderServiceOptions) ports.OrderService {
 	return &OrderServiceImplement{
 		repo:          repo,
-		cartClient:    cartClient,
-		paymentClient: paymentClient,
+		shipments:     shipments,
+		returns:       returns,
+		invoices:      invoices,
+		cartClient:    opts.CartClient,
+		paymentClient: opts.PaymentClient,
+		productClient: opts.ProductClient,
+		userClient:    opts.UserClient,
+		taxCalculator: taxCalculator,
+		legalEntities: legalEntities,
+		renderer:      renderer,
+		jobs:          opts.Jobs,
+		publisher:     opts.Publisher,
+		unpaidTTL:     opts.UnpaidOrderTTL,
+		subscriptions: opts.Subscriptions,
+		retries:       opts.SubscriptionRetries,
+		watchers:      newWatchHub(),
 	}
 }
 
 // CreateOrderFromCart fetches the user's cart via gRPC and creates an order.
 // It sets the order status = PENDING, saves it, then notifies Payment-MS.
 // Payment-MS will later process the payment and call UpdateOrderStatus back.
-func (s *OrderServiceImplement) CreateOrderFromCart(ctx context.Context, userID string) (*domain.Order, error) {
+func (s *OrderServiceImplement) CreateOrderFromCart(ctx context.Context, userID string, checkout domain.Checkout) (*domain.Order, error) {
+	if err := s.checkEmailVerified(ctx, userID); err != nil {
+		return nil, err
+	}
+	region := checkout.ShippingAddress.Region()
+
 	// 1. Fetch cart
-	cartResp, err := s.cartClient.GetCart(ctx, userID)
+	cartResp, err := s.cartClient.GetCart(ctx, userID, region)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch cart: %w", err)
 	}
 	if len(cartResp.Items) == 0 {
-		return nil, fmt.Errorf("cart is empty")
+		return nil, fmt.Errorf("%w: user %s has nothing to order", domain.ErrCartEmpty, userID)
 	}
 
-	// 2. Build order
+	// 2. Build order; lines are priced from product-ms, not from the cart
 	var items []domain.OrderItem
-	var totalPrice float64
 	for _, ci := range cartResp.Items {
-		price := ci.Price
 		items = append(items, domain.OrderItem{
 			ProductID: ci.ProductId,
 			Quantity:  int(ci.Quantity),
-			Price:     price,
 		})
-		totalPrice += float64(ci.Quantity) * price
 	}
 
+	address := checkout.ShippingAddress
 	order := &domain.Order{
-		UserID:     userID,
-		Items:      items,
-		Total:    totalPrice,
-		Status:     "PENDING",
+		UserID:          userID,
+		Items:           items,
+		Currency:        cartResp.Currency,
+		ShippingAddress: &address,
+		ShippingMethod:  checkout.ShippingMethod,
+		Status:          "PENDING",
+	}
+
+	if err := s.snapshotItems(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := s.applyTax(order, region); err != nil {
+		return nil, err
+	}
+
+	// the cart is quoted by cart-ms from its own weight and value
+	if err := s.applyShipping(ctx, order, userID); err != nil {
+		return nil, err
 	}
 
 	// 3. Save order in DB
@@ -68,6 +132,9 @@ func (s *OrderServiceImplement) CreateOrderFromCart(ctx context.Context, userID
 	// 	fmt.Println("payment-ms response:", msg)
 	// }
 
+	s.scheduleExpiry(ctx, createdOrder)
+	s.orderCreated(ctx, createdOrder)
+
 	paymentRes, err := s.paymentClient.NotifyOrderCreated(ctx, createdOrder.ID)
 	if err != nil {
 		fmt.Println("warning: failed to process payment:", err)
@@ -76,21 +143,66 @@ func (s *OrderServiceImplement) CreateOrderFromCart(ctx context.Context, userID
 	}
 
 
-	// 5. Clear cart
+	// 5. Clear cart, taking its reserved stock for the order
 	if _, err := s.cartClient.ClearCart(ctx, userID); err != nil {
 		fmt.Println("warning: failed to clear cart:", err)
+	} else if err := s.repo.MarkStockCommitted(ctx, createdOrder.ID); err != nil {
+		fmt.Println("warning: failed to mark stock committed:", err)
+	} else {
+		createdOrder.StockCommitted = true
 	}
 
 	return createdOrder, nil
 }
 
+// checkEmailVerified refuses users who have not verified their email, if
+// checkout requires it.
+func (s *OrderServiceImplement) checkEmailVerified(ctx context.Context, userID string) error {
+	if s.userClient == nil {
+		return nil
+	}
+	verified, err := s.userClient.EmailVerified(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check email verification: %w", err)
+	}
+	if !verified {
+		return domain.ErrEmailNotVerified
+	}
+	return nil
+}
+
+// CreateOrder places an order for the given lines. Lines carry only
+// products and quantities: prices are taken from product-ms and totals are
+// computed here, and an order that comes with prices is refused.
 func (s *OrderServiceImplement) CreateOrder(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	for _, item := range order.Items {
+		if !item.Price.IsZero() || !item.Tax.IsZero() || !item.LineTotal.IsZero() {
+			return nil, fmt.Errorf("%w: prices of product %s are set by the server and must be left out", domain.ErrInvalidOrder, item.ProductID)
+		}
+	}
+
 	order.Status = "PENDING"
+	if order.ShippingAddress != nil {
+		order.TaxRegion = order.ShippingAddress.Region()
+	}
+	if err := s.snapshotItems(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := s.applyTax(order, order.TaxRegion); err != nil {
+		return nil, err
+	}
+	if err := s.applyShipping(ctx, order, ""); err != nil {
+		return nil, err
+	}
+
 	createdOrder, err := s.repo.Create(ctx, order)
 	if err != nil {
 		return nil, err
 	}
 
+	s.scheduleExpiry(ctx, createdOrder)
+	s.orderCreated(ctx, createdOrder)
+
 	// Notify Payment-MS about this order
 	if msg, err := s.paymentClient.NotifyOrderCreated(ctx, createdOrder.ID); err != nil {
 		fmt.Println("warning: failed to notify payment-ms:", err)
@@ -105,14 +217,439 @@ func (s *OrderServiceImplement) GetOrder(ctx context.Context, id string) (*domai
 	return s.repo.FindByID(ctx, id)
 }
 
-func (s *OrderServiceImplement) ListOrders(ctx context.Context) ([]*domain.Order, error) {
-	return s.repo.List(ctx)
+func (s *OrderServiceImplement) GetOwnOrder(ctx context.Context, id, actorID, role string) (*domain.Order, error) {
+	return s.findOwnOrder(ctx, id, actorID, role)
 }
 
+func (s *OrderServiceImplement) ListOrders(ctx context.Context, actorID, role string) ([]*domain.Order, error) {
+	if role == domain.RoleAdmin || role == domain.RoleSystem {
+		return s.repo.List(ctx, "")
+	}
+	if actorID == "" {
+		return []*domain.Order{}, nil
+	}
+	return s.repo.List(ctx, actorID)
+}
+
+// UpdateOrderStatus records the payment status of an unpaid order; see
+// domain.CanChangePaymentStatus. Other statuses follow cancellation and
+// shipments. Reporting the status the order already has changes nothing.
 func (s *OrderServiceImplement) UpdateOrderStatus(ctx context.Context, id string, status string) (*domain.Order, error) {
-	return s.repo.UpdateOrderStatus(ctx, id, status)
+	if status == domain.StatusCancelled {
+		return nil, fmt.Errorf("%w: use cancel to cancel an order", domain.ErrNotCancellable)
+	}
+
+	order, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == status {
+		return order, nil
+	}
+	if !domain.CanChangePaymentStatus(order.Status, status) {
+		return nil, fmt.Errorf("%w: order %s cannot go from %s to %q", domain.ErrInvalidStatus, id, order.Status, status)
+	}
+
+	updated, err := s.repo.UpdateOrderStatus(ctx, id, order.Status, status)
+	if err != nil {
+		return nil, err
+	}
+	s.statusChanged(ctx, updated, order.Status, updated.Status)
+	s.syncSubscription(ctx, updated)
+	if !updated.Unpaid() {
+		if err := s.jobs.Unschedule(ctx, domain.JobExpireUnpaidOrder, id); err != nil {
+			fmt.Println("warning: failed to drop expiry of order", id, err)
+		}
+	}
+	// a paid order is invoiced right away; failing that, on first download
+	if updated.Invoiceable() {
+		if _, err := s.issueInvoice(ctx, updated); err != nil {
+			fmt.Println("warning: failed to issue invoice for order", id, err)
+		}
+	}
+	return updated, nil
+}
+
+// CancelOrder cancels an order if the actor's role allows it in the
+// order's current status. The order is marked cancelled first so payment-ms
+// refuses new payments for it, then its payment is reversed and the stock
+// taken at checkout is put back. Calling it again on a cancelled order
+// retries whichever of those steps failed.
+func (s *OrderServiceImplement) CancelOrder(ctx context.Context, id, actorID, role, reason, note string) (*domain.Order, error) {
+	admin := role == domain.RoleAdmin
+
+	order, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// customers only see their own orders
+	if !admin && order.UserID != actorID {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	if order.Status == domain.StatusCancelled {
+		if order.Cancellation == nil || order.Cancellation.Complete() {
+			return nil, fmt.Errorf("%w: order is already cancelled", domain.ErrNotCancellable)
+		}
+		return s.reverseCancelledOrder(ctx, order)
+	}
+
+	if !domain.CanCancel(order.Status, admin) {
+		return nil, fmt.Errorf("%w: %s orders cannot be cancelled by %s", domain.ErrNotCancellable, strings.ToLower(order.Status), actorRole(admin))
+	}
+	if !domain.ValidCancelReason(reason, admin) {
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidCancelReason, reason)
+	}
+
+	// a parcel may have been picked up before the order status caught up
+	shipments, err := s.shipments.FindByOrder(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shipments: %w", err)
+	}
+	for _, sh := range shipments {
+		if sh.Shipped() {
+			return nil, fmt.Errorf("%w: shipment %s already left the warehouse", domain.ErrNotCancellable, sh.ID)
+		}
+	}
+
+	c := &domain.Cancellation{
+		Reason:      reason,
+		Note:        strings.TrimSpace(note),
+		CancelledBy: actorID,
+		Role:        actorRole(admin),
+		CancelledAt: time.Now(),
+	}
+	ok, err := s.repo.Cancel(ctx, id, order.Status, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel order: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: order %s changed while it was being cancelled", domain.ErrNotCancellable, id)
+	}
+	from := order.Status
+	order.Status = domain.StatusCancelled
+	order.Cancellation = c
+	s.statusChanged(ctx, order, from, order.Status)
+	s.voidShipments(ctx, shipments)
+	s.syncSubscription(ctx, order)
+	return s.reverseCancelledOrder(ctx, order)
+}
+
+// reverseCancelledOrder runs the outstanding reversal steps of a cancelled
+// order and records their outcome on the order.
+func (s *OrderServiceImplement) reverseCancelledOrder(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	c := order.Cancellation
+	var errs []error
+
+	if c.PaymentAction == "" {
+		res, err := s.paymentClient.CancelOrderPayment(ctx, order.ID, c.Reason)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to reverse payment: %w", err))
+		} else {
+			c.PaymentAction = res.GetAction()
+			if res.GetRefunded() != nil {
+				c.Refunded = money.FromProto(res.GetRefunded())
+			}
+		}
+	}
+
+	if !c.StockReleased {
+		if !order.StockCommitted {
+			c.StockReleased = true
+		} else if err := s.productClient.RestockItems(ctx, "order:"+order.ID, order.Items); err != nil {
+			errs = append(errs, fmt.Errorf("failed to release stock: %w", err))
+		} else {
+			c.StockReleased = true
+		}
+	}
+
+	if err := s.repo.UpdateCancellation(ctx, order.ID, c); err != nil {
+		errs = append(errs, fmt.Errorf("failed to save cancellation: %w", err))
+	}
+	if c.PaymentAction == domain.ActionRefunded {
+		if err := s.issueCancellationCreditNote(ctx, order); err != nil {
+			fmt.Println("warning: failed to issue credit note for order", order.ID, err)
+		}
+	}
+	if len(errs) > 0 {
+		return order, fmt.Errorf("order %s is cancelled but not fully reversed, cancel again to retry: %w", order.ID, errors.Join(errs...))
+	}
+	return order, nil
+}
+
+// voidShipments cancels the labels of a cancelled order's shipments that
+// were not picked up yet.
+func (s *OrderServiceImplement) voidShipments(ctx context.Context, shipments []*domain.Shipment) {
+	for _, sh := range shipments {
+		if sh.Status != domain.ShipmentLabelCreated {
+			continue
+		}
+		from := sh.Status
+		if err := sh.Apply(domain.ShipmentUpdate{Status: domain.ShipmentCancelled, Note: "order cancelled"}); err != nil {
+			continue
+		}
+		if ok, err := s.shipments.Update(ctx, sh, from); err != nil || !ok {
+			fmt.Println("warning: failed to void shipment", sh.ID, err)
+			continue
+		}
+		s.publish(ctx, domain.EventShipmentUpdated, sh.OrderID, domain.NewShipmentEvent(sh))
+	}
+}
+
+func actorRole(admin bool) string {
+	if admin {
+		return "admin"
+	}
+	return "customer"
+}
+
+
+// CreateShipment creates a shipment with its label for lines of a paid
+// order. The order's fulfillment revision is advanced after the insert, so
+// of two shipments created at once for the same goods only one survives.
+func (s *OrderServiceImplement) CreateShipment(ctx context.Context, orderID, actorID string, lines []domain.ShipmentLine, carrier, trackingNumber string) (*domain.Shipment, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		order, err := s.repo.FindByID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		existing, err := s.shipments.FindByOrder(ctx, orderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load shipments: %w", err)
+		}
+
+		shipment, err := order.NewShipment(existing, lines, carrier, trackingNumber, actorID)
+		if err != nil {
+			return nil, err
+		}
+		created, err := s.shipments.Create(ctx, shipment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create shipment: %w", err)
+		}
+
+		ok, err := s.repo.AdvanceFulfillment(ctx, orderID, order.FulfillmentRev)
+		if err == nil && ok {
+			s.publish(ctx, domain.EventShipmentCreated, orderID, domain.NewShipmentEvent(created))
+			return created, nil
+		}
+		if delErr := s.shipments.Delete(ctx, created.ID); delErr != nil {
+			fmt.Println("warning: failed to remove shipment", created.ID, delErr)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create shipment: %w", err)
+		}
+	}
+	return nil, domain.ErrFulfillmentChanged
+}
+
+// ListShipments returns the shipments of an order; customers only see
+// their own orders.
+func (s *OrderServiceImplement) ListShipments(ctx context.Context, orderID, actorID, role string) ([]*domain.Shipment, error) {
+	order, err := s.repo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if role != domain.RoleAdmin && order.UserID != actorID {
+		return nil, domain.ErrOrderNotFound
+	}
+	return s.shipments.FindByOrder(ctx, orderID)
 }
 
-func (s *OrderServiceImplement) DeleteOrder(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+func (s *OrderServiceImplement) UpdateShipment(ctx context.Context, orderID, shipmentID string, update domain.ShipmentUpdate) (*domain.Shipment, error) {
+	shipment, err := s.shipments.FindByID(ctx, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+	if shipment.OrderID != orderID {
+		return nil, domain.ErrShipmentNotFound
+	}
+
+	from := shipment.Status
+	if err := shipment.Apply(update); err != nil {
+		return nil, err
+	}
+	ok, err := s.shipments.Update(ctx, shipment, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update shipment: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: shipment %s was updated meanwhile", domain.ErrFulfillmentChanged, shipmentID)
+	}
+	s.publish(ctx, domain.EventShipmentUpdated, orderID, domain.NewShipmentEvent(shipment))
+
+	if err := s.syncFulfillmentStatus(ctx, orderID); err != nil {
+		return shipment, fmt.Errorf("shipment updated but order status not refreshed: %w", err)
+	}
+	return shipment, nil
+}
+
+// syncFulfillmentStatus derives the order status from its shipments. It
+// claims the next fulfillment revision before reading the shipments, so a
+// concurrent update that claims a later one overrides a stale status.
+func (s *OrderServiceImplement) syncFulfillmentStatus(ctx context.Context, orderID string) error {
+	for attempt := 0; attempt < 3; attempt++ {
+		order, err := s.repo.FindByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		ok, err := s.repo.AdvanceFulfillment(ctx, orderID, order.FulfillmentRev)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		shipments, err := s.shipments.FindByOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		status := order.FulfillmentStatus(shipments)
+		if status == order.Status {
+			return nil
+		}
+		// losing the race means a later change derives the status instead
+		ok, err = s.repo.SetFulfillmentStatus(ctx, orderID, order.FulfillmentRev+1, status)
+		if ok {
+			s.statusChanged(ctx, order, order.Status, status)
+		}
+		return err
+	}
+	return domain.ErrFulfillmentChanged
+}
+
+// DeleteOrder soft-deletes an order; it is hidden until restored or purged.
+func (s *OrderServiceImplement) DeleteOrder(ctx context.Context, id, actorID, role string) error {
+	if role != domain.RoleAdmin && role != domain.RoleSystem {
+		order, err := s.findOwnOrder(ctx, id, actorID, role)
+		if err != nil {
+			return err
+		}
+		if !order.DeletableByCustomer() {
+			return fmt.Errorf("%w: order %s is %s", domain.ErrNotDeletable, id, order.Status)
+		}
+	}
+	return s.repo.Delete(ctx, id, actorID)
+}
+
+func (s *OrderServiceImplement) RestoreOrder(ctx context.Context, id string) (*domain.Order, error) {
+	return s.repo.Restore(ctx, id)
+}
+
+func (s *OrderServiceImplement) PurgeDeletedOrders(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.Purge(ctx, before)
+}
+
+// snapshotItems copies the product details and the current unit price of
+// every line from product-ms, so the order keeps them if the product
+// changes later. Prices are quoted in the order currency; an order without
+// one takes the currency of its first product.
+func (s *OrderServiceImplement) snapshotItems(ctx context.Context, order *domain.Order) error {
+	if len(order.Items) == 0 {
+		return fmt.Errorf("%w: an order needs at least one line", domain.ErrInvalidOrder)
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity of product %s must be positive", domain.ErrInvalidOrder, item.ProductID)
+		}
+
+		p, err := s.productClient.GetProduct(ctx, item.ProductID, order.Currency)
+		if err != nil {
+			return fmt.Errorf("%w: product %s: %v", domain.ErrInvalidOrder, item.ProductID, err)
+		}
+
+		item.Name = p.GetName()
+		item.SKU = p.GetSku()
+		item.ImageURL = p.GetImageUrl()
+		item.TaxCategory = p.GetTaxCategory()
+		item.Weight = p.GetWeight()
+		item.Price = money.FromProto(p.GetPrice())
+		item.PriceSource = "base"
+		item.ExchangeRate = 1
+		if q := p.GetQuote(); q != nil {
+			item.Price = money.FromProto(q.GetPrice())
+			item.PriceSource = q.GetSource()
+			item.ExchangeRate = q.GetExchangeRate()
+		}
+
+		if order.Currency == "" {
+			order.Currency = item.Price.Currency
+		}
+		if item.Price.Currency != order.Currency {
+			return fmt.Errorf("%w: product %s is priced in %s, not %s", domain.ErrInvalidOrder, item.ProductID, item.Price.Currency, order.Currency)
+		}
+	}
+	return nil
+}
+
+// applyTax prices every line of the order for the region and sets the
+// per-line and total tax amounts that invoicing relies on.
+func (s *OrderServiceImplement) applyTax(order *domain.Order, region string) error {
+	lines := make([]tax.Line, len(order.Items))
+	for i, item := range order.Items {
+		lines[i] = tax.Line{
+			Category:  item.TaxCategory,
+			UnitPrice: item.Price,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	result, err := s.taxCalculator.Calculate(region, lines)
+	if err != nil {
+		return fmt.Errorf("failed to calculate tax: %w", err)
+	}
+
+	for i, lt := range result.Lines {
+		order.Items[i].TaxCategory = lt.Category
+		order.Items[i].TaxRate = lt.Rate
+		order.Items[i].Tax = lt.Tax
+		order.Items[i].LineTotal = lt.Gross
+	}
+
+	order.TaxRegion = result.Region
+	order.PricesIncludeTax = result.PricesIncludeTax
+	order.Subtotal = result.Subtotal
+	order.TaxTotal = result.TaxTotal
+	order.Total = result.Total
+	if order.Currency == "" {
+		order.Currency = result.Total.Currency
+	}
+	if order.Total.Currency != "" && order.Total.Currency != order.Currency {
+		return fmt.Errorf("order currency is %s but items are priced in %s", order.Currency, order.Total.Currency)
+	}
+	return nil
+}
+
+// applyShipping prices the order's chosen shipping method through cart-ms
+// and adds the cost to the order total. With a userID the user's cart is
+// quoted, otherwise the parcel is the order's lines by weight and subtotal.
+func (s *OrderServiceImplement) applyShipping(ctx context.Context, order *domain.Order, userID string) error {
+	if order.ShippingMethod == "" {
+		return nil
+	}
+	if order.ShippingAddress == nil {
+		return fmt.Errorf("shipping address is required for shipping method %s", order.ShippingMethod)
+	}
+
+	quotes, err := s.cartClient.GetShippingQuotes(ctx, userID, *order.ShippingAddress, order.Weight(), order.Subtotal)
+	if err != nil {
+		return fmt.Errorf("failed to quote shipping: %w", err)
+	}
+
+	for _, q := range quotes {
+		if q.MethodCode == order.ShippingMethod {
+			cost := money.FromProto(q.Cost)
+			total, err := order.Total.Add(cost)
+			if err != nil {
+				return fmt.Errorf("failed to add shipping cost: %w", err)
+			}
+			order.ShippingCost = cost
+			order.Total = total
+			return nil
+		}
+	}
+
+	return fmt.Errorf("shipping method %s is not available for this address", order.ShippingMethod)
 }
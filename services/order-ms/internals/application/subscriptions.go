@@ -0,0 +1,499 @@
+package application
+
+import (
+	"context"
+	"ecom-api/pkg/events"
+	"ecom-api/pkg/money"
+	"errors"
+	"fmt"
+	"log"
+	"order-microservice/internals/domain"
+	"strings"
+	"time"
+)
+
+const (
+	subscriptionBatchSize = 100
+	// paymentCheckWait is how long a cycle waits for the outcome of a
+	// payment payment-ms has not settled yet before looking again.
+	paymentCheckWait = time.Hour
+	// paymentTimeout is how long a payment may go without an outcome
+	// before the attempt counts as failed.
+	paymentTimeout = 72 * time.Hour
+)
+
+// CreateSubscription subscribes a user to products ordered every interval.
+// Without startAt the first order is placed and charged right away.
+func (s *OrderServiceImplement) CreateSubscription(ctx context.Context, sub *domain.Subscription, startAt *time.Time) (*domain.Subscription, error) {
+	now := time.Now()
+	sub.Status = domain.SubscriptionActive
+	sub.NextRunAt = now
+	if startAt != nil {
+		if startAt.Before(now) {
+			return nil, fmt.Errorf("%w: start must not be in the past", domain.ErrInvalidSubscription)
+		}
+		sub.NextRunAt = *startAt
+	}
+	sub.Cycles = 0
+	sub.Current = nil
+	sub.History = []domain.SubscriptionCycle{}
+	sub.CreatedAt = now
+	sub.UpdatedAt = now
+
+	if err := sub.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEmailVerified(ctx, sub.UserID); err != nil {
+		return nil, err
+	}
+	if err := s.checkSubscriptionItems(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	created, err := s.subscriptions.Create(ctx, sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	s.scheduleBilling(ctx, created)
+	if created.NextRunAt.After(now) {
+		return created, nil
+	}
+
+	// the job picks it up again if the first order cannot be billed now
+	if err := s.BillSubscription(ctx, created.ID); err != nil {
+		fmt.Println("warning: failed to bill subscription", created.ID, err)
+	}
+	return s.subscriptions.FindByID(ctx, created.ID)
+}
+
+// GetSubscription returns a subscription; customers only see their own.
+func (s *OrderServiceImplement) GetSubscription(ctx context.Context, id, actorID, role string) (*domain.Subscription, error) {
+	sub, err := s.subscriptions.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if role != domain.RoleAdmin && sub.UserID != actorID {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
+// ListSubscriptions returns the actor's subscriptions; admins may list any
+// user's, or everyone's without userID.
+func (s *OrderServiceImplement) ListSubscriptions(ctx context.Context, actorID, role, userID, status string, limit int64) ([]*domain.Subscription, error) {
+	if role != domain.RoleAdmin {
+		userID = actorID
+	}
+	return s.subscriptions.List(ctx, userID, strings.ToUpper(status), limit)
+}
+
+// UpdateSubscription changes the products, schedule, payment method or
+// shipping of a subscription from its next order on.
+func (s *OrderServiceImplement) UpdateSubscription(ctx context.Context, id, actorID, role string, change domain.SubscriptionChange) (*domain.Subscription, error) {
+	return s.changeSubscription(ctx, id, actorID, role, func(sub *domain.Subscription, now time.Time) error {
+		if err := sub.Change(change, now); err != nil {
+			return err
+		}
+		if change.Items != nil {
+			return s.checkSubscriptionItems(ctx, sub)
+		}
+		return nil
+	})
+}
+
+// PauseSubscription stops a subscription from placing orders until until,
+// or until it is resumed if until is nil.
+func (s *OrderServiceImplement) PauseSubscription(ctx context.Context, id, actorID, role string, until *time.Time) (*domain.Subscription, error) {
+	return s.changeSubscription(ctx, id, actorID, role, func(sub *domain.Subscription, now time.Time) error {
+		return sub.Pause(until, now)
+	})
+}
+
+func (s *OrderServiceImplement) ResumeSubscription(ctx context.Context, id, actorID, role string) (*domain.Subscription, error) {
+	return s.changeSubscription(ctx, id, actorID, role, func(sub *domain.Subscription, now time.Time) error {
+		return sub.Resume(now)
+	})
+}
+
+// SkipSubscription leaves out a subscription's next order, or the order
+// whose payment is being retried.
+func (s *OrderServiceImplement) SkipSubscription(ctx context.Context, id, actorID, role string) (*domain.Subscription, error) {
+	var open *domain.SubscriptionCycle
+	sub, err := s.changeSubscription(ctx, id, actorID, role, func(sub *domain.Subscription, now time.Time) error {
+		open = sub.Current
+		return sub.Skip(now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if open != nil && open.OrderID != "" {
+		s.cancelCycleOrder(ctx, open.OrderID, domain.ReasonCustomerRequest, "subscription order skipped")
+	}
+	return sub, nil
+}
+
+// CancelSubscription ends a subscription. The order of a cycle that is
+// not paid yet is cancelled with it.
+func (s *OrderServiceImplement) CancelSubscription(ctx context.Context, id, actorID, role string) (*domain.Subscription, error) {
+	return s.cancelSubscription(ctx, id, actorID, role, domain.SubscriptionCancelledByCustomer, domain.ReasonCustomerRequest)
+}
+
+// cancelSubscription ends a subscription for reason, cancelling the unpaid
+// order of its open cycle for orderReason.
+func (s *OrderServiceImplement) cancelSubscription(ctx context.Context, id, actorID, role, reason, orderReason string) (*domain.Subscription, error) {
+	var open *domain.SubscriptionCycle
+	sub, err := s.changeSubscription(ctx, id, actorID, role, func(sub *domain.Subscription, now time.Time) error {
+		open = sub.Current
+		return sub.Cancel(reason, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if open != nil && open.OrderID != "" {
+		s.cancelCycleOrder(ctx, open.OrderID, orderReason, "subscription cancelled")
+	}
+	s.publishSubscription(ctx, domain.EventSubscriptionCancelled, sub, sub.History[len(sub.History)-1])
+	return sub, nil
+}
+
+// changeSubscription applies a customer's change to a subscription,
+// reading it again if it changed while the change was being saved.
+func (s *OrderServiceImplement) changeSubscription(ctx context.Context, id, actorID, role string, change func(*domain.Subscription, time.Time) error) (*domain.Subscription, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		sub, err := s.GetSubscription(ctx, id, actorID, role)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		if err := change(sub, now); err != nil {
+			return nil, err
+		}
+		sub.UpdatedAt = now
+		ok, err := s.subscriptions.Update(ctx, sub)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save subscription: %w", err)
+		}
+		if ok {
+			s.scheduleBilling(ctx, sub)
+			return sub, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: subscription %s changed meanwhile", domain.ErrSubscriptionStateChange, id)
+}
+
+// checkSubscriptionItems makes sure every product of a subscription is
+// sold in its currency, taking the currency of the first product if it
+// has none, so a bad line is refused now instead of at the next order.
+func (s *OrderServiceImplement) checkSubscriptionItems(ctx context.Context, sub *domain.Subscription) error {
+	for _, it := range sub.Items {
+		p, err := s.productClient.GetProduct(ctx, it.ProductID, sub.Currency)
+		if err != nil {
+			return fmt.Errorf("%w: product %s: %v", domain.ErrInvalidSubscription, it.ProductID, err)
+		}
+		price := money.FromProto(p.GetPrice())
+		if q := p.GetQuote(); q != nil {
+			price = money.FromProto(q.GetPrice())
+		}
+		if sub.Currency == "" {
+			sub.Currency = price.Currency
+		}
+		if price.Currency != sub.Currency {
+			return fmt.Errorf("%w: product %s is priced in %s, not %s", domain.ErrInvalidSubscription, it.ProductID, price.Currency, sub.Currency)
+		}
+	}
+	return nil
+}
+
+// scheduleBilling arranges for the scheduler to bill a subscription when
+// it is next due. If scheduling fails the sweep in BillDueSubscriptions
+// catches it instead.
+func (s *OrderServiceImplement) scheduleBilling(ctx context.Context, sub *domain.Subscription) {
+	var err error
+	if due := sub.DueAt(); due.IsZero() {
+		err = s.jobs.Unschedule(ctx, domain.JobBillSubscription, sub.ID)
+	} else {
+		err = s.jobs.Schedule(ctx, domain.JobBillSubscription, sub.ID, due)
+	}
+	if err != nil {
+		fmt.Println("warning: failed to schedule billing of subscription", sub.ID, err)
+	}
+}
+
+// BillSubscription does what is due for a subscription: places and
+// charges the order of its next cycle, retries or checks the payment of
+// its open cycle, or ends its pause. It is the handler of the
+// subscription's billing job; an error means the job should run again.
+func (s *OrderServiceImplement) BillSubscription(ctx context.Context, id string) error {
+	sub, err := s.subscriptions.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrSubscriptionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	due := sub.DueAt()
+	if due.IsZero() {
+		return nil
+	}
+	if now.Before(due) {
+		// it was put off after the job was scheduled
+		return s.jobs.Schedule(ctx, domain.JobBillSubscription, id, due)
+	}
+
+	switch {
+	case sub.Status == domain.SubscriptionPaused:
+		if err := sub.Resume(now); err != nil {
+			return err
+		}
+		return s.saveSubscription(ctx, sub, now)
+	case sub.Current == nil:
+		if err := sub.OpenCycle(now); err != nil {
+			return err
+		}
+		if err := s.saveSubscription(ctx, sub, now); err != nil {
+			return err
+		}
+	}
+	return s.chargeCycle(ctx, sub, now)
+}
+
+// chargeCycle charges the order of a subscription's open cycle, placing
+// it first if needed. Outcomes payment-ms reports later reach the cycle
+// through UpdateOrderStatus.
+func (s *OrderServiceImplement) chargeCycle(ctx context.Context, sub *domain.Subscription, now time.Time) error {
+	c := sub.Current
+	order, err := s.cycleOrder(ctx, sub)
+	if errors.Is(err, domain.ErrInvalidOrder) {
+		// e.g. a product is no longer sold; retried like a declined payment
+		sub.AttemptPayment(now, paymentCheckWait)
+		if err := s.saveSubscription(ctx, sub, now); err != nil {
+			return err
+		}
+		return s.settleCycle(ctx, sub.ID, "", domain.StatusFailed, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	if c.OrderID == "" {
+		c.OrderID = order.ID
+		if err := s.saveSubscription(ctx, sub, now); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case order.Status == domain.StatusCancelled:
+		// the cycle missed hearing of it
+		return s.settleCycle(ctx, sub.ID, order.ID, domain.StatusCancelled, "")
+	case order.Paid():
+		// possibly shipped already, and the cycle missed hearing of it
+		return s.settleCycle(ctx, sub.ID, order.ID, domain.StatusCompleted, "")
+	case !order.Unpaid():
+		return fmt.Errorf("order %s of subscription %s has status %s, which settles no payment", order.ID, sub.ID, order.Status)
+	case c.Failures < c.Attempts:
+		if c.LastAttemptAt != nil && now.Sub(*c.LastAttemptAt) < paymentTimeout {
+			sub.AwaitPayment(now, paymentCheckWait)
+			return s.saveSubscription(ctx, sub, now)
+		}
+		return s.settleCycle(ctx, sub.ID, order.ID, domain.StatusFailed, fmt.Sprintf("no payment outcome within %s", paymentTimeout))
+	}
+
+	// the order waits for payment again
+	if order.Status == domain.StatusFailed {
+		if _, err := s.repo.UpdateOrderStatus(ctx, order.ID, domain.StatusFailed, domain.StatusPending); err != nil {
+			return fmt.Errorf("failed to reopen order %s: %w", order.ID, err)
+		}
+		s.statusChanged(ctx, order, order.Status, domain.StatusPending)
+	}
+	sub.AttemptPayment(now, paymentCheckWait)
+	if err := s.saveSubscription(ctx, sub, now); err != nil {
+		return err
+	}
+
+	payment, err := s.paymentClient.ProcessPayment(ctx, order.ID, sub.UserID, order.Total, sub.MethodID)
+	if err != nil {
+		return s.settleCycle(ctx, sub.ID, order.ID, domain.StatusFailed, err.Error())
+	}
+	return s.settleCycle(ctx, sub.ID, order.ID, payment.GetStatus(), "payment failed")
+}
+
+// cycleOrder returns the order of a subscription's open cycle, placing it
+// if the cycle has none yet.
+func (s *OrderServiceImplement) cycleOrder(ctx context.Context, sub *domain.Subscription) (*domain.Order, error) {
+	c := sub.Current
+	if c.OrderID != "" {
+		return s.repo.FindByID(ctx, c.OrderID)
+	}
+	// an earlier run may have placed it but not saved it on the cycle
+	order, err := s.repo.FindBySubscriptionCycle(ctx, sub.ID, c.Number)
+	if err == nil {
+		return order, nil
+	}
+	if !errors.Is(err, domain.ErrOrderNotFound) {
+		return nil, err
+	}
+
+	items := make([]domain.OrderItem, len(sub.Items))
+	for i, it := range sub.Items {
+		items[i] = domain.OrderItem{ProductID: it.ProductID, Quantity: it.Quantity}
+	}
+	address := sub.ShippingAddress
+	return s.CreateOrder(ctx, &domain.Order{
+		UserID:            sub.UserID,
+		Items:             items,
+		Currency:          sub.Currency,
+		ShippingAddress:   &address,
+		ShippingMethod:    sub.ShippingMethod,
+		SubscriptionID:    sub.ID,
+		SubscriptionCycle: c.Number,
+	})
+}
+
+// syncSubscription passes the payment outcome of an order a subscription
+// placed on to the subscription.
+func (s *OrderServiceImplement) syncSubscription(ctx context.Context, order *domain.Order) {
+	if order.SubscriptionID == "" {
+		return
+	}
+	if err := s.settleCycle(ctx, order.SubscriptionID, order.ID, order.Status, "payment failed"); err != nil {
+		fmt.Println("warning: failed to update subscription", order.SubscriptionID, "from order", order.ID, err)
+	}
+}
+
+// settleCycle records what became of the payment of the open cycle whose
+// order is orderID: paid, failed for reason, or cancelled. Any other
+// status, pending or not a payment outcome at all, is ignored, as is an
+// outcome that was recorded already, so it is safe to call for every
+// report of the same payment.
+func (s *OrderServiceImplement) settleCycle(ctx context.Context, subscriptionID, orderID, status, reason string) error {
+	if status != domain.StatusCompleted && status != domain.StatusFailed && status != domain.StatusCancelled {
+		return nil
+	}
+	for attempt := 0; attempt < 3; attempt++ {
+		sub, err := s.subscriptions.FindByID(ctx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		if sub.Current == nil || sub.Current.OrderID != orderID {
+			return nil
+		}
+
+		now := time.Now()
+		var changed bool
+		switch status {
+		case domain.StatusCancelled:
+			changed = sub.OrderCancelled(now)
+		case domain.StatusFailed:
+			changed = sub.PaymentFailed(reason, now, s.retries)
+		case domain.StatusCompleted:
+			changed = sub.PaymentSucceeded(now)
+		}
+		if !changed {
+			return nil
+		}
+
+		sub.UpdatedAt = now
+		ok, err := s.subscriptions.Update(ctx, sub)
+		if err != nil {
+			return fmt.Errorf("failed to save subscription %s: %w", subscriptionID, err)
+		}
+		if ok {
+			s.scheduleBilling(ctx, sub)
+			s.cycleSettled(ctx, sub)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: subscription %s changed meanwhile", domain.ErrSubscriptionStateChange, subscriptionID)
+}
+
+// cycleSettled announces the outcome of a payment and cancels the order
+// of a cycle whose retries ran out.
+func (s *OrderServiceImplement) cycleSettled(ctx context.Context, sub *domain.Subscription) {
+	if c := sub.Current; c != nil {
+		s.publishSubscription(ctx, domain.EventSubscriptionPaymentFailed, sub, *c)
+		return
+	}
+
+	c := sub.History[len(sub.History)-1]
+	switch c.Status {
+	case domain.CyclePaid:
+		s.publishSubscription(ctx, domain.EventSubscriptionRenewed, sub, c)
+	case domain.CycleFailed:
+		s.publishSubscription(ctx, domain.EventSubscriptionPaymentFailed, sub, c)
+		s.publishSubscription(ctx, domain.EventSubscriptionCancelled, sub, c)
+		if c.OrderID != "" {
+			s.cancelCycleOrder(ctx, c.OrderID, domain.ReasonPaymentFailed, fmt.Sprintf("subscription payment failed %d times", c.Failures))
+		}
+	}
+}
+
+// cancelCycleOrder cancels the order of a cycle the subscription gave up
+// on, unless it was paid meanwhile.
+func (s *OrderServiceImplement) cancelCycleOrder(ctx context.Context, orderID, reason, note string) {
+	order, err := s.repo.FindByID(ctx, orderID)
+	if err != nil {
+		fmt.Println("warning: failed to load order", orderID, err)
+		return
+	}
+	if !order.Unpaid() {
+		return
+	}
+	if err := s.cancelAsSystem(ctx, order, reason, note); err != nil {
+		fmt.Println("warning: failed to cancel order", orderID, err)
+		return
+	}
+	if _, err := s.reverseCancelledOrder(ctx, order); err != nil {
+		fmt.Println("warning:", err)
+	}
+}
+
+func (s *OrderServiceImplement) saveSubscription(ctx context.Context, sub *domain.Subscription, now time.Time) error {
+	sub.UpdatedAt = now
+	ok, err := s.subscriptions.Update(ctx, sub)
+	if err != nil {
+		return fmt.Errorf("failed to save subscription %s: %w", sub.ID, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: subscription %s changed meanwhile", domain.ErrSubscriptionStateChange, sub.ID)
+	}
+	s.scheduleBilling(ctx, sub)
+	return nil
+}
+
+// publishSubscription announces an event about cycle c of a
+// subscription. The change it reports is saved already, so a failure is
+// only logged.
+func (s *OrderServiceImplement) publishSubscription(ctx context.Context, eventType string, sub *domain.Subscription, c domain.SubscriptionCycle) {
+	event := events.New(eventType, eventSource, sub.ID, domain.NewSubscriptionEvent(sub, c, time.Now().UTC()))
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		fmt.Println("warning: failed to publish", eventType, "for subscription", sub.ID, err)
+	}
+}
+
+// BillDueSubscriptions bills a batch of subscriptions that are due. It
+// backs up the per-subscription jobs, covering those whose job could not
+// be scheduled.
+func (s *OrderServiceImplement) BillDueSubscriptions(ctx context.Context) (int, error) {
+	subs, err := s.subscriptions.FindDue(ctx, time.Now(), subscriptionBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find due subscriptions: %w", err)
+	}
+
+	billed := 0
+	for _, sub := range subs {
+		if err := s.BillSubscription(ctx, sub.ID); err != nil {
+			log.Printf("failed to bill subscription %s: %v", sub.ID, err)
+			continue
+		}
+		billed++
+	}
+	if billed > 0 {
+		log.Printf("subscription sweep: %d subscriptions billed", billed)
+	}
+	return billed, nil
+}
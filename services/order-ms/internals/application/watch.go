@@ -0,0 +1,94 @@
+package application
+
+import (
+	"context"
+	"order-microservice/internals/domain"
+	"sync"
+	"time"
+)
+
+// watchPollInterval bounds how late a watcher learns of a change made by
+// another replica; changes made by this one wake it right away.
+const watchPollInterval = 2 * time.Second
+
+// WatchOrder sends the status changes of an order later than after to send
+// as they happen, until ctx ends or send fails. A client that reconnects
+// passes the Seq of the last change it received and misses nothing. send is
+// called on every check of the order, with no changes if there were none,
+// so the first call also tells that the order was found.
+func (s *OrderServiceImplement) WatchOrder(ctx context.Context, id, actorID, role string, after int64, send func([]domain.StatusChange) error) error {
+	// subscribe before the first read so no change slips in between
+	wake := s.watchers.subscribe(id)
+	defer s.watchers.unsubscribe(id, wake)
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		order, err := s.findOwnOrder(ctx, id, actorID, role)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		changes := order.ChangesAfter(after)
+		if err := send(changes); err != nil {
+			return err
+		}
+		if len(changes) > 0 {
+			after = changes[len(changes)-1].Seq
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchHub wakes the watchers of an order on this replica when its status
+// changes.
+type watchHub struct {
+	mu       sync.Mutex
+	watchers map[string]map[chan struct{}]struct{}
+}
+
+func newWatchHub() *watchHub {
+	return &watchHub{watchers: map[string]map[chan struct{}]struct{}{}}
+}
+
+func (h *watchHub) subscribe(orderID string) chan struct{} {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.watchers[orderID] == nil {
+		h.watchers[orderID] = map[chan struct{}]struct{}{}
+	}
+	h.watchers[orderID][ch] = struct{}{}
+	return ch
+}
+
+func (h *watchHub) unsubscribe(orderID string, ch chan struct{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.watchers[orderID], ch)
+	if len(h.watchers[orderID]) == 0 {
+		delete(h.watchers, orderID)
+	}
+}
+
+// notify wakes the watchers of an order. A watcher that has not caught up
+// with an earlier wake-up yet is skipped; it reads the latest status anyway.
+func (h *watchHub) notify(orderID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.watchers[orderID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "errors"
+
+// ErrOrdersInProgress refuses to anonymize a user whose paid orders have
+// not arrived yet; shipping them needs the user's address.
+var ErrOrdersInProgress = errors.New("user has orders in progress")
+
+// InProgressStatuses are the statuses of paid orders still on their way.
+var InProgressStatuses = []string{StatusCompleted, StatusPartiallyShipped, StatusShipped}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"errors"
+	"time"
+)
+
+// Order statuses. Payment-ms moves a PENDING order to COMPLETED or FAILED.
+const (
+	StatusPending   = "PENDING"
+	StatusCompleted = "COMPLETED"
+	StatusFailed    = "FAILED"
+	StatusCancelled = "CANCELLED"
+)
+
+// CanChangePaymentStatus reports whether an order may move from one
+// payment status to another: a PENDING order is paid or fails, a FAILED
+// one is paid or waits for payment again. A paid order stays paid.
+func CanChangePaymentStatus(from, to string) bool {
+	switch from {
+	case StatusPending:
+		return to == StatusCompleted || to == StatusFailed
+	case StatusFailed:
+		return to == StatusPending || to == StatusCompleted
+	}
+	return false
+}
+
+const RoleAdmin = "admin"
+
+// RoleSystem cancels orders on its own, e.g. when they expire unpaid.
+const RoleSystem = "system"
+
+var (
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrInvalidOrder        = errors.New("invalid order")
+	ErrCartEmpty           = errors.New("cart is empty")
+	ErrNotCancellable      = errors.New("order cannot be cancelled")
+	ErrInvalidCancelReason = errors.New("invalid cancellation reason")
+	ErrNotDeletable        = errors.New("order cannot be deleted")
+	ErrInvalidStatus       = errors.New("order status cannot be set")
+	ErrStatusChanged       = errors.New("order status changed concurrently")
+)
+
+// Cancellation reason codes
+const (
+	ReasonChangedMind      = "changed_mind"
+	ReasonOrderedByMistake = "ordered_by_mistake"
+	ReasonFoundCheaper     = "found_cheaper"
+	ReasonDeliveryTooSlow  = "delivery_too_slow"
+	ReasonCustomerRequest  = "customer_request"
+	ReasonOutOfStock       = "out_of_stock"
+	ReasonPaymentFailed    = "payment_failed"
+	ReasonFraudSuspected   = "fraud_suspected"
+	ReasonPaymentTimeout   = "payment_timeout" // set by the system only
+	ReasonAccountDeleted   = "account_deleted" // set by the system only
+	ReasonOther            = "other"
+)
+
+var customerCancelReasons = map[string]bool{
+	ReasonChangedMind:      true,
+	ReasonOrderedByMistake: true,
+	ReasonFoundCheaper:     true,
+	ReasonDeliveryTooSlow:  true,
+	ReasonOther:            true,
+}
+
+var adminCancelReasons = map[string]bool{
+	ReasonCustomerRequest: true,
+	ReasonOutOfStock:      true,
+	ReasonPaymentFailed:   true,
+	ReasonFraudSuspected:  true,
+	ReasonOther:           true,
+}
+
+// cancelPolicy lists who may cancel an order in each status. Customers may
+// cancel until the order is paid; once the money is captured only an admin
+// can cancel and refund it.
+var cancelPolicy = map[string]struct{ customer, admin bool }{
+	StatusPending:   {customer: true, admin: true},
+	StatusFailed:    {customer: true, admin: true},
+	StatusCompleted: {customer: false, admin: true},
+}
+
+// CanCancel reports whether an order in status may be cancelled by a
+// customer or, with admin set, by an admin.
+func CanCancel(status string, admin bool) bool {
+	p, ok := cancelPolicy[status]
+	if !ok {
+		return false
+	}
+	if admin {
+		return p.admin
+	}
+	return p.customer
+}
+
+// ValidCancelReason reports whether reason is a code the actor may use.
+func ValidCancelReason(reason string, admin bool) bool {
+	if admin {
+		return adminCancelReasons[reason]
+	}
+	return customerCancelReasons[reason]
+}
+
+// ActionRefunded is the payment action of a cancellation that refunded a
+// captured payment.
+const ActionRefunded = "refunded"
+
+// Cancellation records who cancelled an order and how far the reversal
+// got. PaymentAction stays empty and StockReleased false until the
+// respective step succeeded, so cancelling again finishes the job.
+type Cancellation struct {
+	Reason        string      `json:"reason" bson:"reason"`
+	Note          string      `json:"note,omitempty" bson:"note,omitempty"`
+	CancelledBy   string      `json:"cancelled_by" bson:"cancelled_by"`
+	Role          string      `json:"role" bson:"role"` // customer, admin or system
+	CancelledAt   time.Time   `json:"cancelled_at" bson:"cancelled_at"`
+	PaymentAction string      `json:"payment_action,omitempty" bson:"payment_action,omitempty"` // refunded, voided or none
+	Refunded      money.Money `json:"refunded" bson:"refunded"`
+	StockReleased bool        `json:"stock_released" bson:"stock_released"`
+}
+
+// Complete reports whether the payment was reversed and the stock put back.
+func (c *Cancellation) Complete() bool {
+	return c.PaymentAction != "" && c.StockReleased
+}
+
+// DeletableByCustomer reports whether the customer may delete the order:
+// only once nothing is left to do for it, since a deleted order is no
+// longer found by payment, return or subscription updates.
+func (o *Order) DeletableByCustomer() bool {
+	switch o.Status {
+	case StatusFailed:
+		return true
+	case StatusCancelled:
+		return o.Cancellation == nil || o.Cancellation.Complete()
+	}
+	return false
+}
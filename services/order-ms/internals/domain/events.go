@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"time"
+)
+
+// Events announcing order and shipment changes. Their subject is the order
+// ID.
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventShipmentCreated    = "shipment.created"
+	EventShipmentUpdated    = "shipment.updated"
+)
+
+// OrderCreatedEvent is the payload of order.created.
+type OrderCreatedEvent struct {
+	OrderID   string      `json:"order_id" bson:"order_id"`
+	UserID    string      `json:"user_id" bson:"user_id"`
+	Status    string      `json:"status" bson:"status"`
+	Items     []OrderItem `json:"items" bson:"items"`
+	Total     money.Money `json:"total" bson:"total"`
+	CreatedAt time.Time   `json:"created_at" bson:"created_at"`
+}
+
+// OrderStatusChangedEvent is the payload of order.status_changed.
+type OrderStatusChangedEvent struct {
+	OrderID   string    `json:"order_id" bson:"order_id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	From      string    `json:"from" bson:"from"`
+	To        string    `json:"to" bson:"to"`
+	ChangedAt time.Time `json:"changed_at" bson:"changed_at"`
+}
+
+// ShipmentEvent is the payload of shipment.created and shipment.updated.
+type ShipmentEvent struct {
+	ShipmentID     string         `json:"shipment_id" bson:"shipment_id"`
+	OrderID        string         `json:"order_id" bson:"order_id"`
+	Status         string         `json:"status" bson:"status"`
+	Carrier        string         `json:"carrier" bson:"carrier"`
+	TrackingNumber string         `json:"tracking_number,omitempty" bson:"tracking_number,omitempty"`
+	Lines          []ShipmentLine `json:"lines" bson:"lines"`
+	ShippedAt      *time.Time     `json:"shipped_at,omitempty" bson:"shipped_at,omitempty"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
+
+// NewShipmentEvent describes the current state of a shipment.
+func NewShipmentEvent(s *Shipment) ShipmentEvent {
+	return ShipmentEvent{
+		ShipmentID:     s.ID,
+		OrderID:        s.OrderID,
+		Status:         s.Status,
+		Carrier:        s.Carrier,
+		TrackingNumber: s.TrackingNumber,
+		Lines:          s.Lines,
+		ShippedAt:      s.ShippedAt,
+		DeliveredAt:    s.DeliveredAt,
+	}
+}
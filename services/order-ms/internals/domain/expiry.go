@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"time"
+)
+
+// JobExpireUnpaidOrder is the scheduler job that cancels an order nobody
+// paid for; its subject is the order ID.
+const JobExpireUnpaidOrder = "order.expire_unpaid"
+
+// EventOrderExpired announces an order cancelled because it was not paid
+// in time.
+const EventOrderExpired = "order.expired"
+
+// OrderExpiredEvent is the payload of order.expired.
+type OrderExpiredEvent struct {
+	OrderID   string      `json:"order_id" bson:"order_id"`
+	UserID    string      `json:"user_id" bson:"user_id"`
+	Status    string      `json:"status" bson:"status"` // PENDING or FAILED before it expired
+	Total     money.Money `json:"total" bson:"total"`
+	Items     []OrderItem `json:"items" bson:"items"`
+	CreatedAt time.Time   `json:"created_at" bson:"created_at"`
+	ExpiredAt time.Time   `json:"expired_at" bson:"expired_at"`
+}
+
+// Unpaid reports whether an order is still waiting for a successful
+// payment.
+func (o *Order) Unpaid() bool {
+	return o.Status == StatusPending || o.Status == StatusFailed
+}
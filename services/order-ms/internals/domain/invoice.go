@@ -0,0 +1,297 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Invoice kinds. Each legal entity numbers invoices and credit notes in
+// its own gap-free sequence.
+const (
+	KindInvoice    = "invoice"
+	KindCreditNote = "credit_note"
+)
+
+// ReferenceOrder is the reference of an order's invoice; credit notes
+// reference what they refund, e.g. "return:<id>" or ReferenceCancellation.
+const (
+	ReferenceOrder        = "order"
+	ReferenceCancellation = "cancellation"
+)
+
+var (
+	ErrInvoiceNotFound      = errors.New("invoice not found")
+	ErrNotInvoiceable       = errors.New("order cannot be invoiced yet")
+	ErrInvoiceNumberTaken   = errors.New("invoice number already taken")
+	ErrInvoiceAlreadyIssued = errors.New("invoice already issued")
+)
+
+// Invoice is an issued invoice or credit note. The rendered PDF is stored
+// with it and served as is, so a document never changes once issued.
+type Invoice struct {
+	ID            string        `json:"id" bson:"_id,omitempty"`
+	Kind          string        `json:"kind" bson:"kind"`
+	Entity        string        `json:"entity" bson:"entity"` // legal entity code
+	Sequence      int64         `json:"sequence" bson:"sequence"`
+	Number        string        `json:"number" bson:"number"` // formatted, e.g. INV-US-000042
+	OrderID       string        `json:"order_id" bson:"order_id"`
+	Reference     string        `json:"reference" bson:"reference"`
+	InvoiceNumber string        `json:"invoice_number,omitempty" bson:"invoice_number,omitempty"` // invoice a credit note corrects
+	IssuedAt      time.Time     `json:"issued_at" bson:"issued_at"`
+	Currency      string        `json:"currency" bson:"currency"`
+	BillTo        *Address      `json:"bill_to,omitempty" bson:"bill_to,omitempty"`
+	Lines         []InvoiceLine `json:"lines" bson:"lines"`
+	Subtotal      money.Money   `json:"subtotal" bson:"subtotal"`
+	TaxTotal      money.Money   `json:"tax_total" bson:"tax_total"`
+	Shipping      money.Money   `json:"shipping" bson:"shipping"`
+	Total         money.Money   `json:"total" bson:"total"`
+	TaxSummary    []TaxSummary  `json:"tax_summary" bson:"tax_summary"`
+	PDF           []byte        `json:"-" bson:"pdf,omitempty"`
+	PDFSHA256     string        `json:"pdf_sha256" bson:"pdf_sha256"`
+}
+
+// InvoiceLine is a priced line of an invoice or credit note.
+type InvoiceLine struct {
+	ProductID   string      `json:"product_id" bson:"product_id"`
+	Description string      `json:"description" bson:"description"`
+	Quantity    int         `json:"quantity" bson:"quantity"`
+	UnitPrice   money.Money `json:"unit_price" bson:"unit_price"`
+	TaxRate     float64     `json:"tax_rate" bson:"tax_rate"`
+	Net         money.Money `json:"net" bson:"net"`
+	Tax         money.Money `json:"tax" bson:"tax"`
+	Gross       money.Money `json:"gross" bson:"gross"`
+}
+
+// TaxSummary totals an invoice per tax rate.
+type TaxSummary struct {
+	Rate float64     `json:"rate" bson:"rate"`
+	Net  money.Money `json:"net" bson:"net"`
+	Tax  money.Money `json:"tax" bson:"tax"`
+}
+
+// LegalEntity is a company that issues invoices for orders taxed in its
+// regions.
+type LegalEntity struct {
+	Code             string   `json:"code"`
+	Name             string   `json:"name"`
+	Address          []string `json:"address"`
+	TaxID            string   `json:"tax_id"`
+	Regions          []string `json:"regions"` // e.g. "DE" or "US-CA"; "*" matches any
+	InvoicePrefix    string   `json:"invoice_prefix"`
+	CreditNotePrefix string   `json:"credit_note_prefix"`
+}
+
+// LegalEntities picks the entity that invoices a tax region.
+type LegalEntities struct {
+	Entities []LegalEntity `json:"entities"`
+}
+
+// LoadLegalEntities reads a JSON file of the form {"entities": [...]}.
+// Without a path no entity is configured and orders cannot be invoiced.
+func LoadLegalEntities(path string) (*LegalEntities, error) {
+	if path == "" {
+		return &LegalEntities{}, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read legal entities %s: %w", path, err)
+	}
+
+	var le LegalEntities
+	if err := json.Unmarshal(raw, &le); err != nil {
+		return nil, fmt.Errorf("failed to parse legal entities %s: %w", path, err)
+	}
+	seen := map[string]bool{}
+	for _, e := range le.Entities {
+		if e.Code == "" || seen[e.Code] {
+			return nil, fmt.Errorf("legal entities %s: missing or duplicate code %q", path, e.Code)
+		}
+		seen[e.Code] = true
+	}
+	return &le, nil
+}
+
+// For returns the entity for region, preferring the most specific match:
+// "US-CA" before "US" before "*".
+func (le *LegalEntities) For(region string) (*LegalEntity, error) {
+	region = strings.ToUpper(region)
+	candidates := []string{region}
+	if i := strings.Index(region, "-"); i > 0 {
+		candidates = append(candidates, region[:i])
+	}
+	candidates = append(candidates, "*")
+
+	for _, c := range candidates {
+		for i := range le.Entities {
+			for _, r := range le.Entities[i].Regions {
+				if strings.EqualFold(r, c) {
+					return &le.Entities[i], nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("no legal entity invoices region %q", region)
+}
+
+// Format returns the printed number of the sequence-th document of kind.
+func (e *LegalEntity) Format(kind string, sequence int64) string {
+	prefix := e.InvoicePrefix
+	if kind == KindCreditNote {
+		prefix = e.CreditNotePrefix
+	}
+	return fmt.Sprintf("%s%06d", prefix, sequence)
+}
+
+// Invoiceable reports whether an order was paid, so it can be invoiced. A
+// cancelled order that was refunded was paid too and gets an invoice with
+// a credit note.
+func (o *Order) Invoiceable() bool {
+	if fulfillmentStatus(o.Status) {
+		return true
+	}
+	return o.Status == StatusCancelled && o.Cancellation != nil && o.Cancellation.PaymentAction == ActionRefunded
+}
+
+// Credited returns, per product, the units of a return that were received
+// and refunded.
+func (r *Return) Credited() map[string]int {
+	qty := map[string]int{}
+	for _, l := range r.Lines {
+		if l.Received > 0 {
+			qty[l.ProductID] += l.Received
+		}
+	}
+	return qty
+}
+
+// NewInvoice prices every line of the order, shipping included. Lines are
+// described by the product name recorded on the order, else by names,
+// which maps product IDs to current names, else by the product ID.
+func (o *Order) NewInvoice(names map[string]string) (*Invoice, error) {
+	if !o.Invoiceable() {
+		return nil, fmt.Errorf("%w: order is %s", ErrNotInvoiceable, strings.ToLower(o.Status))
+	}
+
+	inv := &Invoice{
+		Kind:      KindInvoice,
+		OrderID:   o.ID,
+		Reference: ReferenceOrder,
+		Currency:  o.Currency,
+		BillTo:    o.ShippingAddress,
+		Shipping:  o.ShippingCost,
+	}
+	for _, item := range o.Items {
+		inv.Lines = append(inv.Lines, invoiceLine(item, item.Quantity, names))
+	}
+	return inv, inv.total()
+}
+
+// NewCreditNote credits qty units per product of the order at what was paid
+// for them, plus shipping if given. An empty qty credits the whole order.
+func (o *Order) NewCreditNote(reference string, qty map[string]int, shipping money.Money, names map[string]string) (*Invoice, error) {
+	cn := &Invoice{
+		Kind:      KindCreditNote,
+		OrderID:   o.ID,
+		Reference: reference,
+		Currency:  o.Currency,
+		BillTo:    o.ShippingAddress,
+		Shipping:  shipping,
+	}
+
+	left := map[string]int{}
+	for id, q := range qty {
+		left[id] = q
+	}
+	for _, item := range o.Items {
+		n := item.Quantity
+		if qty != nil {
+			n = min(left[item.ProductID], item.Quantity)
+			left[item.ProductID] -= n
+		}
+		if n > 0 {
+			cn.Lines = append(cn.Lines, invoiceLine(item, n, names))
+		}
+	}
+	if len(cn.Lines) == 0 && !shipping.IsPositive() {
+		return nil, fmt.Errorf("credit note %s credits nothing", reference)
+	}
+	return cn, cn.total()
+}
+
+// invoiceLine prices qty units of an order line in proportion to what the
+// whole line cost, so crediting all units gives back exactly the line.
+func invoiceLine(item OrderItem, qty int, names map[string]string) InvoiceLine {
+	part := func(m money.Money) money.Money {
+		if item.Quantity == 0 {
+			return money.Zero(m.Currency)
+		}
+		return money.New(m.Amount*int64(qty)/int64(item.Quantity), m.Currency)
+	}
+
+	gross := part(item.LineTotal)
+	tax := part(item.Tax)
+	net, _ := gross.Sub(tax)
+
+	desc := item.Name
+	if desc == "" {
+		desc = names[item.ProductID]
+	}
+	if desc == "" {
+		desc = item.ProductID
+	}
+	if item.SKU != "" {
+		desc += " (" + item.SKU + ")"
+	}
+	return InvoiceLine{
+		ProductID:   item.ProductID,
+		Description: desc,
+		Quantity:    qty,
+		UnitPrice:   item.Price,
+		TaxRate:     item.TaxRate,
+		Net:         net,
+		Tax:         tax,
+		Gross:       gross,
+	}
+}
+
+func (inv *Invoice) total() error {
+	inv.Subtotal = money.Zero(inv.Currency)
+	inv.TaxTotal = money.Zero(inv.Currency)
+	inv.TaxSummary = nil
+	if inv.Shipping.Currency == "" {
+		inv.Shipping = money.Zero(inv.Currency)
+	}
+
+	byRate := map[float64]int{}
+	for _, l := range inv.Lines {
+		var err error
+		if inv.Subtotal, err = inv.Subtotal.Add(l.Net); err != nil {
+			return err
+		}
+		if inv.TaxTotal, err = inv.TaxTotal.Add(l.Tax); err != nil {
+			return err
+		}
+
+		i, ok := byRate[l.TaxRate]
+		if !ok {
+			i = len(inv.TaxSummary)
+			byRate[l.TaxRate] = i
+			inv.TaxSummary = append(inv.TaxSummary, TaxSummary{Rate: l.TaxRate, Net: money.Zero(inv.Currency), Tax: money.Zero(inv.Currency)})
+		}
+		s := &inv.TaxSummary[i]
+		s.Net, _ = s.Net.Add(l.Net)
+		s.Tax, _ = s.Tax.Add(l.Tax)
+	}
+
+	total, err := money.Sum(inv.Subtotal, inv.TaxTotal, inv.Shipping)
+	if err != nil {
+		return err
+	}
+	inv.Total = total
+	return nil
+}
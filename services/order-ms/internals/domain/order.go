@@ -1,17 +1,49 @@
 package domain
 
+import (
+	"ecom-api/pkg/money"
+	"time"
+)
+
 //import "go.mongodb.org/mongo-driver/bson/primitive"
 
 type Order struct {
 	ID         string   `json:"id" bson:"_id,omitempty"`
 	UserID     string      `json:"user_id" bson:"user_id"`
 	Items      []OrderItem `json:"items" bson:"items"`
-	Total float64     `json:"total" bson:"total"`
-	Status     string      `json:"status" bson:"status"` // PENDING, CONFIRMED, CANCELLED
+	Subtotal   money.Money `json:"subtotal" bson:"subtotal"`   // sum of line amounts excluding tax
+	TaxTotal   money.Money `json:"tax_total" bson:"tax_total"`
+	Total money.Money `json:"total" bson:"total"`
+	Currency   string      `json:"currency" bson:"currency"` // locked in when the order is placed
+	TaxRegion        string `json:"tax_region" bson:"tax_region"`
+	PricesIncludeTax bool   `json:"prices_include_tax" bson:"prices_include_tax"`
+	ShippingAddress  *Address `json:"shipping_address,omitempty" bson:"shipping_address,omitempty"`
+	ShippingMethod   string   `json:"shipping_method,omitempty" bson:"shipping_method,omitempty"`
+	ShippingCost     money.Money `json:"shipping_cost" bson:"shipping_cost"`
+	Status     string      `json:"status" bson:"status"` // PENDING, COMPLETED, FAILED, CANCELLED, PARTIALLY_SHIPPED, SHIPPED, DELIVERED
+	StockCommitted bool        `json:"stock_committed" bson:"stock_committed"` // the cart's reserved stock was taken for this order
+	Cancellation   *Cancellation `json:"cancellation,omitempty" bson:"cancellation,omitempty"`
+	FulfillmentRev int64         `json:"-" bson:"fulfillment_rev"` // bumped on every shipment and return change
+	StatusHistory  []StatusChange `json:"status_history,omitempty" bson:"status_history,omitempty"`
+	SubscriptionID    string `json:"subscription_id,omitempty" bson:"subscription_id,omitempty"` // set on orders a subscription placed
+	SubscriptionCycle int    `json:"subscription_cycle,omitempty" bson:"subscription_cycle,omitempty"`
+	CreatedAt      time.Time     `json:"created_at" bson:"created_at"`
+	DeletedAt      *time.Time    `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy      string        `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 }
 
 type OrderItem struct {
 	ProductID string  `json:"product_id" bson:"product_id"`
+	Name      string  `json:"name,omitempty" bson:"name,omitempty"`           // product details as of the order
+	SKU       string  `json:"sku,omitempty" bson:"sku,omitempty"`
+	ImageURL  string  `json:"image_url,omitempty" bson:"image_url,omitempty"`
 	Quantity  int     `json:"quantity" bson:"quantity"`
-	Price     float64 `json:"price" bson:"price"` // price per item
+	Weight    float64 `json:"weight,omitempty" bson:"weight,omitempty"` // shipping weight per item in kg
+	Price     money.Money `json:"price" bson:"price"` // price per item
+	TaxCategory string  `json:"tax_category" bson:"tax_category"`
+	TaxRate     float64 `json:"tax_rate" bson:"tax_rate"`
+	Tax         money.Money `json:"tax" bson:"tax"`               // tax amount of the line
+	LineTotal   money.Money `json:"line_total" bson:"line_total"` // line amount including tax
+	PriceSource  string  `json:"price_source,omitempty" bson:"price_source,omitempty"` // base, list or converted
+	ExchangeRate float64 `json:"exchange_rate,omitempty" bson:"exchange_rate,omitempty"` // rate locked in for a converted price
 }
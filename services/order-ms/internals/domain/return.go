@@ -0,0 +1,309 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Return (RMA) statuses. A requested return is approved or rejected by an
+// admin; once the warehouse received the goods the refund and restock run,
+// and the return is completed when both went through.
+const (
+	ReturnRequested = "REQUESTED"
+	ReturnApproved  = "APPROVED"
+	ReturnRejected  = "REJECTED"
+	ReturnReceived  = "RECEIVED"
+	ReturnCompleted = "COMPLETED"
+)
+
+const RoleWarehouse = "warehouse"
+
+var (
+	ErrReturnNotFound    = errors.New("return not found")
+	ErrInvalidReturn     = errors.New("invalid return")
+	ErrReturnNotAllowed  = errors.New("return not allowed")
+	ErrReturnStateChange = errors.New("invalid return status change")
+)
+
+// Return reason codes
+const (
+	ReturnReasonDamaged        = "damaged"
+	ReturnReasonDefective      = "defective"
+	ReturnReasonWrongItem      = "wrong_item"
+	ReturnReasonNotAsDescribed = "not_as_described"
+	ReturnReasonNoLongerNeeded = "no_longer_needed"
+	ReturnReasonOther          = "other"
+)
+
+var returnReasons = map[string]bool{
+	ReturnReasonDamaged:        true,
+	ReturnReasonDefective:      true,
+	ReturnReasonWrongItem:      true,
+	ReturnReasonNotAsDescribed: true,
+	ReturnReasonNoLongerNeeded: true,
+	ReturnReasonOther:          true,
+}
+
+// Condition of returned goods, recorded on receipt. New and opened goods
+// go back on the shelf, damaged ones do not.
+const (
+	ConditionNew     = "new"
+	ConditionOpened  = "opened"
+	ConditionDamaged = "damaged"
+)
+
+var resellable = map[string]bool{
+	ConditionNew:    true,
+	ConditionOpened: true,
+}
+
+// Return is a customer's request to send back delivered order lines.
+type Return struct {
+	ID            string         `json:"id" bson:"_id,omitempty"`
+	OrderID       string         `json:"order_id" bson:"order_id"`
+	UserID        string         `json:"user_id" bson:"user_id"`
+	Lines         []ReturnLine   `json:"lines" bson:"lines"`
+	Status        string         `json:"status" bson:"status"`
+	RequestedAt   time.Time      `json:"requested_at" bson:"requested_at"`
+	Review        *ReturnReview  `json:"review,omitempty" bson:"review,omitempty"`
+	Receipt       *ReturnReceipt `json:"receipt,omitempty" bson:"receipt,omitempty"`
+	Refund        money.Money    `json:"refund" bson:"refund"` // owed for the received goods
+	Refunded      bool           `json:"refunded" bson:"refunded"`
+	StockReturned bool           `json:"stock_returned" bson:"stock_returned"`
+	CompletedAt   *time.Time     `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+	// RefundToStoreCredit pays the refund into the customer's wallet
+	// instead of back to how the order was paid.
+	RefundToStoreCredit bool `json:"refund_to_store_credit" bson:"refund_to_store_credit"`
+}
+
+// ReturnLine is a quantity of an order line the customer sends back.
+// Received and Condition are filled in by the warehouse.
+type ReturnLine struct {
+	ProductID string `json:"product_id" bson:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" bson:"quantity" validate:"gt=0"`
+	Reason    string `json:"reason" bson:"reason" validate:"required"`
+	Note      string `json:"note,omitempty" bson:"note,omitempty"`
+	Received  int    `json:"received" bson:"received"`
+	Condition string `json:"condition,omitempty" bson:"condition,omitempty"`
+}
+
+// ReturnReview is an admin's decision on a return.
+type ReturnReview struct {
+	Approved   bool      `json:"approved" bson:"approved"`
+	Note       string    `json:"note,omitempty" bson:"note,omitempty"`
+	ReviewedBy string    `json:"reviewed_by" bson:"reviewed_by"`
+	ReviewedAt time.Time `json:"reviewed_at" bson:"reviewed_at"`
+}
+
+// ReturnReceipt records who took the goods in at the warehouse.
+type ReturnReceipt struct {
+	ReceivedBy string    `json:"received_by" bson:"received_by"`
+	ReceivedAt time.Time `json:"received_at" bson:"received_at"`
+}
+
+// ReceivedLine is the warehouse's count and inspection of a returned
+// product.
+type ReceivedLine struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"gte=0"`
+	Condition string `json:"condition" validate:"required,oneof=new opened damaged"`
+}
+
+// Returnable returns, per product, the delivered quantity of the order that
+// is not already part of a return still in progress or completed.
+func (o *Order) Returnable(shipments []*Shipment, returns []*Return) map[string]int {
+	left := map[string]int{}
+	for _, s := range shipments {
+		if s.Status != ShipmentDelivered {
+			continue
+		}
+		for _, l := range s.Lines {
+			left[l.ProductID] += l.Quantity
+		}
+	}
+	for _, r := range returns {
+		if r.Status == ReturnRejected {
+			continue
+		}
+		for _, l := range r.Lines {
+			left[l.ProductID] -= l.Quantity
+		}
+	}
+	return left
+}
+
+// NewReturn validates the lines a customer wants to send back against what
+// was delivered and not returned yet.
+func (o *Order) NewReturn(shipments []*Shipment, returns []*Return, lines []ReturnLine) (*Return, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: a return needs at least one line", ErrInvalidReturn)
+	}
+
+	left := o.Returnable(shipments, returns)
+	seen := map[string]bool{}
+	out := make([]ReturnLine, 0, len(lines))
+	for _, l := range lines {
+		if l.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity of %s must be positive", ErrInvalidReturn, l.ProductID)
+		}
+		if !returnReasons[l.Reason] {
+			return nil, fmt.Errorf("%w: unknown reason %q", ErrInvalidReturn, l.Reason)
+		}
+		if seen[l.ProductID] {
+			return nil, fmt.Errorf("%w: product %s is listed twice", ErrInvalidReturn, l.ProductID)
+		}
+		seen[l.ProductID] = true
+		if l.Quantity > left[l.ProductID] {
+			return nil, fmt.Errorf("%w: only %d of product %s can be returned", ErrReturnNotAllowed, max(left[l.ProductID], 0), l.ProductID)
+		}
+		out = append(out, ReturnLine{
+			ProductID: l.ProductID,
+			Quantity:  l.Quantity,
+			Reason:    l.Reason,
+			Note:      strings.TrimSpace(l.Note),
+		})
+	}
+
+	return &Return{
+		OrderID:     o.ID,
+		UserID:      o.UserID,
+		Lines:       out,
+		Status:      ReturnRequested,
+		RequestedAt: time.Now(),
+	}, nil
+}
+
+// Decide approves or rejects a requested return.
+func (r *Return) Decide(approved bool, note, reviewer string) error {
+	if r.Status != ReturnRequested {
+		return fmt.Errorf("%w: return is %s", ErrReturnStateChange, strings.ToLower(r.Status))
+	}
+
+	r.Status = ReturnRejected
+	if approved {
+		r.Status = ReturnApproved
+	}
+	r.Review = &ReturnReview{
+		Approved:   approved,
+		Note:       strings.TrimSpace(note),
+		ReviewedBy: reviewer,
+		ReviewedAt: time.Now(),
+	}
+	return nil
+}
+
+// Receive records what arrived at the warehouse and prices the refund from
+// the order lines, tax included. Shipping is not refunded. The other
+// returns of the order are needed so the units of a product refunded over
+// several returns add up to exactly what was paid for them.
+func (r *Return) Receive(o *Order, others []*Return, received []ReceivedLine, receiver string) error {
+	if r.Status != ReturnApproved {
+		return fmt.Errorf("%w: return is %s", ErrReturnStateChange, strings.ToLower(r.Status))
+	}
+
+	returned := map[string]bool{}
+	for _, l := range r.Lines {
+		returned[l.ProductID] = true
+	}
+	counted := map[string]ReceivedLine{}
+	for _, l := range received {
+		if !returned[l.ProductID] {
+			return fmt.Errorf("%w: product %s is not part of the return", ErrInvalidReturn, l.ProductID)
+		}
+		if _, dup := counted[l.ProductID]; dup {
+			return fmt.Errorf("%w: product %s received twice", ErrInvalidReturn, l.ProductID)
+		}
+		counted[l.ProductID] = l
+	}
+
+	earlier := map[string]int{}
+	for _, other := range others {
+		if other.ID == r.ID {
+			continue
+		}
+		for _, l := range other.Lines {
+			earlier[l.ProductID] += l.Received
+		}
+	}
+
+	refund := money.Zero(o.Currency)
+	for i := range r.Lines {
+		line := &r.Lines[i]
+		c, ok := counted[line.ProductID]
+		if !ok {
+			return fmt.Errorf("%w: no receipt for product %s", ErrInvalidReturn, line.ProductID)
+		}
+		if c.Quantity > line.Quantity {
+			return fmt.Errorf("%w: %d of product %s received but %d returned", ErrInvalidReturn, c.Quantity, line.ProductID, line.Quantity)
+		}
+
+		line.Received = c.Quantity
+		line.Condition = c.Condition
+		amount, err := o.refundFor(line.ProductID, earlier[line.ProductID], c.Quantity)
+		if err != nil {
+			return err
+		}
+		if refund, err = refund.Add(amount); err != nil {
+			return err
+		}
+	}
+
+	r.Status = ReturnReceived
+	r.Refund = refund
+	r.Receipt = &ReturnReceipt{ReceivedBy: receiver, ReceivedAt: time.Now()}
+	return nil
+}
+
+// refundFor prices qty units of a product, after the units refunded
+// earlier, at what the customer paid for them, tax included. Each refund
+// is the share of the paid amount up to its last unit less the share
+// refunded before, so the last units returned get whatever is left.
+func (o *Order) refundFor(productID string, earlier, qty int) (money.Money, error) {
+	paid := money.Zero(o.Currency)
+	units := 0
+	for _, item := range o.Items {
+		if item.ProductID != productID {
+			continue
+		}
+		sum, err := paid.Add(item.LineTotal)
+		if err != nil {
+			return money.Money{}, err
+		}
+		paid = sum
+		units += item.Quantity
+	}
+	if units == 0 {
+		return money.Money{}, fmt.Errorf("%w: product %s is not part of the order", ErrInvalidReturn, productID)
+	}
+	if earlier+qty > units {
+		return money.Money{}, fmt.Errorf("%w: %d of product %s returned but %d ordered", ErrInvalidReturn, earlier+qty, productID, units)
+	}
+	before := paid.Share(int64(earlier), int64(units))
+	return paid.Share(int64(earlier+qty), int64(units)).Sub(before)
+}
+
+// Restock returns the received lines that can be sold again.
+func (r *Return) Restock() []ReturnLine {
+	var out []ReturnLine
+	for _, l := range r.Lines {
+		if l.Received > 0 && resellable[l.Condition] {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// Complete marks a received return done once the refund and restock went
+// through.
+func (r *Return) Complete() bool {
+	if r.Status != ReturnReceived || !r.Refunded || !r.StockReturned {
+		return false
+	}
+	now := time.Now()
+	r.Status = ReturnCompleted
+	r.CompletedAt = &now
+	return true
+}
@@ -0,0 +1,276 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Order statuses derived from shipment progress once an order is paid.
+const (
+	StatusPartiallyShipped = "PARTIALLY_SHIPPED"
+	StatusShipped          = "SHIPPED"
+	StatusDelivered        = "DELIVERED"
+)
+
+// Shipment statuses
+const (
+	ShipmentLabelCreated   = "LABEL_CREATED"
+	ShipmentInTransit      = "IN_TRANSIT"
+	ShipmentOutForDelivery = "OUT_FOR_DELIVERY"
+	ShipmentDelivered      = "DELIVERED"
+	ShipmentCancelled      = "CANCELLED" // label voided before pickup
+)
+
+var (
+	ErrShipmentNotFound   = errors.New("shipment not found")
+	ErrInvalidShipment    = errors.New("invalid shipment")
+	ErrNotFulfillable     = errors.New("order cannot be shipped")
+	ErrInvalidTransition  = errors.New("invalid shipment status change")
+	ErrFulfillmentChanged = errors.New("order fulfillment changed concurrently")
+)
+
+// shipmentTransitions lists the statuses a shipment may move to. A
+// shipment in transit can be scanned again, and a failed delivery attempt
+// sends it back in transit.
+var shipmentTransitions = map[string][]string{
+	ShipmentLabelCreated:   {ShipmentInTransit, ShipmentDelivered, ShipmentCancelled},
+	ShipmentInTransit:      {ShipmentInTransit, ShipmentOutForDelivery, ShipmentDelivered},
+	ShipmentOutForDelivery: {ShipmentInTransit, ShipmentDelivered},
+}
+
+// Shipment is a parcel carrying some or all of an order's lines. An order
+// can be split across several shipments and a line across several parcels.
+type Shipment struct {
+	ID             string          `json:"id" bson:"_id,omitempty"`
+	OrderID        string          `json:"order_id" bson:"order_id"`
+	Lines          []ShipmentLine  `json:"lines" bson:"lines"`
+	Carrier        string          `json:"carrier" bson:"carrier"`
+	TrackingNumber string          `json:"tracking_number,omitempty" bson:"tracking_number,omitempty"`
+	Status         string          `json:"status" bson:"status"`
+	Events         []TrackingEvent `json:"events" bson:"events"`
+	CreatedBy      string          `json:"created_by" bson:"created_by"`
+	CreatedAt      time.Time       `json:"created_at" bson:"created_at"`
+	ShippedAt      *time.Time      `json:"shipped_at,omitempty" bson:"shipped_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
+
+// ShipmentLine is the quantity of an order line packed in a shipment.
+type ShipmentLine struct {
+	ProductID string `json:"product_id" bson:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" bson:"quantity" validate:"gt=0"`
+}
+
+// TrackingEvent is a status change reported for a shipment.
+type TrackingEvent struct {
+	Status   string    `json:"status" bson:"status"`
+	Location string    `json:"location,omitempty" bson:"location,omitempty"`
+	Note     string    `json:"note,omitempty" bson:"note,omitempty"`
+	At       time.Time `json:"at" bson:"at"`
+}
+
+// ShipmentUpdate is a tracking update for a shipment. TrackingNumber
+// fills in a number that was not known when the label was created.
+type ShipmentUpdate struct {
+	Status         string    `json:"status" validate:"required,oneof=IN_TRANSIT OUT_FOR_DELIVERY DELIVERED CANCELLED"`
+	Location       string    `json:"location,omitempty"`
+	Note           string    `json:"note,omitempty"`
+	TrackingNumber string    `json:"tracking_number,omitempty"`
+	At             time.Time `json:"at,omitempty"` // when the carrier reported it; defaults to now
+}
+
+// Shipped reports whether the shipment left the warehouse.
+func (s *Shipment) Shipped() bool {
+	switch s.Status {
+	case ShipmentInTransit, ShipmentOutForDelivery, ShipmentDelivered:
+		return true
+	}
+	return false
+}
+
+// Apply moves the shipment to the update's status and records the
+// tracking event.
+func (s *Shipment) Apply(u ShipmentUpdate) error {
+	if !canTransition(s.Status, u.Status) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, s.Status, u.Status)
+	}
+	if u.At.IsZero() {
+		u.At = time.Now()
+	}
+
+	s.Status = u.Status
+	if u.TrackingNumber != "" {
+		s.TrackingNumber = strings.TrimSpace(u.TrackingNumber)
+	}
+	if s.Shipped() && s.ShippedAt == nil {
+		at := u.At
+		s.ShippedAt = &at
+	}
+	if s.Status == ShipmentDelivered {
+		at := u.At
+		s.DeliveredAt = &at
+	}
+	s.Events = append(s.Events, TrackingEvent{
+		Status:   u.Status,
+		Location: strings.TrimSpace(u.Location),
+		Note:     strings.TrimSpace(u.Note),
+		At:       u.At,
+	})
+	return nil
+}
+
+func canTransition(from, to string) bool {
+	for _, s := range shipmentTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Fulfillable reports whether shipments can be created for an order in
+// status: it has to be paid and not fully shipped yet.
+func Fulfillable(status string) bool {
+	return status == StatusCompleted || status == StatusPartiallyShipped
+}
+
+// fulfillmentStatus reports whether status is one derived from shipments,
+// including COMPLETED for a paid order nothing has shipped for yet.
+func fulfillmentStatus(status string) bool {
+	switch status {
+	case StatusCompleted, StatusPartiallyShipped, StatusShipped, StatusDelivered:
+		return true
+	}
+	return false
+}
+
+// Paid reports whether an order was paid for, whether or not anything
+// has shipped since.
+func (o *Order) Paid() bool {
+	return fulfillmentStatus(o.Status)
+}
+
+// Unshipped returns, per product, the quantity of the order not yet
+// packed in a shipment that is still live.
+func (o *Order) Unshipped(shipments []*Shipment) map[string]int {
+	open := make(map[string]int, len(o.Items))
+	for _, item := range o.Items {
+		open[item.ProductID] += item.Quantity
+	}
+	for _, s := range shipments {
+		if s.Status == ShipmentCancelled {
+			continue
+		}
+		for _, l := range s.Lines {
+			open[l.ProductID] -= l.Quantity
+		}
+	}
+	return open
+}
+
+// NewShipment validates lines against what is left to ship of the order
+// and returns a shipment with its label created.
+func (o *Order) NewShipment(existing []*Shipment, lines []ShipmentLine, carrier, trackingNumber, createdBy string) (*Shipment, error) {
+	if !Fulfillable(o.Status) {
+		return nil, fmt.Errorf("%w: order is %s", ErrNotFulfillable, strings.ToLower(o.Status))
+	}
+	carrier = strings.TrimSpace(carrier)
+	if carrier == "" {
+		return nil, fmt.Errorf("%w: carrier is required", ErrInvalidShipment)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: a shipment needs at least one line", ErrInvalidShipment)
+	}
+
+	// merge repeated products so each appears once
+	qty := map[string]int{}
+	var ids []string
+	for _, l := range lines {
+		if l.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity of %s must be positive", ErrInvalidShipment, l.ProductID)
+		}
+		if _, seen := qty[l.ProductID]; !seen {
+			ids = append(ids, l.ProductID)
+		}
+		qty[l.ProductID] += l.Quantity
+	}
+
+	open := o.Unshipped(existing)
+	merged := make([]ShipmentLine, 0, len(ids))
+	for _, id := range ids {
+		left, ok := open[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: product %s is not part of the order", ErrInvalidShipment, id)
+		}
+		if qty[id] > left {
+			return nil, fmt.Errorf("%w: only %d of product %s left to ship", ErrInvalidShipment, left, id)
+		}
+		merged = append(merged, ShipmentLine{ProductID: id, Quantity: qty[id]})
+	}
+
+	now := time.Now()
+	return &Shipment{
+		OrderID:        o.ID,
+		Lines:          merged,
+		Carrier:        carrier,
+		TrackingNumber: strings.TrimSpace(trackingNumber),
+		Status:         ShipmentLabelCreated,
+		Events:         []TrackingEvent{{Status: ShipmentLabelCreated, At: now}},
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+	}, nil
+}
+
+// FulfillmentStatus derives the order status from its shipments: DELIVERED
+// once every unit was delivered, SHIPPED once every unit left the
+// warehouse, PARTIALLY_SHIPPED once any did and COMPLETED before that.
+// Orders that are not paid keep their status.
+func (o *Order) FulfillmentStatus(shipments []*Shipment) string {
+	if !fulfillmentStatus(o.Status) {
+		return o.Status
+	}
+
+	shipped := map[string]int{}
+	delivered := map[string]int{}
+	for _, s := range shipments {
+		if !s.Shipped() {
+			continue
+		}
+		for _, l := range s.Lines {
+			shipped[l.ProductID] += l.Quantity
+			if s.Status == ShipmentDelivered {
+				delivered[l.ProductID] += l.Quantity
+			}
+		}
+	}
+
+	ordered := map[string]int{}
+	for _, item := range o.Items {
+		ordered[item.ProductID] += item.Quantity
+	}
+
+	allShipped, allDelivered, anyShipped := true, true, false
+	for id, qty := range ordered {
+		if shipped[id] > 0 {
+			anyShipped = true
+		}
+		if shipped[id] < qty {
+			allShipped = false
+		}
+		if delivered[id] < qty {
+			allDelivered = false
+		}
+	}
+
+	switch {
+	case allDelivered && anyShipped:
+		return StatusDelivered
+	case allShipped && anyShipped:
+		return StatusShipped
+	case anyShipped:
+		return StatusPartiallyShipped
+	default:
+		return StatusCompleted
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "strings"
+
+// Address is the destination an order ships to.
+type Address struct {
+	Name       string `json:"name" bson:"name" validate:"required"`
+	Line1      string `json:"line1" bson:"line1" validate:"required"`
+	Line2      string `json:"line2,omitempty" bson:"line2,omitempty"`
+	City       string `json:"city" bson:"city" validate:"required"`
+	State      string `json:"state,omitempty" bson:"state,omitempty"`
+	PostalCode string `json:"postal_code" bson:"postal_code" validate:"required"`
+	Country    string `json:"country" bson:"country" validate:"required,len=2"` // ISO 3166-1 alpha-2
+	Phone      string `json:"phone,omitempty" bson:"phone,omitempty"`
+}
+
+// Region is the tax region of the address, e.g. "US-CA" or "DE".
+func (a Address) Region() string {
+	country := strings.ToUpper(strings.TrimSpace(a.Country))
+	state := strings.ToUpper(strings.TrimSpace(a.State))
+	if country == "" || state == "" {
+		return country
+	}
+	return country + "-" + state
+}
+
+// Checkout carries the choices the customer makes when placing an order.
+type Checkout struct {
+	ShippingAddress Address `json:"shipping_address" validate:"required"`
+	ShippingMethod  string  `json:"shipping_method" validate:"required"`
+}
+
+// Weight is the shipping weight of the order's lines in kg, from the
+// weights snapshotted when the order was placed.
+func (o *Order) Weight() float64 {
+	var total float64
+	for _, it := range o.Items {
+		total += it.Weight * float64(it.Quantity)
+	}
+	return total
+}
@@ -0,0 +1,469 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Subscription statuses. An active subscription places an order every
+// interval. When its payment fails it is past due while the payment is
+// retried, and it is cancelled once the retries ran out.
+const (
+	SubscriptionActive    = "ACTIVE"
+	SubscriptionPaused    = "PAUSED"
+	SubscriptionPastDue   = "PAST_DUE"
+	SubscriptionCancelled = "CANCELLED"
+)
+
+// Outcomes of a billing cycle
+const (
+	CycleOpen      = "OPEN"      // its order waits for payment
+	CyclePaid      = "PAID"      // its order was paid
+	CycleSkipped   = "SKIPPED"   // the customer skipped it, no order was placed
+	CycleFailed    = "FAILED"    // every payment attempt failed
+	CycleCancelled = "CANCELLED" // its order was cancelled before it was paid
+)
+
+// Interval units
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// JobBillSubscription is the scheduler job that places a subscription's
+// next order or retries its payment; its subject is the subscription ID.
+const JobBillSubscription = "subscription.bill"
+
+// Events announcing subscription billing. Their subject is the
+// subscription ID.
+const (
+	EventSubscriptionRenewed       = "subscription.renewed"
+	EventSubscriptionPaymentFailed = "subscription.payment_failed"
+	EventSubscriptionCancelled     = "subscription.cancelled"
+)
+
+// Subscription cancellation reasons
+const (
+	SubscriptionCancelledByCustomer = "customer_request"
+	SubscriptionCancelledUnpaid     = "payment_failed"  // set by the system only
+	SubscriptionCancelledAccount    = "account_deleted" // set by the system only
+)
+
+var (
+	ErrSubscriptionNotFound    = errors.New("subscription not found")
+	ErrInvalidSubscription     = errors.New("invalid subscription")
+	ErrSubscriptionStateChange = errors.New("invalid subscription status change")
+)
+
+// DefaultRetrySchedule is how long to wait before each retry of a failed
+// subscription payment.
+var DefaultRetrySchedule = []time.Duration{24 * time.Hour, 72 * time.Hour, 120 * time.Hour}
+
+// Interval is how often a subscription places an order, e.g. every 2
+// weeks.
+type Interval struct {
+	Unit  string `json:"unit" bson:"unit" validate:"required,oneof=day week month" example:"month"`
+	Count int    `json:"count" bson:"count" validate:"gt=0" example:"1"`
+}
+
+// Validate checks that the interval is a known unit and at most a year.
+func (i Interval) Validate() error {
+	max := map[string]int{IntervalDay: 365, IntervalWeek: 52, IntervalMonth: 12}[i.Unit]
+	if max == 0 {
+		return fmt.Errorf("%w: unknown interval unit %q", ErrInvalidSubscription, i.Unit)
+	}
+	if i.Count < 1 || i.Count > max {
+		return fmt.Errorf("%w: every %d %ss is not between one %s and a year", ErrInvalidSubscription, i.Count, i.Unit, i.Unit)
+	}
+	return nil
+}
+
+// Next returns the time one interval after t.
+func (i Interval) Next(t time.Time) time.Time {
+	switch i.Unit {
+	case IntervalDay:
+		return t.AddDate(0, 0, i.Count)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7*i.Count)
+	default:
+		// the 31st of a month is followed by the last day of a shorter one
+		next := t.AddDate(0, i.Count, 0)
+		if next.Day() != t.Day() {
+			next = next.AddDate(0, 0, -next.Day())
+		}
+		return next
+	}
+}
+
+// SubscriptionItem is a product and the quantity ordered every cycle.
+type SubscriptionItem struct {
+	ProductID string `json:"product_id" bson:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" bson:"quantity" validate:"gt=0"`
+}
+
+// Subscription orders the same products on a schedule and charges them to
+// a saved payment method in payment-ms. Rev is bumped on every save, so
+// two changes made at once cannot overwrite each other.
+type Subscription struct {
+	ID              string              `json:"id" bson:"_id,omitempty"`
+	UserID          string              `json:"user_id" bson:"user_id"`
+	Items           []SubscriptionItem  `json:"items" bson:"items"`
+	Interval        Interval            `json:"interval" bson:"interval"`
+	Currency        string              `json:"currency" bson:"currency"`
+	ShippingAddress Address             `json:"shipping_address" bson:"shipping_address"`
+	ShippingMethod  string              `json:"shipping_method" bson:"shipping_method"`
+	MethodID        string              `json:"method_id" bson:"method_id"` // saved payment method in payment-ms
+	Status          string              `json:"status" bson:"status"`
+	NextRunAt       time.Time           `json:"next_run_at" bson:"next_run_at"`                       // when the next order is placed
+	PausedUntil     *time.Time          `json:"paused_until,omitempty" bson:"paused_until,omitempty"` // nil pauses until resumed
+	Cycles          int                 `json:"cycles" bson:"cycles"`                                 // billing cycles so far, skipped ones included
+	Current         *SubscriptionCycle  `json:"current,omitempty" bson:"current,omitempty"`           // cycle whose order is not paid yet
+	History         []SubscriptionCycle `json:"history" bson:"history"`
+	CancelReason    string              `json:"cancel_reason,omitempty" bson:"cancel_reason,omitempty"`
+	CancelledAt     *time.Time          `json:"cancelled_at,omitempty" bson:"cancelled_at,omitempty"`
+	CreatedAt       time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at" bson:"updated_at"`
+	Rev             int64               `json:"-" bson:"rev"`
+}
+
+// SubscriptionCycle is one order of a subscription and the attempts to
+// pay it.
+type SubscriptionCycle struct {
+	Number        int        `json:"number" bson:"number"`
+	ScheduledFor  time.Time  `json:"scheduled_for" bson:"scheduled_for"`
+	Status        string     `json:"status" bson:"status"`
+	OrderID       string     `json:"order_id,omitempty" bson:"order_id,omitempty"`
+	Attempts      int        `json:"attempts" bson:"attempts"`
+	Failures      int        `json:"failures" bson:"failures"` // attempts known to have failed
+	LastError     string     `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty" bson:"last_attempt_at,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" bson:"next_attempt_at,omitempty"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty" bson:"closed_at,omitempty"`
+}
+
+// SubscriptionChange is what a customer changes on a subscription; nil
+// fields are left as they are. Changes apply from the next order on.
+type SubscriptionChange struct {
+	Items           []SubscriptionItem
+	Interval        *Interval
+	MethodID        *string
+	ShippingAddress *Address
+	ShippingMethod  *string
+	NextRunAt       *time.Time
+}
+
+// Validate checks a subscription about to be saved.
+func (s *Subscription) Validate() error {
+	if len(s.Items) == 0 {
+		return fmt.Errorf("%w: a subscription needs at least one product", ErrInvalidSubscription)
+	}
+	seen := map[string]bool{}
+	for _, it := range s.Items {
+		if it.ProductID == "" || it.Quantity <= 0 {
+			return fmt.Errorf("%w: every line needs a product and a positive quantity", ErrInvalidSubscription)
+		}
+		if seen[it.ProductID] {
+			return fmt.Errorf("%w: product %s is listed twice", ErrInvalidSubscription, it.ProductID)
+		}
+		seen[it.ProductID] = true
+	}
+	if err := s.Interval.Validate(); err != nil {
+		return err
+	}
+	if s.MethodID == "" {
+		return fmt.Errorf("%w: a saved payment method is required", ErrInvalidSubscription)
+	}
+	if s.ShippingMethod == "" {
+		return fmt.Errorf("%w: a shipping method is required", ErrInvalidSubscription)
+	}
+	return nil
+}
+
+// DueAt returns when the subscription next needs the scheduler: to place
+// an order, to retry or check a payment, or to end a pause. It is zero
+// when nothing is due, e.g. for a cancelled subscription.
+func (s *Subscription) DueAt() time.Time {
+	switch {
+	case s.Status == SubscriptionCancelled:
+		return time.Time{}
+	case s.Status == SubscriptionPaused:
+		if s.PausedUntil == nil {
+			return time.Time{}
+		}
+		return *s.PausedUntil
+	case s.Current != nil:
+		if s.Current.NextAttemptAt == nil {
+			return s.Current.ScheduledFor
+		}
+		return *s.Current.NextAttemptAt
+	}
+	return s.NextRunAt
+}
+
+// OpenCycle starts the cycle due at NextRunAt and moves NextRunAt on by
+// one interval, past at if the subscription fell behind.
+func (s *Subscription) OpenCycle(at time.Time) error {
+	if s.Status != SubscriptionActive || s.Current != nil {
+		return fmt.Errorf("%w: subscription is %s", ErrSubscriptionStateChange, strings.ToLower(s.Status))
+	}
+	s.Cycles++
+	s.Current = &SubscriptionCycle{Number: s.Cycles, ScheduledFor: s.NextRunAt, Status: CycleOpen}
+	s.NextRunAt = s.nextRunAfter(s.NextRunAt, at)
+	return nil
+}
+
+// AttemptPayment records that the current cycle's order is being charged.
+// Until its outcome is known the next look is due after wait.
+func (s *Subscription) AttemptPayment(at time.Time, wait time.Duration) {
+	c := s.Current
+	c.Attempts++
+	c.LastAttemptAt = &at
+	next := at.Add(wait)
+	c.NextAttemptAt = &next
+}
+
+// AwaitPayment puts off the next look at a payment still in progress.
+func (s *Subscription) AwaitPayment(at time.Time, wait time.Duration) {
+	next := at.Add(wait)
+	s.Current.NextAttemptAt = &next
+}
+
+// PaymentSucceeded closes the current cycle as paid. A past due
+// subscription is active again; orders it missed meanwhile are not placed
+// late. It reports false if the cycle was closed already.
+func (s *Subscription) PaymentSucceeded(at time.Time) bool {
+	if s.Current == nil {
+		return false
+	}
+	s.closeCycle(CyclePaid, at)
+	if s.Status == SubscriptionPastDue {
+		s.Status = SubscriptionActive
+	}
+	if !s.NextRunAt.After(at) {
+		s.NextRunAt = s.nextRunAfter(s.NextRunAt, at)
+	}
+	return true
+}
+
+// PaymentFailed records that the last payment attempt failed and plans
+// the next one after the wait in retries for that attempt. When the
+// retries ran out, the cycle fails and the subscription is cancelled. It
+// reports false if the failure was recorded already.
+func (s *Subscription) PaymentFailed(reason string, at time.Time, retries []time.Duration) bool {
+	c := s.Current
+	if c == nil || c.Failures >= c.Attempts {
+		return false
+	}
+	c.Failures = c.Attempts
+	c.LastError = reason
+
+	if c.Failures > len(retries) {
+		s.closeCycle(CycleFailed, at)
+		s.cancel(SubscriptionCancelledUnpaid, at)
+		return true
+	}
+	next := at.Add(retries[c.Failures-1])
+	if c.LastAttemptAt != nil {
+		next = c.LastAttemptAt.Add(retries[c.Failures-1])
+	}
+	c.NextAttemptAt = &next
+	if s.Status == SubscriptionActive {
+		s.Status = SubscriptionPastDue
+	}
+	return true
+}
+
+// OrderCancelled closes the current cycle when its order was cancelled
+// before it was paid. It reports false if the cycle was closed already.
+func (s *Subscription) OrderCancelled(at time.Time) bool {
+	if s.Current == nil {
+		return false
+	}
+	s.closeCycle(CycleCancelled, at)
+	if s.Status == SubscriptionPastDue {
+		s.Status = SubscriptionActive
+	}
+	if s.Status == SubscriptionActive && !s.NextRunAt.After(at) {
+		s.NextRunAt = s.nextRunAfter(s.NextRunAt, at)
+	}
+	return true
+}
+
+// Pause stops placing orders until until, or until resumed if until is
+// nil.
+func (s *Subscription) Pause(until *time.Time, at time.Time) error {
+	if s.Status != SubscriptionActive {
+		return fmt.Errorf("%w: only active subscriptions can be paused, this one is %s", ErrSubscriptionStateChange, strings.ToLower(s.Status))
+	}
+	if s.Current != nil {
+		return fmt.Errorf("%w: the order of cycle %d is still being paid", ErrSubscriptionStateChange, s.Current.Number)
+	}
+	if until != nil && !until.After(at) {
+		return fmt.Errorf("%w: pause must end in the future", ErrInvalidSubscription)
+	}
+	s.Status = SubscriptionPaused
+	s.PausedUntil = until
+	return nil
+}
+
+// Resume restarts a paused subscription. Orders missed while it was paused
+// are not placed; the next one follows the subscription's schedule.
+func (s *Subscription) Resume(at time.Time) error {
+	if s.Status != SubscriptionPaused {
+		return fmt.Errorf("%w: subscription is %s, not paused", ErrSubscriptionStateChange, strings.ToLower(s.Status))
+	}
+	s.Status = SubscriptionActive
+	s.PausedUntil = nil
+	if !s.NextRunAt.After(at) {
+		s.NextRunAt = s.nextRunAfter(s.NextRunAt, at)
+	}
+	return nil
+}
+
+// Skip leaves out the next order; the one after follows an interval later.
+// A cycle whose payment is being retried is skipped instead, and its order
+// is the caller's to cancel.
+func (s *Subscription) Skip(at time.Time) error {
+	if s.Status != SubscriptionActive && s.Status != SubscriptionPastDue {
+		return fmt.Errorf("%w: subscription is %s", ErrSubscriptionStateChange, strings.ToLower(s.Status))
+	}
+	if c := s.Current; c != nil {
+		if c.Failures < c.Attempts {
+			return fmt.Errorf("%w: the order of cycle %d is being paid", ErrSubscriptionStateChange, c.Number)
+		}
+		s.closeCycle(CycleSkipped, at)
+		s.Status = SubscriptionActive
+		return nil
+	}
+	s.Cycles++
+	s.History = append(s.History, SubscriptionCycle{
+		Number:       s.Cycles,
+		ScheduledFor: s.NextRunAt,
+		Status:       CycleSkipped,
+		ClosedAt:     &at,
+	})
+	s.NextRunAt = s.nextRunAfter(s.NextRunAt, at)
+	return nil
+}
+
+// Change applies a customer's change. Changing the payment method of a
+// past due subscription retries its payment right away.
+func (s *Subscription) Change(c SubscriptionChange, at time.Time) error {
+	if s.Status == SubscriptionCancelled {
+		return fmt.Errorf("%w: subscription is cancelled", ErrSubscriptionStateChange)
+	}
+	if c.Items != nil {
+		s.Items = c.Items
+	}
+	if c.Interval != nil {
+		s.Interval = *c.Interval
+	}
+	if c.ShippingAddress != nil {
+		s.ShippingAddress = *c.ShippingAddress
+	}
+	if c.ShippingMethod != nil {
+		s.ShippingMethod = *c.ShippingMethod
+	}
+	if c.NextRunAt != nil {
+		if !c.NextRunAt.After(at) {
+			return fmt.Errorf("%w: next order must be in the future", ErrInvalidSubscription)
+		}
+		s.NextRunAt = *c.NextRunAt
+	}
+	if c.MethodID != nil && *c.MethodID != s.MethodID {
+		s.MethodID = *c.MethodID
+		if s.Status == SubscriptionPastDue && s.Current != nil && s.Current.Failures == s.Current.Attempts {
+			s.Current.NextAttemptAt = &at
+		}
+	}
+	return s.Validate()
+}
+
+// Cancel ends the subscription. The order of an open cycle is the
+// caller's to cancel.
+func (s *Subscription) Cancel(reason string, at time.Time) error {
+	if s.Status == SubscriptionCancelled {
+		return fmt.Errorf("%w: subscription is cancelled already", ErrSubscriptionStateChange)
+	}
+	if s.Current != nil {
+		s.closeCycle(CycleCancelled, at)
+	}
+	s.cancel(reason, at)
+	return nil
+}
+
+func (s *Subscription) cancel(reason string, at time.Time) {
+	s.Status = SubscriptionCancelled
+	s.CancelReason = reason
+	s.CancelledAt = &at
+	s.PausedUntil = nil
+}
+
+func (s *Subscription) closeCycle(status string, at time.Time) {
+	c := *s.Current
+	c.Status = status
+	c.NextAttemptAt = nil
+	c.ClosedAt = &at
+	s.History = append(s.History, c)
+	s.Current = nil
+}
+
+// nextRunAfter steps from t one interval at a time until it is past at.
+func (s *Subscription) nextRunAfter(t, at time.Time) time.Time {
+	next := s.Interval.Next(t)
+	for !next.After(at) {
+		next = s.Interval.Next(next)
+	}
+	return next
+}
+
+// ParseRetrySchedule parses a comma-separated list of waits between
+// subscription payment retries, e.g. "24h,72h,120h". An empty value
+// returns DefaultRetrySchedule.
+func ParseRetrySchedule(v string) ([]time.Duration, error) {
+	if strings.TrimSpace(v) == "" {
+		return DefaultRetrySchedule, nil
+	}
+	var schedule []time.Duration
+	for _, part := range strings.Split(v, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid retry wait %q", part)
+		}
+		schedule = append(schedule, d)
+	}
+	return schedule, nil
+}
+
+// SubscriptionEvent is the payload of the subscription events.
+type SubscriptionEvent struct {
+	SubscriptionID string     `json:"subscription_id" bson:"subscription_id"`
+	UserID         string     `json:"user_id" bson:"user_id"`
+	Status         string     `json:"status" bson:"status"`
+	Cycle          int        `json:"cycle" bson:"cycle"`
+	OrderID        string     `json:"order_id,omitempty" bson:"order_id,omitempty"`
+	Attempts       int        `json:"attempts,omitempty" bson:"attempts,omitempty"`
+	Error          string     `json:"error,omitempty" bson:"error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty" bson:"next_attempt_at,omitempty"`
+	CancelReason   string     `json:"cancel_reason,omitempty" bson:"cancel_reason,omitempty"`
+	At             time.Time  `json:"at" bson:"at"`
+}
+
+// NewSubscriptionEvent describes cycle c of a subscription.
+func NewSubscriptionEvent(s *Subscription, c SubscriptionCycle, at time.Time) SubscriptionEvent {
+	return SubscriptionEvent{
+		SubscriptionID: s.ID,
+		UserID:         s.UserID,
+		Status:         s.Status,
+		Cycle:          c.Number,
+		OrderID:        c.OrderID,
+		Attempts:       c.Attempts,
+		Error:          c.LastError,
+		NextAttemptAt:  c.NextAttemptAt,
+		CancelReason:   s.CancelReason,
+		At:             at,
+	}
+}
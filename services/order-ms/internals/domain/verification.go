@@ -0,0 +1,7 @@
+package domain
+
+import "errors"
+
+// ErrEmailNotVerified refuses checkout to a user who has not verified
+// their email while verification is required.
+var ErrEmailNotVerified = errors.New("email address is not verified")
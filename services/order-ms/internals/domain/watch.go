@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// StatusChange is one entry of an order's status history. Seq numbers the
+// changes of an order from 1 in the order they happened, so a watcher that
+// has seen change n picks up after it when it reconnects.
+type StatusChange struct {
+	Seq    int64     `json:"seq" bson:"-"` // position in the history
+	Status string    `json:"status" bson:"status"`
+	At     time.Time `json:"at" bson:"at"`
+}
+
+// ChangesAfter returns the status changes of an order later than seq.
+func (o *Order) ChangesAfter(seq int64) []StatusChange {
+	if seq < 0 {
+		seq = 0
+	}
+	if seq >= int64(len(o.StatusHistory)) {
+		return nil
+	}
+	return o.StatusHistory[seq:]
+}
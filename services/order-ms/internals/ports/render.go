@@ -0,0 +1,8 @@
+package ports
+
+import "order-microservice/internals/domain"
+
+// InvoiceRenderer lays out an invoice or credit note as a PDF.
+type InvoiceRenderer interface {
+	Render(inv *domain.Invoice, seller *domain.LegalEntity) ([]byte, error)
+}
@@ -3,12 +3,94 @@ package ports
 import (
 	"context"
 	"order-microservice/internals/domain"
+	"time"
 )
 
 type OrderRepository interface {
 	Create(ctx context.Context, order *domain.Order)(*domain.Order, error)
 	FindByID(ctx context.Context, id string)(*domain.Order, error)
-	List(ctx context.Context) ([]*domain.Order, error)
-	UpdateOrderStatus(ctx context.Context, id string, status string) (*domain.Order, error)
+	// List returns the orders of userID, or of every user when it is empty.
+	List(ctx context.Context, userID string) ([]*domain.Order, error)
+	// UpdateOrderStatus moves an order to status if it is still in from
+	// and domain.CanChangePaymentStatus allows it.
+	UpdateOrderStatus(ctx context.Context, id, from, status string) (*domain.Order, error)
+	Delete(ctx context.Context, id, deletedBy string) error
+	// Restore undeletes a soft-deleted order.
+	Restore(ctx context.Context, id string) (*domain.Order, error)
+	// Purge removes orders deleted before before for good.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	// Cancel moves an order from status from to CANCELLED; it reports false
+	// if the order was no longer in from.
+	Cancel(ctx context.Context, id, from string, c *domain.Cancellation) (bool, error)
+	UpdateCancellation(ctx context.Context, id string, c *domain.Cancellation) error
+	MarkStockCommitted(ctx context.Context, id string) error
+	// FindUnpaid returns up to limit PENDING or FAILED orders placed before
+	// before, oldest first, leaving out orders of subscriptions.
+	FindUnpaid(ctx context.Context, before time.Time, limit int64) ([]*domain.Order, error)
+	// FindBySubscriptionCycle returns the order a subscription placed for
+	// a billing cycle.
+	FindBySubscriptionCycle(ctx context.Context, subscriptionID string, cycle int) (*domain.Order, error)
+	// FindByUser returns a user's orders in any of statuses.
+	FindByUser(ctx context.Context, userID string, statuses []string) ([]*domain.Order, error)
+	// AnonymizeUser replaces userID with anonymousID on the user's orders,
+	// deleted ones included, and removes what identifies the user from
+	// their shipping addresses. It returns the number of orders changed.
+	AnonymizeUser(ctx context.Context, userID, anonymousID string) (int64, error)
+	// AdvanceFulfillment bumps the fulfillment revision of an order from
+	// rev; it reports false if another shipment change got there first.
+	AdvanceFulfillment(ctx context.Context, id string, rev int64) (bool, error)
+	// SetFulfillmentStatus sets the status derived from shipments if the
+	// fulfillment revision is still rev.
+	SetFulfillmentStatus(ctx context.Context, id string, rev int64, status string) (bool, error)
+}
+
+type ShipmentRepository interface {
+	Create(ctx context.Context, s *domain.Shipment) (*domain.Shipment, error)
+	FindByID(ctx context.Context, id string) (*domain.Shipment, error)
+	FindByOrder(ctx context.Context, orderID string) ([]*domain.Shipment, error)
+	// Update saves a shipment that had status from and one tracking event
+	// less; it reports false if the shipment was updated meanwhile.
+	Update(ctx context.Context, s *domain.Shipment, from string) (bool, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type ReturnRepository interface {
+	Create(ctx context.Context, r *domain.Return) (*domain.Return, error)
+	FindByID(ctx context.Context, id string) (*domain.Return, error)
+	FindByOrder(ctx context.Context, orderID string) ([]*domain.Return, error)
+	// Update saves a return that had status from; it reports false if the
+	// return moved on meanwhile.
+	Update(ctx context.Context, r *domain.Return, from string) (bool, error)
 	Delete(ctx context.Context, id string) error
-}
\ No newline at end of file
+	AnonymizeUser(ctx context.Context, userID, anonymousID string) error
+}
+
+type SubscriptionRepository interface {
+	Create(ctx context.Context, s *domain.Subscription) (*domain.Subscription, error)
+	FindByID(ctx context.Context, id string) (*domain.Subscription, error)
+	// List returns up to limit subscriptions, newest first, of a user and
+	// in a status if those are set.
+	List(ctx context.Context, userID, status string, limit int64) ([]*domain.Subscription, error)
+	// Update saves a subscription read at revision s.Rev and bumps the
+	// revision; it reports false if the subscription changed meanwhile.
+	Update(ctx context.Context, s *domain.Subscription) (bool, error)
+	// FindDue returns up to limit subscriptions due before before, most
+	// overdue first.
+	FindDue(ctx context.Context, before time.Time, limit int64) ([]*domain.Subscription, error)
+	// AnonymizeUser replaces userID with anonymousID on the user's
+	// subscriptions and removes what identifies the user from their
+	// shipping addresses.
+	AnonymizeUser(ctx context.Context, userID, anonymousID string) error
+}
+
+type InvoiceRepository interface {
+	// NextSequence returns the next number in the entity's sequence of kind.
+	NextSequence(ctx context.Context, entity, kind string) (int64, error)
+	// Create stores an issued document. It fails with ErrInvoiceNumberTaken
+	// if its number was issued meanwhile and ErrInvoiceAlreadyIssued if the
+	// order already has a document of its kind and reference.
+	Create(ctx context.Context, inv *domain.Invoice) (*domain.Invoice, error)
+	FindByID(ctx context.Context, id string) (*domain.Invoice, error)
+	FindByReference(ctx context.Context, orderID, kind, reference string) (*domain.Invoice, error)
+	FindByOrder(ctx context.Context, orderID string) ([]*domain.Invoice, error)
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"ecom-api/pkg/events"
+	"time"
+)
+
+// JobScheduler runs delayed jobs at a later time.
+type JobScheduler interface {
+	Schedule(ctx context.Context, kind, subject string, at time.Time) error
+	Unschedule(ctx context.Context, kind, subject string) error
+}
+
+// EventPublisher announces domain events to other services.
+type EventPublisher interface {
+	Publish(ctx context.Context, e events.Event) error
+}
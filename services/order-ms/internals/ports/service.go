@@ -3,13 +3,72 @@ package ports
 import (
 	"context"
 	"order-microservice/internals/domain"
+	"time"
 )
 
 type OrderService interface {
 	CreateOrder(ctx context.Context, order *domain.Order) (*domain.Order, error)
 	GetOrder(ctx context.Context, id string) (*domain.Order, error)
-	ListOrders(ctx context.Context) ([]*domain.Order, error)
+	// GetOwnOrder returns an order of actorID, or any order to an admin.
+	GetOwnOrder(ctx context.Context, id, actorID, role string) (*domain.Order, error)
+	// ListOrders returns the actor's own orders, or every order to admins.
+	ListOrders(ctx context.Context, actorID, role string) ([]*domain.Order, error)
 	UpdateOrderStatus(ctx context.Context, id string, status string) (*domain.Order, error)
-	DeleteOrder(ctx context.Context, id string) error
-	CreateOrderFromCart(ctx context.Context, userID string) (*domain.Order, error)
+	// WatchOrder streams the status changes of an order after seq to send
+	// until ctx ends.
+	WatchOrder(ctx context.Context, id, actorID, role string, after int64, send func([]domain.StatusChange) error) error
+	// DeleteOrder soft-deletes an order. Customers may only delete their
+	// own orders that are done with; admins and the system any order.
+	DeleteOrder(ctx context.Context, id, actorID, role string) error
+	RestoreOrder(ctx context.Context, id string) (*domain.Order, error)
+	PurgeDeletedOrders(ctx context.Context, before time.Time) (int64, error)
+	CreateOrderFromCart(ctx context.Context, userID string, checkout domain.Checkout) (*domain.Order, error)
+	// CancelOrder cancels an order on behalf of actorID, reversing its
+	// payment and putting its stock back.
+	CancelOrder(ctx context.Context, id, actorID, role, reason, note string) (*domain.Order, error)
+	// ExpireUnpaidOrder cancels an order whose payment window closed; it is
+	// the handler of the order's expiry job.
+	ExpireUnpaidOrder(ctx context.Context, id string) error
+	// ExpireUnpaidOrders sweeps for unpaid orders past their window.
+	ExpireUnpaidOrders(ctx context.Context) (int, error)
+	// CreateShipment packs lines of a paid order into a new shipment.
+	CreateShipment(ctx context.Context, orderID, actorID string, lines []domain.ShipmentLine, carrier, trackingNumber string) (*domain.Shipment, error)
+	ListShipments(ctx context.Context, orderID, actorID, role string) ([]*domain.Shipment, error)
+	// UpdateShipment records a tracking update and derives the order
+	// status from the progress of all its shipments.
+	UpdateShipment(ctx context.Context, orderID, shipmentID string, update domain.ShipmentUpdate) (*domain.Shipment, error)
+	// RequestReturn opens a return for delivered lines of an order. With
+	// refundToStoreCredit the refund is paid as store credit.
+	RequestReturn(ctx context.Context, orderID, actorID, role string, lines []domain.ReturnLine, refundToStoreCredit bool) (*domain.Return, error)
+	ListReturns(ctx context.Context, orderID, actorID, role string) ([]*domain.Return, error)
+	ReviewReturn(ctx context.Context, orderID, returnID, reviewerID string, approve bool, note string) (*domain.Return, error)
+	// ReceiveReturn records the goods that arrived for an approved return,
+	// then refunds them and puts resellable goods back in stock.
+	ReceiveReturn(ctx context.Context, orderID, returnID, receiverID string, lines []domain.ReceivedLine) (*domain.Return, error)
+	// GetInvoice returns the invoice of a paid order with its PDF.
+	GetInvoice(ctx context.Context, orderID, actorID, role string) (*domain.Invoice, error)
+	// ListInvoices returns the invoice and credit notes of an order.
+	ListInvoices(ctx context.Context, orderID, actorID, role string) ([]*domain.Invoice, error)
+	GetInvoiceDocument(ctx context.Context, orderID, invoiceID, actorID, role string) (*domain.Invoice, error)
+	// CreateSubscription subscribes a user to products ordered every
+	// interval and charged to a saved payment method. Without startAt the
+	// first order is placed right away.
+	CreateSubscription(ctx context.Context, sub *domain.Subscription, startAt *time.Time) (*domain.Subscription, error)
+	GetSubscription(ctx context.Context, id, actorID, role string) (*domain.Subscription, error)
+	ListSubscriptions(ctx context.Context, actorID, role, userID, status string, limit int64) ([]*domain.Subscription, error)
+	UpdateSubscription(ctx context.Context, id, actorID, role string, change domain.SubscriptionChange) (*domain.Subscription, error)
+	// PauseSubscription stops a subscription until until, or until it is
+	// resumed if until is nil.
+	PauseSubscription(ctx context.Context, id, actorID, role string, until *time.Time) (*domain.Subscription, error)
+	ResumeSubscription(ctx context.Context, id, actorID, role string) (*domain.Subscription, error)
+	SkipSubscription(ctx context.Context, id, actorID, role string) (*domain.Subscription, error)
+	CancelSubscription(ctx context.Context, id, actorID, role string) (*domain.Subscription, error)
+	// BillSubscription places, charges or retries what is due for a
+	// subscription; it is the handler of the subscription's billing job.
+	BillSubscription(ctx context.Context, id string) error
+	// BillDueSubscriptions sweeps for subscriptions that are due.
+	BillDueSubscriptions(ctx context.Context) (int, error)
+	// AnonymizeUser detaches a deleted account's orders from the user;
+	// it fails with ErrOrdersInProgress while paid orders are on their way.
+	AnonymizeUser(ctx context.Context, userID, anonymousID string) (int64, error)
 }
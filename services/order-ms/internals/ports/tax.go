@@ -0,0 +1,8 @@
+package ports
+
+import "ecom-api/pkg/tax"
+
+// TaxCalculator prices tax for order lines shipped to a region.
+type TaxCalculator interface {
+	Calculate(region string, lines []tax.Line) (*tax.Result, error)
+}
@@ -7,6 +7,7 @@
 package pb
 
 import (
+	moneypb "ecom-api/pkg/money/moneypb"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -26,9 +27,13 @@ type Payment struct {
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	OrderId       string                 `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
 	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Amount        float64                `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	Amount        *moneypb.Money         `protobuf:"bytes,7,opt,name=amount,proto3" json:"amount,omitempty"`
 	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
-	Method        string                 `protobuf:"bytes,6,opt,name=method,proto3" json:"method,omitempty"`
+	Method        string                 `protobuf:"bytes,6,opt,name=method,proto3" json:"method,omitempty"`                         // card, wallet or bank_transfer
+	Reversal      *Reversal              `protobuf:"bytes,8,opt,name=reversal,proto3" json:"reversal,omitempty"`                     // set once the payment is voided or refunded
+	Refunds       []*Refund              `protobuf:"bytes,9,rep,name=refunds,proto3" json:"refunds,omitempty"`                       // partial refunds, e.g. for returns
+	MethodId      string                 `protobuf:"bytes,10,opt,name=method_id,json=methodId,proto3" json:"method_id,omitempty"`    // saved payment method charged
+	SplitWith     string                 `protobuf:"bytes,11,opt,name=split_with,json=splitWith,proto3" json:"split_with,omitempty"` // store credit payment that paid the rest of the order
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -84,11 +89,11 @@ func (x *Payment) GetUserId() string {
 	return ""
 }
 
-func (x *Payment) GetAmount() float64 {
+func (x *Payment) GetAmount() *moneypb.Money {
 	if x != nil {
 		return x.Amount
 	}
-	return 0
+	return nil
 }
 
 func (x *Payment) GetStatus() string {
@@ -105,19 +110,202 @@ func (x *Payment) GetMethod() string {
 	return ""
 }
 
+func (x *Payment) GetReversal() *Reversal {
+	if x != nil {
+		return x.Reversal
+	}
+	return nil
+}
+
+func (x *Payment) GetRefunds() []*Refund {
+	if x != nil {
+		return x.Refunds
+	}
+	return nil
+}
+
+func (x *Payment) GetMethodId() string {
+	if x != nil {
+		return x.MethodId
+	}
+	return ""
+}
+
+func (x *Payment) GetSplitWith() string {
+	if x != nil {
+		return x.SplitWith
+	}
+	return ""
+}
+
+type Refund struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reference     string                 `protobuf:"bytes,1,opt,name=reference,proto3" json:"reference,omitempty"` // what the refund pays back, e.g. return:<id>
+	Amount        *moneypb.Money         `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	RefundedAt    string                 `protobuf:"bytes,4,opt,name=refunded_at,json=refundedAt,proto3" json:"refunded_at,omitempty"` // RFC 3339
+	To            string                 `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`                                   // store_credit, or empty when refunded to the payment's method
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Refund) Reset() {
+	*x = Refund{}
+	mi := &file_payment_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Refund) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Refund) ProtoMessage() {}
+
+func (x *Refund) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Refund.ProtoReflect.Descriptor instead.
+func (*Refund) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Refund) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *Refund) GetAmount() *moneypb.Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *Refund) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Refund) GetRefundedAt() string {
+	if x != nil {
+		return x.RefundedAt
+	}
+	return ""
+}
+
+func (x *Refund) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type Reversal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"` // void or refund
+	Amount        *moneypb.Money         `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	ReversedAt    string                 `protobuf:"bytes,4,opt,name=reversed_at,json=reversedAt,proto3" json:"reversed_at,omitempty"` // RFC 3339
+	To            string                 `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`                                   // store_credit, or empty when refunded to the payment's method
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reversal) Reset() {
+	*x = Reversal{}
+	mi := &file_payment_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reversal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reversal) ProtoMessage() {}
+
+func (x *Reversal) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reversal.ProtoReflect.Descriptor instead.
+func (*Reversal) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Reversal) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Reversal) GetAmount() *moneypb.Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *Reversal) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Reversal) GetReversedAt() string {
+	if x != nil {
+		return x.ReversedAt
+	}
+	return ""
+}
+
+func (x *Reversal) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
 type ProcessPaymentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
 	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Amount        float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
-	Method        string                 `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	Amount        *moneypb.Money         `protobuf:"bytes,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	Method        string                 `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`                                 // card, wallet or bank_transfer, when no saved method is charged
+	MethodId      string                 `protobuf:"bytes,6,opt,name=method_id,json=methodId,proto3" json:"method_id,omitempty"`             // saved payment method to charge; the user's default one when empty
+	UseWallet     bool                   `protobuf:"varint,7,opt,name=use_wallet,json=useWallet,proto3" json:"use_wallet,omitempty"`         // let the user's store credit and gift cards pay first
+	WalletAmount  *moneypb.Money         `protobuf:"bytes,8,opt,name=wallet_amount,json=walletAmount,proto3" json:"wallet_amount,omitempty"` // at most this much from the wallet; as much as it covers when unset
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ProcessPaymentRequest) Reset() {
 	*x = ProcessPaymentRequest{}
-	mi := &file_payment_proto_msgTypes[1]
+	mi := &file_payment_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -129,7 +317,7 @@ func (x *ProcessPaymentRequest) String() string {
 func (*ProcessPaymentRequest) ProtoMessage() {}
 
 func (x *ProcessPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[1]
+	mi := &file_payment_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -142,7 +330,7 @@ func (x *ProcessPaymentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProcessPaymentRequest.ProtoReflect.Descriptor instead.
 func (*ProcessPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{1}
+	return file_payment_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *ProcessPaymentRequest) GetOrderId() string {
@@ -159,11 +347,11 @@ func (x *ProcessPaymentRequest) GetUserId() string {
 	return ""
 }
 
-func (x *ProcessPaymentRequest) GetAmount() float64 {
+func (x *ProcessPaymentRequest) GetAmount() *moneypb.Money {
 	if x != nil {
 		return x.Amount
 	}
-	return 0
+	return nil
 }
 
 func (x *ProcessPaymentRequest) GetMethod() string {
@@ -173,6 +361,27 @@ func (x *ProcessPaymentRequest) GetMethod() string {
 	return ""
 }
 
+func (x *ProcessPaymentRequest) GetMethodId() string {
+	if x != nil {
+		return x.MethodId
+	}
+	return ""
+}
+
+func (x *ProcessPaymentRequest) GetUseWallet() bool {
+	if x != nil {
+		return x.UseWallet
+	}
+	return false
+}
+
+func (x *ProcessPaymentRequest) GetWalletAmount() *moneypb.Money {
+	if x != nil {
+		return x.WalletAmount
+	}
+	return nil
+}
+
 type ProcessPaymentResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Payment       *Payment               `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
@@ -182,7 +391,7 @@ type ProcessPaymentResponse struct {
 
 func (x *ProcessPaymentResponse) Reset() {
 	*x = ProcessPaymentResponse{}
-	mi := &file_payment_proto_msgTypes[2]
+	mi := &file_payment_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -194,7 +403,7 @@ func (x *ProcessPaymentResponse) String() string {
 func (*ProcessPaymentResponse) ProtoMessage() {}
 
 func (x *ProcessPaymentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[2]
+	mi := &file_payment_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -207,7 +416,7 @@ func (x *ProcessPaymentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProcessPaymentResponse.ProtoReflect.Descriptor instead.
 func (*ProcessPaymentResponse) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{2}
+	return file_payment_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *ProcessPaymentResponse) GetPayment() *Payment {
@@ -226,7 +435,7 @@ type GetPaymentRequest struct {
 
 func (x *GetPaymentRequest) Reset() {
 	*x = GetPaymentRequest{}
-	mi := &file_payment_proto_msgTypes[3]
+	mi := &file_payment_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -238,7 +447,7 @@ func (x *GetPaymentRequest) String() string {
 func (*GetPaymentRequest) ProtoMessage() {}
 
 func (x *GetPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[3]
+	mi := &file_payment_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -251,7 +460,7 @@ func (x *GetPaymentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPaymentRequest.ProtoReflect.Descriptor instead.
 func (*GetPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{3}
+	return file_payment_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetPaymentRequest) GetId() string {
@@ -270,7 +479,7 @@ type GetPaymentResponse struct {
 
 func (x *GetPaymentResponse) Reset() {
 	*x = GetPaymentResponse{}
-	mi := &file_payment_proto_msgTypes[4]
+	mi := &file_payment_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -282,7 +491,7 @@ func (x *GetPaymentResponse) String() string {
 func (*GetPaymentResponse) ProtoMessage() {}
 
 func (x *GetPaymentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[4]
+	mi := &file_payment_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -295,7 +504,7 @@ func (x *GetPaymentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPaymentResponse.ProtoReflect.Descriptor instead.
 func (*GetPaymentResponse) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{4}
+	return file_payment_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GetPaymentResponse) GetPayment() *Payment {
@@ -313,7 +522,7 @@ type ListPaymentsRequest struct {
 
 func (x *ListPaymentsRequest) Reset() {
 	*x = ListPaymentsRequest{}
-	mi := &file_payment_proto_msgTypes[5]
+	mi := &file_payment_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -325,7 +534,7 @@ func (x *ListPaymentsRequest) String() string {
 func (*ListPaymentsRequest) ProtoMessage() {}
 
 func (x *ListPaymentsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[5]
+	mi := &file_payment_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -338,7 +547,7 @@ func (x *ListPaymentsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListPaymentsRequest.ProtoReflect.Descriptor instead.
 func (*ListPaymentsRequest) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{5}
+	return file_payment_proto_rawDescGZIP(), []int{7}
 }
 
 type ListPaymentsResponse struct {
@@ -350,7 +559,7 @@ type ListPaymentsResponse struct {
 
 func (x *ListPaymentsResponse) Reset() {
 	*x = ListPaymentsResponse{}
-	mi := &file_payment_proto_msgTypes[6]
+	mi := &file_payment_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -362,7 +571,7 @@ func (x *ListPaymentsResponse) String() string {
 func (*ListPaymentsResponse) ProtoMessage() {}
 
 func (x *ListPaymentsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[6]
+	mi := &file_payment_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -375,7 +584,7 @@ func (x *ListPaymentsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListPaymentsResponse.ProtoReflect.Descriptor instead.
 func (*ListPaymentsResponse) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{6}
+	return file_payment_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ListPaymentsResponse) GetPayments() []*Payment {
@@ -395,7 +604,7 @@ type UpdatePaymentStatusRequest struct {
 
 func (x *UpdatePaymentStatusRequest) Reset() {
 	*x = UpdatePaymentStatusRequest{}
-	mi := &file_payment_proto_msgTypes[7]
+	mi := &file_payment_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -407,7 +616,7 @@ func (x *UpdatePaymentStatusRequest) String() string {
 func (*UpdatePaymentStatusRequest) ProtoMessage() {}
 
 func (x *UpdatePaymentStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[7]
+	mi := &file_payment_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -420,7 +629,7 @@ func (x *UpdatePaymentStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdatePaymentStatusRequest.ProtoReflect.Descriptor instead.
 func (*UpdatePaymentStatusRequest) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{7}
+	return file_payment_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *UpdatePaymentStatusRequest) GetId() string {
@@ -446,7 +655,7 @@ type UpdatePaymentStatusResponse struct {
 
 func (x *UpdatePaymentStatusResponse) Reset() {
 	*x = UpdatePaymentStatusResponse{}
-	mi := &file_payment_proto_msgTypes[8]
+	mi := &file_payment_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -458,7 +667,7 @@ func (x *UpdatePaymentStatusResponse) String() string {
 func (*UpdatePaymentStatusResponse) ProtoMessage() {}
 
 func (x *UpdatePaymentStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[8]
+	mi := &file_payment_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -471,7 +680,7 @@ func (x *UpdatePaymentStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdatePaymentStatusResponse.ProtoReflect.Descriptor instead.
 func (*UpdatePaymentStatusResponse) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{8}
+	return file_payment_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *UpdatePaymentStatusResponse) GetPayment() *Payment {
@@ -490,7 +699,7 @@ type DeletePaymentRequest struct {
 
 func (x *DeletePaymentRequest) Reset() {
 	*x = DeletePaymentRequest{}
-	mi := &file_payment_proto_msgTypes[9]
+	mi := &file_payment_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -502,7 +711,7 @@ func (x *DeletePaymentRequest) String() string {
 func (*DeletePaymentRequest) ProtoMessage() {}
 
 func (x *DeletePaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[9]
+	mi := &file_payment_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -515,7 +724,7 @@ func (x *DeletePaymentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeletePaymentRequest.ProtoReflect.Descriptor instead.
 func (*DeletePaymentRequest) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{9}
+	return file_payment_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *DeletePaymentRequest) GetId() string {
@@ -534,7 +743,7 @@ type DeletePaymentResponse struct {
 
 func (x *DeletePaymentResponse) Reset() {
 	*x = DeletePaymentResponse{}
-	mi := &file_payment_proto_msgTypes[10]
+	mi := &file_payment_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -546,7 +755,7 @@ func (x *DeletePaymentResponse) String() string {
 func (*DeletePaymentResponse) ProtoMessage() {}
 
 func (x *DeletePaymentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[10]
+	mi := &file_payment_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -559,7 +768,7 @@ func (x *DeletePaymentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeletePaymentResponse.ProtoReflect.Descriptor instead.
 func (*DeletePaymentResponse) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{10}
+	return file_payment_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *DeletePaymentResponse) GetMessage() string {
@@ -578,7 +787,7 @@ type NotifyOrderRequest struct {
 
 func (x *NotifyOrderRequest) Reset() {
 	*x = NotifyOrderRequest{}
-	mi := &file_payment_proto_msgTypes[11]
+	mi := &file_payment_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -590,7 +799,7 @@ func (x *NotifyOrderRequest) String() string {
 func (*NotifyOrderRequest) ProtoMessage() {}
 
 func (x *NotifyOrderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[11]
+	mi := &file_payment_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -603,7 +812,7 @@ func (x *NotifyOrderRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NotifyOrderRequest.ProtoReflect.Descriptor instead.
 func (*NotifyOrderRequest) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{11}
+	return file_payment_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *NotifyOrderRequest) GetOrderId() string {
@@ -622,7 +831,7 @@ type NotifyOrderResponse struct {
 
 func (x *NotifyOrderResponse) Reset() {
 	*x = NotifyOrderResponse{}
-	mi := &file_payment_proto_msgTypes[12]
+	mi := &file_payment_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -634,7 +843,7 @@ func (x *NotifyOrderResponse) String() string {
 func (*NotifyOrderResponse) ProtoMessage() {}
 
 func (x *NotifyOrderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[12]
+	mi := &file_payment_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -647,7 +856,7 @@ func (x *NotifyOrderResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NotifyOrderResponse.ProtoReflect.Descriptor instead.
 func (*NotifyOrderResponse) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{12}
+	return file_payment_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *NotifyOrderResponse) GetMessage() string {
@@ -657,23 +866,383 @@ func (x *NotifyOrderResponse) GetMessage() string {
 	return ""
 }
 
+// CancelOrderPayment reverses the payments of a cancelled order: pending
+// payments are voided and completed ones refunded. Already reversed
+// payments are left alone, so the call can be repeated.
+type CancelOrderPaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderPaymentRequest) Reset() {
+	*x = CancelOrderPaymentRequest{}
+	mi := &file_payment_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderPaymentRequest) ProtoMessage() {}
+
+func (x *CancelOrderPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderPaymentRequest.ProtoReflect.Descriptor instead.
+func (*CancelOrderPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CancelOrderPaymentRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *CancelOrderPaymentRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CancelOrderPaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`     // refunded, voided or none when nothing was paid
+	Refunded      *moneypb.Money         `protobuf:"bytes,2,opt,name=refunded,proto3" json:"refunded,omitempty"` // total refunded for the order
+	Payments      []*Payment             `protobuf:"bytes,3,rep,name=payments,proto3" json:"payments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderPaymentResponse) Reset() {
+	*x = CancelOrderPaymentResponse{}
+	mi := &file_payment_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderPaymentResponse) ProtoMessage() {}
+
+func (x *CancelOrderPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderPaymentResponse.ProtoReflect.Descriptor instead.
+func (*CancelOrderPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CancelOrderPaymentResponse) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *CancelOrderPaymentResponse) GetRefunded() *moneypb.Money {
+	if x != nil {
+		return x.Refunded
+	}
+	return nil
+}
+
+func (x *CancelOrderPaymentResponse) GetPayments() []*Payment {
+	if x != nil {
+		return x.Payments
+	}
+	return nil
+}
+
+// RefundPayment refunds part of what was paid for an order. A reference is
+// refunded once, so the call can be repeated.
+type RefundPaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Reference     string                 `protobuf:"bytes,2,opt,name=reference,proto3" json:"reference,omitempty"`
+	Amount        *moneypb.Money         `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	ToStoreCredit bool                   `protobuf:"varint,5,opt,name=to_store_credit,json=toStoreCredit,proto3" json:"to_store_credit,omitempty"` // pay the refund into the user's wallet
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundPaymentRequest) Reset() {
+	*x = RefundPaymentRequest{}
+	mi := &file_payment_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundPaymentRequest) ProtoMessage() {}
+
+func (x *RefundPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundPaymentRequest.ProtoReflect.Descriptor instead.
+func (*RefundPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RefundPaymentRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *RefundPaymentRequest) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *RefundPaymentRequest) GetAmount() *moneypb.Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *RefundPaymentRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RefundPaymentRequest) GetToStoreCredit() bool {
+	if x != nil {
+		return x.ToStoreCredit
+	}
+	return false
+}
+
+type RefundPaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Refunded      *moneypb.Money         `protobuf:"bytes,1,opt,name=refunded,proto3" json:"refunded,omitempty"` // refunded for the reference
+	Payments      []*Payment             `protobuf:"bytes,2,rep,name=payments,proto3" json:"payments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundPaymentResponse) Reset() {
+	*x = RefundPaymentResponse{}
+	mi := &file_payment_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundPaymentResponse) ProtoMessage() {}
+
+func (x *RefundPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundPaymentResponse.ProtoReflect.Descriptor instead.
+func (*RefundPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RefundPaymentResponse) GetRefunded() *moneypb.Money {
+	if x != nil {
+		return x.Refunded
+	}
+	return nil
+}
+
+func (x *RefundPaymentResponse) GetPayments() []*Payment {
+	if x != nil {
+		return x.Payments
+	}
+	return nil
+}
+
+// AnonymizeUser detaches the payments, screenings and wallet of a deleted
+// account from the user, putting anonymous_id in place of its ID, and
+// deletes the user's saved payment methods. The call can be repeated.
+type AnonymizeUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	AnonymousId   string                 `protobuf:"bytes,2,opt,name=anonymous_id,json=anonymousId,proto3" json:"anonymous_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnonymizeUserRequest) Reset() {
+	*x = AnonymizeUserRequest{}
+	mi := &file_payment_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnonymizeUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnonymizeUserRequest) ProtoMessage() {}
+
+func (x *AnonymizeUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnonymizeUserRequest.ProtoReflect.Descriptor instead.
+func (*AnonymizeUserRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AnonymizeUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AnonymizeUserRequest) GetAnonymousId() string {
+	if x != nil {
+		return x.AnonymousId
+	}
+	return ""
+}
+
+type AnonymizeUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnonymizeUserResponse) Reset() {
+	*x = AnonymizeUserResponse{}
+	mi := &file_payment_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnonymizeUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnonymizeUserResponse) ProtoMessage() {}
+
+func (x *AnonymizeUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnonymizeUserResponse.ProtoReflect.Descriptor instead.
+func (*AnonymizeUserResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{20}
+}
+
 var File_payment_proto protoreflect.FileDescriptor
 
 const file_payment_proto_rawDesc = "" +
 	"\n" +
-	"\rpayment.proto\x12\apayment\"\x95\x01\n" +
+	"\rpayment.proto\x12\apayment\x1a\vmoney.proto\"\xbf\x02\n" +
 	"\aPayment\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
 	"\border_id\x18\x02 \x01(\tR\aorderId\x12\x17\n" +
-	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x16\n" +
-	"\x06amount\x18\x04 \x01(\x01R\x06amount\x12\x16\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12$\n" +
+	"\x06amount\x18\a \x01(\v2\f.money.MoneyR\x06amount\x12\x16\n" +
 	"\x06status\x18\x05 \x01(\tR\x06status\x12\x16\n" +
-	"\x06method\x18\x06 \x01(\tR\x06method\"{\n" +
+	"\x06method\x18\x06 \x01(\tR\x06method\x12-\n" +
+	"\breversal\x18\b \x01(\v2\x11.payment.ReversalR\breversal\x12)\n" +
+	"\arefunds\x18\t \x03(\v2\x0f.payment.RefundR\arefunds\x12\x1b\n" +
+	"\tmethod_id\x18\n" +
+	" \x01(\tR\bmethodId\x12\x1d\n" +
+	"\n" +
+	"split_with\x18\v \x01(\tR\tsplitWithJ\x04\b\x04\x10\x05\"\x95\x01\n" +
+	"\x06Refund\x12\x1c\n" +
+	"\treference\x18\x01 \x01(\tR\treference\x12$\n" +
+	"\x06amount\x18\x02 \x01(\v2\f.money.MoneyR\x06amount\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x1f\n" +
+	"\vrefunded_at\x18\x04 \x01(\tR\n" +
+	"refundedAt\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\tR\x02to\"\x8d\x01\n" +
+	"\bReversal\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12$\n" +
+	"\x06amount\x18\x02 \x01(\v2\f.money.MoneyR\x06amount\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x1f\n" +
+	"\vreversed_at\x18\x04 \x01(\tR\n" +
+	"reversedAt\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\tR\x02to\"\xfe\x01\n" +
 	"\x15ProcessPaymentRequest\x12\x19\n" +
 	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
-	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x16\n" +
-	"\x06method\x18\x04 \x01(\tR\x06method\"D\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12$\n" +
+	"\x06amount\x18\x05 \x01(\v2\f.money.MoneyR\x06amount\x12\x16\n" +
+	"\x06method\x18\x04 \x01(\tR\x06method\x12\x1b\n" +
+	"\tmethod_id\x18\x06 \x01(\tR\bmethodId\x12\x1d\n" +
+	"\n" +
+	"use_wallet\x18\a \x01(\bR\tuseWallet\x121\n" +
+	"\rwallet_amount\x18\b \x01(\v2\f.money.MoneyR\fwalletAmountJ\x04\b\x03\x10\x04\"D\n" +
 	"\x16ProcessPaymentResponse\x12*\n" +
 	"\apayment\x18\x01 \x01(\v2\x10.payment.PaymentR\apayment\"#\n" +
 	"\x11GetPaymentRequest\x12\x0e\n" +
@@ -695,7 +1264,27 @@ const file_payment_proto_rawDesc = "" +
 	"\x12NotifyOrderRequest\x12\x19\n" +
 	"\border_id\x18\x01 \x01(\tR\aorderId\"/\n" +
 	"\x13NotifyOrderResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage2\xfa\x03\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"N\n" +
+	"\x19CancelOrderPaymentRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x8c\x01\n" +
+	"\x1aCancelOrderPaymentResponse\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12(\n" +
+	"\brefunded\x18\x02 \x01(\v2\f.money.MoneyR\brefunded\x12,\n" +
+	"\bpayments\x18\x03 \x03(\v2\x10.payment.PaymentR\bpayments\"\xb5\x01\n" +
+	"\x14RefundPaymentRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x1c\n" +
+	"\treference\x18\x02 \x01(\tR\treference\x12$\n" +
+	"\x06amount\x18\x03 \x01(\v2\f.money.MoneyR\x06amount\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12&\n" +
+	"\x0fto_store_credit\x18\x05 \x01(\bR\rtoStoreCredit\"o\n" +
+	"\x15RefundPaymentResponse\x12(\n" +
+	"\brefunded\x18\x01 \x01(\v2\f.money.MoneyR\brefunded\x12,\n" +
+	"\bpayments\x18\x02 \x03(\v2\x10.payment.PaymentR\bpayments\"R\n" +
+	"\x14AnonymizeUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12!\n" +
+	"\fanonymous_id\x18\x02 \x01(\tR\vanonymousId\"\x17\n" +
+	"\x15AnonymizeUserResponse2\xf9\x05\n" +
 	"\x0ePaymentService\x12Q\n" +
 	"\x0eProcessPayment\x12\x1e.payment.ProcessPaymentRequest\x1a\x1f.payment.ProcessPaymentResponse\x12E\n" +
 	"\n" +
@@ -703,7 +1292,10 @@ const file_payment_proto_rawDesc = "" +
 	"\fListPayments\x12\x1c.payment.ListPaymentsRequest\x1a\x1d.payment.ListPaymentsResponse\x12`\n" +
 	"\x13UpdatePaymentStatus\x12#.payment.UpdatePaymentStatusRequest\x1a$.payment.UpdatePaymentStatusResponse\x12N\n" +
 	"\rDeletePayment\x12\x1d.payment.DeletePaymentRequest\x1a\x1e.payment.DeletePaymentResponse\x12O\n" +
-	"\x12NotifyOrderCreated\x12\x1b.payment.NotifyOrderRequest\x1a\x1c.payment.NotifyOrderResponseB>Z<payment-microservice/services/payment-ms/adaptors/grpc/pb;pbb\x06proto3"
+	"\x12NotifyOrderCreated\x12\x1b.payment.NotifyOrderRequest\x1a\x1c.payment.NotifyOrderResponse\x12]\n" +
+	"\x12CancelOrderPayment\x12\".payment.CancelOrderPaymentRequest\x1a#.payment.CancelOrderPaymentResponse\x12N\n" +
+	"\rRefundPayment\x12\x1d.payment.RefundPaymentRequest\x1a\x1e.payment.RefundPaymentResponse\x12N\n" +
+	"\rAnonymizeUser\x12\x1d.payment.AnonymizeUserRequest\x1a\x1e.payment.AnonymizeUserResponseB>Z<payment-microservice/services/payment-ms/adaptors/grpc/pb;pbb\x06proto3"
 
 var (
 	file_payment_proto_rawDescOnce sync.Once
@@ -717,44 +1309,71 @@ func file_payment_proto_rawDescGZIP() []byte {
 	return file_payment_proto_rawDescData
 }
 
-var file_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_payment_proto_goTypes = []any{
 	(*Payment)(nil),                     // 0: payment.Payment
-	(*ProcessPaymentRequest)(nil),       // 1: payment.ProcessPaymentRequest
-	(*ProcessPaymentResponse)(nil),      // 2: payment.ProcessPaymentResponse
-	(*GetPaymentRequest)(nil),           // 3: payment.GetPaymentRequest
-	(*GetPaymentResponse)(nil),          // 4: payment.GetPaymentResponse
-	(*ListPaymentsRequest)(nil),         // 5: payment.ListPaymentsRequest
-	(*ListPaymentsResponse)(nil),        // 6: payment.ListPaymentsResponse
-	(*UpdatePaymentStatusRequest)(nil),  // 7: payment.UpdatePaymentStatusRequest
-	(*UpdatePaymentStatusResponse)(nil), // 8: payment.UpdatePaymentStatusResponse
-	(*DeletePaymentRequest)(nil),        // 9: payment.DeletePaymentRequest
-	(*DeletePaymentResponse)(nil),       // 10: payment.DeletePaymentResponse
-	(*NotifyOrderRequest)(nil),          // 11: payment.NotifyOrderRequest
-	(*NotifyOrderResponse)(nil),         // 12: payment.NotifyOrderResponse
+	(*Refund)(nil),                      // 1: payment.Refund
+	(*Reversal)(nil),                    // 2: payment.Reversal
+	(*ProcessPaymentRequest)(nil),       // 3: payment.ProcessPaymentRequest
+	(*ProcessPaymentResponse)(nil),      // 4: payment.ProcessPaymentResponse
+	(*GetPaymentRequest)(nil),           // 5: payment.GetPaymentRequest
+	(*GetPaymentResponse)(nil),          // 6: payment.GetPaymentResponse
+	(*ListPaymentsRequest)(nil),         // 7: payment.ListPaymentsRequest
+	(*ListPaymentsResponse)(nil),        // 8: payment.ListPaymentsResponse
+	(*UpdatePaymentStatusRequest)(nil),  // 9: payment.UpdatePaymentStatusRequest
+	(*UpdatePaymentStatusResponse)(nil), // 10: payment.UpdatePaymentStatusResponse
+	(*DeletePaymentRequest)(nil),        // 11: payment.DeletePaymentRequest
+	(*DeletePaymentResponse)(nil),       // 12: payment.DeletePaymentResponse
+	(*NotifyOrderRequest)(nil),          // 13: payment.NotifyOrderRequest
+	(*NotifyOrderResponse)(nil),         // 14: payment.NotifyOrderResponse
+	(*CancelOrderPaymentRequest)(nil),   // 15: payment.CancelOrderPaymentRequest
+	(*CancelOrderPaymentResponse)(nil),  // 16: payment.CancelOrderPaymentResponse
+	(*RefundPaymentRequest)(nil),        // 17: payment.RefundPaymentRequest
+	(*RefundPaymentResponse)(nil),       // 18: payment.RefundPaymentResponse
+	(*AnonymizeUserRequest)(nil),        // 19: payment.AnonymizeUserRequest
+	(*AnonymizeUserResponse)(nil),       // 20: payment.AnonymizeUserResponse
+	(*moneypb.Money)(nil),               // 21: money.Money
 }
 var file_payment_proto_depIdxs = []int32{
-	0,  // 0: payment.ProcessPaymentResponse.payment:type_name -> payment.Payment
-	0,  // 1: payment.GetPaymentResponse.payment:type_name -> payment.Payment
-	0,  // 2: payment.ListPaymentsResponse.payments:type_name -> payment.Payment
-	0,  // 3: payment.UpdatePaymentStatusResponse.payment:type_name -> payment.Payment
-	1,  // 4: payment.PaymentService.ProcessPayment:input_type -> payment.ProcessPaymentRequest
-	3,  // 5: payment.PaymentService.GetPayment:input_type -> payment.GetPaymentRequest
-	5,  // 6: payment.PaymentService.ListPayments:input_type -> payment.ListPaymentsRequest
-	7,  // 7: payment.PaymentService.UpdatePaymentStatus:input_type -> payment.UpdatePaymentStatusRequest
-	9,  // 8: payment.PaymentService.DeletePayment:input_type -> payment.DeletePaymentRequest
-	11, // 9: payment.PaymentService.NotifyOrderCreated:input_type -> payment.NotifyOrderRequest
-	2,  // 10: payment.PaymentService.ProcessPayment:output_type -> payment.ProcessPaymentResponse
-	4,  // 11: payment.PaymentService.GetPayment:output_type -> payment.GetPaymentResponse
-	6,  // 12: payment.PaymentService.ListPayments:output_type -> payment.ListPaymentsResponse
-	8,  // 13: payment.PaymentService.UpdatePaymentStatus:output_type -> payment.UpdatePaymentStatusResponse
-	10, // 14: payment.PaymentService.DeletePayment:output_type -> payment.DeletePaymentResponse
-	12, // 15: payment.PaymentService.NotifyOrderCreated:output_type -> payment.NotifyOrderResponse
-	10, // [10:16] is the sub-list for method output_type
-	4,  // [4:10] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	21, // 0: payment.Payment.amount:type_name -> money.Money
+	2,  // 1: payment.Payment.reversal:type_name -> payment.Reversal
+	1,  // 2: payment.Payment.refunds:type_name -> payment.Refund
+	21, // 3: payment.Refund.amount:type_name -> money.Money
+	21, // 4: payment.Reversal.amount:type_name -> money.Money
+	21, // 5: payment.ProcessPaymentRequest.amount:type_name -> money.Money
+	21, // 6: payment.ProcessPaymentRequest.wallet_amount:type_name -> money.Money
+	0,  // 7: payment.ProcessPaymentResponse.payment:type_name -> payment.Payment
+	0,  // 8: payment.GetPaymentResponse.payment:type_name -> payment.Payment
+	0,  // 9: payment.ListPaymentsResponse.payments:type_name -> payment.Payment
+	0,  // 10: payment.UpdatePaymentStatusResponse.payment:type_name -> payment.Payment
+	21, // 11: payment.CancelOrderPaymentResponse.refunded:type_name -> money.Money
+	0,  // 12: payment.CancelOrderPaymentResponse.payments:type_name -> payment.Payment
+	21, // 13: payment.RefundPaymentRequest.amount:type_name -> money.Money
+	21, // 14: payment.RefundPaymentResponse.refunded:type_name -> money.Money
+	0,  // 15: payment.RefundPaymentResponse.payments:type_name -> payment.Payment
+	3,  // 16: payment.PaymentService.ProcessPayment:input_type -> payment.ProcessPaymentRequest
+	5,  // 17: payment.PaymentService.GetPayment:input_type -> payment.GetPaymentRequest
+	7,  // 18: payment.PaymentService.ListPayments:input_type -> payment.ListPaymentsRequest
+	9,  // 19: payment.PaymentService.UpdatePaymentStatus:input_type -> payment.UpdatePaymentStatusRequest
+	11, // 20: payment.PaymentService.DeletePayment:input_type -> payment.DeletePaymentRequest
+	13, // 21: payment.PaymentService.NotifyOrderCreated:input_type -> payment.NotifyOrderRequest
+	15, // 22: payment.PaymentService.CancelOrderPayment:input_type -> payment.CancelOrderPaymentRequest
+	17, // 23: payment.PaymentService.RefundPayment:input_type -> payment.RefundPaymentRequest
+	19, // 24: payment.PaymentService.AnonymizeUser:input_type -> payment.AnonymizeUserRequest
+	4,  // 25: payment.PaymentService.ProcessPayment:output_type -> payment.ProcessPaymentResponse
+	6,  // 26: payment.PaymentService.GetPayment:output_type -> payment.GetPaymentResponse
+	8,  // 27: payment.PaymentService.ListPayments:output_type -> payment.ListPaymentsResponse
+	10, // 28: payment.PaymentService.UpdatePaymentStatus:output_type -> payment.UpdatePaymentStatusResponse
+	12, // 29: payment.PaymentService.DeletePayment:output_type -> payment.DeletePaymentResponse
+	14, // 30: payment.PaymentService.NotifyOrderCreated:output_type -> payment.NotifyOrderResponse
+	16, // 31: payment.PaymentService.CancelOrderPayment:output_type -> payment.CancelOrderPaymentResponse
+	18, // 32: payment.PaymentService.RefundPayment:output_type -> payment.RefundPaymentResponse
+	20, // 33: payment.PaymentService.AnonymizeUser:output_type -> payment.AnonymizeUserResponse
+	25, // [25:34] is the sub-list for method output_type
+	16, // [16:25] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_payment_proto_init() }
@@ -768,7 +1387,7 @@ func file_payment_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_payment_proto_rawDesc), len(file_payment_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -25,6 +25,9 @@ const (
 	PaymentService_UpdatePaymentStatus_FullMethodName = "/payment.PaymentService/UpdatePaymentStatus"
 	PaymentService_DeletePayment_FullMethodName       = "/payment.PaymentService/DeletePayment"
 	PaymentService_NotifyOrderCreated_FullMethodName  = "/payment.PaymentService/NotifyOrderCreated"
+	PaymentService_CancelOrderPayment_FullMethodName  = "/payment.PaymentService/CancelOrderPayment"
+	PaymentService_RefundPayment_FullMethodName       = "/payment.PaymentService/RefundPayment"
+	PaymentService_AnonymizeUser_FullMethodName       = "/payment.PaymentService/AnonymizeUser"
 )
 
 // PaymentServiceClient is the client API for PaymentService service.
@@ -37,6 +40,9 @@ type PaymentServiceClient interface {
 	UpdatePaymentStatus(ctx context.Context, in *UpdatePaymentStatusRequest, opts ...grpc.CallOption) (*UpdatePaymentStatusResponse, error)
 	DeletePayment(ctx context.Context, in *DeletePaymentRequest, opts ...grpc.CallOption) (*DeletePaymentResponse, error)
 	NotifyOrderCreated(ctx context.Context, in *NotifyOrderRequest, opts ...grpc.CallOption) (*NotifyOrderResponse, error)
+	CancelOrderPayment(ctx context.Context, in *CancelOrderPaymentRequest, opts ...grpc.CallOption) (*CancelOrderPaymentResponse, error)
+	RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*RefundPaymentResponse, error)
+	AnonymizeUser(ctx context.Context, in *AnonymizeUserRequest, opts ...grpc.CallOption) (*AnonymizeUserResponse, error)
 }
 
 type paymentServiceClient struct {
@@ -107,6 +113,36 @@ func (c *paymentServiceClient) NotifyOrderCreated(ctx context.Context, in *Notif
 	return out, nil
 }
 
+func (c *paymentServiceClient) CancelOrderPayment(ctx context.Context, in *CancelOrderPaymentRequest, opts ...grpc.CallOption) (*CancelOrderPaymentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelOrderPaymentResponse)
+	err := c.cc.Invoke(ctx, PaymentService_CancelOrderPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*RefundPaymentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefundPaymentResponse)
+	err := c.cc.Invoke(ctx, PaymentService_RefundPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) AnonymizeUser(ctx context.Context, in *AnonymizeUserRequest, opts ...grpc.CallOption) (*AnonymizeUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnonymizeUserResponse)
+	err := c.cc.Invoke(ctx, PaymentService_AnonymizeUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PaymentServiceServer is the server API for PaymentService service.
 // All implementations must embed UnimplementedPaymentServiceServer
 // for forward compatibility.
@@ -117,6 +153,9 @@ type PaymentServiceServer interface {
 	UpdatePaymentStatus(context.Context, *UpdatePaymentStatusRequest) (*UpdatePaymentStatusResponse, error)
 	DeletePayment(context.Context, *DeletePaymentRequest) (*DeletePaymentResponse, error)
 	NotifyOrderCreated(context.Context, *NotifyOrderRequest) (*NotifyOrderResponse, error)
+	CancelOrderPayment(context.Context, *CancelOrderPaymentRequest) (*CancelOrderPaymentResponse, error)
+	RefundPayment(context.Context, *RefundPaymentRequest) (*RefundPaymentResponse, error)
+	AnonymizeUser(context.Context, *AnonymizeUserRequest) (*AnonymizeUserResponse, error)
 	mustEmbedUnimplementedPaymentServiceServer()
 }
 
@@ -145,6 +184,15 @@ func (UnimplementedPaymentServiceServer) DeletePayment(context.Context, *DeleteP
 func (UnimplementedPaymentServiceServer) NotifyOrderCreated(context.Context, *NotifyOrderRequest) (*NotifyOrderResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method NotifyOrderCreated not implemented")
 }
+func (UnimplementedPaymentServiceServer) CancelOrderPayment(context.Context, *CancelOrderPaymentRequest) (*CancelOrderPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrderPayment not implemented")
+}
+func (UnimplementedPaymentServiceServer) RefundPayment(context.Context, *RefundPaymentRequest) (*RefundPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundPayment not implemented")
+}
+func (UnimplementedPaymentServiceServer) AnonymizeUser(context.Context, *AnonymizeUserRequest) (*AnonymizeUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnonymizeUser not implemented")
+}
 func (UnimplementedPaymentServiceServer) mustEmbedUnimplementedPaymentServiceServer() {}
 func (UnimplementedPaymentServiceServer) testEmbeddedByValue()                        {}
 
@@ -274,6 +322,60 @@ func _PaymentService_NotifyOrderCreated_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_CancelOrderPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).CancelOrderPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_CancelOrderPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).CancelOrderPayment(ctx, req.(*CancelOrderPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_RefundPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).RefundPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_RefundPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).RefundPayment(ctx, req.(*RefundPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_AnonymizeUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnonymizeUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).AnonymizeUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_AnonymizeUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).AnonymizeUser(ctx, req.(*AnonymizeUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PaymentService_ServiceDesc is the grpc.ServiceDesc for PaymentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -305,6 +407,18 @@ var PaymentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "NotifyOrderCreated",
 			Handler:    _PaymentService_NotifyOrderCreated_Handler,
 		},
+		{
+			MethodName: "CancelOrderPayment",
+			Handler:    _PaymentService_CancelOrderPayment_Handler,
+		},
+		{
+			MethodName: "RefundPayment",
+			Handler:    _PaymentService_RefundPayment_Handler,
+		},
+		{
+			MethodName: "AnonymizeUser",
+			Handler:    _PaymentService_AnonymizeUser_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "payment.proto",
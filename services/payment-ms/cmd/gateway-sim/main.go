@@ -0,0 +1,108 @@
+// Command gateway-sim plays a payment provider against a local payment-ms.
+// It posts a signed payment.succeeded or payment.failed event for a payment
+// to the webhook endpoint and prints the answer. Run payment-ms with
+// PAYMENT_GATEWAY_ASYNC=true so payments wait for the event, then e.g.
+//
+//	go run ./cmd/gateway-sim -payment <payment id> -amount 25.00
+//
+// Sending the same -event twice shows deduplication, and -skew shows the
+// timestamp tolerance at work.
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"ecom-api/pkg/money"
+	"ecom-api/pkg/webhook"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"payment-microservice/internals/domain"
+)
+
+func main() {
+	url := flag.String("url", "http://localhost:8085/webhooks/payments/sim", "webhook endpoint of payment-ms")
+	secret := flag.String("secret", envOr("PAYMENT_WEBHOOK_SECRET", "whsec_local_simulator"), "secret shared with payment-ms")
+	paymentID := flag.String("payment", "", "ID of the payment the event is about")
+	orderID := flag.String("order", "", "ID of the payment's order, optional")
+	amount := flag.String("amount", "", "captured amount, e.g. 25.00; required for succeeded")
+	currency := flag.String("currency", envOr("DEFAULT_CURRENCY", money.DefaultCurrency), "currency of the amount")
+	outcome := flag.String("outcome", "succeeded", "succeeded or failed")
+	eventID := flag.String("event", "", "event ID; random when empty, repeat one to test deduplication")
+	skew := flag.Duration("skew", 0, "shift the signature timestamp, e.g. -10m")
+	flag.Parse()
+
+	if *paymentID == "" {
+		log.Fatal("-payment is required")
+	}
+
+	var event domain.ProviderEvent
+	event.ID = *eventID
+	if event.ID == "" {
+		event.ID = "evt_" + randomHex(12)
+	}
+	event.Created = time.Now().UTC()
+	event.Data.PaymentID = *paymentID
+	event.Data.OrderID = *orderID
+
+	switch *outcome {
+	case "succeeded":
+		event.Type = domain.WebhookPaymentSucceeded
+		m, err := money.Parse(*amount, *currency)
+		if err != nil {
+			log.Fatalf("invalid -amount %q: %v", *amount, err)
+		}
+		event.Data.Amount = m
+	case "failed":
+		event.Type = domain.WebhookPaymentFailed
+		event.Data.FailureReason = "card_declined"
+	default:
+		log.Fatalf("invalid -outcome %q, want succeeded or failed", *outcome)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, *url, bytes.NewReader(body))
+	if err != nil {
+		log.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(*secret, time.Now().Add(*skew), body))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer res.Body.Close()
+	answer, _ := io.ReadAll(res.Body)
+
+	fmt.Printf("sent %s %s\n%s\n%s", event.Type, event.ID, res.Status, answer)
+	if res.StatusCode >= 300 {
+		os.Exit(1)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -4,20 +4,29 @@ import (
 	//"cart-microservice/internal/application"
 	"context"
 	"ecom-api/pkg/auth"
+	"ecom-api/pkg/events"
+	"ecom-api/pkg/money"
+	"ecom-api/pkg/scheduler"
+	"ecom-api/pkg/softdelete"
+	"ecom-api/pkg/webhook"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"time"
 
 	"payment-microservice/adaptors/grpc/pb/payment-microservice/services/payment-ms/adaptors/grpc/pb"
 	"payment-microservice/internals/adaptors/db"
 	grpcAdapter "payment-microservice/internals/adaptors/grpc"
 	httpAdapter "payment-microservice/internals/adaptors/http"
+	"payment-microservice/internals/adaptors/provider"
+	"payment-microservice/internals/adaptors/settlement"
 
 	"payment-microservice/internals/application"
+	"payment-microservice/internals/domain"
 
 	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -61,7 +70,7 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI).SetRegistry(money.Registry(os.Getenv("DEFAULT_CURRENCY"))))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -74,7 +83,7 @@ func main() {
 	//grpc connection
 	orderConn, err := grpc.Dial(orderMSAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
-		log.Fatalf("failed to connect to order-ms at %s: %v", orderMSAddr)
+		log.Fatalf("failed to connect to order-ms at %s: %v", orderMSAddr, err)
 
 	}
 	defer orderConn.Close()
@@ -83,12 +92,130 @@ func main() {
 
 
 	repo := db.NewMongoPaymentRepository(dbConn)
+	if err := repo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create payment indexes: %v", err)
+	}
+
+	// --- Provider webhooks ---
+	webhookSecrets, err := webhook.ParseSecrets(os.Getenv("PAYMENT_WEBHOOK_SECRETS"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	webhookConfig := application.WebhookConfig{Secrets: webhookSecrets, Tolerance: webhook.DefaultTolerance}
+	if v := os.Getenv("PAYMENT_WEBHOOK_TOLERANCE"); v != "" {
+		if webhookConfig.Tolerance, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid PAYMENT_WEBHOOK_TOLERANCE: %v", err)
+		}
+	}
+	replayInterval := time.Minute
+	if v := os.Getenv("PAYMENT_WEBHOOK_REPLAY_INTERVAL"); v != "" {
+		if replayInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid PAYMENT_WEBHOOK_REPLAY_INTERVAL: %v", err)
+		}
+	}
+	// with an asynchronous gateway payments stay PENDING until its webhook
+	awaitWebhook := false
+	if v := os.Getenv("PAYMENT_GATEWAY_ASYNC"); v != "" {
+		if awaitWebhook, err = strconv.ParseBool(v); err != nil {
+			log.Fatalf("invalid PAYMENT_GATEWAY_ASYNC: %v", err)
+		}
+	}
+	webhookEvents := db.NewMongoWebhookEventRepository(dbConn)
+	if err := webhookEvents.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create webhook event indexes: %v", err)
+	}
+
+	// --- Ledger ---
+	ledger := db.NewMongoLedgerRepository(dbConn)
+	if err := ledger.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create ledger indexes: %v", err)
+	}
+	fees, err := domain.ParseFeeSchedule(os.Getenv("PAYMENT_PROVIDER_FEE"))
+	if err != nil {
+		log.Fatalf("invalid PAYMENT_PROVIDER_FEE: %v", err)
+	}
+	ledgerSyncInterval := time.Hour
+	if v := os.Getenv("PAYMENT_LEDGER_SYNC_INTERVAL"); v != "" {
+		if ledgerSyncInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid PAYMENT_LEDGER_SYNC_INTERVAL: %v", err)
+		}
+	}
+
+	// --- Settlement reconciliation ---
+	reconciliations := db.NewMongoReconciliationRepository(dbConn)
+	if err := reconciliations.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create reconciliation indexes: %v", err)
+	}
+	// the provider drops settlement reports here; without it reports are uploaded
+	settlementDir := os.Getenv("PAYMENT_SETTLEMENT_DIR")
+	reconcileInterval := time.Hour
+	if v := os.Getenv("PAYMENT_RECONCILE_INTERVAL"); v != "" {
+		if reconcileInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid PAYMENT_RECONCILE_INTERVAL: %v", err)
+		}
+	}
+
+	// --- Fraud screening ---
+	screenings := db.NewMongoScreeningRepository(dbConn)
+	if err := screenings.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create fraud screening indexes: %v", err)
+	}
+	fraudRules, err := domain.LoadFraudConfig(os.Getenv("FRAUD_RULES_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// --- Saved payment methods ---
+	methods := db.NewMongoPaymentMethodRepository(dbConn)
+	if err := methods.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create payment method indexes: %v", err)
+	}
+
+	// --- Store credit and gift cards ---
+	wallets := db.NewMongoWalletRepository(dbConn)
+	if err := wallets.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create wallet indexes: %v", err)
+	}
 
 	// --- Service ---
-	service := application.NewPaymentService(repo, orderClient)
+	// the provider simulator stands in for the provider's token lookup
+	service := application.NewPaymentService(repo, orderClient, webhookEvents, webhookConfig, awaitWebhook, events.NewMongoOutbox(dbConn), ledger, fees, screenings, fraudRules, methods, wallets, provider.NewSimulator())
+	reconciler := application.NewReconciler(repo, reconciliations, fees)
+
+	// --- Scheduler and webhook replay ---
+	jobs, err := scheduler.FromEnv(dbConn, "payment-ms")
+	if err != nil {
+		log.Fatal(err)
+	}
+	jobs.Every("replay-webhook-events", replayInterval, func(ctx context.Context) error {
+		_, err := service.ReplayFailedWebhooks(ctx)
+		return err
+	})
+	jobs.Every("sync-ledger", ledgerSyncInterval, func(ctx context.Context) error {
+		_, err := service.SyncLedger(ctx)
+		return err
+	})
+	if settlementDir != "" {
+		source := settlement.NewDirSource(settlementDir)
+		jobs.Every("reconcile-settlements", reconcileInterval, func(ctx context.Context) error {
+			_, err := reconciler.ReconcileSource(ctx, source)
+			return err
+		})
+	}
+
+	// --- Purge of deleted payments ---
+	purger, err := softdelete.PurgerFromEnv("payments", service.PurgeDeletedPayments)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	//http set up
-	handler := httpAdapter.NewPaymentHandler(service, orderClient)
+	// forwarding headers are only believed from these proxies
+	trustedProxies, err := httpAdapter.ParseTrustedProxies(os.Getenv("PAYMENT_TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("invalid PAYMENT_TRUSTED_PROXIES: %v", err)
+	}
+	handler := httpAdapter.NewPaymentHandler(service, orderClient, reconciler, trustedProxies)
 	httpServer := &http.Server{
 		Addr: httpPort,
 		Handler: httpAdapter.NewPaymentRouter(handler),
@@ -104,6 +231,8 @@ func main() {
 	}
 
 	g := new(errgroup.Group)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
 
 	//http server
 	g.Go(func() error {
@@ -117,6 +246,16 @@ func main() {
 		return grpcServer.Serve(lis)
 	})
 
+	// purge of deleted records
+	g.Go(func() error {
+		return purger.Run(workerCtx)
+	})
+
+	// scheduled jobs, run by one replica at a time
+	g.Go(func() error {
+		return jobs.Run(workerCtx)
+	})
+
 	// Graceful shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, os.Kill)
@@ -124,6 +263,7 @@ func main() {
 	go func() {
 		<-stop
 		fmt.Println("\n🛑 Shutting down Payment service...")
+		stopWorkers()
 		// shutdown http
 		ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -0,0 +1,111 @@
+// Command reconcile matches provider settlement reports in CSV against the
+// payments of payment-ms and prints each report's totals and discrepancies,
+// e.g.
+//
+//	go run ./cmd/reconcile settlement-2026-10-18.csv
+//
+// Results are saved like those of the scheduled job, so a report is
+// reconciled once; running it again prints the saved result. It exits with
+// status 3 if a report has discrepancies.
+package main
+
+import (
+	"context"
+	"ecom-api/pkg/money"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"payment-microservice/internals/adaptors/db"
+	"payment-microservice/internals/adaptors/settlement"
+	"payment-microservice/internals/application"
+	"payment-microservice/internals/domain"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func main() {
+	if err := godotenv.Load("../../../.env"); err != nil {
+		log.Println("Warning: No .env file found, falling back to system environment")
+	}
+
+	fee := flag.String("fee", os.Getenv("PAYMENT_PROVIDER_FEE"), `fee the provider should charge, e.g. "2.9%+0.30"`)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: reconcile [-fee schedule] report.csv...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	mongoURI := os.Getenv("MONGO_URI")
+	dbName := os.Getenv("MONGO_DB_NAME")
+	if mongoURI == "" || dbName == "" {
+		log.Fatal("Missing MONGO_URI or MONGO_DB_NAME in environment")
+	}
+	fees, err := domain.ParseFeeSchedule(*fee)
+	if err != nil {
+		log.Fatalf("invalid -fee: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI).SetRegistry(money.Registry(os.Getenv("DEFAULT_CURRENCY"))))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Disconnect(ctx)
+
+	dbConn := client.Database(dbName)
+	reconciliations := db.NewMongoReconciliationRepository(dbConn)
+	if err := reconciliations.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create reconciliation indexes: %v", err)
+	}
+	reconciler := application.NewReconciler(db.NewMongoPaymentRepository(dbConn), reconciliations, fees)
+
+	discrepancies := false
+	for _, path := range flag.Args() {
+		report, err := settlement.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rec, existed, err := reconciler.Reconcile(ctx, report)
+		if err != nil {
+			log.Fatalf("failed to reconcile %s: %v", path, err)
+		}
+		printReconciliation(rec, existed)
+		discrepancies = discrepancies || rec.Status == domain.ReconciliationDiscrepancies
+	}
+	if discrepancies {
+		os.Exit(3)
+	}
+}
+
+func printReconciliation(rec *domain.Reconciliation, existed bool) {
+	when := ""
+	if existed {
+		when = " (reconciled before, " + rec.ReconciledAt.Format(time.RFC3339) + ")"
+	}
+	fmt.Printf("%s: %s, %d of %d lines matched%s\n", rec.Source, rec.Status, rec.Matched, rec.Lines, when)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, t := range rec.Totals {
+		fmt.Fprintf(tw, "  %s\tcharges %s\trefunds %s\tfees %s\tnet %s\n", t.Currency, t.Charges, t.Refunds, t.Fees, t.Net)
+	}
+	for _, d := range rec.Discrepancies {
+		expected := ""
+		if d.Expected != nil {
+			expected = "expected " + d.Expected.String()
+		}
+		fmt.Fprintf(tw, "  line %d\t%s\t%s %s\t%s %s\t%s\n", d.Line, d.Kind, d.Type, d.Reference, d.Settled, expected, d.Detail)
+	}
+	tw.Flush()
+}
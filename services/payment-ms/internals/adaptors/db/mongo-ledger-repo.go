@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"ecom-api/pkg/money"
+	"errors"
+	"time"
+
+	"payment-microservice/internals/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoLedgerRepository keeps journal entries in an append-only
+// collection. It has no update or delete methods on purpose.
+type MongoLedgerRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoLedgerRepository(db *mongo.Database) *MongoLedgerRepository {
+	return &MongoLedgerRepository{collection: db.Collection("journal_entries")}
+}
+
+func (r *MongoLedgerRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetName("key").SetUnique(true)},
+		{Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "posted_at", Value: -1}}, Options: options.Index().SetName("order_posted_at")},
+		{Keys: bson.D{{Key: "payment_id", Value: 1}, {Key: "posted_at", Value: -1}}, Options: options.Index().SetName("payment_posted_at")},
+		{Keys: bson.D{{Key: "postings.account", Value: 1}, {Key: "posted_at", Value: -1}}, Options: options.Index().SetName("account_posted_at")},
+	})
+	return err
+}
+
+func (r *MongoLedgerRepository) Post(ctx context.Context, e *domain.JournalEntry) (bool, error) {
+	e.ID = ""
+	if e.PostedAt.IsZero() {
+		e.PostedAt = time.Now()
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = e.PostedAt
+	}
+
+	res, err := r.collection.InsertOne(ctx, e)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		e.ID = oid.Hex()
+	}
+	return true, nil
+}
+
+func (r *MongoLedgerRepository) FindByID(ctx context.Context, id string) (*domain.JournalEntry, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrEntryNotFound
+	}
+	return r.findOne(ctx, bson.M{"_id": oid})
+}
+
+func (r *MongoLedgerRepository) FindByKey(ctx context.Context, key string) (*domain.JournalEntry, error) {
+	return r.findOne(ctx, bson.M{"key": key})
+}
+
+func (r *MongoLedgerRepository) List(ctx context.Context, filter domain.EntryFilter, limit int64) ([]*domain.JournalEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "posted_at", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(limit)
+	cur, err := r.collection.Find(ctx, entryFilter(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	entries := []*domain.JournalEntry{}
+	for cur.Next(ctx) {
+		var e domain.JournalEntry
+		if err := cur.Decode(&e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, cur.Err()
+}
+
+func (r *MongoLedgerRepository) Balances(ctx context.Context, filter domain.EntryFilter) ([]domain.AccountBalance, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: entryFilter(filter)}},
+		{{Key: "$unwind", Value: "$postings"}},
+	}
+	if filter.Account != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"postings.account": filter.Account}}})
+	}
+	sumSide := func(direction string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$postings.direction", direction}},
+			"$postings.amount.amount",
+			0,
+		}}}
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":     bson.M{"account": "$postings.account", "currency": "$postings.amount.currency"},
+			"debits":  sumSide(domain.Debit),
+			"credits": sumSide(domain.Credit),
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id.account", Value: 1}, {Key: "_id.currency", Value: 1}}}},
+	)
+
+	cur, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	balances := []domain.AccountBalance{}
+	for cur.Next(ctx) {
+		var row struct {
+			ID struct {
+				Account  string `bson:"account"`
+				Currency string `bson:"currency"`
+			} `bson:"_id"`
+			Debits  int64 `bson:"debits"`
+			Credits int64 `bson:"credits"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, err
+		}
+		balances = append(balances, domain.NewAccountBalance(row.ID.Account,
+			money.New(row.Debits, row.ID.Currency), money.New(row.Credits, row.ID.Currency)))
+	}
+	return balances, cur.Err()
+}
+
+func (r *MongoLedgerRepository) findOne(ctx context.Context, filter bson.M) (*domain.JournalEntry, error) {
+	var e domain.JournalEntry
+	err := r.collection.FindOne(ctx, filter).Decode(&e)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func entryFilter(f domain.EntryFilter) bson.M {
+	filter := bson.M{}
+	if f.OrderID != "" {
+		filter["order_id"] = f.OrderID
+	}
+	if f.PaymentID != "" {
+		filter["payment_id"] = f.PaymentID
+	}
+	if f.Account != "" {
+		filter["postings.account"] = f.Account
+	}
+	return filter
+}
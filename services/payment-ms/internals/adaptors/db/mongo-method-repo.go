@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"payment-microservice/internals/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoPaymentMethodRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoPaymentMethodRepository(db *mongo.Database) *MongoPaymentMethodRepository {
+	return &MongoPaymentMethodRepository{collection: db.Collection("payment_methods")}
+}
+
+// EnsureIndexes creates the index methods are listed by and the one that
+// keeps a user from having two default methods.
+func (r *MongoPaymentMethodRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}, Options: options.Index().SetName("user_id_created_at")},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("user_id_default").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"default": true}),
+		},
+	})
+	return err
+}
+
+func (r *MongoPaymentMethodRepository) Create(ctx context.Context, m *domain.PaymentMethod) error {
+	m.ID = ""
+	res, err := r.collection.InsertOne(ctx, m)
+	if err != nil {
+		return err
+	}
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		m.ID = oid.Hex()
+	}
+	return nil
+}
+
+func (r *MongoPaymentMethodRepository) FindByID(ctx context.Context, userID, id string) (*domain.PaymentMethod, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrPaymentMethodNotFound
+	}
+	return r.findOne(ctx, bson.M{"_id": oid, "user_id": userID})
+}
+
+func (r *MongoPaymentMethodRepository) FindDefault(ctx context.Context, userID string) (*domain.PaymentMethod, error) {
+	m, err := r.findOne(ctx, bson.M{"user_id": userID, "default": true})
+	if errors.Is(err, domain.ErrPaymentMethodNotFound) {
+		return nil, nil
+	}
+	return m, err
+}
+
+func (r *MongoPaymentMethodRepository) findOne(ctx context.Context, filter bson.M) (*domain.PaymentMethod, error) {
+	var m domain.PaymentMethod
+	err := r.collection.FindOne(ctx, filter).Decode(&m)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrPaymentMethodNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *MongoPaymentMethodRepository) ListByUser(ctx context.Context, userID string) ([]*domain.PaymentMethod, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cur, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	methods := []*domain.PaymentMethod{}
+	for cur.Next(ctx) {
+		var m domain.PaymentMethod
+		if err := cur.Decode(&m); err != nil {
+			return nil, err
+		}
+		methods = append(methods, &m)
+	}
+	return methods, cur.Err()
+}
+
+// SetDefault clears the user's current default before setting the new
+// one, as the unique index allows one default per user at any time.
+func (r *MongoPaymentMethodRepository) SetDefault(ctx context.Context, userID, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrPaymentMethodNotFound
+	}
+	n, err := r.collection.CountDocuments(ctx, bson.M{"_id": oid, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrPaymentMethodNotFound
+	}
+
+	if _, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "default": true, "_id": bson.M{"$ne": oid}},
+		bson.M{"$set": bson.M{"default": false}},
+	); err != nil {
+		return err
+	}
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": oid, "user_id": userID}, bson.M{"$set": bson.M{"default": true}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrPaymentMethodNotFound
+	}
+	return nil
+}
+
+func (r *MongoPaymentMethodRepository) Delete(ctx context.Context, userID, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrPaymentMethodNotFound
+	}
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": oid, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrPaymentMethodNotFound
+	}
+	return nil
+}
+
+func (r *MongoPaymentMethodRepository) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
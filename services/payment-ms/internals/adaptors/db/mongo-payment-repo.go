@@ -2,12 +2,17 @@ package db
 
 import (
 	"context"
+	"ecom-api/pkg/softdelete"
+	"errors"
+	"fmt"
+	"time"
 	"payment-microservice/internals/domain"
 	//"payment-ms/internal/domain"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MongoPaymentRepository struct {
@@ -18,8 +23,23 @@ func NewMongoPaymentRepository(db *mongo.Database) *MongoPaymentRepository {
 	return &MongoPaymentRepository{collection: db.Collection("payments")}
 }
 
+// EnsureIndexes lets an order have one payment PENDING or COMPLETED at a
+// time, besides the part a split tender charges after the wallet's.
+func (r *MongoPaymentRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "split_with", Value: 1}},
+		Options: options.Index().SetName("order_id_active").SetUnique(true).SetPartialFilterExpression(bson.M{
+			"status": bson.M{"$in": []string{domain.StatusPending, domain.StatusCompleted}},
+		}),
+	})
+	return err
+}
+
 func (r *MongoPaymentRepository) Create(ctx context.Context, p *domain.Payment) (*domain.Payment, error) {
 	 res, err := r.collection.InsertOne(ctx, p)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("%w: order %s already has an active payment", domain.ErrOrderNotPayable, p.OrderID)
+	}
     if err != nil {
         return nil, err
     }
@@ -37,12 +57,15 @@ func (r *MongoPaymentRepository) FindByID(ctx context.Context, id string) (*doma
 		return nil, err
 	}
 	var payment domain.Payment
-	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&payment)
+	err = r.collection.FindOne(ctx, softdelete.Active(bson.M{"_id": objID})).Decode(&payment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrPaymentNotFound
+	}
 	return &payment, err
 }
 
 func (r *MongoPaymentRepository) List(ctx context.Context) ([]*domain.Payment, error) {
-	cur, err := r.collection.Find(ctx, bson.M{})
+	cur, err := r.collection.Find(ctx, softdelete.Active(nil))
 	if err != nil {
 		return nil, err
 	}
@@ -65,10 +88,13 @@ func (r *MongoPaymentRepository) UpdateStatus(ctx context.Context, id string, st
         return nil, err
     }
 
-    _, err = r.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"status": status}})
+    res, err := r.collection.UpdateOne(ctx, softdelete.Active(bson.M{"_id": objID}), bson.M{"$set": bson.M{"status": status}})
     if err != nil {
         return nil, err
     }
+    if res.MatchedCount == 0 {
+        return nil, domain.ErrPaymentNotFound
+    }
 
     var updated domain.Payment
     err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&updated)
@@ -80,11 +106,151 @@ func (r *MongoPaymentRepository) UpdateStatus(ctx context.Context, id string, st
 }
 
 
-func (r *MongoPaymentRepository) Delete(ctx context.Context, id string) error {
+func (r *MongoPaymentRepository) Delete(ctx context.Context, id, deletedBy string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objID})
-	return err
+	res, err := r.collection.UpdateOne(ctx, softdelete.Active(bson.M{"_id": objID}), softdelete.MarkDeleted(deletedBy, time.Now()))
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrPaymentNotFound
+	}
+	return nil
+}
+
+func (r *MongoPaymentRepository) Restore(ctx context.Context, id string) (*domain.Payment, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.collection.UpdateOne(ctx, softdelete.Deleted(bson.M{"_id": objID}), softdelete.Restore())
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		return nil, domain.ErrPaymentNotFound
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *MongoPaymentRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return softdelete.Purge(ctx, r.collection, before)
+}
+
+// FindByOrder includes deleted payments, so cancelling an order still
+// reverses them.
+func (r *MongoPaymentRepository) FindByOrder(ctx context.Context, orderID string) ([]*domain.Payment, error) {
+	cur, err := r.collection.Find(ctx, bson.M{"order_id": orderID})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var payments []*domain.Payment
+	for cur.Next(ctx) {
+		var p domain.Payment
+		if err := cur.Decode(&p); err != nil {
+			return nil, err
+		}
+		payments = append(payments, &p)
+	}
+	return payments, cur.Err()
+}
+
+func (r *MongoPaymentRepository) FindByIDs(ctx context.Context, ids []string) ([]*domain.Payment, error) {
+	objIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+	if len(objIDs) == 0 {
+		return nil, nil
+	}
+
+	cur, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": objIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var payments []*domain.Payment
+	for cur.Next(ctx) {
+		var p domain.Payment
+		if err := cur.Decode(&p); err != nil {
+			return nil, err
+		}
+		payments = append(payments, &p)
+	}
+	return payments, cur.Err()
+}
+
+func (r *MongoPaymentRepository) Reverse(ctx context.Context, id, from, to string, rev *domain.Reversal) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objID, "status": from},
+		bson.M{"$set": bson.M{"status": to, "reversal": rev}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *MongoPaymentRepository) AddRefund(ctx context.Context, id string, refunds int, refund domain.Refund, full bool) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bson.M{"_id": objID, "status": domain.StatusCompleted, "refunds.reference": bson.M{"$ne": refund.Reference}}
+	if refunds == 0 {
+		filter["refunds"] = bson.M{"$in": bson.A{nil, bson.A{}}}
+	} else {
+		filter["refunds"] = bson.M{"$size": refunds}
+	}
+
+	update := bson.M{"$push": bson.M{"refunds": refund}}
+	if full {
+		update["$set"] = bson.M{"status": domain.StatusRefunded}
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *MongoPaymentRepository) Settle(ctx context.Context, id, from, to string) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objID, "status": from},
+		bson.M{"$set": bson.M{"status": to}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *MongoPaymentRepository) AnonymizeUser(ctx context.Context, userID, anonymousID string) error {
+	for _, field := range []string{"user_id", "deleted_by"} {
+		if _, err := r.collection.UpdateMany(ctx, bson.M{field: userID}, bson.M{"$set": bson.M{field: anonymousID}}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
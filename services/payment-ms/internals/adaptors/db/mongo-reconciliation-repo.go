@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"payment-microservice/internals/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// claimBatchSize is how many settled movements are claimed per insert.
+	claimBatchSize   = 1000
+	duplicateKeyCode = 11000
+)
+
+// MongoReconciliationRepository stores reconciliations, one per report
+// checksum, and a claim per settled payment movement keyed by the
+// movement, which makes the _id index find movements settled twice.
+type MongoReconciliationRepository struct {
+	collection *mongo.Collection
+	claims     *mongo.Collection
+}
+
+func NewMongoReconciliationRepository(db *mongo.Database) *MongoReconciliationRepository {
+	return &MongoReconciliationRepository{
+		collection: db.Collection("reconciliations"),
+		claims:     db.Collection("settlement_claims"),
+	}
+}
+
+func (r *MongoReconciliationRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "checksum", Value: 1}}, Options: options.Index().SetName("checksum").SetUnique(true)},
+		{Keys: bson.D{{Key: "reconciled_at", Value: -1}}, Options: options.Index().SetName("reconciled_at")},
+	})
+	return err
+}
+
+func (r *MongoReconciliationRepository) Create(ctx context.Context, rec *domain.Reconciliation) (bool, error) {
+	rec.ID = ""
+	res, err := r.collection.InsertOne(ctx, rec)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		rec.ID = oid.Hex()
+	}
+	return true, nil
+}
+
+func (r *MongoReconciliationRepository) FindByID(ctx context.Context, id string) (*domain.Reconciliation, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrReconciliationNotFound
+	}
+	return r.findOne(ctx, bson.M{"_id": oid})
+}
+
+func (r *MongoReconciliationRepository) FindByChecksum(ctx context.Context, checksum string) (*domain.Reconciliation, error) {
+	return r.findOne(ctx, bson.M{"checksum": checksum})
+}
+
+func (r *MongoReconciliationRepository) List(ctx context.Context, limit int64) ([]*domain.Reconciliation, error) {
+	opts := options.Find().SetSort(bson.M{"reconciled_at": -1}).SetLimit(limit).SetProjection(bson.M{"discrepancies": 0})
+	cur, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	recs := []*domain.Reconciliation{}
+	for cur.Next(ctx) {
+		var rec domain.Reconciliation
+		if err := cur.Decode(&rec); err != nil {
+			return nil, err
+		}
+		recs = append(recs, &rec)
+	}
+	return recs, cur.Err()
+}
+
+func (r *MongoReconciliationRepository) Claim(ctx context.Context, checksum, source string, keys []string) (map[string]string, error) {
+	var taken []string
+	now := time.Now()
+	for start := 0; start < len(keys); start += claimBatchSize {
+		batch := keys[start:min(start+claimBatchSize, len(keys))]
+		docs := make([]interface{}, len(batch))
+		for i, key := range batch {
+			docs[i] = bson.M{"_id": key, "checksum": checksum, "source": source, "claimed_at": now}
+		}
+
+		_, err := r.claims.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+			for _, we := range bulkErr.WriteErrors {
+				if we.Code != duplicateKeyCode {
+					return nil, err
+				}
+				taken = append(taken, batch[we.Index])
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	earlier := map[string]string{}
+	if len(taken) == 0 {
+		return earlier, nil
+	}
+	// claims of this report are its own, from an earlier attempt
+	cur, err := r.claims.Find(ctx, bson.M{"_id": bson.M{"$in": taken}, "checksum": bson.M{"$ne": checksum}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var claim struct {
+			Key    string `bson:"_id"`
+			Source string `bson:"source"`
+		}
+		if err := cur.Decode(&claim); err != nil {
+			return nil, err
+		}
+		earlier[claim.Key] = claim.Source
+	}
+	return earlier, cur.Err()
+}
+
+func (r *MongoReconciliationRepository) findOne(ctx context.Context, filter bson.M) (*domain.Reconciliation, error) {
+	var rec domain.Reconciliation
+	err := r.collection.FindOne(ctx, filter).Decode(&rec)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrReconciliationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
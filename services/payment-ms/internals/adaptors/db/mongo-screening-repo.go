@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"payment-microservice/internals/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// velocityFields maps velocity rule kinds to the field they count by.
+var velocityFields = map[string]string{
+	domain.RuleUserVelocity: "user_id",
+	domain.RuleCardVelocity: "card_fingerprint",
+	domain.RuleIPVelocity:   "ip",
+}
+
+type MongoScreeningRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoScreeningRepository(db *mongo.Database) *MongoScreeningRepository {
+	return &MongoScreeningRepository{collection: db.Collection("fraud_screenings")}
+}
+
+// EnsureIndexes creates the indexes velocity rules count with and the
+// review queue reads.
+func (r *MongoScreeningRepository) EnsureIndexes(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "review_status", Value: 1}, {Key: "screened_at", Value: 1}}, Options: options.Index().SetName("review_status_screened_at")},
+		{Keys: bson.D{{Key: "screened_at", Value: -1}}, Options: options.Index().SetName("screened_at")},
+	}
+	for _, field := range velocityFields {
+		models = append(models, mongo.IndexModel{
+			Keys:    bson.D{{Key: field, Value: 1}, {Key: "screened_at", Value: -1}},
+			Options: options.Index().SetName(field + "_screened_at"),
+		})
+	}
+	_, err := r.collection.Indexes().CreateMany(ctx, models)
+	return err
+}
+
+func (r *MongoScreeningRepository) Create(ctx context.Context, s *domain.Screening) error {
+	s.ID = ""
+	res, err := r.collection.InsertOne(ctx, s)
+	if err != nil {
+		return err
+	}
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		s.ID = oid.Hex()
+	}
+	return nil
+}
+
+func (r *MongoScreeningRepository) FindByID(ctx context.Context, id string) (*domain.Screening, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrScreeningNotFound
+	}
+	var s domain.Screening
+	err = r.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrScreeningNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *MongoScreeningRepository) List(ctx context.Context, f domain.ScreeningFilter, limit int64) ([]*domain.Screening, error) {
+	filter := bson.M{}
+	if f.Action != "" {
+		filter["action"] = f.Action
+	}
+	if f.ReviewStatus != "" {
+		filter["review_status"] = f.ReviewStatus
+	}
+	if f.UserID != "" {
+		filter["user_id"] = f.UserID
+	}
+	// the review queue is worked oldest first
+	order := -1
+	if f.ReviewStatus == domain.ReviewPending {
+		order = 1
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "screened_at", Value: order}}).SetLimit(limit)
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	screenings := []*domain.Screening{}
+	for cur.Next(ctx) {
+		var s domain.Screening
+		if err := cur.Decode(&s); err != nil {
+			return nil, err
+		}
+		screenings = append(screenings, &s)
+	}
+	return screenings, cur.Err()
+}
+
+func (r *MongoScreeningRepository) CountSince(ctx context.Context, kind, value string, since time.Time) (int64, error) {
+	field, ok := velocityFields[kind]
+	if !ok {
+		return 0, fmt.Errorf("%s is not a velocity rule", kind)
+	}
+	return r.collection.CountDocuments(ctx, bson.M{field: value, "screened_at": bson.M{"$gte": since}})
+}
+
+func (r *MongoScreeningRepository) Review(ctx context.Context, id, status, reviewer, note string, at time.Time) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, domain.ErrScreeningNotFound
+	}
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": oid, "review_status": domain.ReviewPending},
+		bson.M{"$set": bson.M{"review_status": status, "reviewed_by": reviewer, "review_note": note, "reviewed_at": at}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *MongoScreeningRepository) AnonymizeUser(ctx context.Context, userID, anonymousID string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID},
+		bson.M{"$set": bson.M{"user_id": anonymousID}, "$unset": bson.M{"ip": ""}},
+	)
+	return err
+}
@@ -0,0 +1,256 @@
+package db
+
+import (
+	"context"
+	"ecom-api/pkg/money"
+	"errors"
+	"fmt"
+
+	"payment-microservice/internals/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoWalletRepository keeps one store credit document per user and
+// currency, the gift cards, and the transactions that moved either.
+type MongoWalletRepository struct {
+	credit       *mongo.Collection
+	giftCards    *mongo.Collection
+	transactions *mongo.Collection
+}
+
+func NewMongoWalletRepository(db *mongo.Database) *MongoWalletRepository {
+	return &MongoWalletRepository{
+		credit:       db.Collection("store_credit"),
+		giftCards:    db.Collection("gift_cards"),
+		transactions: db.Collection("wallet_transactions"),
+	}
+}
+
+// storeCredit is a user's store credit in one currency.
+type storeCredit struct {
+	UserID  string      `bson:"user_id"`
+	Balance money.Money `bson:"balance"`
+	Applied []string    `bson:"applied,omitempty"` // keys of the credits added
+}
+
+func (r *MongoWalletRepository) EnsureIndexes(ctx context.Context) error {
+	if _, err := r.credit.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "balance.currency", Value: 1}},
+		Options: options.Index().SetName("user_id_currency").SetUnique(true),
+	}); err != nil {
+		return err
+	}
+	if _, err := r.giftCards.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "code", Value: 1}}, Options: options.Index().SetName("code").SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "issued_at", Value: -1}}, Options: options.Index().SetName("user_id_issued_at")},
+	}); err != nil {
+		return err
+	}
+	_, err := r.transactions.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetName("key").SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}, Options: options.Index().SetName("user_id_created_at")},
+	})
+	return err
+}
+
+func (r *MongoWalletRepository) Credit(ctx context.Context, userID string) ([]money.Money, error) {
+	cur, err := r.credit.Find(ctx, bson.M{"user_id": userID}, options.Find().
+		SetSort(bson.D{{Key: "balance.currency", Value: 1}}).
+		SetProjection(bson.M{"applied": 0}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	balances := []money.Money{}
+	for cur.Next(ctx) {
+		var c storeCredit
+		if err := cur.Decode(&c); err != nil {
+			return nil, err
+		}
+		balances = append(balances, c.Balance)
+	}
+	return balances, cur.Err()
+}
+
+// AddCredit adds to the balance first, pushing t's key onto the balance
+// document in the same write, so a retry with the same key never credits
+// twice even when recording the transaction failed. The transaction is
+// recorded after; a retry records it if it is still missing.
+func (r *MongoWalletRepository) AddCredit(ctx context.Context, t *domain.WalletTransaction) (bool, error) {
+	filter := bson.M{"user_id": t.UserID, "balance.currency": t.Amount.Currency, "applied": bson.M{"$ne": t.Key}}
+	update := bson.M{"$inc": bson.M{"balance.amount": t.Amount.Amount}, "$push": bson.M{"applied": t.Key}}
+	_, err := r.credit.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// the balance document exists, either with t's key or because a
+		// concurrent credit created it; without upsert the key decides
+		_, err = r.credit.UpdateOne(ctx, filter, update)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to credit wallet of user %s: %w", t.UserID, err)
+	}
+	ok, err := r.record(ctx, t)
+	if err != nil {
+		return false, fmt.Errorf("failed to record wallet transaction %s: %w", t.Key, err)
+	}
+	return ok, nil
+}
+
+func (r *MongoWalletRepository) SpendCredit(ctx context.Context, t *domain.WalletTransaction) (bool, error) {
+	spent := -t.Amount.Amount
+	res, err := r.credit.UpdateOne(ctx,
+		bson.M{"user_id": t.UserID, "balance.currency": t.Amount.Currency, "balance.amount": bson.M{"$gte": spent}},
+		bson.M{"$inc": bson.M{"balance.amount": -spent}},
+	)
+	if err != nil {
+		return false, err
+	}
+	if res.MatchedCount == 0 {
+		return false, nil
+	}
+	_, err = r.record(ctx, t)
+	return true, err
+}
+
+func (r *MongoWalletRepository) CreateGiftCard(ctx context.Context, g *domain.GiftCard) error {
+	g.ID = ""
+	res, err := r.giftCards.InsertOne(ctx, g)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: code %s is taken", domain.ErrInvalidGiftCard, g.Code)
+	}
+	if err != nil {
+		return err
+	}
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		g.ID = oid.Hex()
+	}
+	return nil
+}
+
+func (r *MongoWalletRepository) FindGiftCard(ctx context.Context, code string) (*domain.GiftCard, error) {
+	var g domain.GiftCard
+	err := r.giftCards.FindOne(ctx, bson.M{"code": code}).Decode(&g)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrGiftCardNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *MongoWalletRepository) ListGiftCards(ctx context.Context, userID string, limit int64) ([]*domain.GiftCard, error) {
+	filter := bson.M{}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "issued_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cur, err := r.giftCards.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	cards := []*domain.GiftCard{}
+	for cur.Next(ctx) {
+		var g domain.GiftCard
+		if err := cur.Decode(&g); err != nil {
+			return nil, err
+		}
+		cards = append(cards, &g)
+	}
+	return cards, cur.Err()
+}
+
+func (r *MongoWalletRepository) ClaimGiftCard(ctx context.Context, id, userID string) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, domain.ErrGiftCardNotFound
+	}
+	res, err := r.giftCards.UpdateOne(ctx,
+		bson.M{"_id": oid, "$or": bson.A{bson.M{"user_id": bson.M{"$exists": false}}, bson.M{"user_id": userID}}},
+		bson.M{"$set": bson.M{"user_id": userID}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (r *MongoWalletRepository) SpendGiftCard(ctx context.Context, id string, red domain.GiftCardRedemption, t *domain.WalletTransaction) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, domain.ErrGiftCardNotFound
+	}
+	res, err := r.giftCards.UpdateOne(ctx,
+		bson.M{
+			"_id":              oid,
+			"balance.currency": red.Amount.Currency,
+			"balance.amount":   bson.M{"$gte": red.Amount.Amount},
+			"$or":              bson.A{bson.M{"expires_at": bson.M{"$exists": false}}, bson.M{"expires_at": bson.M{"$gt": red.RedeemedAt}}},
+		},
+		bson.M{
+			"$inc":  bson.M{"balance.amount": -red.Amount.Amount},
+			"$push": bson.M{"redemptions": red},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	if res.MatchedCount == 0 {
+		return false, nil
+	}
+	_, err = r.record(ctx, t)
+	return true, err
+}
+
+func (r *MongoWalletRepository) ListTransactions(ctx context.Context, userID string, limit int64) ([]*domain.WalletTransaction, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	cur, err := r.transactions.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	txns := []*domain.WalletTransaction{}
+	for cur.Next(ctx) {
+		var t domain.WalletTransaction
+		if err := cur.Decode(&t); err != nil {
+			return nil, err
+		}
+		txns = append(txns, &t)
+	}
+	return txns, cur.Err()
+}
+
+func (r *MongoWalletRepository) AnonymizeUser(ctx context.Context, userID, anonymousID string) error {
+	for _, c := range []*mongo.Collection{r.credit, r.giftCards, r.transactions} {
+		if _, err := c.UpdateMany(ctx, bson.M{"user_id": userID}, bson.M{"$set": bson.M{"user_id": anonymousID}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record stores a transaction; it reports false if its key was taken.
+func (r *MongoWalletRepository) record(ctx context.Context, t *domain.WalletTransaction) (bool, error) {
+	t.ID = ""
+	res, err := r.transactions.InsertOne(ctx, t)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		t.ID = oid.Hex()
+	}
+	return true, nil
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"payment-microservice/internals/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoWebhookEventRepository stores received provider events keyed by
+// provider and event ID, which makes the _id index do the deduplication.
+type MongoWebhookEventRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoWebhookEventRepository(db *mongo.Database) *MongoWebhookEventRepository {
+	return &MongoWebhookEventRepository{collection: db.Collection("webhook_events")}
+}
+
+// EnsureIndexes creates the index the replay of failed events reads.
+func (r *MongoWebhookEventRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "status", Value: 1}, {Key: "received_at", Value: 1}},
+		Options: options.Index().SetName("status_received_at"),
+	})
+	return err
+}
+
+func (r *MongoWebhookEventRepository) Create(ctx context.Context, e *domain.WebhookEvent) (bool, error) {
+	_, err := r.collection.InsertOne(ctx, e)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *MongoWebhookEventRepository) FindByID(ctx context.Context, id string) (*domain.WebhookEvent, error) {
+	var e domain.WebhookEvent
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&e)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrWebhookEventNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *MongoWebhookEventRepository) List(ctx context.Context, status string, limit int64) ([]*domain.WebhookEvent, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.M{"received_at": -1}).SetLimit(limit).SetProjection(bson.M{"payload": 0})
+	return r.find(ctx, filter, opts)
+}
+
+func (r *MongoWebhookEventRepository) FindReplayable(ctx context.Context, maxAttempts int, stuckBefore time.Time, limit int64) ([]*domain.WebhookEvent, error) {
+	filter := bson.M{
+		"attempts": bson.M{"$lt": maxAttempts},
+		"$or": bson.A{
+			bson.M{"status": domain.WebhookFailed},
+			// received long ago means handling it was interrupted
+			bson.M{"status": domain.WebhookReceived, "received_at": bson.M{"$lt": stuckBefore}},
+		},
+	}
+	opts := options.Find().SetSort(bson.M{"received_at": 1}).SetLimit(limit)
+	return r.find(ctx, filter, opts)
+}
+
+func (r *MongoWebhookEventRepository) MarkProcessed(ctx context.Context, id string) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set":   bson.M{"status": domain.WebhookProcessed, "processed_at": now},
+		"$unset": bson.M{"last_error": ""},
+		"$inc":   bson.M{"attempts": 1},
+	})
+	return err
+}
+
+func (r *MongoWebhookEventRepository) MarkFailed(ctx context.Context, id, reason string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"status": domain.WebhookFailed, "last_error": reason},
+		"$inc": bson.M{"attempts": 1},
+	})
+	return err
+}
+
+func (r *MongoWebhookEventRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*domain.WebhookEvent, error) {
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var events []*domain.WebhookEvent
+	for cur.Next(ctx) {
+		var e domain.WebhookEvent
+		if err := cur.Decode(&e); err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+	return events, cur.Err()
+}
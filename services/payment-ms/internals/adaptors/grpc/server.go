@@ -2,10 +2,12 @@ package grpc
 
 import (
 	"context"
+	"ecom-api/pkg/money"
 	"fmt"
 	"payment-microservice/adaptors/grpc/pb/payment-microservice/services/payment-ms/adaptors/grpc/pb"
 	"payment-microservice/internals/domain"
 	"payment-microservice/internals/ports"
+	"time"
 )
 
 type PaymentGrpcServer struct {
@@ -19,27 +21,27 @@ func NewPaymentGrpcServer(service ports.PaymentService) *PaymentGrpcServer{
 
 func (s *PaymentGrpcServer) ProcessPayment(ctx context.Context, req *pb.ProcessPaymentRequest) (*pb.ProcessPaymentResponse, error) {
 	payment := &domain.Payment{
-		OrderID: req.GetOrderId(),
-		UserID:  req.GetUserId(),
-		Amount:  req.GetAmount(),
-		Status:  "PENDING",
-		//Method:  req.GetMethod(),
+		OrderID:  req.GetOrderId(),
+		UserID:   req.GetUserId(),
+		Amount:   money.FromProto(req.GetAmount()),
+		Status:   "PENDING",
+		Method:   req.GetMethod(),
+		MethodID: req.GetMethodId(),
 	}
 
-	createdPayment, err := s.service.ProcessPayment(ctx, payment)
+	tender := domain.Tender{UseWallet: req.GetUseWallet()}
+	if req.GetWalletAmount() != nil {
+		amount := money.FromProto(req.GetWalletAmount())
+		tender.WalletAmount = &amount
+	}
+
+	createdPayment, err := s.service.ProcessPayment(ctx, payment, tender, domain.ClientInfo{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to process payment: %w", err)
 	}
 
 	return &pb.ProcessPaymentResponse{
-		Payment: &pb.Payment{
-			Id:      createdPayment.ID,
-			OrderId: createdPayment.OrderID,
-			UserId:  createdPayment.UserID,
-			Amount:  createdPayment.Amount,
-			Status:  createdPayment.Status,
-			//Method:  createdPayment.Method,
-		},
+		Payment: toProto(createdPayment),
 	}, nil
 }
 
@@ -50,14 +52,7 @@ func (s *PaymentGrpcServer) GetPayment(ctx context.Context, req *pb.GetPaymentRe
 	}
 
 	return &pb.GetPaymentResponse{
-		Payment: &pb.Payment{
-			Id:      payment.ID,
-			OrderId: payment.OrderID,
-			UserId:  payment.UserID,
-			Amount:  payment.Amount,
-			Status:  payment.Status,
-			//Method:  payment.Method,
-		},
+		Payment: toProto(payment),
 	}, nil
 }
 
@@ -69,14 +64,7 @@ func (s *PaymentGrpcServer) ListPayments(ctx context.Context, req *pb.ListPaymen
 
 	var pbPayments []*pb.Payment
 	for _, p := range payments {
-		pbPayments = append(pbPayments, &pb.Payment{
-			Id:      p.ID,
-			OrderId: p.OrderID,
-			UserId:  p.UserID,
-			Amount:  p.Amount,
-			Status:  p.Status,
-			//Method:  p.Method,
-		})
+		pbPayments = append(pbPayments, toProto(p))
 	}
 
 	return &pb.ListPaymentsResponse{Payments: pbPayments}, nil
@@ -89,14 +77,7 @@ func (s *PaymentGrpcServer) UpdatePaymentStatus(ctx context.Context, req *pb.Upd
 	}
 
 	return &pb.UpdatePaymentStatusResponse{
-		Payment: &pb.Payment{
-			Id:      updatedPayment.ID,
-			OrderId: updatedPayment.OrderID,
-			UserId:  updatedPayment.UserID,
-			Amount:  updatedPayment.Amount,
-			Status:  updatedPayment.Status,
-			//Method:  updatedPayment.Method,
-		},
+		Payment: toProto(updatedPayment),
 	}, nil
 }
 
@@ -105,7 +86,7 @@ func (s *PaymentGrpcServer) UpdatePaymentStatus(ctx context.Context, req *pb.Upd
 
 // DeletePayment deletes a payment by ID
 func (s *PaymentGrpcServer) DeletePayment(ctx context.Context, req *pb.DeletePaymentRequest) (*pb.DeletePaymentResponse, error) {
-	if err := s.service.DeletePayment(ctx, req.GetId()); err != nil {
+	if err := s.service.DeletePayment(ctx, req.GetId(), ""); err != nil {
 		return nil, fmt.Errorf("failed to delete payment: %w", err)
 	}
 
@@ -131,3 +112,86 @@ func (s *PaymentGrpcServer) NotifyOrderCreated(ctx context.Context, req *pb.Noti
         Message: fmt.Sprintf("Payment record initialized for order %s", orderID),
     }, nil
 }
+
+// CancelOrderPayment reverses the payments of a cancelled order.
+func (s *PaymentGrpcServer) CancelOrderPayment(ctx context.Context, req *pb.CancelOrderPaymentRequest) (*pb.CancelOrderPaymentResponse, error) {
+	if req.GetOrderId() == "" {
+		return nil, fmt.Errorf("order_id is required")
+	}
+
+	rev, err := s.service.CancelOrderPayment(ctx, req.GetOrderId(), req.GetReason())
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel payment of order %s: %w", req.GetOrderId(), err)
+	}
+
+	resp := &pb.CancelOrderPaymentResponse{Action: rev.Action}
+	if rev.Refunded.Currency != "" {
+		resp.Refunded = money.ToProto(rev.Refunded)
+	}
+	for _, p := range rev.Payments {
+		resp.Payments = append(resp.Payments, toProto(p))
+	}
+	return resp, nil
+}
+
+// RefundPayment refunds part of the payments of an order, e.g. for a return.
+func (s *PaymentGrpcServer) RefundPayment(ctx context.Context, req *pb.RefundPaymentRequest) (*pb.RefundPaymentResponse, error) {
+	if req.GetOrderId() == "" || req.GetAmount() == nil {
+		return nil, fmt.Errorf("order_id and amount are required")
+	}
+
+	refund, err := s.service.RefundOrderPayment(ctx, req.GetOrderId(), req.GetReference(), money.FromProto(req.GetAmount()), req.GetReason(), req.GetToStoreCredit())
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund payment of order %s: %w", req.GetOrderId(), err)
+	}
+
+	resp := &pb.RefundPaymentResponse{Refunded: money.ToProto(refund.Refunded)}
+	for _, p := range refund.Payments {
+		resp.Payments = append(resp.Payments, toProto(p))
+	}
+	return resp, nil
+}
+
+// AnonymizeUser is called by user-ms when an account is deleted.
+func (s *PaymentGrpcServer) AnonymizeUser(ctx context.Context, req *pb.AnonymizeUserRequest) (*pb.AnonymizeUserResponse, error) {
+	if req.GetUserId() == "" || req.GetAnonymousId() == "" {
+		return nil, fmt.Errorf("user_id and anonymous_id are required")
+	}
+	if err := s.service.AnonymizeUser(ctx, req.GetUserId(), req.GetAnonymousId()); err != nil {
+		return nil, fmt.Errorf("failed to anonymize user %s: %w", req.GetUserId(), err)
+	}
+	return &pb.AnonymizeUserResponse{}, nil
+}
+
+// helper to convert domain → proto
+func toProto(p *domain.Payment) *pb.Payment {
+	out := &pb.Payment{
+		Id:        p.ID,
+		OrderId:   p.OrderID,
+		UserId:    p.UserID,
+		Amount:    money.ToProto(p.Amount),
+		Status:    p.Status,
+		Method:    p.Method,
+		MethodId:  p.MethodID,
+		SplitWith: p.SplitWith,
+	}
+	if r := p.Reversal; r != nil {
+		out.Reversal = &pb.Reversal{
+			Kind:       r.Kind,
+			Amount:     money.ToProto(r.Amount),
+			Reason:     r.Reason,
+			ReversedAt: r.ReversedAt.Format(time.RFC3339),
+			To:         r.To,
+		}
+	}
+	for _, r := range p.Refunds {
+		out.Refunds = append(out.Refunds, &pb.Refund{
+			Reference:  r.Reference,
+			Amount:     money.ToProto(r.Amount),
+			Reason:     r.Reason,
+			RefundedAt: r.RefundedAt.Format(time.RFC3339),
+			To:         r.To,
+		})
+	}
+	return out
+}
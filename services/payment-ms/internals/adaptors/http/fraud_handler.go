@@ -0,0 +1,263 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ecom-api/pkg/middleware"
+	"payment-microservice/internals/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// clientCountryHeader carries the country the edge proxy geolocated the
+// client IP to. The proxy must overwrite it on every request.
+const clientCountryHeader = "X-Client-Country"
+
+// ReviewDecision is an admin's decision on a payment held for review.
+type ReviewDecision struct {
+	Note string `json:"note,omitempty" example:"customer confirmed the order by phone"`
+}
+
+// clientInfo returns where a request comes from. Forwarding headers are
+// only believed when the peer is one of the trusted proxies: the client is
+// then the last X-Forwarded-For hop that is not a trusted proxy, since any
+// hop before it may have been written by the client itself. The country
+// header is only believed from a trusted proxy too, which sets it.
+func clientInfo(r *http.Request, trusted []*net.IPNet) domain.ClientInfo {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !isTrusted(peer, trusted) {
+		return domain.ClientInfo{IP: peer}
+	}
+
+	ip := peer
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !isTrusted(hop, trusted) {
+			break
+		}
+	}
+	return domain.ClientInfo{
+		IP:      ip,
+		Country: strings.ToUpper(strings.TrimSpace(r.Header.Get(clientCountryHeader))),
+	}
+}
+
+func isTrusted(ip string, trusted []*net.IPNet) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, n := range trusted {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies reads the proxies in front of the service from a
+// list of addresses or CIDR ranges such as "10.0.0.0/8,192.168.1.10".
+func ParseTrustedProxies(list string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// @Summary      Get Fraud Rules
+// @Description  Get the rules payments are screened with and the score thresholds for review and denial (admin only).
+// @Tags         Fraud
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  domain.FraudConfig
+// @Failure      403  {object}  map[string]string
+// @Router       /fraud/rules [get]
+func (h *PaymentHandler) GetFraudRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.FraudRules())
+}
+
+// @Summary      List Review Queue
+// @Description  List the payments held for review, oldest first (admin only).
+// @Tags         Fraud
+// @Produce      json
+// @Security     BearerAuth
+// @Param        limit  query  int  false  "Maximum number of reviews (default 50)"
+// @Success      200  {array}   domain.Screening
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /fraud/reviews [get]
+func (h *PaymentHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	h.listScreenings(w, r, domain.ScreeningFilter{ReviewStatus: domain.ReviewPending})
+}
+
+// @Summary      List Fraud Screenings
+// @Description  List fraud screenings of payment attempts, newest first (admin only).
+// @Tags         Fraud
+// @Produce      json
+// @Security     BearerAuth
+// @Param        action         query  string  false  "allow, review or deny"
+// @Param        review_status  query  string  false  "pending, approved or rejected"
+// @Param        user_id        query  string  false  "Only screenings of this user"
+// @Param        limit          query  int     false  "Maximum number of screenings (default 50)"
+// @Success      200  {array}   domain.Screening
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /fraud/screenings [get]
+func (h *PaymentHandler) ListScreenings(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	h.listScreenings(w, r, domain.ScreeningFilter{
+		Action:       q.Get("action"),
+		ReviewStatus: q.Get("review_status"),
+		UserID:       q.Get("user_id"),
+	})
+}
+
+func (h *PaymentHandler) listScreenings(w http.ResponseWriter, r *http.Request, filter domain.ScreeningFilter) {
+	limit := int64(50)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error": "invalid limit"}`, http.StatusBadRequest)
+			return
+		}
+		limit = min(n, 500)
+	}
+
+	screenings, err := h.service.ListScreenings(r.Context(), filter, limit)
+	if err != nil {
+		writeFraudError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(screenings)
+}
+
+// @Summary      Get Fraud Screening
+// @Description  Get a fraud screening with the rules the payment attempt matched (admin only).
+// @Tags         Fraud
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Screening ID"
+// @Success      200  {object}  domain.Screening
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /fraud/screenings/{id} [get]
+func (h *PaymentHandler) GetScreening(w http.ResponseWriter, r *http.Request) {
+	screening, err := h.service.GetScreening(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeFraudError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(screening)
+}
+
+// @Summary      Approve Review
+// @Description  Approve a payment held for review and charge it (admin only).
+// @Tags         Fraud
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path  string          true   "Screening ID"
+// @Param        decision  body  ReviewDecision  false  "Note on the decision"
+// @Success      200  {object}  domain.Payment
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /fraud/reviews/{id}/approve [post]
+func (h *PaymentHandler) ApproveReview(w http.ResponseWriter, r *http.Request) {
+	h.decideReview(w, r, true)
+}
+
+// @Summary      Reject Review
+// @Description  Reject a payment held for review; it fails and its order is told so (admin only).
+// @Tags         Fraud
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path  string          true   "Screening ID"
+// @Param        decision  body  ReviewDecision  false  "Note on the decision"
+// @Success      200  {object}  domain.Payment
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /fraud/reviews/{id}/reject [post]
+func (h *PaymentHandler) RejectReview(w http.ResponseWriter, r *http.Request) {
+	h.decideReview(w, r, false)
+}
+
+func (h *PaymentHandler) decideReview(w http.ResponseWriter, r *http.Request, approve bool) {
+	var req ReviewDecision
+	json.NewDecoder(r.Body).Decode(&req) // the note is optional
+	reviewer, _ := middleware.FromContext(r.Context())
+
+	decide := h.service.RejectReview
+	if approve {
+		decide = h.service.ApproveReview
+	}
+	payment, err := decide(r.Context(), chi.URLParam(r, "id"), reviewer, req.Note)
+	if err != nil {
+		writeFraudError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payment)
+}
+
+func writeFraudError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrScreeningNotFound), errors.Is(err, domain.ErrPaymentNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrNotUnderReview), errors.Is(err, domain.ErrPaymentStateConflict):
+		status = http.StatusConflict
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -2,9 +2,13 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 
 	"ecom-api/pkg/middleware"
+	"ecom-api/pkg/money"
 	"payment-microservice/internals/domain"
 	"payment-microservice/internals/ports"
 
@@ -31,29 +35,48 @@ import (
 type PaymentHandler struct {
 	service ports.PaymentService
 	orderClient ports.OrderClient
+	reconciler ports.ReconciliationService
+	trustedProxies []*net.IPNet
 }
 
-func NewPaymentHandler(s ports.PaymentService, oc ports.OrderClient) *PaymentHandler {
-	return &PaymentHandler{service: s, orderClient: oc}
+func NewPaymentHandler(s ports.PaymentService, oc ports.OrderClient, rc ports.ReconciliationService, trustedProxies []*net.IPNet) *PaymentHandler {
+	return &PaymentHandler{service: s, orderClient: oc, reconciler: rc, trustedProxies: trustedProxies}
 }
 
 
 
 
+// CreatePaymentRequest optionally names the saved payment method to charge.
+// Without one, the caller's default method is charged. Cards are screened
+// on the details the provider reported when the method was saved.
+// With use_wallet set, the caller's store credit and gift cards pay first,
+// up to wallet_amount if given, and the method is charged the rest.
+type CreatePaymentRequest struct {
+	MethodID     string `json:"method_id,omitempty" example:"665f1c2e8b3e4a0012ab34cd"`
+	UseWallet    bool   `json:"use_wallet,omitempty"`
+	WalletAmount string `json:"wallet_amount,omitempty" example:"25.00"`
+}
+
 // @Summary      Create Payment
-// @Description  Create a payment for a given order (requires Bearer token).
+// @Description  Create a payment for one of the caller's orders (requires Bearer token). It charges the saved payment method named by method_id or, without a method, the caller's default one. The payment is screened for fraud first: a denied payment is answered with 402 and not created, one held for review stays PENDING until an admin decides. With use_wallet, the wallet pays its part as a separate store_credit payment named by split_with; when it covers the whole order, that payment is returned.
 // @Tags         Payments
+// @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        order_id path string true "The ID of the order to pay for"
+// @Param        request  body CreatePaymentRequest false "Saved payment method and wallet use"
 // @Success      200  {object}  domain.Payment
 // @Failure      400  {object}  map[string]string
 // @Failure      401  {object}  map[string]string
+// @Failure      402  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /payments/{order_id} [post]
 func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 	
-	userID, _ := middleware.FromContext(r.Context())
+	userID, role := middleware.FromContext(r.Context())
 	if userID == "" {
 		http.Error(w, `{"error": "unauthorized: missing or invalid token"}`, http.StatusUnauthorized)
 		return
@@ -65,25 +88,61 @@ func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var req CreatePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
 	// 1. Fetch order from Order-MS
 	order, err := h.orderClient.GetOrder(r.Context(), orderID)
 	if err != nil {
 		http.Error(w, `{"error": "failed to fetch order: `+err.Error()+`"}`, http.StatusBadRequest)
 		return
 	}
+	if order.UserId != userID && role != "admin" {
+		http.Error(w, `{"error": "order belongs to another user"}`, http.StatusForbidden)
+		return
+	}
 
 	// 2. Build payment object from order data
 	payment := &domain.Payment{
-		OrderID: order.Id,
-		UserID:  order.UserId,
-		Amount:  order.Total,
-		Status:  "PENDING",
+		OrderID:  order.Id,
+		UserID:   order.UserId,
+		Amount:   money.FromProto(order.Total),
+		Status:   "PENDING",
+		MethodID: req.MethodID,
+	}
+
+	tender := domain.Tender{UseWallet: req.UseWallet || req.WalletAmount != ""}
+	if req.WalletAmount != "" {
+		amount, err := money.Parse(req.WalletAmount, payment.Amount.Currency)
+		if err != nil {
+			http.Error(w, `{"error": "invalid wallet_amount: `+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		tender.WalletAmount = &amount
 	}
 
-	// 3. Persist via PaymentService
-	created, err := h.service.ProcessPayment(r.Context(), payment)
+	// 3. Screen and persist via PaymentService
+	created, err := h.service.ProcessPayment(r.Context(), payment, tender, clientInfo(r, h.trustedProxies))
 	if err != nil {
-		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, domain.ErrPaymentDenied), errors.Is(err, domain.ErrInsufficientCredit):
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusPaymentRequired)
+		case errors.Is(err, domain.ErrOrderNotPayable):
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusConflict)
+		case errors.Is(err, domain.ErrInvalidTender):
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		case errors.Is(err, domain.ErrOrderNotOwned):
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusForbidden)
+		case errors.Is(err, domain.ErrPaymentMethodNotFound):
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusNotFound)
+		case errors.Is(err, domain.ErrPaymentMethodExpired):
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		default:
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -91,3 +150,29 @@ func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(created)
 		
 }
+
+// @Summary      Restore Payment
+// @Description  Restore a soft-deleted payment record (admin only).
+// @Tags         Payments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Payment ID"
+// @Success      200  {object}  domain.Payment
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /payments/{id}/restore [post]
+func (h *PaymentHandler) RestorePayment(w http.ResponseWriter, r *http.Request) {
+	payment, err := h.service.RestorePayment(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrPaymentNotFound) {
+			http.Error(w, `{"error": "no deleted payment with this id"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payment)
+}
@@ -0,0 +1,263 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ecom-api/pkg/money"
+	"payment-microservice/internals/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// LedgerAccount is an account of the chart with its balance per currency.
+type LedgerAccount struct {
+	domain.Account
+	Balances []domain.AccountBalance `json:"balances"`
+}
+
+// OrderLedger is everything the ledger holds about an order.
+type OrderLedger struct {
+	OrderID  string                  `json:"order_id"`
+	Balances []domain.AccountBalance `json:"balances"`
+	Entries  []*domain.JournalEntry  `json:"entries"`
+}
+
+type ReverseEntryRequest struct {
+	Memo string `json:"memo" example:"posted twice by mistake"`
+}
+
+type PayoutRequest struct {
+	Reference string `json:"reference" example:"po_1N2x"`
+	Amount    string `json:"amount" example:"1250.00"`
+	Currency  string `json:"currency" example:"USD"`
+	Memo      string `json:"memo,omitempty"`
+}
+
+// @Summary      List Ledger Accounts
+// @Description  List the chart of accounts with the balance of each account per currency (admin only).
+// @Tags         Ledger
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   LedgerAccount
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /ledger/accounts [get]
+func (h *PaymentHandler) ListLedgerAccounts(w http.ResponseWriter, r *http.Request) {
+	balances, err := h.service.LedgerBalances(r.Context(), domain.EntryFilter{})
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+
+	out := make([]LedgerAccount, 0, len(domain.Accounts))
+	for _, a := range domain.Accounts {
+		out = append(out, accountWithBalances(a, balances))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// @Summary      Get Ledger Account
+// @Description  Get an account with its balance per currency (admin only).
+// @Tags         Ledger
+// @Produce      json
+// @Security     BearerAuth
+// @Param        code  path  string  true  "Account code, e.g. provider_receivable"
+// @Success      200  {object}  LedgerAccount
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /ledger/accounts/{code} [get]
+func (h *PaymentHandler) GetLedgerAccount(w http.ResponseWriter, r *http.Request) {
+	account, ok := domain.FindAccount(chi.URLParam(r, "code"))
+	if !ok {
+		http.Error(w, `{"error": "account not found"}`, http.StatusNotFound)
+		return
+	}
+
+	balances, err := h.service.LedgerBalances(r.Context(), domain.EntryFilter{Account: account.Code})
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(accountWithBalances(account, balances))
+}
+
+// @Summary      Get Order Ledger
+// @Description  Get the account balances and journal entries of an order (admin only).
+// @Tags         Ledger
+// @Produce      json
+// @Security     BearerAuth
+// @Param        order_id  path  string  true  "Order ID"
+// @Success      200  {object}  OrderLedger
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /ledger/orders/{order_id} [get]
+func (h *PaymentHandler) GetOrderLedger(w http.ResponseWriter, r *http.Request) {
+	filter := domain.EntryFilter{OrderID: chi.URLParam(r, "order_id")}
+
+	balances, err := h.service.LedgerBalances(r.Context(), filter)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	entries, err := h.service.ListJournalEntries(r.Context(), filter, 500)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(OrderLedger{OrderID: filter.OrderID, Balances: balances, Entries: entries})
+}
+
+// @Summary      List Journal Entries
+// @Description  List journal entries, newest first (admin only).
+// @Tags         Ledger
+// @Produce      json
+// @Security     BearerAuth
+// @Param        order_id    query  string  false  "Only entries of this order"
+// @Param        payment_id  query  string  false  "Only entries of this payment"
+// @Param        account     query  string  false  "Only entries posting to this account"
+// @Param        limit       query  int     false  "Maximum number of entries (default 50)"
+// @Success      200  {array}   domain.JournalEntry
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /ledger/entries [get]
+func (h *PaymentHandler) ListJournalEntries(w http.ResponseWriter, r *http.Request) {
+	limit := int64(50)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error": "invalid limit"}`, http.StatusBadRequest)
+			return
+		}
+		limit = min(n, 500)
+	}
+
+	q := r.URL.Query()
+	entries, err := h.service.ListJournalEntries(r.Context(), domain.EntryFilter{
+		OrderID:   q.Get("order_id"),
+		PaymentID: q.Get("payment_id"),
+		Account:   q.Get("account"),
+	}, limit)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// @Summary      Get Journal Entry
+// @Description  Get a journal entry with its postings (admin only).
+// @Tags         Ledger
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Entry ID"
+// @Success      200  {object}  domain.JournalEntry
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /ledger/entries/{id} [get]
+func (h *PaymentHandler) GetJournalEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := h.service.GetJournalEntry(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// @Summary      Reverse Journal Entry
+// @Description  Correct a journal entry by posting one with the opposite postings (admin only). Entries are never changed; an entry is reversed at most once.
+// @Tags         Ledger
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  string               true  "Entry ID"
+// @Param        request  body  ReverseEntryRequest  true  "Why the entry is reversed"
+// @Success      201  {object}  domain.JournalEntry
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /ledger/entries/{id}/reverse [post]
+func (h *PaymentHandler) ReverseJournalEntry(w http.ResponseWriter, r *http.Request) {
+	var req ReverseEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Memo == "" {
+		http.Error(w, `{"error": "memo is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.service.ReverseJournalEntry(r.Context(), chi.URLParam(r, "id"), req.Memo)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// @Summary      Record Payout
+// @Description  Book a payout from the payment provider to the bank (admin only). The reference is the provider's payout ID; recording it again returns the first entry.
+// @Tags         Ledger
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payout  body  PayoutRequest  true  "Payout"
+// @Success      201  {object}  domain.JournalEntry
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /ledger/payouts [post]
+func (h *PaymentHandler) RecordPayout(w http.ResponseWriter, r *http.Request) {
+	var req PayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	amount, err := money.Parse(req.Amount, req.Currency)
+	if err != nil {
+		http.Error(w, `{"error": "invalid amount"}`, http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.service.RecordPayout(r.Context(), req.Reference, amount, req.Memo)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+func accountWithBalances(a domain.Account, balances []domain.AccountBalance) LedgerAccount {
+	out := LedgerAccount{Account: a, Balances: []domain.AccountBalance{}}
+	for _, b := range balances {
+		if b.Account == a.Code {
+			out.Balances = append(out.Balances, b)
+		}
+	}
+	return out
+}
+
+func writeLedgerError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrEntryNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrUnknownAccount), errors.Is(err, domain.ErrInvalidPayout), errors.Is(err, domain.ErrUnbalancedEntry):
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ecom-api/pkg/middleware"
+	"payment-microservice/internals/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SavePaymentMethodRequest is the token the provider issued for a card,
+// wallet or bank account. What the method is, and for cards the brand,
+// last four digits, expiry, country and fingerprint, is asked of the
+// provider.
+type SavePaymentMethodRequest struct {
+	Token   string `json:"token" example:"tok_visa_us_4242"`
+	Default bool   `json:"default,omitempty"`
+}
+
+// @Summary      Save Payment Method
+// @Description  Save a tokenized card, wallet or bank transfer for the caller. Its details are looked up with the payment provider. The first saved method, or one saved with default set, becomes the default method.
+// @Tags         Payment Methods
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        method  body  SavePaymentMethodRequest  true  "Provider token"
+// @Success      201  {object}  domain.PaymentMethod
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /payment-methods [post]
+func (h *PaymentHandler) SavePaymentMethod(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	var req SavePaymentMethodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	m, err := h.service.SavePaymentMethod(r.Context(), userID, req.Token, req.Default)
+	if err != nil {
+		writePaymentMethodError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(m)
+}
+
+// @Summary      List Payment Methods
+// @Description  List the caller's saved payment methods, newest first.
+// @Tags         Payment Methods
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   domain.PaymentMethod
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /payment-methods [get]
+func (h *PaymentHandler) ListPaymentMethods(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	methods, err := h.service.ListPaymentMethods(r.Context(), userID)
+	if err != nil {
+		writePaymentMethodError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(methods)
+}
+
+// @Summary      Get Payment Method
+// @Description  Get one of the caller's saved payment methods.
+// @Tags         Payment Methods
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Payment method ID"
+// @Success      200  {object}  domain.PaymentMethod
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /payment-methods/{id} [get]
+func (h *PaymentHandler) GetPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	m, err := h.service.GetPaymentMethod(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writePaymentMethodError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+// @Summary      Set Default Payment Method
+// @Description  Make one of the caller's saved payment methods the default one, which payments are charged to when they name no method.
+// @Tags         Payment Methods
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Payment method ID"
+// @Success      200  {object}  domain.PaymentMethod
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /payment-methods/{id}/default [post]
+func (h *PaymentHandler) SetDefaultPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	m, err := h.service.SetDefaultPaymentMethod(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writePaymentMethodError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+// @Summary      Delete Payment Method
+// @Description  Delete one of the caller's saved payment methods. If it was the default, the newest remaining method becomes the default.
+// @Tags         Payment Methods
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Payment method ID"
+// @Success      204
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /payment-methods/{id} [delete]
+func (h *PaymentHandler) DeletePaymentMethod(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	if err := h.service.DeletePaymentMethod(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		writePaymentMethodError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePaymentMethodError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrPaymentMethodNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidPaymentMethod), errors.Is(err, domain.ErrPaymentMethodExpired):
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
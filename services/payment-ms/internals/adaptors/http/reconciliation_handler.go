@@ -0,0 +1,140 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"payment-microservice/internals/adaptors/settlement"
+	"payment-microservice/internals/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxSettlementReport caps the size of an uploaded settlement report.
+const maxSettlementReport = 32 << 20
+
+// @Summary      Reconcile Settlement Report
+// @Description  Upload a provider settlement report in CSV and reconcile it against payments (admin only). The first row names the columns reference, amount and currency, and optionally type (charge or refund), fee, payout_id and settled_at; reference is the payment ID. A report uploaded before is answered with its first reconciliation and 200.
+// @Tags         Reconciliation
+// @Accept       text/csv
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name    query  string  false  "Name of the report, e.g. its file name"
+// @Param        report  body   string  true   "Settlement report"
+// @Success      200  {object}  domain.Reconciliation
+// @Success      201  {object}  domain.Reconciliation
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      413  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /reconciliations [post]
+func (h *PaymentHandler) ReconcileSettlement(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSettlementReport))
+	if err != nil {
+		http.Error(w, `{"error": "settlement report is too large"}`, http.StatusRequestEntityTooLarge)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "upload-" + time.Now().UTC().Format("20060102T150405Z") + ".csv"
+	}
+
+	report, err := settlement.Parse(name, data)
+	if err != nil {
+		writeReconciliationError(w, err)
+		return
+	}
+	rec, existed, err := h.reconciler.Reconcile(r.Context(), report)
+	if err != nil {
+		writeReconciliationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !existed {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(rec)
+}
+
+// @Summary      List Reconciliations
+// @Description  List the latest reconciliations with their totals, without discrepancies (admin only).
+// @Tags         Reconciliation
+// @Produce      json
+// @Security     BearerAuth
+// @Param        limit  query  int  false  "Maximum number of reconciliations (default 50)"
+// @Success      200  {array}   domain.Reconciliation
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /reconciliations [get]
+func (h *PaymentHandler) ListReconciliations(w http.ResponseWriter, r *http.Request) {
+	limit := int64(50)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error": "invalid limit"}`, http.StatusBadRequest)
+			return
+		}
+		limit = min(n, 500)
+	}
+
+	recs, err := h.reconciler.ListReconciliations(r.Context(), limit)
+	if err != nil {
+		writeReconciliationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recs)
+}
+
+// @Summary      Get Reconciliation Report
+// @Description  Get a reconciliation with its totals and discrepancies (admin only).
+// @Tags         Reconciliation
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path   string  true   "Reconciliation ID"
+// @Param        kind  query  string  false  "Only discrepancies of this kind: missing_payment, missing_refund, duplicate, amount_mismatch, fee_mismatch or status_mismatch"
+// @Success      200  {object}  domain.Reconciliation
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /reconciliations/{id} [get]
+func (h *PaymentHandler) GetReconciliation(w http.ResponseWriter, r *http.Request) {
+	rec, err := h.reconciler.GetReconciliation(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeReconciliationError(w, err)
+		return
+	}
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		var only []domain.Discrepancy
+		for _, d := range rec.Discrepancies {
+			if d.Kind == kind {
+				only = append(only, d)
+			}
+		}
+		rec.Discrepancies = only
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+func writeReconciliationError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrReconciliationNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidSettlement):
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -23,6 +23,75 @@ func NewPaymentRouter(handler *PaymentHandler) http.Handler {
 
 		// No request body, only orderID in path + Bearer token
 		r.Post("/{order_id}", handler.CreatePayment)
+		r.With(middleware.AdminOnly).Post("/{id}/restore", handler.RestorePayment)
+	})
+
+	r.Route("/payment-methods", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware)
+		r.Get("/", handler.ListPaymentMethods)
+		r.Post("/", handler.SavePaymentMethod)
+		r.Get("/{id}", handler.GetPaymentMethod)
+		r.Delete("/{id}", handler.DeletePaymentMethod)
+		r.Post("/{id}/default", handler.SetDefaultPaymentMethod)
+	})
+
+	r.Route("/wallet", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware)
+		r.Get("/", handler.GetWallet)
+		r.Get("/transactions", handler.ListWalletTransactions)
+		r.Post("/gift-cards", handler.AddGiftCard)
+	})
+
+	r.Route("/gift-cards", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware, middleware.AdminOnly)
+		r.Post("/", handler.IssueGiftCard)
+		r.Get("/", handler.ListGiftCards)
+		r.Get("/{code}", handler.GetGiftCard)
+	})
+
+	r.Route("/wallets", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware, middleware.AdminOnly)
+		r.Get("/{user_id}", handler.GetUserWallet)
+		r.Post("/{user_id}/credits", handler.GrantStoreCredit)
+	})
+
+	// provider events are authenticated by their signature, not a token
+	r.Route("/webhooks/payments", func(r chi.Router) {
+		r.Post("/{provider}", handler.ReceiveWebhook)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.AuthMiddleware, middleware.AdminOnly)
+			r.Get("/events", handler.ListWebhookEvents)
+			r.Post("/events/{id}/replay", handler.ReplayWebhookEvent)
+		})
+	})
+
+	r.Route("/ledger", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware, middleware.AdminOnly)
+		r.Get("/accounts", handler.ListLedgerAccounts)
+		r.Get("/accounts/{code}", handler.GetLedgerAccount)
+		r.Get("/orders/{order_id}", handler.GetOrderLedger)
+		r.Get("/entries", handler.ListJournalEntries)
+		r.Get("/entries/{id}", handler.GetJournalEntry)
+		r.Post("/entries/{id}/reverse", handler.ReverseJournalEntry)
+		r.Post("/payouts", handler.RecordPayout)
+	})
+
+	r.Route("/fraud", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware, middleware.AdminOnly)
+		r.Get("/rules", handler.GetFraudRules)
+		r.Get("/screenings", handler.ListScreenings)
+		r.Get("/screenings/{id}", handler.GetScreening)
+		r.Get("/reviews", handler.ListReviews)
+		r.Post("/reviews/{id}/approve", handler.ApproveReview)
+		r.Post("/reviews/{id}/reject", handler.RejectReview)
+	})
+
+	r.Route("/reconciliations", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware, middleware.AdminOnly)
+		r.Post("/", handler.ReconcileSettlement)
+		r.Get("/", handler.ListReconciliations)
+		r.Get("/{id}", handler.GetReconciliation)
 	})
 
 	return r
@@ -0,0 +1,292 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ecom-api/pkg/middleware"
+	"ecom-api/pkg/money"
+	"payment-microservice/internals/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AddGiftCardRequest is the code printed on a gift card.
+type AddGiftCardRequest struct {
+	Code string `json:"code" example:"7KQM-4XPD-2HWN-9RTA"`
+}
+
+// IssueGiftCardRequest issues a gift card. Without a code a random one is
+// generated; without expires_at the card never expires.
+type IssueGiftCardRequest struct {
+	Amount    string     `json:"amount" example:"50.00"`
+	Currency  string     `json:"currency" example:"USD"`
+	Code      string     `json:"code,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2027-12-31T23:59:59Z"`
+}
+
+// GrantCreditRequest gives a user store credit. The reference makes the
+// grant happen once, e.g. a support ticket ID.
+type GrantCreditRequest struct {
+	Reference string `json:"reference" example:"ticket-4812"`
+	Amount    string `json:"amount" example:"15.00"`
+	Currency  string `json:"currency" example:"USD"`
+	Reason    string `json:"reason,omitempty" example:"late delivery"`
+}
+
+// @Summary      Get Wallet
+// @Description  Get the caller's store credit and usable gift cards, and what they can pay per currency. Pay with them by setting use_wallet when creating a payment.
+// @Tags         Wallet
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  domain.Wallet
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /wallet [get]
+func (h *PaymentHandler) GetWallet(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+	h.writeWallet(w, r, userID)
+}
+
+// @Summary      List Wallet Transactions
+// @Description  List the money that moved into and out of the caller's wallet, newest first.
+// @Tags         Wallet
+// @Produce      json
+// @Security     BearerAuth
+// @Param        limit  query  int  false  "Maximum number of transactions (default 50)"
+// @Success      200  {array}   domain.WalletTransaction
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /wallet/transactions [get]
+func (h *PaymentHandler) ListWalletTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	limit := int64(50)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error": "invalid limit"}`, http.StatusBadRequest)
+			return
+		}
+		limit = min(n, 500)
+	}
+
+	txns, err := h.service.ListWalletTransactions(r.Context(), userID, limit)
+	if err != nil {
+		writeWalletError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(txns)
+}
+
+// @Summary      Add Gift Card
+// @Description  Add a gift card to the caller's wallet by its code. Once added, only the caller can spend it.
+// @Tags         Wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body  AddGiftCardRequest  true  "Gift card code"
+// @Success      200  {object}  domain.GiftCard
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /wallet/gift-cards [post]
+func (h *PaymentHandler) AddGiftCard(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	var req AddGiftCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		http.Error(w, `{"error": "code is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	g, err := h.service.AddGiftCard(r.Context(), userID, req.Code)
+	if err != nil {
+		writeWalletError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(g)
+}
+
+// @Summary      Issue Gift Card
+// @Description  Issue a gift card for an initial balance (admin only). The balance is booked in the ledger as store credit given away.
+// @Tags         Gift Cards
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body  IssueGiftCardRequest  true  "Gift card"
+// @Success      201  {object}  domain.GiftCard
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /gift-cards [post]
+func (h *PaymentHandler) IssueGiftCard(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := middleware.FromContext(r.Context())
+
+	var req IssueGiftCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	amount, err := money.Parse(req.Amount, req.Currency)
+	if err != nil {
+		http.Error(w, `{"error": "invalid amount"}`, http.StatusBadRequest)
+		return
+	}
+
+	g, err := h.service.IssueGiftCard(r.Context(), &domain.GiftCard{
+		Code:      req.Code,
+		Initial:   amount,
+		ExpiresAt: req.ExpiresAt,
+		IssuedBy:  adminID,
+	})
+	if err != nil {
+		writeWalletError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(g)
+}
+
+// @Summary      List Gift Cards
+// @Description  List issued gift cards, newest first (admin only).
+// @Tags         Gift Cards
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id  query  string  false  "Only gift cards in this user's wallet"
+// @Param        limit    query  int     false  "Maximum number of gift cards (default 50)"
+// @Success      200  {array}   domain.GiftCard
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /gift-cards [get]
+func (h *PaymentHandler) ListGiftCards(w http.ResponseWriter, r *http.Request) {
+	limit := int64(50)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error": "invalid limit"}`, http.StatusBadRequest)
+			return
+		}
+		limit = min(n, 500)
+	}
+
+	cards, err := h.service.ListGiftCards(r.Context(), r.URL.Query().Get("user_id"), limit)
+	if err != nil {
+		writeWalletError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cards)
+}
+
+// @Summary      Get Gift Card
+// @Description  Get a gift card with its balance and redemptions by its code (admin only).
+// @Tags         Gift Cards
+// @Produce      json
+// @Security     BearerAuth
+// @Param        code  path  string  true  "Gift card code"
+// @Success      200  {object}  domain.GiftCard
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /gift-cards/{code} [get]
+func (h *PaymentHandler) GetGiftCard(w http.ResponseWriter, r *http.Request) {
+	g, err := h.service.GetGiftCard(r.Context(), chi.URLParam(r, "code"))
+	if err != nil {
+		writeWalletError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(g)
+}
+
+// @Summary      Get User Wallet
+// @Description  Get a user's store credit and usable gift cards (admin only).
+// @Tags         Gift Cards
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id  path  string  true  "User ID"
+// @Success      200  {object}  domain.Wallet
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /wallets/{user_id} [get]
+func (h *PaymentHandler) GetUserWallet(w http.ResponseWriter, r *http.Request) {
+	h.writeWallet(w, r, chi.URLParam(r, "user_id"))
+}
+
+// @Summary      Grant Store Credit
+// @Description  Give a user store credit (admin only). A reference granted before is not credited again. The credit is booked in the ledger as store credit given away.
+// @Tags         Gift Cards
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id  path  string              true  "User ID"
+// @Param        request  body  GrantCreditRequest  true  "Store credit"
+// @Success      200  {object}  domain.Wallet
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /wallets/{user_id}/credits [post]
+func (h *PaymentHandler) GrantStoreCredit(w http.ResponseWriter, r *http.Request) {
+	var req GrantCreditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	amount, err := money.Parse(req.Amount, req.Currency)
+	if err != nil {
+		http.Error(w, `{"error": "invalid amount"}`, http.StatusBadRequest)
+		return
+	}
+
+	wallet, err := h.service.GrantStoreCredit(r.Context(), chi.URLParam(r, "user_id"), req.Reference, amount, req.Reason)
+	if err != nil {
+		writeWalletError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(wallet)
+}
+
+func (h *PaymentHandler) writeWallet(w http.ResponseWriter, r *http.Request, userID string) {
+	wallet, err := h.service.GetWallet(r.Context(), userID)
+	if err != nil {
+		writeWalletError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(wallet)
+}
+
+func writeWalletError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrGiftCardNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrGiftCardClaimed):
+		status = http.StatusConflict
+	case errors.Is(err, domain.ErrGiftCardExpired), errors.Is(err, domain.ErrInvalidGiftCard), errors.Is(err, domain.ErrInvalidCredit):
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"ecom-api/pkg/webhook"
+	"payment-microservice/internals/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxWebhookBody caps the size of a provider event.
+const maxWebhookBody = 1 << 20
+
+// WebhookReceipt acknowledges a provider event.
+type WebhookReceipt struct {
+	EventID   string `json:"event_id"`
+	Status    string `json:"status"` // processed, or failed when it is kept for replay
+	Duplicate bool   `json:"duplicate"`
+	Error     string `json:"error,omitempty"`
+}
+
+// @Summary      Receive Payment Webhook
+// @Description  Receive an event from a payment provider. The raw body must be signed in the Webhook-Signature header as "t=<unix time>,v1=<hex HMAC-SHA256 of t.body>" with the provider's secret, and t must be within the tolerance of the server clock. Events are deduplicated by provider and event ID. An event that cannot be applied yet is stored and answered with 202, and replayed later.
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param        provider           path    string  true  "Payment provider, e.g. sim"
+// @Param        Webhook-Signature  header  string  true  "Signature of the body"
+// @Success      200  {object}  WebhookReceipt
+// @Success      202  {object}  WebhookReceipt
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/payments/{provider} [post]
+func (h *PaymentHandler) ReceiveWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	event, duplicate, err := h.service.ReceiveWebhook(r.Context(), chi.URLParam(r, "provider"), r.Header.Get(webhook.SignatureHeader), body)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if event.Status != domain.WebhookProcessed {
+		status = http.StatusAccepted
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(WebhookReceipt{
+		EventID:   event.EventID,
+		Status:    event.Status,
+		Duplicate: duplicate,
+		Error:     event.LastError,
+	})
+}
+
+// @Summary      List Webhook Events
+// @Description  List the latest received payment provider events, newest first (admin only).
+// @Tags         Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status  query  string  false  "received, processed or failed"
+// @Param        limit   query  int     false  "Maximum number of events (default 50)"
+// @Success      200  {array}   domain.WebhookEvent
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/payments/events [get]
+func (h *PaymentHandler) ListWebhookEvents(w http.ResponseWriter, r *http.Request) {
+	limit := int64(50)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error": "invalid limit"}`, http.StatusBadRequest)
+			return
+		}
+		limit = min(n, 500)
+	}
+
+	events, err := h.service.ListWebhookEvents(r.Context(), r.URL.Query().Get("status"), limit)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// @Summary      Replay Webhook Event
+// @Description  Apply a stored payment provider event that failed again (admin only). Processed events are returned unchanged.
+// @Tags         Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Event ID as provider:event"
+// @Success      200  {object}  domain.WebhookEvent
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/payments/events/{id}/replay [post]
+func (h *PaymentHandler) ReplayWebhookEvent(w http.ResponseWriter, r *http.Request) {
+	event, err := h.service.ReplayWebhookEvent(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrUnknownProvider), errors.Is(err, domain.ErrWebhookEventNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, webhook.ErrNoSignature), errors.Is(err, webhook.ErrBadSignature), errors.Is(err, webhook.ErrStaleTimestamp):
+		status = http.StatusUnauthorized
+	case errors.Is(err, domain.ErrInvalidWebhook):
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
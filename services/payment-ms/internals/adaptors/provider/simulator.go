@@ -0,0 +1,51 @@
+// Package provider talks to the payment provider.
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"payment-microservice/internals/domain"
+)
+
+// Simulator plays the payment provider for local runs. It issues no
+// tokens but reads what they stand for from their shape:
+//
+//	tok_<brand>_<country>_<last4>  a card, e.g. tok_visa_us_4242
+//	wal_<wallet>_<id>              a wallet, e.g. wal_paypal_8f2e
+//	ba_<bank>_<last4>              a bank account, e.g. ba_chase_6789
+//
+// Cards expire at the end of the year three years from now, and tokens
+// of the same brand, country and last four digits share a fingerprint.
+type Simulator struct{}
+
+func NewSimulator() *Simulator {
+	return &Simulator{}
+}
+
+func (s *Simulator) DescribeToken(ctx context.Context, token string) (*domain.PaymentMethod, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(token)), "_")
+	switch {
+	case parts[0] == "tok" && len(parts) == 4:
+		brand, country, last4 := parts[1], strings.ToUpper(parts[2]), parts[3]
+		sum := sha256.Sum256([]byte(brand + ":" + country + ":" + last4))
+		return &domain.PaymentMethod{
+			Type:        domain.MethodCard,
+			Brand:       brand,
+			Last4:       last4,
+			ExpMonth:    12,
+			ExpYear:     time.Now().Year() + 3,
+			Country:     country,
+			Fingerprint: hex.EncodeToString(sum[:8]),
+		}, nil
+	case parts[0] == "wal" && len(parts) == 3:
+		return &domain.PaymentMethod{Type: domain.MethodWallet, Wallet: parts[1]}, nil
+	case parts[0] == "ba" && len(parts) == 3:
+		return &domain.PaymentMethod{Type: domain.MethodBankTransfer, BankName: parts[1], Last4: parts[2]}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown token %q", domain.ErrInvalidPaymentMethod, token)
+}
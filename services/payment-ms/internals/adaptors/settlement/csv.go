@@ -0,0 +1,145 @@
+package settlement
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"ecom-api/pkg/money"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"payment-microservice/internals/domain"
+)
+
+// Columns of a settlement report. The first row names them, in any order;
+// reference, amount and currency are required.
+const (
+	colReference = "reference"
+	colType      = "type"
+	colAmount    = "amount"
+	colCurrency  = "currency"
+	colFee       = "fee"
+	colPayoutID  = "payout_id"
+	colSettledAt = "settled_at"
+)
+
+// Parse reads a settlement report in CSV, such as
+//
+//	reference,type,amount,currency,fee,payout_id,settled_at
+//	6650f1c2a4e8b1d2c3f4a5b6,charge,49.90,USD,1.75,po_123,2026-10-18T09:12:00Z
+//	6650f1c2a4e8b1d2c3f4a5b6,refund,-10.00,USD,,po_123,2026-10-18
+//
+// Amounts are decimal strings in major units. A line without a type is a
+// refund if its amount is negative and a charge otherwise.
+func Parse(name string, data []byte) (*domain.SettlementReport, error) {
+	sum := sha256.Sum256(data)
+	report := &domain.SettlementReport{Name: name, Checksum: hex.EncodeToString(sum[:])}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %s is empty", domain.ErrInvalidSettlement, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSettlement, err)
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, c := range []string{colReference, colAmount, colCurrency} {
+		if _, ok := cols[c]; !ok {
+			return nil, fmt.Errorf("%w: %s has no %s column", domain.ErrInvalidSettlement, name, c)
+		}
+	}
+
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSettlement, err)
+		}
+		lineNo, _ := r.FieldPos(0)
+		field := func(c string) string {
+			if i, ok := cols[c]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		line, err := parseLine(lineNo, field)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s line %d: %v", domain.ErrInvalidSettlement, name, lineNo, err)
+		}
+		report.Lines = append(report.Lines, line)
+	}
+	return report, nil
+}
+
+func parseLine(lineNo int, field func(string) string) (domain.SettlementLine, error) {
+	line := domain.SettlementLine{
+		Line:      lineNo,
+		Reference: field(colReference),
+		PayoutID:  field(colPayoutID),
+	}
+	if line.Reference == "" {
+		return line, errors.New("reference is empty")
+	}
+
+	currency := strings.ToUpper(field(colCurrency))
+	amount, err := money.Parse(field(colAmount), currency)
+	if err != nil {
+		return line, fmt.Errorf("amount: %w", err)
+	}
+	if amount.IsZero() {
+		return line, errors.New("amount is zero")
+	}
+
+	switch t := strings.ToLower(field(colType)); t {
+	case "":
+		line.Type = domain.SettlementCharge
+		if amount.IsNegative() {
+			line.Type = domain.SettlementRefund
+		}
+	case "charge", "payment", "capture", "sale":
+		line.Type = domain.SettlementCharge
+	case "refund":
+		line.Type = domain.SettlementRefund
+	default:
+		return line, fmt.Errorf("unknown type %q", t)
+	}
+	if amount.IsNegative() {
+		amount.Amount = -amount.Amount
+	}
+	line.Amount = amount
+
+	if v := field(colFee); v != "" {
+		if line.Fee, err = money.Parse(v, currency); err != nil {
+			return line, fmt.Errorf("fee: %w", err)
+		}
+		if line.Fee.IsNegative() {
+			line.Fee.Amount = -line.Fee.Amount
+		}
+	}
+
+	if v := field(colSettledAt); v != "" {
+		if line.SettledAt, err = time.Parse(time.RFC3339, v); err != nil {
+			if line.SettledAt, err = time.Parse(time.DateOnly, v); err != nil {
+				return line, fmt.Errorf("settled_at %q is neither RFC 3339 nor a date", v)
+			}
+		}
+	}
+	return line, nil
+}
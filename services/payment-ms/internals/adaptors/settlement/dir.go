@@ -0,0 +1,71 @@
+package settlement
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"payment-microservice/internals/domain"
+)
+
+// DirSource reads the settlement reports the provider drops into a
+// directory as .csv files. Files are left in place; reports reconciled
+// before are recognized by their content.
+type DirSource struct {
+	dir string
+}
+
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{dir: dir}
+}
+
+// List returns the .csv files of the directory, oldest first.
+func (s *DirSource) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type file struct {
+		name    string
+		modUnix int64
+	}
+	var files []file
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".csv") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // removed meanwhile
+		}
+		files = append(files, file{name: e.Name(), modUnix: info.ModTime().UnixNano()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].modUnix != files[j].modUnix {
+			return files[i].modUnix < files[j].modUnix
+		}
+		return files[i].name < files[j].name
+	})
+
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.name
+	}
+	return names, nil
+}
+
+func (s *DirSource) Read(ctx context.Context, name string) (*domain.SettlementReport, error) {
+	return ReadFile(filepath.Join(s.dir, filepath.Base(name)))
+}
+
+// ReadFile parses the settlement report at path, named after the file.
+func ReadFile(path string) (*domain.SettlementReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(filepath.Base(path), data)
+}
@@ -0,0 +1,27 @@
+package application
+
+import (
+	"context"
+	"fmt"
+)
+
+// AnonymizeUser detaches the payments, fraud screenings and wallet of a
+// deleted account from the user, putting anonymousID in place of the
+// user's ID, and deletes the user's saved payment methods. Amounts and
+// ledger entries stay as they are. Calling it again finishes a call that
+// failed part way.
+func (s *PaymentServiceImplement) AnonymizeUser(ctx context.Context, userID, anonymousID string) error {
+	if err := s.methods.DeleteByUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete payment methods: %w", err)
+	}
+	if err := s.repo.AnonymizeUser(ctx, userID, anonymousID); err != nil {
+		return fmt.Errorf("failed to anonymize payments: %w", err)
+	}
+	if err := s.screenings.AnonymizeUser(ctx, userID, anonymousID); err != nil {
+		return fmt.Errorf("failed to anonymize screenings: %w", err)
+	}
+	if err := s.wallets.AnonymizeUser(ctx, userID, anonymousID); err != nil {
+		return fmt.Errorf("failed to anonymize wallet: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package application
+
+import (
+	"context"
+	"ecom-api/pkg/events"
+	"ecom-api/pkg/money"
+	"fmt"
+	"payment-microservice/internals/domain"
+	"time"
+)
+
+const eventSource = "payment-ms"
+
+// publish announces a payment event. The change it reports is saved
+// already, so a failure is only logged.
+func (s *PaymentServiceImplement) publish(ctx context.Context, eventType string, data domain.PaymentEvent) {
+	data.OccurredAt = time.Now().UTC()
+	if err := s.publisher.Publish(ctx, events.New(eventType, eventSource, data.PaymentID, data)); err != nil {
+		fmt.Println("warning: failed to publish", eventType, "for payment", data.PaymentID, err)
+	}
+}
+
+// statusChanged announces that a payment reached its current status,
+// moving amount.
+func (s *PaymentServiceImplement) statusChanged(ctx context.Context, p *domain.Payment, amount money.Money, reason string) {
+	eventType, ok := p.StatusEvent()
+	if !ok {
+		return
+	}
+	s.publish(ctx, eventType, domain.PaymentEvent{
+		PaymentID: p.ID,
+		OrderID:   p.OrderID,
+		UserID:    p.UserID,
+		Status:    p.Status,
+		Amount:    amount,
+		Reason:    reason,
+	})
+}
@@ -0,0 +1,182 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"payment-microservice/internals/domain"
+	"time"
+)
+
+// screen runs the fraud rules over a payment attempt and stores the
+// screening, which later attempts count for their velocity rules.
+func (s *PaymentServiceImplement) screen(ctx context.Context, p *domain.Payment, shippingCountry string, client domain.ClientInfo) (*domain.Screening, error) {
+	check := domain.FraudCheck{
+		OrderID:         p.OrderID,
+		UserID:          p.UserID,
+		Amount:          p.Amount,
+		IP:              client.IP,
+		IPCountry:       client.Country,
+		ShippingCountry: shippingCountry,
+		Recent:          map[string]int{},
+	}
+	if p.Card != nil {
+		check.CardFingerprint = p.Card.Fingerprint
+		check.CardCountry = p.Card.Country
+	}
+
+	now := time.Now()
+	for _, r := range s.fraud.Rules {
+		if r.Disabled || !r.IsVelocity() {
+			continue
+		}
+		value := r.VelocityValue(check)
+		if value == "" {
+			continue
+		}
+		n, err := s.screenings.CountSince(ctx, r.Kind, value, now.Add(-r.WindowDuration()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to count recent payments for %s: %w", r.Name, err)
+		}
+		check.Recent[r.Name] = int(n)
+	}
+
+	screening := s.fraud.Screen(check, now)
+	if err := s.screenings.Create(ctx, screening); err != nil {
+		return nil, fmt.Errorf("failed to save fraud screening: %w", err)
+	}
+	if screening.Action != domain.FraudAllow {
+		log.Printf("fraud: screening %s of order %s: %s with score %d", screening.ID, p.OrderID, screening.Action, screening.Score)
+	}
+	return screening, nil
+}
+
+func (s *PaymentServiceImplement) FraudRules() domain.FraudConfig {
+	return s.fraud
+}
+
+func (s *PaymentServiceImplement) ListScreenings(ctx context.Context, filter domain.ScreeningFilter, limit int64) ([]*domain.Screening, error) {
+	return s.screenings.List(ctx, filter, limit)
+}
+
+func (s *PaymentServiceImplement) GetScreening(ctx context.Context, id string) (*domain.Screening, error) {
+	return s.screenings.FindByID(ctx, id)
+}
+
+// ApproveReview sends a payment held for review to the gateway and
+// reports the outcome to order-ms, as ProcessPayment would have.
+func (s *PaymentServiceImplement) ApproveReview(ctx context.Context, screeningID, reviewer, note string) (*domain.Payment, error) {
+	p, err := s.review(ctx, screeningID, domain.ReviewApproved, reviewer, note)
+	if err != nil {
+		return nil, err
+	}
+
+	to := domain.StatusCompleted
+	if p.Method != domain.MethodStoreCredit {
+		// store credit was taken when the payment was made
+		to = s.charge()
+	}
+	if to == domain.StatusPending {
+		// the provider reports the outcome by webhook
+		return p, nil
+	}
+	if err := s.settle(ctx, p, to, ""); err != nil {
+		return nil, err
+	}
+	if err := s.orderClient.UpdateOrderStatus(ctx, p.OrderID, to); err != nil {
+		fmt.Println("warning: failed to notify order-ms:", err)
+	}
+	return p, nil
+}
+
+// RejectReview fails a payment held for review.
+func (s *PaymentServiceImplement) RejectReview(ctx context.Context, screeningID, reviewer, note string) (*domain.Payment, error) {
+	p, err := s.review(ctx, screeningID, domain.ReviewRejected, reviewer, note)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := "rejected by fraud review"
+	if note != "" {
+		reason += ": " + note
+	}
+	if err := s.settle(ctx, p, domain.StatusFailed, reason); err != nil {
+		return nil, err
+	}
+	if err := s.orderClient.UpdateOrderStatus(ctx, p.OrderID, domain.StatusFailed); err != nil {
+		fmt.Println("warning: failed to notify order-ms:", err)
+	}
+	return p, nil
+}
+
+// review decides a pending review and returns the payment it held. The
+// decision is recorded first, so two admins cannot both decide.
+func (s *PaymentServiceImplement) review(ctx context.Context, screeningID, status, reviewer, note string) (*domain.Payment, error) {
+	screening, err := s.screenings.FindByID(ctx, screeningID)
+	if err != nil {
+		return nil, err
+	}
+	if screening.ReviewStatus != domain.ReviewPending {
+		return nil, fmt.Errorf("%w: screening %s is %s", domain.ErrNotUnderReview, screeningID, orNone(screening.ReviewStatus))
+	}
+	p, err := s.heldPayment(ctx, screening)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.screenings.Review(ctx, screeningID, status, reviewer, note, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to record review: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: screening %s was decided meanwhile", domain.ErrNotUnderReview, screeningID)
+	}
+	return p, nil
+}
+
+// heldPayment returns the PENDING payment a screening holds for review.
+// With split tender the wallet's part shares the screening; the payment
+// charging the rest is the one held, and settling it settles the other.
+func (s *PaymentServiceImplement) heldPayment(ctx context.Context, screening *domain.Screening) (*domain.Payment, error) {
+	payments, err := s.repo.FindByOrder(ctx, screening.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payments of order %s: %w", screening.OrderID, err)
+	}
+	var held *domain.Payment
+	for _, p := range payments {
+		if p.ScreeningID != screening.ID || (held != nil && p.Method == domain.MethodStoreCredit) {
+			continue
+		}
+		held = p
+		if p.Method != domain.MethodStoreCredit {
+			break
+		}
+	}
+	if held != nil {
+		if held.Status != domain.StatusPending {
+			return nil, fmt.Errorf("%w: payment %s is %s", domain.ErrNotUnderReview, held.ID, held.Status)
+		}
+		return held, nil
+	}
+	return nil, fmt.Errorf("%w: no payment of order %s was held by screening %s", domain.ErrPaymentNotFound, screening.OrderID, screening.ID)
+}
+
+// heldForReview reports whether a payment waits for a review, which
+// provider events must not settle.
+func (s *PaymentServiceImplement) heldForReview(ctx context.Context, p *domain.Payment) (bool, error) {
+	if p.ScreeningID == "" || p.Status != domain.StatusPending {
+		return false, nil
+	}
+	screening, err := s.screenings.FindByID(ctx, p.ScreeningID)
+	if err != nil {
+		return false, err
+	}
+	return screening.ReviewStatus == domain.ReviewPending, nil
+}
+
+func orNone(status string) string {
+	if status == "" {
+		return "not held for review"
+	}
+	return status
+}
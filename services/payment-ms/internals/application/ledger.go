@@ -0,0 +1,135 @@
+package application
+
+import (
+	"context"
+	"ecom-api/pkg/money"
+	"errors"
+	"fmt"
+	"log"
+	"payment-microservice/internals/domain"
+)
+
+// journal posts the entries a payment's state implies that are not in the
+// ledger yet. The payment change is saved already, so a failure is only
+// logged; SyncLedger posts the entry later.
+func (s *PaymentServiceImplement) journal(ctx context.Context, p *domain.Payment) int {
+	posted := 0
+	for _, e := range p.JournalEntries(s.fees) {
+		ok, err := s.post(ctx, e)
+		if err != nil {
+			fmt.Println("warning: failed to post journal entry", e.Key, err)
+			continue
+		}
+		if ok {
+			posted++
+		}
+	}
+	return posted
+}
+
+// post validates an entry and stores it, reporting false if its key was
+// posted before.
+func (s *PaymentServiceImplement) post(ctx context.Context, e *domain.JournalEntry) (bool, error) {
+	if err := e.Validate(); err != nil {
+		return false, err
+	}
+	return s.ledger.Post(ctx, e)
+}
+
+// postOnce posts an entry, or returns the one posted before with its key.
+func (s *PaymentServiceImplement) postOnce(ctx context.Context, e *domain.JournalEntry) (*domain.JournalEntry, error) {
+	ok, err := s.post(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return s.ledger.FindByKey(ctx, e.Key)
+	}
+	return e, nil
+}
+
+// SyncLedger posts the entries missing for any payment, e.g. for payments
+// made before the ledger existed or whose entries failed to post.
+func (s *PaymentServiceImplement) SyncLedger(ctx context.Context) (int, error) {
+	payments, err := s.repo.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	posted := 0
+	for _, p := range payments {
+		if ctx.Err() != nil {
+			break
+		}
+		posted += s.journal(ctx, p)
+	}
+	if posted > 0 {
+		log.Printf("ledger: posted %d missing journal entries", posted)
+	}
+	return posted, nil
+}
+
+// LedgerBalances returns the balances of the accounts, per currency, over
+// the entries matching filter.
+func (s *PaymentServiceImplement) LedgerBalances(ctx context.Context, filter domain.EntryFilter) ([]domain.AccountBalance, error) {
+	if filter.Account != "" {
+		if _, ok := domain.FindAccount(filter.Account); !ok {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownAccount, filter.Account)
+		}
+	}
+	return s.ledger.Balances(ctx, filter)
+}
+
+func (s *PaymentServiceImplement) ListJournalEntries(ctx context.Context, filter domain.EntryFilter, limit int64) ([]*domain.JournalEntry, error) {
+	if filter.Account != "" {
+		if _, ok := domain.FindAccount(filter.Account); !ok {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownAccount, filter.Account)
+		}
+	}
+	return s.ledger.List(ctx, filter, limit)
+}
+
+func (s *PaymentServiceImplement) GetJournalEntry(ctx context.Context, id string) (*domain.JournalEntry, error) {
+	return s.ledger.FindByID(ctx, id)
+}
+
+// ReverseJournalEntry posts the entry that undoes id. An entry is reversed
+// at most once; asking again returns the first reversal.
+func (s *PaymentServiceImplement) ReverseJournalEntry(ctx context.Context, id, memo string) (*domain.JournalEntry, error) {
+	entry, err := s.ledger.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.postOnce(ctx, entry.Reversal(memo))
+}
+
+// RecordPayout books money the provider paid out to the bank. It cannot
+// exceed what the provider holds for us in that currency. A reference
+// recorded before returns the earlier entry.
+func (s *PaymentServiceImplement) RecordPayout(ctx context.Context, reference string, amount money.Money, memo string) (*domain.JournalEntry, error) {
+	entry, err := domain.Payout(reference, amount, memo)
+	if err != nil {
+		return nil, err
+	}
+	if earlier, err := s.ledger.FindByKey(ctx, entry.Key); err == nil {
+		return earlier, nil
+	} else if !errors.Is(err, domain.ErrEntryNotFound) {
+		return nil, err
+	}
+
+	held := money.Zero(amount.Currency)
+	balances, err := s.ledger.Balances(ctx, domain.EntryFilter{Account: domain.AccountProviderReceivable})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load provider balance: %w", err)
+	}
+	for _, b := range balances {
+		if b.Balance.Currency == amount.Currency {
+			held = b.Balance
+		}
+	}
+	if cmp, _ := amount.Cmp(held); cmp > 0 {
+		return nil, fmt.Errorf("%w: payout of %s exceeds the %s the provider holds", domain.ErrInvalidPayout, amount, held)
+	}
+
+	return s.postOnce(ctx, entry)
+}
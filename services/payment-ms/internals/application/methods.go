@@ -0,0 +1,120 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"payment-microservice/internals/domain"
+	"strings"
+	"time"
+)
+
+// SavePaymentMethod saves the method a provider token stands for. What is
+// kept besides the token, card details in particular, comes from the
+// provider and never from the caller.
+func (s *PaymentServiceImplement) SavePaymentMethod(ctx context.Context, userID, token string, makeDefault bool) (*domain.PaymentMethod, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, fmt.Errorf("%w: token is required", domain.ErrInvalidPaymentMethod)
+	}
+	m, err := s.provider.DescribeToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	m.ID = ""
+	m.UserID = userID
+	m.Token = token
+	m.Type = strings.ToLower(strings.TrimSpace(m.Type))
+	m.Country = strings.ToUpper(strings.TrimSpace(m.Country))
+	m.CreatedAt = time.Now()
+	m.Default = false
+	if err := m.Validate(m.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	current, err := s.methods.FindDefault(ctx, m.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load default payment method: %w", err)
+	}
+	if err := s.methods.Create(ctx, m); err != nil {
+		return nil, fmt.Errorf("failed to save payment method: %w", err)
+	}
+	if current == nil || makeDefault {
+		if err := s.methods.SetDefault(ctx, m.UserID, m.ID); err != nil {
+			return nil, fmt.Errorf("failed to make payment method %s the default: %w", m.ID, err)
+		}
+		m.Default = true
+	}
+	return m, nil
+}
+
+func (s *PaymentServiceImplement) ListPaymentMethods(ctx context.Context, userID string) ([]*domain.PaymentMethod, error) {
+	return s.methods.ListByUser(ctx, userID)
+}
+
+func (s *PaymentServiceImplement) GetPaymentMethod(ctx context.Context, userID, id string) (*domain.PaymentMethod, error) {
+	return s.methods.FindByID(ctx, userID, id)
+}
+
+func (s *PaymentServiceImplement) SetDefaultPaymentMethod(ctx context.Context, userID, id string) (*domain.PaymentMethod, error) {
+	if err := s.methods.SetDefault(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.methods.FindByID(ctx, userID, id)
+}
+
+func (s *PaymentServiceImplement) DeletePaymentMethod(ctx context.Context, userID, id string) error {
+	m, err := s.methods.FindByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.methods.Delete(ctx, userID, id); err != nil {
+		return err
+	}
+	if !m.Default {
+		return nil
+	}
+
+	rest, err := s.methods.ListByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load remaining payment methods: %w", err)
+	}
+	if len(rest) > 0 {
+		if err := s.methods.SetDefault(ctx, userID, rest[0].ID); err != nil {
+			return fmt.Errorf("failed to make payment method %s the default: %w", rest[0].ID, err)
+		}
+	}
+	return nil
+}
+
+// applyMethod fills in how a payment is made from the saved method it
+// names, or else from the user's default method, if there is one. The
+// card a payment is screened on only ever comes from the saved method, as
+// the provider reported it, never from the caller.
+func (s *PaymentServiceImplement) applyMethod(ctx context.Context, p *domain.Payment) error {
+	p.Card = nil
+	var m *domain.PaymentMethod
+	var err error
+	switch {
+	case p.MethodID != "":
+		m, err = s.methods.FindByID(ctx, p.UserID, p.MethodID)
+		if err != nil {
+			return fmt.Errorf("payment method %s: %w", p.MethodID, err)
+		}
+	case p.Method == "":
+		m, err = s.methods.FindDefault(ctx, p.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to load default payment method: %w", err)
+		}
+	}
+
+	if m == nil {
+		return nil
+	}
+	if m.Expired(time.Now()) {
+		return fmt.Errorf("%w: card ending in %s expired %02d/%d", domain.ErrPaymentMethodExpired, m.Last4, m.ExpMonth, m.ExpYear)
+	}
+	p.MethodID = m.ID
+	p.Method = m.Type
+	p.Card = m.Card()
+	return nil
+}
@@ -2,52 +2,152 @@ package application
 
 import (
 	"context"
+	"ecom-api/pkg/money"
 	"fmt"
+	"log"
 	"payment-microservice/internals/domain"
 	"payment-microservice/internals/ports"
+	"time"
+)
+
+// The statuses order-ms gives orders still waiting to be paid
+const (
+	orderPending = "PENDING"
+	orderFailed  = "FAILED"
 )
 
 // PaymentServiceImplement implements ports.PaymentService
 type PaymentServiceImplement struct {
-	repo        ports.PaymentRepository
-	orderClient ports.OrderClient // optional: if you want to call order-ms back
+	repo          ports.PaymentRepository
+	orderClient   ports.OrderClient // optional: if you want to call order-ms back
+	webhookEvents ports.WebhookEventRepository
+	webhooks      WebhookConfig
+	awaitWebhook  bool // the provider reports the outcome of a payment later, by webhook
+	publisher     ports.EventPublisher
+	ledger        ports.LedgerRepository
+	fees          domain.FeeSchedule // what the provider charges per captured payment
+	screenings    ports.ScreeningRepository
+	fraud         domain.FraudConfig
+	methods       ports.PaymentMethodRepository
+	wallets       ports.WalletRepository
+	provider      ports.PaymentProvider // describes the tokens of saved methods
 }
 
 // constructor
-func NewPaymentService(repo ports.PaymentRepository, orderClient ports.OrderClient) ports.PaymentService {
+func NewPaymentService(repo ports.PaymentRepository, orderClient ports.OrderClient, webhookEvents ports.WebhookEventRepository, webhooks WebhookConfig, awaitWebhook bool, publisher ports.EventPublisher, ledger ports.LedgerRepository, fees domain.FeeSchedule, screenings ports.ScreeningRepository, fraud domain.FraudConfig, methods ports.PaymentMethodRepository, wallets ports.WalletRepository, provider ports.PaymentProvider) ports.PaymentService {
 	return &PaymentServiceImplement{
-		repo:        repo,
-		orderClient: orderClient,
+		repo:          repo,
+		orderClient:   orderClient,
+		webhookEvents: webhookEvents,
+		webhooks:      webhooks,
+		awaitWebhook:  awaitWebhook,
+		publisher:     publisher,
+		ledger:        ledger,
+		fees:          fees,
+		screenings:    screenings,
+		fraud:         fraud,
+		methods:       methods,
+		wallets:       wallets,
+		provider:      provider,
 	}
 }
 
-// ProcessPayment simulates processing and persists result
-func (s *PaymentServiceImplement) ProcessPayment(ctx context.Context, payment *domain.Payment) (*domain.Payment, error) {
+// ProcessPayment screens a payment for fraud, then simulates processing
+// and persists the result. A denied payment is not created; one held for
+// review stays PENDING until ApproveReview charges it. With split tender
+// the wallet pays its part as a separate store credit payment, which is
+// settled together with the payment charging the rest.
+func (s *PaymentServiceImplement) ProcessPayment(ctx context.Context, payment *domain.Payment, tender domain.Tender, client domain.ClientInfo) (*domain.Payment, error) {
 	// 1. Fetch order details to get total amount
 	order, err := s.orderClient.GetOrder(ctx, payment.OrderID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch order %s: %w", payment.OrderID, err)
 	}
 
-	// ✅ Always trust Order-MS total (prevents tampering)
-	payment.Amount = order.Total
+	// ✅ Always trust Order-MS total (prevents tampering) and charge in
+	// the currency the order was placed in
+	if order.Status != orderPending && order.Status != orderFailed {
+		return nil, fmt.Errorf("%w: order %s is %s", domain.ErrOrderNotPayable, payment.OrderID, order.Status)
+	}
+	earlier, err := s.repo.FindByOrder(ctx, payment.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payments of order %s: %w", payment.OrderID, err)
+	}
+	for _, p := range earlier {
+		if p.Status == domain.StatusPending || p.Status == domain.StatusCompleted {
+			return nil, fmt.Errorf("%w: order %s already has payment %s, which is %s", domain.ErrOrderNotPayable, payment.OrderID, p.ID, p.Status)
+		}
+	}
+	if payment.UserID != "" && payment.UserID != order.UserId {
+		return nil, fmt.Errorf("%w: order %s", domain.ErrOrderNotOwned, payment.OrderID)
+	}
+	total := money.FromProto(order.Total)
+	if order.Currency != "" && total.Currency != order.Currency {
+		return nil, fmt.Errorf("order %s total is in %s but the order currency is %s", payment.OrderID, total.Currency, order.Currency)
+	}
+	if c := payment.Amount.Currency; c != "" && c != total.Currency {
+		return nil, fmt.Errorf("payment currency %s does not match order currency %s", c, total.Currency)
+	}
+	payment.Amount = total
 	payment.UserID = order.UserId
+	if err := s.applyMethod(ctx, payment); err != nil {
+		return nil, err
+	}
 
-	// 2. Simulate payment gateway logic
-	success := true // here you could integrate with Stripe/PayPal etc.
-	if success {
-		payment.Status = "COMPLETED"
-	} else {
-		payment.Status = "FAILED"
+	// 2. Fraud screening
+	screening, err := s.screen(ctx, payment, order.GetShippingAddress().GetCountry(), client)
+	if err != nil {
+		return nil, err
+	}
+	if screening.Action == domain.FraudDeny {
+		return nil, fmt.Errorf("%w: screening %s", domain.ErrPaymentDenied, screening.ID)
+	}
+	payment.ScreeningID = screening.ID
+
+	// 3. Split tender: the wallet pays its part first
+	credit, err := s.payFromWallet(ctx, payment, tender)
+	if err != nil {
+		return nil, err
+	}
+	if credit != nil {
+		rest, _ := payment.Amount.Sub(credit.Amount)
+		if !rest.IsPositive() {
+			return s.payWithCredit(ctx, credit, screening)
+		}
+		payment.Amount = rest
+		payment.SplitWith = credit.ID
 	}
 
-	// 3. Persist payment record
+	if screening.Action == domain.FraudReview {
+		payment.Status = domain.StatusPending
+		created, err := s.repo.Create(ctx, payment)
+		if err != nil {
+			s.releaseCredit(ctx, credit)
+			return nil, fmt.Errorf("failed to persist payment: %w", err)
+		}
+		s.journal(ctx, created)
+		log.Printf("fraud: payment %s of order %s is held for review with score %d", created.ID, created.OrderID, screening.Score)
+		return created, nil
+	}
+
+	// 4. Simulate payment gateway logic
+	payment.Status = s.charge()
+
+	// 5. Persist payment record
 	created, err := s.repo.Create(ctx, payment)
 	if err != nil {
+		s.releaseCredit(ctx, credit)
 		return nil, fmt.Errorf("failed to persist payment: %w", err)
 	}
+	s.journal(ctx, created)
 
-	// 4. Notify Order-MS of new status
+	if payment.Status == domain.StatusPending {
+		return created, nil
+	}
+	s.statusChanged(ctx, created, created.Amount, "")
+	s.settleSplit(ctx, created)
+
+	// 6. Notify Order-MS of new status
 	fmt.Println("🔎 Payment updating orderID=", payment.OrderID)
 	fmt.Printf("📡 Sending status update to Order-MS: order=%s, newStatus=%s\n", payment.OrderID, payment.Status)
 
@@ -60,6 +160,35 @@ func (s *PaymentServiceImplement) ProcessPayment(ctx context.Context, payment *d
 	return created, nil
 }
 
+// payWithCredit completes an order the wallet pays in full, unless the
+// screening holds it for review.
+func (s *PaymentServiceImplement) payWithCredit(ctx context.Context, credit *domain.Payment, screening *domain.Screening) (*domain.Payment, error) {
+	if screening.Action == domain.FraudReview {
+		log.Printf("fraud: payment %s of order %s is held for review with score %d", credit.ID, credit.OrderID, screening.Score)
+		return credit, nil
+	}
+	if err := s.settle(ctx, credit, domain.StatusCompleted, ""); err != nil {
+		return nil, err
+	}
+	if err := s.orderClient.UpdateOrderStatus(ctx, credit.OrderID, credit.Status); err != nil {
+		fmt.Println("warning: failed to notify order-ms:", err)
+	}
+	return credit, nil
+}
+
+// charge simulates the payment gateway and returns the status a payment
+// gets from it.
+func (s *PaymentServiceImplement) charge() string {
+	success := true // here you could integrate with Stripe/PayPal etc.
+	if s.awaitWebhook {
+		// the provider posts the outcome to ReceiveWebhook
+		return domain.StatusPending
+	} else if success {
+		return domain.StatusCompleted
+	}
+	return domain.StatusFailed
+}
+
 
 // InitPayment creates a PENDING payment for an order
 func (s *PaymentServiceImplement) InitPayment(ctx context.Context, orderID string) (*domain.Payment, error) {
@@ -80,14 +209,66 @@ func (s *PaymentServiceImplement) ListPayments(ctx context.Context) ([]*domain.P
 	return s.repo.List(ctx)
 }
 
-// UpdatePaymentStatus changes the status of a payment
+// UpdatePaymentStatus settles a PENDING payment as COMPLETED or FAILED,
+// unless it is held for fraud review, which only an admin's decision ends.
+// Money that was taken is only given back through cancellation or
+// refunds, so every movement reaches the ledger.
 func (s *PaymentServiceImplement) UpdatePaymentStatus(ctx context.Context, id string, status string) (*domain.Payment, error) {
-	return s.repo.UpdateStatus(ctx, id, status)
+	p, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status == status {
+		return p, nil
+	}
+	if p.Status != domain.StatusPending || (status != domain.StatusCompleted && status != domain.StatusFailed) {
+		return nil, fmt.Errorf("%w: %s to %s, only PENDING payments can be settled as COMPLETED or FAILED", domain.ErrInvalidStatusChange, p.Status, status)
+	}
+	held, err := s.heldForReview(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check fraud review of payment %s: %w", p.ID, err)
+	}
+	if held {
+		return nil, fmt.Errorf("%w: payment %s is held for fraud review", domain.ErrPaymentStateConflict, p.ID)
+	}
+	if err := s.settle(ctx, p, status, ""); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// settle moves a PENDING payment to to and records the outcome.
+func (s *PaymentServiceImplement) settle(ctx context.Context, p *domain.Payment, to, reason string) error {
+	ok, err := s.repo.Settle(ctx, p.ID, domain.StatusPending, to)
+	if err != nil {
+		return fmt.Errorf("failed to settle payment %s: %w", p.ID, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: payment %s changed while it was being settled", domain.ErrPaymentStateConflict, p.ID)
+	}
+	p.Status = to
+	s.journal(ctx, p)
+	s.statusChanged(ctx, p, p.Amount, reason)
+	if p.Method == domain.MethodStoreCredit && to == domain.StatusFailed {
+		s.returnToWallet(ctx, p, domain.TxnRelease, "", p.Amount, reason)
+	}
+	s.settleSplit(ctx, p)
+	return nil
 }
 
-// DeletePayment removes a payment record
-func (s *PaymentServiceImplement) DeletePayment(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+// DeletePayment soft-deletes a payment record
+func (s *PaymentServiceImplement) DeletePayment(ctx context.Context, id, deletedBy string) error {
+	return s.repo.Delete(ctx, id, deletedBy)
+}
+
+// RestorePayment undeletes a soft-deleted payment record
+func (s *PaymentServiceImplement) RestorePayment(ctx context.Context, id string) (*domain.Payment, error) {
+	return s.repo.Restore(ctx, id)
+}
+
+// PurgeDeletedPayments removes payments deleted before before for good
+func (s *PaymentServiceImplement) PurgeDeletedPayments(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.Purge(ctx, before)
 }
 
 // NotifyOrderCreated is called by Order-MS when a new order is placed.
@@ -97,3 +278,198 @@ func (s *PaymentServiceImplement) NotifyOrderCreated(ctx context.Context, orderI
     return nil
 }
 
+// CancelOrderPayment voids pending payments and refunds completed ones.
+// Failed and already reversed payments are left as they are, so a retry
+// only finishes what an earlier call could not.
+func (s *PaymentServiceImplement) CancelOrderPayment(ctx context.Context, orderID, reason string) (*domain.OrderReversal, error) {
+	payments, err := s.repo.FindByOrder(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payments of order %s: %w", orderID, err)
+	}
+
+	for _, p := range payments {
+		var to, kind string
+		switch p.Status {
+		case domain.StatusPending:
+			to, kind = domain.StatusVoided, domain.ReversalVoid
+		case domain.StatusCompleted:
+			// simulated gateway refund, see ProcessPayment
+			to, kind = domain.StatusRefunded, domain.ReversalRefund
+		default:
+			// finishes returning wallet money an earlier call could not
+			if err := s.returnReversal(ctx, p); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		amount := p.Amount
+		if kind == domain.ReversalRefund {
+			// partial refunds were already paid back
+			amount = p.Refundable()
+		}
+		rev := &domain.Reversal{Kind: kind, Amount: amount, Reason: reason, ReversedAt: time.Now()}
+		if p.Method == domain.MethodStoreCredit && kind == domain.ReversalRefund {
+			rev.To = domain.RefundToStoreCredit
+		}
+		ok, err := s.repo.Reverse(ctx, p.ID, p.Status, to, rev)
+		if err != nil {
+			return nil, fmt.Errorf("failed to %s payment %s: %w", kind, p.ID, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("payment %s changed while it was being reversed", p.ID)
+		}
+		p.Status, p.Reversal = to, rev
+		s.journal(ctx, p)
+		s.statusChanged(ctx, p, rev.Amount, reason)
+		if err := s.returnReversal(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+
+	return summarizeReversal(payments)
+}
+
+// returnReversal gives the money of a voided or refunded store credit
+// payment back to the wallet.
+func (s *PaymentServiceImplement) returnReversal(ctx context.Context, p *domain.Payment) error {
+	rev := p.Reversal
+	if p.Method != domain.MethodStoreCredit || rev == nil || !rev.Amount.IsPositive() {
+		return nil
+	}
+	txn := domain.TxnRelease
+	if rev.Kind == domain.ReversalRefund {
+		txn = domain.TxnRefund
+	}
+	return s.returnToWallet(ctx, p, txn, "cancellation", rev.Amount, rev.Reason)
+}
+
+// RefundOrderPayment refunds amount of the completed payments of an order,
+// taking it from the first payment with money left. A reference that was
+// refunded before returns the earlier refund instead of paying again.
+// Refunds of store credit payments, and all of them when toStoreCredit is
+// set, are paid into the user's wallet.
+func (s *PaymentServiceImplement) RefundOrderPayment(ctx context.Context, orderID, reference string, amount money.Money, reason string, toStoreCredit bool) (*domain.OrderRefund, error) {
+	if reference == "" {
+		return nil, fmt.Errorf("refund reference is required")
+	}
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("refund amount must be positive")
+	}
+
+	payments, err := s.repo.FindByOrder(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payments of order %s: %w", orderID, err)
+	}
+
+	out := &domain.OrderRefund{Refunded: money.Zero(amount.Currency), Payments: payments}
+	for _, p := range payments {
+		if r := p.RefundFor(reference); r != nil {
+			sum, err := out.Refunded.Add(r.Amount)
+			if err != nil {
+				return nil, err
+			}
+			out.Refunded = sum
+			// finishes a store credit refund an earlier call could not
+			if r.To == domain.RefundToStoreCredit {
+				if err := s.returnToWallet(ctx, p, domain.TxnRefund, reference, r.Amount, r.Reason); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if out.Refunded.IsPositive() {
+		return out, nil
+	}
+
+	available := money.Zero(amount.Currency)
+	for _, p := range payments {
+		if r := p.Refundable(); r.Currency == amount.Currency {
+			available, _ = available.Add(r)
+		}
+	}
+	if cmp, _ := amount.Cmp(available); cmp > 0 {
+		return nil, fmt.Errorf("%w: %s requested but %s left to refund for order %s", domain.ErrRefundExceeded, amount, available, orderID)
+	}
+
+	left := amount
+	for _, p := range payments {
+		if !left.IsPositive() {
+			break
+		}
+		refundable := p.Refundable()
+		if !refundable.IsPositive() || refundable.Currency != amount.Currency {
+			continue
+		}
+
+		part := left
+		if cmp, _ := part.Cmp(refundable); cmp > 0 {
+			part = refundable
+		}
+		// simulated gateway refund, see ProcessPayment
+		r := domain.Refund{Reference: reference, Amount: part, Reason: reason, RefundedAt: time.Now()}
+		if toStoreCredit || p.Method == domain.MethodStoreCredit {
+			r.To = domain.RefundToStoreCredit
+		}
+		full := part == refundable
+		ok, err := s.repo.AddRefund(ctx, p.ID, len(p.Refunds), r, full)
+		if err != nil {
+			return nil, fmt.Errorf("failed to refund payment %s: %w", p.ID, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("payment %s changed while it was being refunded", p.ID)
+		}
+
+		p.Refunds = append(p.Refunds, r)
+		if full {
+			p.Status = domain.StatusRefunded
+		}
+		if r.To == domain.RefundToStoreCredit {
+			if err := s.returnToWallet(ctx, p, domain.TxnRefund, reference, part, reason); err != nil {
+				return nil, err
+			}
+		}
+		s.journal(ctx, p)
+		s.publish(ctx, domain.EventPaymentRefunded, domain.PaymentEvent{
+			PaymentID: p.ID,
+			OrderID:   p.OrderID,
+			UserID:    p.UserID,
+			Status:    p.Status,
+			Amount:    part,
+			Partial:   !full,
+			Reference: reference,
+			Reason:    reason,
+		})
+		left, _ = left.Sub(part)
+		out.Refunded, _ = out.Refunded.Add(part)
+	}
+
+	return out, nil
+}
+
+func summarizeReversal(payments []*domain.Payment) (*domain.OrderReversal, error) {
+	out := &domain.OrderReversal{Action: domain.ActionNone, Payments: payments}
+	for _, p := range payments {
+		switch p.Status {
+		case domain.StatusRefunded:
+			if out.Refunded.Currency == "" {
+				out.Refunded = money.Zero(p.Amount.Currency)
+			}
+			amount := p.Amount
+			if p.Reversal != nil {
+				amount = p.Reversal.Amount
+			}
+			sum, err := out.Refunded.Add(amount)
+			if err != nil {
+				return nil, err
+			}
+			out.Refunded = sum
+			out.Action = domain.ActionRefunded
+		case domain.StatusVoided:
+			if out.Action == domain.ActionNone {
+				out.Action = domain.ActionVoided
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,121 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"payment-microservice/internals/domain"
+	"payment-microservice/internals/ports"
+	"time"
+)
+
+// Reconciler implements ports.ReconciliationService. It only reads
+// payments, so it runs inside the service as well as from the command line.
+type Reconciler struct {
+	payments        ports.PaymentRepository
+	reconciliations ports.ReconciliationRepository
+	fees            domain.FeeSchedule // what the provider should charge per payment
+}
+
+func NewReconciler(payments ports.PaymentRepository, reconciliations ports.ReconciliationRepository, fees domain.FeeSchedule) ports.ReconciliationService {
+	return &Reconciler{payments: payments, reconciliations: reconciliations, fees: fees}
+}
+
+// Reconcile matches each line of a report with the payment it references
+// and flags lines for unknown payments or refunds, lines settling what an
+// earlier line or report settled, and amounts or fees that differ from
+// ours. Reconciling a report again returns the first reconciliation.
+func (r *Reconciler) Reconcile(ctx context.Context, report *domain.SettlementReport) (*domain.Reconciliation, bool, error) {
+	if earlier, err := r.reconciliations.FindByChecksum(ctx, report.Checksum); err == nil {
+		return earlier, true, nil
+	} else if !errors.Is(err, domain.ErrReconciliationNotFound) {
+		return nil, false, err
+	}
+
+	seen := map[string]bool{}
+	var ids []string
+	for _, l := range report.Lines {
+		if !seen[l.Reference] {
+			seen[l.Reference] = true
+			ids = append(ids, l.Reference)
+		}
+	}
+	found, err := r.payments.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load payments: %w", err)
+	}
+	payments := make(map[string]*domain.Payment, len(found))
+	for _, p := range found {
+		payments[p.ID] = p
+	}
+
+	rec, matches := domain.Reconcile(report, payments, r.fees)
+
+	keys := make([]string, len(matches))
+	for i, m := range matches {
+		keys[i] = m.Key
+	}
+	earlier, err := r.reconciliations.Claim(ctx, report.Checksum, report.Name, keys)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record settled payments: %w", err)
+	}
+	for _, m := range matches {
+		if source, ok := earlier[m.Key]; ok {
+			rec.Flag(m.Line, domain.DiscrepancyDuplicate, nil, "settled before in "+source)
+		}
+	}
+	rec.Finish(time.Now())
+
+	created, err := r.reconciliations.Create(ctx, rec)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to save reconciliation: %w", err)
+	}
+	if !created {
+		// reconciled by another replica meanwhile
+		rec, err = r.reconciliations.FindByChecksum(ctx, report.Checksum)
+		return rec, true, err
+	}
+	if rec.Status == domain.ReconciliationDiscrepancies {
+		log.Printf("reconciliation: %s has %d discrepancies in %d lines", rec.Source, len(rec.Discrepancies), rec.Lines)
+	}
+	return rec, false, nil
+}
+
+// ReconcileSource reconciles the reports of source that were not
+// reconciled yet. A report that cannot be read is skipped and tried again
+// on the next run.
+func (r *Reconciler) ReconcileSource(ctx context.Context, source ports.SettlementSource) (int, error) {
+	names, err := source.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list settlement reports: %w", err)
+	}
+
+	reconciled := 0
+	for _, name := range names {
+		if ctx.Err() != nil {
+			break
+		}
+		report, err := source.Read(ctx, name)
+		if err != nil {
+			log.Printf("reconciliation: skipping %s: %v", name, err)
+			continue
+		}
+		_, existed, err := r.Reconcile(ctx, report)
+		if err != nil {
+			return reconciled, fmt.Errorf("failed to reconcile %s: %w", name, err)
+		}
+		if !existed {
+			reconciled++
+		}
+	}
+	return reconciled, nil
+}
+
+func (r *Reconciler) ListReconciliations(ctx context.Context, limit int64) ([]*domain.Reconciliation, error) {
+	return r.reconciliations.List(ctx, limit)
+}
+
+func (r *Reconciler) GetReconciliation(ctx context.Context, id string) (*domain.Reconciliation, error) {
+	return r.reconciliations.FindByID(ctx, id)
+}
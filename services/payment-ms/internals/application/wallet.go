@@ -0,0 +1,285 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"ecom-api/pkg/money"
+	"fmt"
+	"payment-microservice/internals/domain"
+	"time"
+)
+
+// giftCardAlphabet leaves out letters and digits that are easily mixed up.
+const giftCardAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+func (s *PaymentServiceImplement) GetWallet(ctx context.Context, userID string) (*domain.Wallet, error) {
+	credit, err := s.wallets.Credit(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load store credit: %w", err)
+	}
+	cards, err := s.wallets.ListGiftCards(ctx, userID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gift cards: %w", err)
+	}
+	return domain.NewWallet(userID, credit, cards, time.Now()), nil
+}
+
+func (s *PaymentServiceImplement) ListWalletTransactions(ctx context.Context, userID string, limit int64) ([]*domain.WalletTransaction, error) {
+	return s.wallets.ListTransactions(ctx, userID, limit)
+}
+
+func (s *PaymentServiceImplement) AddGiftCard(ctx context.Context, userID, code string) (*domain.GiftCard, error) {
+	g, err := s.wallets.FindGiftCard(ctx, domain.NormalizeGiftCardCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if g.UserID != "" && g.UserID != userID {
+		return nil, domain.ErrGiftCardClaimed
+	}
+	if g.Expired(time.Now()) {
+		return nil, fmt.Errorf("%w: on %s", domain.ErrGiftCardExpired, g.ExpiresAt.Format(time.DateOnly))
+	}
+
+	ok, err := s.wallets.ClaimGiftCard(ctx, g.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add gift card: %w", err)
+	}
+	if !ok {
+		return nil, domain.ErrGiftCardClaimed
+	}
+	g.UserID = userID
+	return g, nil
+}
+
+// IssueGiftCard issues a gift card for its initial balance and books it
+// as store credit given away.
+func (s *PaymentServiceImplement) IssueGiftCard(ctx context.Context, g *domain.GiftCard) (*domain.GiftCard, error) {
+	g.Code = domain.NormalizeGiftCardCode(g.Code)
+	if g.Code == "" {
+		code, err := newGiftCardCode()
+		if err != nil {
+			return nil, err
+		}
+		g.Code = code
+	}
+	g.Balance = g.Initial
+	g.IssuedAt = time.Now()
+	g.Redemptions = []domain.GiftCardRedemption{}
+	if err := g.Validate(g.IssuedAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.wallets.CreateGiftCard(ctx, g); err != nil {
+		return nil, err
+	}
+	entry := domain.CreditIssued("gift_card:"+g.ID, g.Initial, "gift card ending in "+g.Code[len(g.Code)-4:])
+	if _, err := s.post(ctx, entry); err != nil {
+		fmt.Println("warning: failed to post journal entry", entry.Key, err)
+	}
+	return g, nil
+}
+
+func (s *PaymentServiceImplement) ListGiftCards(ctx context.Context, userID string, limit int64) ([]*domain.GiftCard, error) {
+	return s.wallets.ListGiftCards(ctx, userID, limit)
+}
+
+func (s *PaymentServiceImplement) GetGiftCard(ctx context.Context, code string) (*domain.GiftCard, error) {
+	return s.wallets.FindGiftCard(ctx, domain.NormalizeGiftCardCode(code))
+}
+
+// GrantStoreCredit adds store credit to a user's wallet, e.g. as a
+// goodwill gesture. A reference granted before is not credited again.
+func (s *PaymentServiceImplement) GrantStoreCredit(ctx context.Context, userID, reference string, amount money.Money, reason string) (*domain.Wallet, error) {
+	if userID == "" || reference == "" {
+		return nil, fmt.Errorf("%w: user and reference are required", domain.ErrInvalidCredit)
+	}
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("%w: amount must be positive", domain.ErrInvalidCredit)
+	}
+
+	key := domain.TxnGrant + ":" + userID + ":" + reference
+	ok, err := s.wallets.AddCredit(ctx, &domain.WalletTransaction{
+		Key:       key,
+		UserID:    userID,
+		Kind:      domain.TxnGrant,
+		Source:    domain.SourceStoreCredit,
+		Amount:    amount,
+		Reference: reference,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		entry := domain.CreditIssued(key, amount, reason)
+		if _, err := s.post(ctx, entry); err != nil {
+			fmt.Println("warning: failed to post journal entry", entry.Key, err)
+		}
+	}
+	return s.GetWallet(ctx, userID)
+}
+
+// payFromWallet takes the wallet's part of a payment as a PENDING store
+// credit payment, which is settled together with the payment that charges
+// the rest. It returns nil when the wallet pays nothing.
+func (s *PaymentServiceImplement) payFromWallet(ctx context.Context, p *domain.Payment, tender domain.Tender) (*domain.Payment, error) {
+	if !tender.UseWallet {
+		return nil, nil
+	}
+	want := p.Amount
+	if a := tender.WalletAmount; a != nil {
+		if a.Currency != want.Currency || !a.IsPositive() {
+			return nil, fmt.Errorf("%w: wallet amount %s does not fit an order of %s", domain.ErrInvalidTender, a, want)
+		}
+		if cmp, _ := a.Cmp(want); cmp < 0 {
+			want = *a
+		}
+	}
+
+	wallet, err := s.GetWallet(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	available := wallet.AvailableIn(want.Currency)
+	if cmp, _ := want.Cmp(available); cmp > 0 {
+		if tender.WalletAmount != nil {
+			return nil, fmt.Errorf("%w: %s requested but the wallet has %s", domain.ErrInsufficientCredit, want, available)
+		}
+		want = available
+	}
+	if !want.IsPositive() {
+		return nil, nil
+	}
+
+	wp, err := s.repo.Create(ctx, &domain.Payment{
+		OrderID:     p.OrderID,
+		UserID:      p.UserID,
+		Amount:      want,
+		Status:      domain.StatusPending,
+		Method:      domain.MethodStoreCredit,
+		ScreeningID: p.ScreeningID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to persist store credit payment: %w", err)
+	}
+	if err := s.spendWallet(ctx, wp, wallet.Plan(want)); err != nil {
+		if _, serr := s.repo.Settle(ctx, wp.ID, domain.StatusPending, domain.StatusFailed); serr != nil {
+			fmt.Println("warning: failed to fail store credit payment", wp.ID, serr)
+		}
+		return nil, err
+	}
+	return wp, nil
+}
+
+// spendWallet takes the draws of a store credit payment from the wallet.
+// If the wallet changed meanwhile, what was taken is given back.
+func (s *PaymentServiceImplement) spendWallet(ctx context.Context, p *domain.Payment, draws []domain.WalletDraw) error {
+	now := time.Now()
+	spent := money.Zero(p.Amount.Currency)
+	for _, d := range draws {
+		t := &domain.WalletTransaction{
+			Key:        domain.TxnPayment + ":" + p.ID + ":" + d.Source + ":" + d.GiftCardID,
+			UserID:     p.UserID,
+			Kind:       domain.TxnPayment,
+			Source:     d.Source,
+			GiftCardID: d.GiftCardID,
+			Amount:     money.New(-d.Amount.Amount, d.Amount.Currency),
+			PaymentID:  p.ID,
+			OrderID:    p.OrderID,
+			CreatedAt:  now,
+		}
+		var ok bool
+		var err error
+		if d.Source == domain.SourceGiftCard {
+			red := domain.GiftCardRedemption{PaymentID: p.ID, OrderID: p.OrderID, Amount: d.Amount, RedeemedAt: now}
+			ok, err = s.wallets.SpendGiftCard(ctx, d.GiftCardID, red, t)
+		} else {
+			ok, err = s.wallets.SpendCredit(ctx, t)
+		}
+		if ok {
+			spent, _ = spent.Add(d.Amount)
+		}
+		if err == nil && !ok {
+			err = fmt.Errorf("%w: the wallet changed while paying %s", domain.ErrInsufficientCredit, p.Amount)
+		}
+		if err != nil {
+			if spent.IsPositive() {
+				s.returnToWallet(ctx, p, domain.TxnRelease, "", spent, "payment could not be taken from the wallet")
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// returnToWallet pays money of a payment back as store credit, once per
+// kind and reference.
+func (s *PaymentServiceImplement) returnToWallet(ctx context.Context, p *domain.Payment, kind, reference string, amount money.Money, reason string) error {
+	key := kind + ":" + p.ID
+	if reference != "" {
+		key += ":" + reference
+	}
+	_, err := s.wallets.AddCredit(ctx, &domain.WalletTransaction{
+		Key:       key,
+		UserID:    p.UserID,
+		Kind:      kind,
+		Source:    domain.SourceStoreCredit,
+		Amount:    amount,
+		PaymentID: p.ID,
+		OrderID:   p.OrderID,
+		Reference: reference,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		fmt.Println("warning: failed to return", amount, "of payment", p.ID, "to the wallet:", err)
+	}
+	return err
+}
+
+// releaseCredit fails the store credit payment of a split whose other
+// part could not be created, which gives its money back to the wallet.
+func (s *PaymentServiceImplement) releaseCredit(ctx context.Context, credit *domain.Payment) {
+	if credit == nil {
+		return
+	}
+	if err := s.settle(ctx, credit, domain.StatusFailed, "the rest of the order could not be charged"); err != nil {
+		fmt.Println("warning: failed to release store credit payment", credit.ID, err)
+	}
+}
+
+// settleSplit settles the store credit payment that pays part of p's
+// order the way p was settled: captured with it, or given back when p
+// failed.
+func (s *PaymentServiceImplement) settleSplit(ctx context.Context, p *domain.Payment) {
+	if p.SplitWith == "" || (p.Status != domain.StatusCompleted && p.Status != domain.StatusFailed) {
+		return
+	}
+	wp, err := s.repo.FindByID(ctx, p.SplitWith)
+	if err != nil {
+		fmt.Println("warning: failed to load store credit payment", p.SplitWith, err)
+		return
+	}
+	if wp.Status != domain.StatusPending {
+		return
+	}
+	reason := ""
+	if p.Status == domain.StatusFailed {
+		reason = "the rest of the order could not be charged"
+	}
+	if err := s.settle(ctx, wp, p.Status, reason); err != nil {
+		fmt.Println("warning: failed to settle store credit payment", wp.ID, err)
+	}
+}
+
+func newGiftCardCode() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate gift card code: %w", err)
+	}
+	for i := range b {
+		b[i] = giftCardAlphabet[int(b[i])%len(giftCardAlphabet)]
+	}
+	return string(b), nil
+}
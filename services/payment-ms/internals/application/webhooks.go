@@ -0,0 +1,198 @@
+package application
+
+import (
+	"context"
+	"ecom-api/pkg/webhook"
+	"encoding/json"
+	"fmt"
+	"log"
+	"payment-microservice/internals/domain"
+	"time"
+)
+
+const (
+	// maxWebhookAttempts is how often a failed event is replayed on its
+	// own; after that it waits for an admin to replay it.
+	maxWebhookAttempts = 10
+	replayBatchSize    = 100
+	// stuckWebhookAge is how long an event may stay received before it is
+	// taken for interrupted and replayed.
+	stuckWebhookAge = 5 * time.Minute
+)
+
+// WebhookConfig holds the shared secrets of the payment providers allowed
+// to post events, and how far their timestamps may be off.
+type WebhookConfig struct {
+	Secrets   map[string][]string // provider → secrets
+	Tolerance time.Duration
+}
+
+// ReceiveWebhook verifies a provider event, stores it and applies it to
+// its payment and order. An event that was received before is reported as
+// a duplicate and not applied again. An event that cannot be applied is
+// kept as failed, without an error, so the provider stops resending it and
+// it is replayed from the store instead.
+func (s *PaymentServiceImplement) ReceiveWebhook(ctx context.Context, provider, signature string, body []byte) (*domain.WebhookEvent, bool, error) {
+	secrets, ok := s.webhooks.Secrets[provider]
+	if !ok {
+		return nil, false, fmt.Errorf("%w: %s", domain.ErrUnknownProvider, provider)
+	}
+	if err := webhook.Verify(signature, body, secrets, s.webhooks.Tolerance, time.Now()); err != nil {
+		return nil, false, err
+	}
+
+	var pe domain.ProviderEvent
+	if err := json.Unmarshal(body, &pe); err != nil {
+		return nil, false, fmt.Errorf("%w: %v", domain.ErrInvalidWebhook, err)
+	}
+	if pe.ID == "" || pe.Type == "" {
+		return nil, false, fmt.Errorf("%w: id and type are required", domain.ErrInvalidWebhook)
+	}
+
+	event := &domain.WebhookEvent{
+		ID:         domain.WebhookEventID(provider, pe.ID),
+		Provider:   provider,
+		EventID:    pe.ID,
+		Type:       pe.Type,
+		PaymentID:  pe.Data.PaymentID,
+		Payload:    string(body),
+		Status:     domain.WebhookReceived,
+		ReceivedAt: time.Now(),
+	}
+	created, err := s.webhookEvents.Create(ctx, event)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to store webhook event: %w", err)
+	}
+	if !created {
+		existing, err := s.webhookEvents.FindByID(ctx, event.ID)
+		return existing, true, err
+	}
+
+	return s.processWebhook(ctx, event, &pe), false, nil
+}
+
+// ListWebhookEvents returns the latest received events, optionally only
+// those in status.
+func (s *PaymentServiceImplement) ListWebhookEvents(ctx context.Context, status string, limit int64) ([]*domain.WebhookEvent, error) {
+	return s.webhookEvents.List(ctx, status, limit)
+}
+
+// ReplayWebhookEvent applies a stored event again, whatever its status and
+// attempts. Events that were processed are left alone.
+func (s *PaymentServiceImplement) ReplayWebhookEvent(ctx context.Context, id string) (*domain.WebhookEvent, error) {
+	event, err := s.webhookEvents.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == domain.WebhookProcessed {
+		return event, nil
+	}
+	return s.replay(ctx, event), nil
+}
+
+// ReplayFailedWebhooks replays a batch of failed and interrupted events
+// and returns how many of them were processed now.
+func (s *PaymentServiceImplement) ReplayFailedWebhooks(ctx context.Context) (int, error) {
+	events, err := s.webhookEvents.FindReplayable(ctx, maxWebhookAttempts, time.Now().Add(-stuckWebhookAge), replayBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find webhook events to replay: %w", err)
+	}
+
+	processed := 0
+	for _, e := range events {
+		if ctx.Err() != nil {
+			break
+		}
+		if s.replay(ctx, e).Status == domain.WebhookProcessed {
+			processed++
+		}
+	}
+	if len(events) > 0 {
+		log.Printf("webhooks: replayed %d events, %d processed", len(events), processed)
+	}
+	return processed, nil
+}
+
+// replay applies a stored event from its payload. Its signature was checked
+// when it was received, and its timestamp is too old to check again.
+func (s *PaymentServiceImplement) replay(ctx context.Context, event *domain.WebhookEvent) *domain.WebhookEvent {
+	var pe domain.ProviderEvent
+	if err := json.Unmarshal([]byte(event.Payload), &pe); err != nil {
+		s.failWebhook(ctx, event, fmt.Errorf("%w: %v", domain.ErrInvalidWebhook, err))
+		return event
+	}
+	return s.processWebhook(ctx, event, &pe)
+}
+
+// processWebhook applies an event and records the outcome on it.
+func (s *PaymentServiceImplement) processWebhook(ctx context.Context, event *domain.WebhookEvent, pe *domain.ProviderEvent) *domain.WebhookEvent {
+	event.Attempts++
+	if err := s.applyProviderEvent(ctx, pe); err != nil {
+		s.failWebhook(ctx, event, err)
+		return event
+	}
+
+	now := time.Now()
+	event.Status, event.ProcessedAt, event.LastError = domain.WebhookProcessed, &now, ""
+	if err := s.webhookEvents.MarkProcessed(ctx, event.ID); err != nil {
+		fmt.Println("warning: failed to mark webhook event processed", event.ID, err)
+	}
+	return event
+}
+
+func (s *PaymentServiceImplement) failWebhook(ctx context.Context, event *domain.WebhookEvent, cause error) {
+	event.Status, event.LastError = domain.WebhookFailed, cause.Error()
+	if err := s.webhookEvents.MarkFailed(ctx, event.ID, cause.Error()); err != nil {
+		fmt.Println("warning: failed to mark webhook event failed", event.ID, err)
+	}
+}
+
+// applyProviderEvent settles the payment the event is about and passes the
+// outcome on to order-ms. A payment that was settled by an earlier attempt
+// only has its order updated, so replaying an event is safe.
+func (s *PaymentServiceImplement) applyProviderEvent(ctx context.Context, pe *domain.ProviderEvent) error {
+	to, err := pe.Transition()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, pe.Type)
+	}
+
+	payment, err := s.repo.FindByID(ctx, pe.Data.PaymentID)
+	if err != nil {
+		return fmt.Errorf("payment %s: %w", pe.Data.PaymentID, err)
+	}
+	if payment.Method == domain.MethodStoreCredit {
+		return fmt.Errorf("%w: payment %s is paid with store credit, not by the provider", domain.ErrInvalidWebhook, payment.ID)
+	}
+	if pe.Data.OrderID != "" && pe.Data.OrderID != payment.OrderID {
+		return fmt.Errorf("%w: payment %s belongs to order %s, not %s", domain.ErrInvalidWebhook, payment.ID, payment.OrderID, pe.Data.OrderID)
+	}
+	if to == domain.StatusCompleted && pe.Data.Amount != payment.Amount {
+		return fmt.Errorf("%w: provider captured %s but payment %s is for %s", domain.ErrInvalidWebhook, pe.Data.Amount, payment.ID, payment.Amount)
+	}
+
+	switch payment.Status {
+	case to:
+	case domain.StatusPending:
+		held, err := s.heldForReview(ctx, payment)
+		if err != nil {
+			return fmt.Errorf("failed to check fraud review of payment %s: %w", payment.ID, err)
+		}
+		if held {
+			return fmt.Errorf("%w: payment %s is held for fraud review", domain.ErrPaymentStateConflict, payment.ID)
+		}
+		if err := s.settle(ctx, payment, to, pe.Data.FailureReason); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: payment %s is %s, provider reports %s", domain.ErrPaymentStateConflict, payment.ID, payment.Status, pe.Type)
+	}
+
+	order, err := s.orderClient.GetOrder(ctx, payment.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch order %s: %w", payment.OrderID, err)
+	}
+	if order.Status == to {
+		return nil
+	}
+	return s.orderClient.UpdateOrderStatus(ctx, payment.OrderID, to)
+}
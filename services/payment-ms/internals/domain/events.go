@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"time"
+)
+
+// Events announcing payment changes. Their subject is the payment ID.
+const (
+	EventPaymentCompleted = "payment.completed"
+	EventPaymentFailed    = "payment.failed"
+	EventPaymentVoided    = "payment.voided"
+	EventPaymentRefunded  = "payment.refunded"
+)
+
+// PaymentEvent is the payload of the payment events. Amount is what the
+// event moved: the captured amount, or the amount voided or refunded.
+type PaymentEvent struct {
+	PaymentID  string      `json:"payment_id" bson:"payment_id"`
+	OrderID    string      `json:"order_id" bson:"order_id"`
+	UserID     string      `json:"user_id" bson:"user_id"`
+	Status     string      `json:"status" bson:"status"`
+	Amount     money.Money `json:"amount" bson:"amount"`
+	Partial    bool        `json:"partial,omitempty" bson:"partial,omitempty"` // a refund that leaves money on the payment
+	Reference  string      `json:"reference,omitempty" bson:"reference,omitempty"`
+	Reason     string      `json:"reason,omitempty" bson:"reason,omitempty"`
+	OccurredAt time.Time   `json:"occurred_at" bson:"occurred_at"`
+}
+
+// StatusEvent returns the event announcing that a payment reached its
+// current status, if there is one for it.
+func (p *Payment) StatusEvent() (string, bool) {
+	switch p.Status {
+	case StatusCompleted:
+		return EventPaymentCompleted, true
+	case StatusFailed:
+		return EventPaymentFailed, true
+	case StatusVoided:
+		return EventPaymentVoided, true
+	case StatusRefunded:
+		return EventPaymentRefunded, true
+	}
+	return "", false
+}
@@ -0,0 +1,299 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPaymentDenied     = errors.New("payment denied by fraud screening")
+	ErrOrderNotOwned     = errors.New("order belongs to another user")
+	ErrScreeningNotFound = errors.New("fraud screening not found")
+	ErrNotUnderReview    = errors.New("payment is not waiting for review")
+	ErrInvalidFraudRules = errors.New("invalid fraud rules")
+)
+
+// Fraud actions, from mildest to strictest
+const (
+	FraudAllow  = "allow"
+	FraudReview = "review" // hold the payment until an admin approves it
+	FraudDeny   = "deny"
+)
+
+// fraudActionRank orders the actions so the strictest one wins.
+var fraudActionRank = map[string]int{FraudAllow: 0, FraudReview: 1, FraudDeny: 2}
+
+// Rule kinds
+const (
+	RuleUserVelocity        = "user_velocity"         // payments by the user within the window
+	RuleCardVelocity        = "card_velocity"         // payments with the card within the window
+	RuleIPVelocity          = "ip_velocity"           // payments from the IP within the window
+	RuleAmountThreshold     = "amount_threshold"      // orders of at least the amount
+	RuleCardCountryMismatch = "card_country_mismatch" // card issued elsewhere than the shipping country
+	RuleIPCountryMismatch   = "ip_country_mismatch"   // paid from elsewhere than the shipping country
+)
+
+// FraudRule adds its score to a payment it matches and asks for its
+// action. Velocity rules match once Limit payments were screened within
+// Window before; the amount threshold matches orders of at least Amount
+// in Currency.
+type FraudRule struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Score    int    `json:"score"`
+	Action   string `json:"action"` // allow, review or deny
+	Limit    int    `json:"limit,omitempty"`
+	Window   string `json:"window,omitempty"` // e.g. 1h
+	Amount   string `json:"amount,omitempty"` // major units, e.g. 1000.00
+	Currency string `json:"currency,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// IsVelocity reports whether the rule counts recent payments.
+func (r FraudRule) IsVelocity() bool {
+	return r.Kind == RuleUserVelocity || r.Kind == RuleCardVelocity || r.Kind == RuleIPVelocity
+}
+
+// WindowDuration is the window of a velocity rule.
+func (r FraudRule) WindowDuration() time.Duration {
+	d, _ := time.ParseDuration(r.Window)
+	return d
+}
+
+// VelocityValue returns the user, card or IP a velocity rule counts the
+// payments of, or "" when the check does not have it.
+func (r FraudRule) VelocityValue(c FraudCheck) string {
+	switch r.Kind {
+	case RuleUserVelocity:
+		return c.UserID
+	case RuleCardVelocity:
+		return c.CardFingerprint
+	case RuleIPVelocity:
+		return c.IP
+	}
+	return ""
+}
+
+// FraudConfig is the rule set. Besides the actions of the rules that
+// match, a payment is reviewed once its total score reaches ReviewScore
+// and denied once it reaches DenyScore; zero disables a threshold.
+type FraudConfig struct {
+	ReviewScore int         `json:"review_score"`
+	DenyScore   int         `json:"deny_score"`
+	Rules       []FraudRule `json:"rules"`
+}
+
+// DefaultFraudConfig is used when no rules are configured.
+func DefaultFraudConfig() FraudConfig {
+	return FraudConfig{
+		ReviewScore: 50,
+		DenyScore:   90,
+		Rules: []FraudRule{
+			{Name: "user_velocity", Kind: RuleUserVelocity, Score: 40, Action: FraudReview, Limit: 5, Window: "1h"},
+			{Name: "card_velocity", Kind: RuleCardVelocity, Score: 40, Action: FraudReview, Limit: 3, Window: "1h"},
+			{Name: "ip_velocity", Kind: RuleIPVelocity, Score: 60, Action: FraudDeny, Limit: 20, Window: "1h"},
+			{Name: "large_order", Kind: RuleAmountThreshold, Score: 30, Action: FraudReview, Amount: "1000.00", Currency: money.DefaultCurrency},
+			{Name: "card_country_mismatch", Kind: RuleCardCountryMismatch, Score: 25, Action: FraudAllow},
+			{Name: "ip_country_mismatch", Kind: RuleIPCountryMismatch, Score: 15, Action: FraudAllow},
+		},
+	}
+}
+
+// LoadFraudConfig reads a rule set from a JSON file; without a path the
+// default rules apply.
+func LoadFraudConfig(path string) (FraudConfig, error) {
+	if path == "" {
+		return DefaultFraudConfig(), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return FraudConfig{}, fmt.Errorf("failed to read fraud rules %s: %w", path, err)
+	}
+
+	var cfg FraudConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return FraudConfig{}, fmt.Errorf("%w: %s: %v", ErrInvalidFraudRules, path, err)
+	}
+	return cfg, cfg.Validate()
+}
+
+func (cfg FraudConfig) Validate() error {
+	if cfg.ReviewScore < 0 || cfg.DenyScore < 0 {
+		return fmt.Errorf("%w: thresholds cannot be negative", ErrInvalidFraudRules)
+	}
+	names := map[string]bool{}
+	for _, r := range cfg.Rules {
+		if r.Name == "" || names[r.Name] {
+			return fmt.Errorf("%w: rule names must be set and unique, got %q", ErrInvalidFraudRules, r.Name)
+		}
+		names[r.Name] = true
+		if _, ok := fraudActionRank[r.Action]; !ok {
+			return fmt.Errorf("%w: rule %s has unknown action %q", ErrInvalidFraudRules, r.Name, r.Action)
+		}
+		switch r.Kind {
+		case RuleUserVelocity, RuleCardVelocity, RuleIPVelocity:
+			if r.Limit <= 0 || r.WindowDuration() <= 0 {
+				return fmt.Errorf("%w: rule %s needs a positive limit and window", ErrInvalidFraudRules, r.Name)
+			}
+		case RuleAmountThreshold:
+			if _, err := money.Parse(r.Amount, r.Currency); err != nil {
+				return fmt.Errorf("%w: rule %s has amount %q in %q: %v", ErrInvalidFraudRules, r.Name, r.Amount, r.Currency, err)
+			}
+		case RuleCardCountryMismatch, RuleIPCountryMismatch:
+		default:
+			return fmt.Errorf("%w: rule %s has unknown kind %q", ErrInvalidFraudRules, r.Name, r.Kind)
+		}
+	}
+	return nil
+}
+
+// FraudCheck is what a payment is screened on. Card and client details
+// are optional; rules that need missing ones do not match.
+type FraudCheck struct {
+	OrderID         string
+	UserID          string
+	Amount          money.Money
+	CardFingerprint string
+	CardCountry     string
+	IP              string
+	IPCountry       string
+	ShippingCountry string
+	// Recent counts the payments screened within each velocity rule's
+	// window, by rule name.
+	Recent map[string]int
+}
+
+// FraudHit is a rule a payment matched.
+type FraudHit struct {
+	Rule   string `json:"rule" bson:"rule"`
+	Kind   string `json:"kind" bson:"kind"`
+	Score  int    `json:"score" bson:"score"`
+	Action string `json:"action" bson:"action"`
+	Detail string `json:"detail" bson:"detail"`
+}
+
+// Review statuses of a screening held for review
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// Screening records how a payment attempt was screened. Screenings of
+// held payments double as the review queue.
+type Screening struct {
+	ID              string      `json:"id" bson:"_id,omitempty"`
+	OrderID         string      `json:"order_id" bson:"order_id"`
+	UserID          string      `json:"user_id" bson:"user_id"`
+	Amount          money.Money `json:"amount" bson:"amount"`
+	CardFingerprint string      `json:"card_fingerprint,omitempty" bson:"card_fingerprint,omitempty"`
+	IP              string      `json:"ip,omitempty" bson:"ip,omitempty"`
+	Score           int         `json:"score" bson:"score"`
+	Action          string      `json:"action" bson:"action"` // allow, review or deny
+	Hits            []FraudHit  `json:"hits" bson:"hits"`
+	ScreenedAt      time.Time   `json:"screened_at" bson:"screened_at"`
+	ReviewStatus    string      `json:"review_status,omitempty" bson:"review_status,omitempty"` // pending, approved or rejected
+	ReviewedBy      string      `json:"reviewed_by,omitempty" bson:"reviewed_by,omitempty"`
+	ReviewNote      string      `json:"review_note,omitempty" bson:"review_note,omitempty"`
+	ReviewedAt      *time.Time  `json:"reviewed_at,omitempty" bson:"reviewed_at,omitempty"`
+}
+
+// ScreeningFilter narrows screenings down; empty fields match all.
+type ScreeningFilter struct {
+	Action       string
+	ReviewStatus string
+	UserID       string
+}
+
+// Screen runs the rules over a payment attempt.
+func (cfg FraudConfig) Screen(c FraudCheck, at time.Time) *Screening {
+	s := &Screening{
+		OrderID:         c.OrderID,
+		UserID:          c.UserID,
+		Amount:          c.Amount,
+		CardFingerprint: c.CardFingerprint,
+		IP:              c.IP,
+		Action:          FraudAllow,
+		Hits:            []FraudHit{},
+		ScreenedAt:      at,
+	}
+
+	for _, r := range cfg.Rules {
+		if r.Disabled {
+			continue
+		}
+		detail, ok := r.match(c)
+		if !ok {
+			continue
+		}
+		s.Hits = append(s.Hits, FraudHit{Rule: r.Name, Kind: r.Kind, Score: r.Score, Action: r.Action, Detail: detail})
+		s.Score += r.Score
+		s.escalate(r.Action)
+	}
+	if cfg.ReviewScore > 0 && s.Score >= cfg.ReviewScore {
+		s.escalate(FraudReview)
+	}
+	if cfg.DenyScore > 0 && s.Score >= cfg.DenyScore {
+		s.escalate(FraudDeny)
+	}
+	if s.Action == FraudReview {
+		s.ReviewStatus = ReviewPending
+	}
+	return s
+}
+
+func (s *Screening) escalate(action string) {
+	if fraudActionRank[action] > fraudActionRank[s.Action] {
+		s.Action = action
+	}
+}
+
+func (r FraudRule) match(c FraudCheck) (string, bool) {
+	switch r.Kind {
+	case RuleUserVelocity, RuleCardVelocity, RuleIPVelocity:
+		if r.VelocityValue(c) == "" {
+			return "", false
+		}
+		n := c.Recent[r.Name]
+		return fmt.Sprintf("%d payments within %s, limit %d", n, r.Window, r.Limit), n >= r.Limit
+	case RuleAmountThreshold:
+		threshold, err := money.Parse(r.Amount, r.Currency)
+		if err != nil || c.Amount.Currency != threshold.Currency {
+			return "", false
+		}
+		return fmt.Sprintf("%s is at least %s", c.Amount, threshold), c.Amount.Amount >= threshold.Amount
+	case RuleCardCountryMismatch:
+		return countryMismatch("card", c.CardCountry, c.ShippingCountry)
+	case RuleIPCountryMismatch:
+		return countryMismatch("IP", c.IPCountry, c.ShippingCountry)
+	}
+	return "", false
+}
+
+func countryMismatch(what, country, shipping string) (string, bool) {
+	if country == "" || shipping == "" || strings.EqualFold(country, shipping) {
+		return "", false
+	}
+	return fmt.Sprintf("%s country %s, shipping to %s", what, strings.ToUpper(country), strings.ToUpper(shipping)), true
+}
+
+// Card describes the card a payment is made with, as the provider's
+// tokenization reports it. The fingerprint is the same for every token of
+// the same card.
+type Card struct {
+	Brand       string `json:"brand,omitempty" bson:"brand,omitempty"`
+	Last4       string `json:"last4,omitempty" bson:"last4,omitempty"`
+	Country     string `json:"country,omitempty" bson:"country,omitempty"` // ISO 3166-1 alpha-2 of the issuer
+	Fingerprint string `json:"fingerprint,omitempty" bson:"fingerprint,omitempty"`
+}
+
+// ClientInfo is where a payment attempt comes from, when known.
+type ClientInfo struct {
+	IP      string
+	Country string // ISO 3166-1 alpha-2, as geolocated by the edge proxy
+}
@@ -0,0 +1,344 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnbalancedEntry     = errors.New("journal entry does not balance")
+	ErrUnknownAccount      = errors.New("unknown ledger account")
+	ErrEntryNotFound       = errors.New("journal entry not found")
+	ErrInvalidPayout       = errors.New("invalid payout")
+	ErrInvalidStatusChange = errors.New("payment status cannot be changed this way")
+)
+
+// Ledger accounts
+const (
+	AccountAuthorizations     = "authorizations"      // funds customers' banks hold for us
+	AccountAuthorizationHolds = "authorization_holds" // counterpart of the holds until capture or release
+	AccountProviderReceivable = "provider_receivable" // captured funds the provider holds for us
+	AccountSales              = "sales"               // captured customer payments
+	AccountRefunds            = "refunds"             // money paid back to customers
+	AccountProcessingFees     = "processing_fees"     // what the provider charges per payment
+	AccountBank               = "bank"                // payouts received from the provider
+	AccountStoreCredit        = "store_credit"        // store credit and gift card balances owed to customers
+	AccountCreditIssued       = "credit_issued"       // store credit and gift cards given away
+)
+
+// Account types
+const (
+	AccountAsset     = "asset"
+	AccountLiability = "liability"
+	AccountRevenue   = "revenue"
+	AccountExpense   = "expense"
+)
+
+// Account is an account of the chart. Asset and expense accounts grow
+// with debits, the others with credits.
+type Account struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Type string `json:"type"` // asset, liability, revenue or expense
+}
+
+// DebitNormal reports whether debits increase the account's balance.
+func (a Account) DebitNormal() bool {
+	return a.Type == AccountAsset || a.Type == AccountExpense
+}
+
+// Accounts is the chart of accounts.
+var Accounts = []Account{
+	{Code: AccountAuthorizations, Name: "Authorizations", Type: AccountAsset},
+	{Code: AccountAuthorizationHolds, Name: "Authorization holds", Type: AccountLiability},
+	{Code: AccountProviderReceivable, Name: "Receivable from payment provider", Type: AccountAsset},
+	{Code: AccountSales, Name: "Sales", Type: AccountRevenue},
+	{Code: AccountRefunds, Name: "Refunds", Type: AccountExpense},
+	{Code: AccountProcessingFees, Name: "Processing fees", Type: AccountExpense},
+	{Code: AccountBank, Name: "Bank", Type: AccountAsset},
+	{Code: AccountStoreCredit, Name: "Store credit owed to customers", Type: AccountLiability},
+	{Code: AccountCreditIssued, Name: "Store credit issued", Type: AccountExpense},
+}
+
+// FindAccount looks an account up by code.
+func FindAccount(code string) (Account, bool) {
+	for _, a := range Accounts {
+		if a.Code == code {
+			return a, true
+		}
+	}
+	return Account{}, false
+}
+
+// Kinds of journal entries
+const (
+	EntryAuthorization = "authorization" // funds put on hold for a payment
+	EntryCapture       = "capture"       // held funds taken
+	EntryRelease       = "release"       // hold dropped, for a failed or voided payment
+	EntryRefund        = "refund"
+	EntryFee           = "fee"
+	EntryPayout        = "payout"
+	EntryCreditIssued  = "credit_issued" // store credit or a gift card given away
+	EntryReversal      = "reversal"      // undoes an earlier entry
+)
+
+// Posting directions
+const (
+	Debit  = "debit"
+	Credit = "credit"
+)
+
+// Posting moves an amount into one side of an account.
+type Posting struct {
+	Account   string      `json:"account" bson:"account"`
+	Direction string      `json:"direction" bson:"direction"` // debit or credit
+	Amount    money.Money `json:"amount" bson:"amount"`
+}
+
+// JournalEntry is a balanced set of postings. Entries are immutable: a
+// mistake is corrected by posting a reversal. The key identifies the
+// movement an entry records, so the same movement is never posted twice.
+type JournalEntry struct {
+	ID         string    `json:"id" bson:"_id,omitempty"`
+	Key        string    `json:"key" bson:"key"`
+	Kind       string    `json:"kind" bson:"kind"`
+	PaymentID  string    `json:"payment_id,omitempty" bson:"payment_id,omitempty"`
+	OrderID    string    `json:"order_id,omitempty" bson:"order_id,omitempty"`
+	ReversalOf string    `json:"reversal_of,omitempty" bson:"reversal_of,omitempty"`
+	Memo       string    `json:"memo,omitempty" bson:"memo,omitempty"`
+	Postings   []Posting `json:"postings" bson:"postings"`
+	OccurredAt time.Time `json:"occurred_at" bson:"occurred_at"` // when the money moved
+	PostedAt   time.Time `json:"posted_at" bson:"posted_at"`
+}
+
+// Validate checks that the entry has postings to known accounts in one
+// currency and that its debits equal its credits.
+func (e *JournalEntry) Validate() error {
+	if e.Key == "" || e.Kind == "" {
+		return fmt.Errorf("%w: key and kind are required", ErrUnbalancedEntry)
+	}
+	if len(e.Postings) < 2 {
+		return fmt.Errorf("%w: %s has fewer than two postings", ErrUnbalancedEntry, e.Key)
+	}
+
+	currency := e.Postings[0].Amount.Currency
+	var debits, credits int64
+	for _, p := range e.Postings {
+		if _, ok := FindAccount(p.Account); !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownAccount, p.Account)
+		}
+		if !p.Amount.IsPositive() || p.Amount.Currency != currency {
+			return fmt.Errorf("%w: %s posts %s, want positive amounts in %s", ErrUnbalancedEntry, e.Key, p.Amount, currency)
+		}
+		switch p.Direction {
+		case Debit:
+			debits += p.Amount.Amount
+		case Credit:
+			credits += p.Amount.Amount
+		default:
+			return fmt.Errorf("%w: %s has a posting that is neither debit nor credit", ErrUnbalancedEntry, e.Key)
+		}
+	}
+	if debits != credits {
+		return fmt.Errorf("%w: %s debits %d and credits %d", ErrUnbalancedEntry, e.Key, debits, credits)
+	}
+	return nil
+}
+
+// Reversal returns the entry that undoes e.
+func (e *JournalEntry) Reversal(memo string) *JournalEntry {
+	out := &JournalEntry{
+		Key:        "reversal:" + e.ID,
+		Kind:       EntryReversal,
+		PaymentID:  e.PaymentID,
+		OrderID:    e.OrderID,
+		ReversalOf: e.ID,
+		Memo:       memo,
+	}
+	for _, p := range e.Postings {
+		if p.Direction == Debit {
+			p.Direction = Credit
+		} else {
+			p.Direction = Debit
+		}
+		out.Postings = append(out.Postings, p)
+	}
+	return out
+}
+
+// EntryFilter narrows entries and balances down; empty fields match all.
+type EntryFilter struct {
+	OrderID   string
+	PaymentID string
+	Account   string
+}
+
+// AccountBalance is what an account holds in one currency.
+type AccountBalance struct {
+	Account string      `json:"account"`
+	Type    string      `json:"type"`
+	Debits  money.Money `json:"debits"`
+	Credits money.Money `json:"credits"`
+	Balance money.Money `json:"balance"` // on the account's normal side
+}
+
+// NewAccountBalance computes the balance of an account from its totals.
+func NewAccountBalance(code string, debits, credits money.Money) AccountBalance {
+	a, _ := FindAccount(code)
+	balance, _ := debits.Sub(credits)
+	if !a.DebitNormal() {
+		balance, _ = credits.Sub(debits)
+	}
+	return AccountBalance{Account: code, Type: a.Type, Debits: debits, Credits: credits, Balance: balance}
+}
+
+// FeeSchedule is what the payment provider charges for a captured payment:
+// a percentage plus a fixed amount in major units of the payment currency.
+type FeeSchedule struct {
+	Percent float64
+	Fixed   string
+}
+
+// ParseFeeSchedule reads a schedule such as "2.9%+0.30", "1.5%" or "0.25".
+// An empty schedule charges nothing.
+func ParseFeeSchedule(v string) (FeeSchedule, error) {
+	var fs FeeSchedule
+	for _, part := range strings.Split(v, "+") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if pct, ok := strings.CutSuffix(part, "%"); ok {
+			p, err := strconv.ParseFloat(pct, 64)
+			if err != nil || p < 0 || p >= 100 {
+				return FeeSchedule{}, fmt.Errorf("invalid fee percentage %q", part)
+			}
+			fs.Percent = p
+			continue
+		}
+		if _, err := money.Parse(part, money.DefaultCurrency); err != nil {
+			return FeeSchedule{}, fmt.Errorf("invalid fixed fee %q: %w", part, err)
+		}
+		fs.Fixed = part
+	}
+	return fs, nil
+}
+
+// Fee returns the fee on a captured amount.
+func (fs FeeSchedule) Fee(amount money.Money) money.Money {
+	fee := amount.MulRate(fs.Percent / 100)
+	if fs.Fixed != "" {
+		if fixed, err := money.Parse(fs.Fixed, amount.Currency); err == nil {
+			fee, _ = fee.Add(fixed)
+		}
+	}
+	return fee
+}
+
+// JournalEntries returns the entries that record how a payment's money
+// moved so far, derived from its state. Posting them again is harmless, as
+// their keys are already taken.
+func (p *Payment) JournalEntries(fees FeeSchedule) []*JournalEntry {
+	if p.ID == "" || !p.Amount.IsPositive() {
+		return nil
+	}
+
+	entry := func(key, kind string, at time.Time, postings ...Posting) *JournalEntry {
+		return &JournalEntry{
+			Key:        key + ":" + p.ID,
+			Kind:       kind,
+			PaymentID:  p.ID,
+			OrderID:    p.OrderID,
+			Postings:   postings,
+			OccurredAt: at,
+		}
+	}
+	move := func(debit, credit string, amount money.Money) []Posting {
+		return []Posting{
+			{Account: debit, Direction: Debit, Amount: amount},
+			{Account: credit, Direction: Credit, Amount: amount},
+		}
+	}
+
+	// refunds come out of what the provider holds, unless they are paid
+	// as store credit
+	refundFrom := func(to string) string {
+		if to == RefundToStoreCredit {
+			return AccountStoreCredit
+		}
+		return AccountProviderReceivable
+	}
+
+	var out []*JournalEntry
+	if p.Method == MethodStoreCredit {
+		// the wallet is debited outright, there is no authorization or fee
+		if p.Status == StatusCompleted || p.Status == StatusRefunded {
+			out = append(out, entry(EntryCapture, EntryCapture, time.Time{}, move(AccountStoreCredit, AccountSales, p.Amount)...))
+		}
+	} else {
+		out = append(out, entry(EntryAuthorization, EntryAuthorization, time.Time{}, move(AccountAuthorizations, AccountAuthorizationHolds, p.Amount)...))
+
+		switch p.Status {
+		case StatusCompleted, StatusRefunded:
+			postings := append(move(AccountAuthorizationHolds, AccountAuthorizations, p.Amount), move(AccountProviderReceivable, AccountSales, p.Amount)...)
+			out = append(out, entry(EntryCapture, EntryCapture, time.Time{}, postings...))
+			if fee := fees.Fee(p.Amount); fee.IsPositive() {
+				out = append(out, entry(EntryFee, EntryFee, time.Time{}, move(AccountProcessingFees, AccountProviderReceivable, fee)...))
+			}
+		case StatusFailed, StatusVoided:
+			out = append(out, entry(EntryRelease, EntryRelease, time.Time{}, move(AccountAuthorizationHolds, AccountAuthorizations, p.Amount)...))
+		}
+	}
+
+	for _, r := range p.Refunds {
+		e := entry(EntryRefund, EntryRefund, r.RefundedAt, move(AccountRefunds, refundFrom(r.To), r.Amount)...)
+		e.Key += ":" + r.Reference
+		e.Memo = r.Reason
+		out = append(out, e)
+	}
+	if r := p.Reversal; r != nil && r.Kind == ReversalRefund && r.Amount.IsPositive() {
+		e := entry(EntryRefund, EntryRefund, r.ReversedAt, move(AccountRefunds, refundFrom(r.To), r.Amount)...)
+		e.Key += ":cancellation"
+		e.Memo = r.Reason
+		out = append(out, e)
+	}
+	return out
+}
+
+// Payout builds the entry for money the provider paid out to the bank.
+// Reference is the provider's payout ID.
+func Payout(reference string, amount money.Money, memo string) (*JournalEntry, error) {
+	if strings.TrimSpace(reference) == "" {
+		return nil, fmt.Errorf("%w: reference is required", ErrInvalidPayout)
+	}
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidPayout)
+	}
+	return &JournalEntry{
+		Key:  EntryPayout + ":" + reference,
+		Kind: EntryPayout,
+		Memo: memo,
+		Postings: []Posting{
+			{Account: AccountBank, Direction: Debit, Amount: amount},
+			{Account: AccountProviderReceivable, Direction: Credit, Amount: amount},
+		},
+	}, nil
+}
+
+// CreditIssued builds the entry for store credit or a gift card given
+// away. Key identifies what was issued, e.g. gift_card:<id>.
+func CreditIssued(key string, amount money.Money, memo string) *JournalEntry {
+	return &JournalEntry{
+		Key:  EntryCreditIssued + ":" + key,
+		Kind: EntryCreditIssued,
+		Memo: memo,
+		Postings: []Posting{
+			{Account: AccountCreditIssued, Direction: Debit, Amount: amount},
+			{Account: AccountStoreCredit, Direction: Credit, Amount: amount},
+		},
+	}
+}
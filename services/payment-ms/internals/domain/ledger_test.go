@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPaymentJournalEntries(t *testing.T) {
+	usd := func(cents int64) money.Money { return money.New(cents, "USD") }
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	fees := FeeSchedule{Percent: 2.9, Fixed: "0.30"}
+
+	tests := []struct {
+		name    string
+		payment Payment
+		keys    []string
+	}{
+		{
+			name:    "pending card payment is authorized",
+			payment: Payment{ID: "p1", Amount: usd(10000), Status: StatusPending, Method: MethodCard},
+			keys:    []string{"authorization:p1"},
+		},
+		{
+			name:    "completed card payment is captured with a fee",
+			payment: Payment{ID: "p1", Amount: usd(10000), Status: StatusCompleted, Method: MethodCard},
+			keys:    []string{"authorization:p1", "capture:p1", "fee:p1"},
+		},
+		{
+			name:    "failed payment releases the hold",
+			payment: Payment{ID: "p1", Amount: usd(10000), Status: StatusFailed},
+			keys:    []string{"authorization:p1", "release:p1"},
+		},
+		{
+			name:    "voided payment releases the hold",
+			payment: Payment{ID: "p1", Amount: usd(10000), Status: StatusVoided},
+			keys:    []string{"authorization:p1", "release:p1"},
+		},
+		{
+			name: "partial refunds, one to store credit",
+			payment: Payment{ID: "p1", Amount: usd(10000), Status: StatusCompleted, Refunds: []Refund{
+				{Reference: "r1", Amount: usd(2500), RefundedAt: at},
+				{Reference: "r2", Amount: usd(1000), To: RefundToStoreCredit, RefundedAt: at},
+			}},
+			keys: []string{"authorization:p1", "capture:p1", "fee:p1", "refund:p1:r1", "refund:p1:r2"},
+		},
+		{
+			name: "cancelled after capture",
+			payment: Payment{ID: "p1", Amount: usd(10000), Status: StatusRefunded,
+				Reversal: &Reversal{Kind: ReversalRefund, Amount: usd(10000), ReversedAt: at}},
+			keys: []string{"authorization:p1", "capture:p1", "fee:p1", "refund:p1:cancellation"},
+		},
+		{
+			name:    "store credit payment has no authorization or fee",
+			payment: Payment{ID: "p1", Amount: usd(10000), Status: StatusCompleted, Method: MethodStoreCredit},
+			keys:    []string{"capture:p1"},
+		},
+		{
+			name:    "pending store credit payment posts nothing",
+			payment: Payment{ID: "p1", Amount: usd(10000), Status: StatusPending, Method: MethodStoreCredit},
+		},
+		{
+			name:    "unsaved payment posts nothing",
+			payment: Payment{Amount: usd(10000), Status: StatusCompleted},
+		},
+		{
+			name:    "zero amount posts nothing",
+			payment: Payment{ID: "p1", Amount: usd(0), Status: StatusCompleted},
+		},
+	}
+
+	for _, tt := range tests {
+		entries := tt.payment.JournalEntries(fees)
+
+		var keys []string
+		for _, e := range entries {
+			if err := e.Validate(); err != nil {
+				t.Errorf("%s: %s does not validate: %v", tt.name, e.Key, err)
+			}
+			if e.PaymentID != tt.payment.ID {
+				t.Errorf("%s: %s has payment %q, want %q", tt.name, e.Key, e.PaymentID, tt.payment.ID)
+			}
+			keys = append(keys, e.Key)
+		}
+		sort.Strings(keys)
+		if strings.Join(keys, " ") != strings.Join(tt.keys, " ") {
+			t.Errorf("%s: keys %v, want %v", tt.name, keys, tt.keys)
+		}
+
+		// keys are derived from the payment alone, so posting again is a no-op
+		again := tt.payment.JournalEntries(fees)
+		if len(again) != len(entries) {
+			t.Errorf("%s: %d entries on the second call, want %d", tt.name, len(again), len(entries))
+			continue
+		}
+		for i := range again {
+			if again[i].Key != entries[i].Key {
+				t.Errorf("%s: key %q changed to %q", tt.name, entries[i].Key, again[i].Key)
+			}
+		}
+	}
+}
+
+func TestPaymentJournalEntriesKeepKeysAsPaymentProgresses(t *testing.T) {
+	p := Payment{ID: "p1", Amount: money.New(5000, "EUR"), Status: StatusPending}
+	before := p.JournalEntries(FeeSchedule{})
+
+	p.Status = StatusCompleted
+	after := p.JournalEntries(FeeSchedule{})
+
+	if before[0].Key != after[0].Key {
+		t.Errorf("authorization key changed from %q to %q", before[0].Key, after[0].Key)
+	}
+}
+
+func TestFeeAmount(t *testing.T) {
+	tests := []struct {
+		schedule string
+		amount   money.Money
+		want     money.Money
+	}{
+		{schedule: "2.9%+0.30", amount: money.New(10000, "USD"), want: money.New(320, "USD")},
+		{schedule: "1.5%", amount: money.New(1000, "EUR"), want: money.New(15, "EUR")},
+		{schedule: "0.25", amount: money.New(1000, "EUR"), want: money.New(25, "EUR")},
+		{schedule: "", amount: money.New(1000, "EUR"), want: money.New(0, "EUR")},
+	}
+
+	for _, tt := range tests {
+		fs, err := ParseFeeSchedule(tt.schedule)
+		if err != nil {
+			t.Errorf("ParseFeeSchedule(%q): %v", tt.schedule, err)
+			continue
+		}
+		if got := fs.Fee(tt.amount); got != tt.want {
+			t.Errorf("fee %q on %v = %v, want %v", tt.schedule, tt.amount, got, tt.want)
+		}
+	}
+}
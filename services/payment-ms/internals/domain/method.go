@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+	ErrInvalidPaymentMethod  = errors.New("invalid payment method")
+	ErrPaymentMethodExpired  = errors.New("payment method expired")
+)
+
+// Payment method types
+const (
+	MethodCard         = "card"
+	MethodWallet       = "wallet"        // e.g. PayPal or Apple Pay
+	MethodBankTransfer = "bank_transfer" // a debit mandate on a bank account
+	MethodStoreCredit  = "store_credit"  // the user's wallet; never saved as a method
+)
+
+// PaymentMethod is a way to pay a user saved. Card details never reach
+// the service: the provider tokenizes the card and only the token and what
+// is safe to show are kept. The token is what the provider is charged with
+// and is never returned.
+type PaymentMethod struct {
+	ID          string    `json:"id" bson:"_id,omitempty"`
+	UserID      string    `json:"user_id" bson:"user_id"`
+	Type        string    `json:"type" bson:"type"` // card, wallet or bank_transfer
+	Token       string    `json:"-" bson:"token"`
+	Brand       string    `json:"brand,omitempty" bson:"brand,omitempty"` // card brand, e.g. visa
+	Last4       string    `json:"last4,omitempty" bson:"last4,omitempty"` // of the card or bank account
+	ExpMonth    int       `json:"exp_month,omitempty" bson:"exp_month,omitempty"`
+	ExpYear     int       `json:"exp_year,omitempty" bson:"exp_year,omitempty"`
+	Country     string    `json:"country,omitempty" bson:"country,omitempty"`         // ISO 3166-1 alpha-2 of the card issuer
+	Fingerprint string    `json:"fingerprint,omitempty" bson:"fingerprint,omitempty"` // same for every token of a card
+	Wallet      string    `json:"wallet,omitempty" bson:"wallet,omitempty"`           // e.g. paypal, apple_pay
+	BankName    string    `json:"bank_name,omitempty" bson:"bank_name,omitempty"`
+	Default     bool      `json:"default" bson:"default"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+}
+
+// Validate checks that a method has what its type needs and, for cards,
+// that it has not expired at at.
+func (m *PaymentMethod) Validate(at time.Time) error {
+	if m.Token == "" {
+		return fmt.Errorf("%w: token is required", ErrInvalidPaymentMethod)
+	}
+	switch m.Type {
+	case MethodCard:
+		if m.Brand == "" || !isLast4(m.Last4) {
+			return fmt.Errorf("%w: a card needs a brand and the last four digits", ErrInvalidPaymentMethod)
+		}
+		if m.ExpMonth < 1 || m.ExpMonth > 12 || m.ExpYear < 2000 {
+			return fmt.Errorf("%w: a card needs an expiry month and a four-digit year", ErrInvalidPaymentMethod)
+		}
+		if m.Expired(at) {
+			return fmt.Errorf("%w: card ending in %s expired %02d/%d", ErrPaymentMethodExpired, m.Last4, m.ExpMonth, m.ExpYear)
+		}
+	case MethodWallet:
+		if m.Wallet == "" {
+			return fmt.Errorf("%w: a wallet needs its provider", ErrInvalidPaymentMethod)
+		}
+	case MethodBankTransfer:
+		if m.BankName == "" || !isLast4(m.Last4) {
+			return fmt.Errorf("%w: a bank transfer needs the bank name and the last four digits of the account", ErrInvalidPaymentMethod)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidPaymentMethod, m.Type)
+	}
+	return nil
+}
+
+// Expired reports whether a card is past the last day of its expiry month
+// at at. Other methods do not expire.
+func (m *PaymentMethod) Expired(at time.Time) bool {
+	if m.Type != MethodCard {
+		return false
+	}
+	end := time.Date(m.ExpYear, time.Month(m.ExpMonth)+1, 1, 0, 0, 0, 0, time.UTC)
+	return !at.Before(end)
+}
+
+// Card returns the card details a payment with the method is screened on,
+// or nil for other methods.
+func (m *PaymentMethod) Card() *Card {
+	if m.Type != MethodCard {
+		return nil
+	}
+	return &Card{Brand: m.Brand, Last4: m.Last4, Country: strings.ToUpper(m.Country), Fingerprint: m.Fingerprint}
+}
+
+func isLast4(s string) bool {
+	if len(s) != 4 {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -1,14 +1,110 @@
 package domain
 
+import (
+	"ecom-api/pkg/money"
+	"errors"
+	"time"
+)
 
+var (
+	ErrPaymentNotFound = errors.New("payment not found")
+	ErrRefundExceeded  = errors.New("refund exceeds the refundable amount")
+	ErrOrderNotPayable = errors.New("order cannot be paid")
+)
+
+const (
+	StatusPending   = "PENDING"
+	StatusCompleted = "COMPLETED"
+	StatusFailed    = "FAILED"
+	StatusVoided    = "VOIDED"
+	StatusRefunded  = "REFUNDED"
+)
+
+// Reversal kinds
+const (
+	ReversalVoid   = "void"
+	ReversalRefund = "refund"
+)
 
 type Payment struct {
 	ID       string  `json:"id" bson:"_id,omitempty"`
 	OrderID  string  `json:"order_id" bson:"order_id"`
 	UserID   string  `json:"user_id" bson:"user_id"`
-	Amount   float64 `json:"amount" bson:"amount"`
-	Status   string  `json:"status" bson:"status"` // PENDING, COMPLETED, FAILED
-	
-	
-	
+	Amount   money.Money `json:"amount" bson:"amount"`
+	Status   string  `json:"status" bson:"status"` // PENDING, COMPLETED, FAILED, VOIDED, REFUNDED
+	Reversal *Reversal `json:"reversal,omitempty" bson:"reversal,omitempty"`
+	Refunds  []Refund  `json:"refunds,omitempty" bson:"refunds,omitempty"` // partial refunds, e.g. for returns
+	Method      string `json:"method,omitempty" bson:"method,omitempty"`       // card, wallet or bank_transfer
+	MethodID    string `json:"method_id,omitempty" bson:"method_id,omitempty"` // saved payment method charged
+	SplitWith   string `json:"split_with,omitempty" bson:"split_with,omitempty"` // store credit payment that paid the rest of the order
+	Card        *Card  `json:"card,omitempty" bson:"card,omitempty"`
+	ScreeningID string `json:"screening_id,omitempty" bson:"screening_id,omitempty"` // fraud screening of the attempt
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+}
+
+// Reversal records how a payment of a cancelled order was undone. A void
+// releases an uncaptured payment, a refund returns captured money.
+type Reversal struct {
+	Kind       string      `json:"kind" bson:"kind"`
+	Amount     money.Money `json:"amount" bson:"amount"`
+	Reason     string      `json:"reason" bson:"reason"`
+	To         string      `json:"to,omitempty" bson:"to,omitempty"` // store_credit, or empty when refunded to the payment's method
+	ReversedAt time.Time   `json:"reversed_at" bson:"reversed_at"`
+}
+
+// Outcomes of reversing the payments of a cancelled order
+const (
+	ActionRefunded = "refunded"
+	ActionVoided   = "voided"
+	ActionNone     = "none"
+)
+
+// OrderReversal summarizes the payments of a cancelled order after they
+// were reversed.
+type OrderReversal struct {
+	Action   string
+	Refunded money.Money
+	Payments []*Payment
+}
+
+// Refund is a partial refund of a completed payment. Reference identifies
+// what it pays back, e.g. a return, and is applied once per payment.
+type Refund struct {
+	Reference  string      `json:"reference" bson:"reference"`
+	Amount     money.Money `json:"amount" bson:"amount"`
+	Reason     string      `json:"reason,omitempty" bson:"reason,omitempty"`
+	To         string      `json:"to,omitempty" bson:"to,omitempty"` // store_credit, or empty when refunded to the payment's method
+	RefundedAt time.Time   `json:"refunded_at" bson:"refunded_at"`
+}
+
+// Refundable is what is left of a completed payment after its partial
+// refunds.
+func (p *Payment) Refundable() money.Money {
+	if p.Status != StatusCompleted {
+		return money.Zero(p.Amount.Currency)
+	}
+	left := p.Amount
+	for _, r := range p.Refunds {
+		if rest, err := left.Sub(r.Amount); err == nil {
+			left = rest
+		}
+	}
+	return left
+}
+
+// RefundFor returns the refund made for reference, if any.
+func (p *Payment) RefundFor(reference string) *Refund {
+	for i := range p.Refunds {
+		if p.Refunds[i].Reference == reference {
+			return &p.Refunds[i]
+		}
+	}
+	return nil
+}
+
+// OrderRefund summarizes a partial refund across the payments of an order.
+type OrderRefund struct {
+	Refunded money.Money
+	Payments []*Payment
 }
@@ -0,0 +1,265 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	ErrInvalidSettlement      = errors.New("invalid settlement report")
+	ErrReconciliationNotFound = errors.New("reconciliation not found")
+)
+
+// Settlement line types
+const (
+	SettlementCharge = "charge"
+	SettlementRefund = "refund"
+)
+
+// SettlementLine is one line of a provider's settlement report. Reference
+// is the payment ID we gave the provider; amounts are positive.
+type SettlementLine struct {
+	Line      int
+	Reference string
+	Type      string // charge or refund
+	Amount    money.Money
+	Fee       money.Money // no currency when the report has no fees
+	PayoutID  string
+	SettledAt time.Time
+}
+
+// SettlementReport is what the provider reports as settled. Checksum
+// identifies the report's content, whatever its name.
+type SettlementReport struct {
+	Name     string
+	Checksum string
+	Lines    []SettlementLine
+}
+
+// Discrepancy kinds
+const (
+	DiscrepancyMissingPayment = "missing_payment" // settled for a payment we have no record of
+	DiscrepancyMissingRefund  = "missing_refund"  // settled a refund we never made
+	DiscrepancyDuplicate      = "duplicate"       // settled what was settled before
+	DiscrepancyAmountMismatch = "amount_mismatch"
+	DiscrepancyFeeMismatch    = "fee_mismatch"
+	DiscrepancyStatusMismatch = "status_mismatch" // settled a payment we do not hold as captured
+)
+
+// Discrepancy is a settlement line that does not agree with our records.
+type Discrepancy struct {
+	Kind      string       `json:"kind" bson:"kind"`
+	Line      int          `json:"line" bson:"line"`
+	Reference string       `json:"reference" bson:"reference"`
+	Type      string       `json:"type" bson:"type"`
+	Settled   money.Money  `json:"settled" bson:"settled"`
+	Expected  *money.Money `json:"expected,omitempty" bson:"expected,omitempty"`
+	Detail    string       `json:"detail,omitempty" bson:"detail,omitempty"`
+}
+
+// SettlementTotal sums a report in one currency. Net is what the provider
+// owes us for it.
+type SettlementTotal struct {
+	Currency string      `json:"currency" bson:"currency"`
+	Charges  money.Money `json:"charges" bson:"charges"`
+	Refunds  money.Money `json:"refunds" bson:"refunds"`
+	Fees     money.Money `json:"fees" bson:"fees"`
+	Net      money.Money `json:"net" bson:"net"`
+}
+
+// Reconciliation statuses
+const (
+	ReconciliationBalanced      = "balanced"
+	ReconciliationDiscrepancies = "discrepancies"
+)
+
+// Reconciliation is the outcome of matching a settlement report against
+// our payments.
+type Reconciliation struct {
+	ID            string            `json:"id" bson:"_id,omitempty"`
+	Source        string            `json:"source" bson:"source"`
+	Checksum      string            `json:"checksum" bson:"checksum"`
+	Status        string            `json:"status" bson:"status"` // balanced or discrepancies
+	Lines         int               `json:"lines" bson:"lines"`
+	Matched       int               `json:"matched" bson:"matched"` // lines without discrepancies
+	Totals        []SettlementTotal `json:"totals" bson:"totals"`
+	Discrepancies []Discrepancy     `json:"discrepancies,omitempty" bson:"discrepancies,omitempty"`
+	ReconciledAt  time.Time         `json:"reconciled_at" bson:"reconciled_at"`
+}
+
+// Flag records a discrepancy for a line.
+func (r *Reconciliation) Flag(line SettlementLine, kind string, expected *money.Money, detail string) {
+	r.Discrepancies = append(r.Discrepancies, Discrepancy{
+		Kind:      kind,
+		Line:      line.Line,
+		Reference: line.Reference,
+		Type:      line.Type,
+		Settled:   line.Amount,
+		Expected:  expected,
+		Detail:    detail,
+	})
+}
+
+// Finish counts the matched lines and sets the status.
+func (r *Reconciliation) Finish(at time.Time) {
+	flagged := map[int]bool{}
+	for _, d := range r.Discrepancies {
+		flagged[d.Line] = true
+	}
+	sort.SliceStable(r.Discrepancies, func(i, j int) bool { return r.Discrepancies[i].Line < r.Discrepancies[j].Line })
+
+	r.Matched = r.Lines - len(flagged)
+	r.Status = ReconciliationBalanced
+	if len(r.Discrepancies) > 0 {
+		r.Status = ReconciliationDiscrepancies
+	}
+	r.ReconciledAt = at
+}
+
+// SettlementMatch ties a settlement line to the payment movement it
+// settles. A movement is settled once, so a second line with the same key
+// is a duplicate, in the same report or a later one.
+type SettlementMatch struct {
+	Key  string
+	Line SettlementLine
+}
+
+// settledMovement is a charge or refund of a payment a line can settle.
+type settledMovement struct {
+	key    string
+	amount money.Money
+}
+
+// refundMovements lists the refunds made on a payment.
+func (p *Payment) refundMovements() []settledMovement {
+	var out []settledMovement
+	for _, r := range p.Refunds {
+		out = append(out, settledMovement{key: SettlementRefund + ":" + p.ID + ":" + r.Reference, amount: r.Amount})
+	}
+	if r := p.Reversal; r != nil && r.Kind == ReversalRefund && r.Amount.IsPositive() {
+		out = append(out, settledMovement{key: SettlementRefund + ":" + p.ID + ":cancellation", amount: r.Amount})
+	}
+	return out
+}
+
+// Reconcile matches the lines of a report against payments, keyed by ID,
+// and expects the provider to charge fees as scheduled. It returns the
+// movements the lines settle, for the caller to check against earlier
+// reports; the reconciliation is not finished yet.
+func Reconcile(report *SettlementReport, payments map[string]*Payment, fees FeeSchedule) (*Reconciliation, []SettlementMatch) {
+	rec := &Reconciliation{
+		Source:   report.Name,
+		Checksum: report.Checksum,
+		Lines:    len(report.Lines),
+		Totals:   settlementTotals(report.Lines),
+	}
+
+	var matches []SettlementMatch
+	settledBy := map[string]int{} // movement key → line
+	claim := func(key string, line SettlementLine) {
+		settledBy[key] = line.Line
+		matches = append(matches, SettlementMatch{Key: key, Line: line})
+	}
+
+	for _, line := range report.Lines {
+		p, ok := payments[line.Reference]
+		if !ok {
+			rec.Flag(line, DiscrepancyMissingPayment, nil, "no payment with this reference")
+			continue
+		}
+
+		switch line.Type {
+		case SettlementCharge:
+			key := SettlementCharge + ":" + p.ID
+			if first, ok := settledBy[key]; ok {
+				rec.Flag(line, DiscrepancyDuplicate, nil, fmt.Sprintf("line %d settles this charge already", first))
+				continue
+			}
+			claim(key, line)
+
+			if p.Status != StatusCompleted && p.Status != StatusRefunded {
+				rec.Flag(line, DiscrepancyStatusMismatch, nil, "payment is "+p.Status)
+			} else if line.Amount != p.Amount {
+				expected := p.Amount
+				rec.Flag(line, DiscrepancyAmountMismatch, &expected, "")
+			}
+			if line.Fee.Currency != "" {
+				if expected := fees.Fee(p.Amount); line.Fee != expected {
+					rec.Discrepancies = append(rec.Discrepancies, Discrepancy{
+						Kind:      DiscrepancyFeeMismatch,
+						Line:      line.Line,
+						Reference: line.Reference,
+						Type:      line.Type,
+						Settled:   line.Fee,
+						Expected:  &expected,
+					})
+				}
+			}
+
+		case SettlementRefund:
+			refunds := p.refundMovements()
+			// prefer the refund with the same amount, then any unsettled one
+			var pick *settledMovement
+			for i, m := range refunds {
+				if _, ok := settledBy[m.key]; ok {
+					continue
+				}
+				if m.amount == line.Amount {
+					pick = &refunds[i]
+					break
+				}
+				if pick == nil {
+					pick = &refunds[i]
+				}
+			}
+			switch {
+			case pick != nil:
+				claim(pick.key, line)
+				if line.Amount != pick.amount {
+					expected := pick.amount
+					rec.Flag(line, DiscrepancyAmountMismatch, &expected, "")
+				}
+			case len(refunds) > 0:
+				rec.Flag(line, DiscrepancyDuplicate, nil, "every refund of this payment is settled already")
+			default:
+				rec.Flag(line, DiscrepancyMissingRefund, nil, "payment has no refunds")
+			}
+		}
+	}
+	return rec, matches
+}
+
+func settlementTotals(lines []SettlementLine) []SettlementTotal {
+	byCurrency := map[string]*SettlementTotal{}
+	var currencies []string
+	for _, l := range lines {
+		c := l.Amount.Currency
+		t, ok := byCurrency[c]
+		if !ok {
+			t = &SettlementTotal{Currency: c, Charges: money.Zero(c), Refunds: money.Zero(c), Fees: money.Zero(c)}
+			byCurrency[c] = t
+			currencies = append(currencies, c)
+		}
+		if l.Type == SettlementRefund {
+			t.Refunds, _ = t.Refunds.Add(l.Amount)
+		} else {
+			t.Charges, _ = t.Charges.Add(l.Amount)
+		}
+		if l.Fee.Currency == c {
+			t.Fees, _ = t.Fees.Add(l.Fee)
+		}
+	}
+
+	sort.Strings(currencies)
+	totals := make([]SettlementTotal, 0, len(currencies))
+	for _, c := range currencies {
+		t := byCurrency[c]
+		t.Net, _ = t.Charges.Sub(t.Refunds)
+		t.Net, _ = t.Net.Sub(t.Fees)
+		totals = append(totals, *t)
+	}
+	return totals
+}
@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrGiftCardNotFound   = errors.New("gift card not found")
+	ErrGiftCardExpired    = errors.New("gift card expired")
+	ErrGiftCardClaimed    = errors.New("gift card is in another wallet")
+	ErrInvalidGiftCard    = errors.New("invalid gift card")
+	ErrInvalidCredit      = errors.New("invalid store credit")
+	ErrInsufficientCredit = errors.New("not enough store credit")
+	ErrInvalidTender      = errors.New("invalid split tender")
+)
+
+// Where a refund is paid
+const (
+	RefundToOriginal    = ""             // back to the method the payment was made with
+	RefundToStoreCredit = "store_credit" // into the user's wallet
+)
+
+// Sources of wallet money
+const (
+	SourceStoreCredit = "store_credit"
+	SourceGiftCard    = "gift_card"
+)
+
+// Wallet transaction kinds
+const (
+	TxnGrant   = "grant"   // credit given by an admin
+	TxnRefund  = "refund"  // a refund paid as store credit
+	TxnPayment = "payment" // spent on a payment
+	TxnRelease = "release" // given back when a payment could not take it all
+)
+
+// GiftCard is redeemable for its balance until it expires. Once added to
+// a wallet, only that wallet's user can spend it. Balances only go down:
+// money a gift card paid comes back as store credit.
+type GiftCard struct {
+	ID          string               `json:"id" bson:"_id,omitempty"`
+	Code        string               `json:"code" bson:"code"`
+	Initial     money.Money          `json:"initial" bson:"initial"`
+	Balance     money.Money          `json:"balance" bson:"balance"`
+	ExpiresAt   *time.Time           `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	UserID      string               `json:"user_id,omitempty" bson:"user_id,omitempty"` // wallet it was added to
+	IssuedBy    string               `json:"issued_by,omitempty" bson:"issued_by,omitempty"`
+	IssuedAt    time.Time            `json:"issued_at" bson:"issued_at"`
+	Redemptions []GiftCardRedemption `json:"redemptions" bson:"redemptions"`
+}
+
+// GiftCardRedemption is a part of a gift card's balance spent on a
+// payment.
+type GiftCardRedemption struct {
+	PaymentID  string      `json:"payment_id" bson:"payment_id"`
+	OrderID    string      `json:"order_id" bson:"order_id"`
+	Amount     money.Money `json:"amount" bson:"amount"`
+	RedeemedAt time.Time   `json:"redeemed_at" bson:"redeemed_at"`
+}
+
+// NormalizeGiftCardCode makes codes typed with spaces, dashes or in lower
+// case match the stored one.
+func NormalizeGiftCardCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// Validate checks a gift card about to be issued at at.
+func (g *GiftCard) Validate(at time.Time) error {
+	if n := len(g.Code); n < 8 || n > 32 {
+		return fmt.Errorf("%w: code must have 8 to 32 letters and digits", ErrInvalidGiftCard)
+	}
+	for _, c := range g.Code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return fmt.Errorf("%w: code must have 8 to 32 letters and digits", ErrInvalidGiftCard)
+		}
+	}
+	if !g.Initial.IsPositive() || !money.ValidCurrency(g.Initial.Currency) {
+		return fmt.Errorf("%w: initial balance must be positive", ErrInvalidGiftCard)
+	}
+	if g.ExpiresAt != nil && !g.ExpiresAt.After(at) {
+		return fmt.Errorf("%w: expiry must be in the future", ErrInvalidGiftCard)
+	}
+	return nil
+}
+
+// Expired reports whether the gift card can no longer be spent at at.
+func (g *GiftCard) Expired(at time.Time) bool {
+	return g.ExpiresAt != nil && !at.Before(*g.ExpiresAt)
+}
+
+// Usable reports whether the gift card has money left to spend at at.
+func (g *GiftCard) Usable(at time.Time) bool {
+	return !g.Expired(at) && g.Balance.IsPositive()
+}
+
+// WalletTransaction is money moved into or out of a wallet. Amount is
+// positive for money coming in and negative for money spent. Key makes
+// each movement happen once.
+type WalletTransaction struct {
+	ID         string      `json:"id" bson:"_id,omitempty"`
+	Key        string      `json:"-" bson:"key"`
+	UserID     string      `json:"user_id" bson:"user_id"`
+	Kind       string      `json:"kind" bson:"kind"`     // grant, refund, payment or release
+	Source     string      `json:"source" bson:"source"` // store_credit or gift_card
+	GiftCardID string      `json:"gift_card_id,omitempty" bson:"gift_card_id,omitempty"`
+	Amount     money.Money `json:"amount" bson:"amount"`
+	PaymentID  string      `json:"payment_id,omitempty" bson:"payment_id,omitempty"`
+	OrderID    string      `json:"order_id,omitempty" bson:"order_id,omitempty"`
+	Reference  string      `json:"reference,omitempty" bson:"reference,omitempty"`
+	Reason     string      `json:"reason,omitempty" bson:"reason,omitempty"`
+	CreatedAt  time.Time   `json:"created_at" bson:"created_at"`
+}
+
+// Wallet is what a user can pay with besides their payment methods:
+// store credit and the gift cards they added.
+type Wallet struct {
+	UserID    string        `json:"user_id"`
+	Credit    []money.Money `json:"credit"`     // store credit per currency
+	GiftCards []*GiftCard   `json:"gift_cards"` // usable gift cards
+	Available []money.Money `json:"available"`  // store credit and gift cards per currency
+}
+
+// NewWallet sums up a user's store credit and the gift cards usable at at.
+func NewWallet(userID string, credit []money.Money, giftCards []*GiftCard, at time.Time) *Wallet {
+	w := &Wallet{UserID: userID, Credit: []money.Money{}, GiftCards: []*GiftCard{}, Available: []money.Money{}}
+	available := map[string]money.Money{}
+	var currencies []string
+	add := func(m money.Money) {
+		if _, ok := available[m.Currency]; !ok {
+			currencies = append(currencies, m.Currency)
+		}
+		available[m.Currency], _ = available[m.Currency].Add(m)
+	}
+
+	for _, c := range credit {
+		if c.IsPositive() {
+			w.Credit = append(w.Credit, c)
+			add(c)
+		}
+	}
+	for _, g := range giftCards {
+		if g.Usable(at) {
+			w.GiftCards = append(w.GiftCards, g)
+			add(g.Balance)
+		}
+	}
+	// spend the gift cards that expire first first
+	sort.SliceStable(w.GiftCards, func(i, j int) bool {
+		a, b := w.GiftCards[i].ExpiresAt, w.GiftCards[j].ExpiresAt
+		return a != nil && (b == nil || a.Before(*b))
+	})
+	for _, c := range currencies {
+		w.Available = append(w.Available, available[c])
+	}
+	return w
+}
+
+// AvailableIn returns what the wallet can pay in currency.
+func (w *Wallet) AvailableIn(currency string) money.Money {
+	for _, a := range w.Available {
+		if a.Currency == currency {
+			return a
+		}
+	}
+	return money.Zero(currency)
+}
+
+// WalletDraw is the part of a payment taken from one source of a wallet.
+type WalletDraw struct {
+	Source     string
+	GiftCardID string
+	Amount     money.Money
+}
+
+// Plan splits amount over the wallet: gift cards first, those expiring
+// soonest first, then store credit. It takes less than amount when the
+// wallet does not cover it.
+func (w *Wallet) Plan(amount money.Money) []WalletDraw {
+	var draws []WalletDraw
+	left := amount
+	take := func(source, giftCardID string, balance money.Money) {
+		if !left.IsPositive() || balance.Currency != amount.Currency || !balance.IsPositive() {
+			return
+		}
+		part := left
+		if cmp, _ := part.Cmp(balance); cmp > 0 {
+			part = balance
+		}
+		draws = append(draws, WalletDraw{Source: source, GiftCardID: giftCardID, Amount: part})
+		left, _ = left.Sub(part)
+	}
+
+	for _, g := range w.GiftCards {
+		take(SourceGiftCard, g.ID, g.Balance)
+	}
+	for _, c := range w.Credit {
+		take(SourceStoreCredit, "", c)
+	}
+	return draws
+}
+
+// Tender says how much of an order a user's wallet pays; the rest is
+// charged to the payment's method.
+type Tender struct {
+	UseWallet bool
+	// WalletAmount caps what the wallet pays; nil lets it pay as much as
+	// it covers.
+	WalletAmount *money.Money
+}
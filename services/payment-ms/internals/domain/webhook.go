@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnknownProvider        = errors.New("unknown payment provider")
+	ErrInvalidWebhook         = errors.New("invalid webhook event")
+	ErrWebhookEventNotFound   = errors.New("webhook event not found")
+	ErrUnsupportedWebhookType = errors.New("unsupported webhook event type")
+	ErrPaymentStateConflict   = errors.New("payment is not in a state the event applies to")
+)
+
+// Types of provider events
+const (
+	WebhookPaymentSucceeded = "payment.succeeded"
+	WebhookPaymentFailed    = "payment.failed"
+)
+
+// States of a received webhook event
+const (
+	WebhookReceived  = "received"  // verified and stored, not handled yet
+	WebhookProcessed = "processed" // applied to the payment and its order
+	WebhookFailed    = "failed"    // could not be applied; replayed later
+)
+
+// ProviderEvent is the body a payment provider posts to the webhook
+// endpoint. The payment ID is the one payment-ms handed to the provider
+// when the payment was started.
+type ProviderEvent struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"type"` // payment.succeeded or payment.failed
+	Created time.Time `json:"created"`
+	Data    struct {
+		PaymentID     string      `json:"payment_id"`
+		OrderID       string      `json:"order_id,omitempty"`
+		Amount        money.Money `json:"amount"`
+		FailureReason string      `json:"failure_reason,omitempty"`
+	} `json:"data"`
+}
+
+// WebhookEvent is a verified provider event as stored for deduplication
+// and replay. Events are keyed by provider and event ID, so a delivery the
+// provider repeats is recognised.
+type WebhookEvent struct {
+	ID          string     `json:"id" bson:"_id"` // provider:event ID
+	Provider    string     `json:"provider" bson:"provider"`
+	EventID     string     `json:"event_id" bson:"event_id"`
+	Type        string     `json:"type" bson:"type"`
+	PaymentID   string     `json:"payment_id,omitempty" bson:"payment_id,omitempty"`
+	Payload     string     `json:"payload,omitempty" bson:"payload"` // raw body as signed by the provider
+	Status      string     `json:"status" bson:"status"`             // received, processed or failed
+	Attempts    int        `json:"attempts" bson:"attempts"`
+	LastError   string     `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	ReceivedAt  time.Time  `json:"received_at" bson:"received_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty" bson:"processed_at,omitempty"`
+}
+
+// WebhookEventID is the key of a provider's event.
+func WebhookEventID(provider, eventID string) string {
+	return provider + ":" + eventID
+}
+
+// Transition returns the payment status the event moves a payment to.
+func (e *ProviderEvent) Transition() (string, error) {
+	switch e.Type {
+	case WebhookPaymentSucceeded:
+		return StatusCompleted, nil
+	case WebhookPaymentFailed:
+		return StatusFailed, nil
+	}
+	return "", ErrUnsupportedWebhookType
+}
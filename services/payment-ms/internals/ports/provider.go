@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"payment-microservice/internals/domain"
+)
+
+// PaymentProvider looks up the tokens the payment provider issued.
+type PaymentProvider interface {
+	// DescribeToken returns the method a token stands for, with what is
+	// safe to show and screen on, as the provider recorded it. It fails
+	// with domain.ErrInvalidPaymentMethod for a token it did not issue.
+	DescribeToken(ctx context.Context, token string) (*domain.PaymentMethod, error)
+}
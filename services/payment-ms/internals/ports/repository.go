@@ -2,7 +2,9 @@ package ports
 
 import (
 	"context"
+	"ecom-api/pkg/money"
 	"payment-microservice/internals/domain"
+	"time"
 )
 
 type PaymentRepository interface {
@@ -10,5 +12,148 @@ type PaymentRepository interface {
 	FindByID(ctx context.Context, id string) (*domain.Payment, error)
 	List(ctx context.Context) ([]*domain.Payment, error)
 	UpdateStatus(ctx context.Context, id string, status string) (*domain.Payment, error)
-	Delete(ctx context.Context, id string) error
-}
\ No newline at end of file
+	Delete(ctx context.Context, id, deletedBy string) error
+	// Restore undeletes a soft-deleted payment.
+	Restore(ctx context.Context, id string) (*domain.Payment, error)
+	// Purge removes payments deleted before before for good.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	FindByOrder(ctx context.Context, orderID string) ([]*domain.Payment, error)
+	// FindByIDs returns the payments with the given IDs, deleted ones
+	// included; unknown IDs are left out.
+	FindByIDs(ctx context.Context, ids []string) ([]*domain.Payment, error)
+	// Reverse moves a payment from status from to status to and records the
+	// reversal; it reports false if the payment was no longer in from.
+	Reverse(ctx context.Context, id, from, to string, r *domain.Reversal) (bool, error)
+	// AddRefund records a partial refund on a completed payment that has
+	// refunds refunds so far, moving it to REFUNDED when nothing is left.
+	// It reports false if the payment changed meanwhile.
+	AddRefund(ctx context.Context, id string, refunds int, r domain.Refund, full bool) (bool, error)
+	// Settle moves a payment from status from to status to; it reports
+	// false if the payment was no longer in from.
+	Settle(ctx context.Context, id, from, to string) (bool, error)
+	// AnonymizeUser replaces userID with anonymousID on the user's
+	// payments, deleted ones included.
+	AnonymizeUser(ctx context.Context, userID, anonymousID string) error
+}
+type WebhookEventRepository interface {
+	// Create stores a received event; it reports false if the event was
+	// received before.
+	Create(ctx context.Context, e *domain.WebhookEvent) (bool, error)
+	FindByID(ctx context.Context, id string) (*domain.WebhookEvent, error)
+	// List returns the latest events, without payloads, optionally only
+	// those in status.
+	List(ctx context.Context, status string, limit int64) ([]*domain.WebhookEvent, error)
+	// FindReplayable returns failed events, and events still received
+	// before stuckBefore, that were tried fewer than maxAttempts times.
+	FindReplayable(ctx context.Context, maxAttempts int, stuckBefore time.Time, limit int64) ([]*domain.WebhookEvent, error)
+	MarkProcessed(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id, reason string) error
+}
+
+// LedgerRepository stores journal entries. Entries are only ever inserted;
+// there is no way to change or delete one.
+type LedgerRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Post stores an entry; it reports false if an entry with its key was
+	// posted before.
+	Post(ctx context.Context, e *domain.JournalEntry) (bool, error)
+	FindByID(ctx context.Context, id string) (*domain.JournalEntry, error)
+	FindByKey(ctx context.Context, key string) (*domain.JournalEntry, error)
+	// List returns the latest entries matching filter, newest first.
+	List(ctx context.Context, filter domain.EntryFilter, limit int64) ([]*domain.JournalEntry, error)
+	// Balances sums the postings of the entries matching filter per account
+	// and currency.
+	Balances(ctx context.Context, filter domain.EntryFilter) ([]domain.AccountBalance, error)
+}
+
+// ReconciliationRepository stores reconciliations and which report settled
+// each payment movement.
+type ReconciliationRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Create stores a reconciliation; it reports false if the report was
+	// reconciled before.
+	Create(ctx context.Context, r *domain.Reconciliation) (bool, error)
+	FindByID(ctx context.Context, id string) (*domain.Reconciliation, error)
+	FindByChecksum(ctx context.Context, checksum string) (*domain.Reconciliation, error)
+	// List returns the latest reconciliations without their discrepancies.
+	List(ctx context.Context, limit int64) ([]*domain.Reconciliation, error)
+	// Claim records that the report with checksum settled the movements
+	// keys. It returns the keys another report settled before, mapped to
+	// that report's source.
+	Claim(ctx context.Context, checksum, source string, keys []string) (map[string]string, error)
+}
+
+// ScreeningRepository stores fraud screenings. Velocity rules count them,
+// and those held for review make up the review queue.
+type ScreeningRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, s *domain.Screening) error
+	FindByID(ctx context.Context, id string) (*domain.Screening, error)
+	// List returns the latest screenings matching filter, newest first,
+	// except pending reviews, which are listed oldest first.
+	List(ctx context.Context, filter domain.ScreeningFilter, limit int64) ([]*domain.Screening, error)
+	// CountSince counts the screenings since since of the user, card or
+	// IP that a velocity rule of kind counts by.
+	CountSince(ctx context.Context, kind, value string, since time.Time) (int64, error)
+	// Review moves a pending review to status; it reports false if the
+	// review was no longer pending.
+	Review(ctx context.Context, id, status, reviewer, note string, at time.Time) (bool, error)
+	// AnonymizeUser replaces userID with anonymousID on the user's
+	// screenings and drops the IPs they were made from.
+	AnonymizeUser(ctx context.Context, userID, anonymousID string) error
+}
+
+// PaymentMethodRepository stores the payment methods users saved. A user
+// has at most one default method.
+type PaymentMethodRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, m *domain.PaymentMethod) error
+	// FindByID returns a method of the user.
+	FindByID(ctx context.Context, userID, id string) (*domain.PaymentMethod, error)
+	// FindDefault returns the user's default method, or nil if the user
+	// has none.
+	FindDefault(ctx context.Context, userID string) (*domain.PaymentMethod, error)
+	// ListByUser returns the user's methods, newest first.
+	ListByUser(ctx context.Context, userID string) ([]*domain.PaymentMethod, error)
+	// SetDefault makes a method of the user the default one.
+	SetDefault(ctx context.Context, userID, id string) error
+	Delete(ctx context.Context, userID, id string) error
+	// DeleteByUser deletes all of the user's methods.
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+// WalletRepository stores store credit balances, gift cards and the
+// transactions that moved wallet money. Balances change only together
+// with a transaction, and a transaction key is used once.
+type WalletRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Credit returns the user's store credit per currency.
+	Credit(ctx context.Context, userID string) ([]money.Money, error)
+	// AddCredit adds t's amount to the user's store credit once per key
+	// and records t; it reports false if t was recorded before.
+	AddCredit(ctx context.Context, t *domain.WalletTransaction) (bool, error)
+	// SpendCredit takes what t spends, a negative amount, from the user's
+	// store credit and records t; it reports false if the balance does not
+	// cover it.
+	SpendCredit(ctx context.Context, t *domain.WalletTransaction) (bool, error)
+
+	CreateGiftCard(ctx context.Context, g *domain.GiftCard) error
+	FindGiftCard(ctx context.Context, code string) (*domain.GiftCard, error)
+	// ListGiftCards returns the latest gift cards, only those in the
+	// user's wallet when userID is set.
+	ListGiftCards(ctx context.Context, userID string, limit int64) ([]*domain.GiftCard, error)
+	// ClaimGiftCard adds a gift card to the user's wallet; it reports false
+	// if the card is in another wallet.
+	ClaimGiftCard(ctx context.Context, id, userID string) (bool, error)
+	// SpendGiftCard takes r's amount from the gift card and records the
+	// redemption and t; it reports false if the card expired or its balance
+	// does not cover it.
+	SpendGiftCard(ctx context.Context, id string, r domain.GiftCardRedemption, t *domain.WalletTransaction) (bool, error)
+
+	// ListTransactions returns the user's latest wallet transactions.
+	ListTransactions(ctx context.Context, userID string, limit int64) ([]*domain.WalletTransaction, error)
+
+	// AnonymizeUser replaces userID with anonymousID on the user's store
+	// credit, gift cards and wallet transactions.
+	AnonymizeUser(ctx context.Context, userID, anonymousID string) error
+}
@@ -2,15 +2,87 @@ package ports
 
 import (
 	"context"
+	"ecom-api/pkg/money"
 	"payment-microservice/internals/domain"
+	"time"
 )
 
 type PaymentService interface {
-    ProcessPayment(ctx context.Context, payment *domain.Payment) (*domain.Payment, error)
+    // ProcessPayment screens a payment for fraud and charges it, or holds
+    // it for review. A payment with a user ID must be for that user's order.
+    // It is made with the saved method MethodID names or, when it names
+    // neither a method nor a card, with the user's default method. With
+    // split tender the user's wallet pays part of the order first.
+    ProcessPayment(ctx context.Context, payment *domain.Payment, tender domain.Tender, client domain.ClientInfo) (*domain.Payment, error)
     InitPayment(ctx context.Context, orderID string) (*domain.Payment, error)
     GetPayment(ctx context.Context, id string) (*domain.Payment, error)
     ListPayments(ctx context.Context) ([]*domain.Payment, error)
     UpdatePaymentStatus(ctx context.Context, id string, status string) (*domain.Payment, error)
-    DeletePayment(ctx context.Context, id string) error
+    DeletePayment(ctx context.Context, id, deletedBy string) error
+    RestorePayment(ctx context.Context, id string) (*domain.Payment, error)
+    PurgeDeletedPayments(ctx context.Context, before time.Time) (int64, error)
 	NotifyOrderCreated(ctx context.Context, orderID string) error
+	// CancelOrderPayment voids or refunds the payments of a cancelled order.
+	CancelOrderPayment(ctx context.Context, orderID, reason string) (*domain.OrderReversal, error)
+	// RefundOrderPayment refunds part of what was paid for an order, once
+	// per reference, optionally as store credit.
+	RefundOrderPayment(ctx context.Context, orderID, reference string, amount money.Money, reason string, toStoreCredit bool) (*domain.OrderRefund, error)
+	// ReceiveWebhook verifies, stores and applies an event posted by a
+	// payment provider; it reports true for an event received before.
+	ReceiveWebhook(ctx context.Context, provider, signature string, body []byte) (*domain.WebhookEvent, bool, error)
+	ListWebhookEvents(ctx context.Context, status string, limit int64) ([]*domain.WebhookEvent, error)
+	// ReplayWebhookEvent applies a stored event that failed again.
+	ReplayWebhookEvent(ctx context.Context, id string) (*domain.WebhookEvent, error)
+	// ReplayFailedWebhooks retries failed events; it runs periodically.
+	ReplayFailedWebhooks(ctx context.Context) (int, error)
+
+	// LedgerBalances sums the ledger per account and currency, over the
+	// entries of an order, a payment or an account.
+	LedgerBalances(ctx context.Context, filter domain.EntryFilter) ([]domain.AccountBalance, error)
+	ListJournalEntries(ctx context.Context, filter domain.EntryFilter, limit int64) ([]*domain.JournalEntry, error)
+	GetJournalEntry(ctx context.Context, id string) (*domain.JournalEntry, error)
+	// ReverseJournalEntry corrects an entry by posting its opposite.
+	ReverseJournalEntry(ctx context.Context, id, memo string) (*domain.JournalEntry, error)
+	// RecordPayout books a payout from the provider to the bank, once per
+	// reference.
+	RecordPayout(ctx context.Context, reference string, amount money.Money, memo string) (*domain.JournalEntry, error)
+	// SyncLedger posts journal entries missing for payments; it runs
+	// periodically.
+	SyncLedger(ctx context.Context) (int, error)
+
+	// FraudRules returns the rules payments are screened with.
+	FraudRules() domain.FraudConfig
+	ListScreenings(ctx context.Context, filter domain.ScreeningFilter, limit int64) ([]*domain.Screening, error)
+	GetScreening(ctx context.Context, id string) (*domain.Screening, error)
+	// ApproveReview charges a payment held for review.
+	ApproveReview(ctx context.Context, screeningID, reviewer, note string) (*domain.Payment, error)
+	// RejectReview fails a payment held for review.
+	RejectReview(ctx context.Context, screeningID, reviewer, note string) (*domain.Payment, error)
+
+	// SavePaymentMethod saves a tokenized method for its user; a user's
+	// first method becomes the default one.
+	SavePaymentMethod(ctx context.Context, userID, token string, makeDefault bool) (*domain.PaymentMethod, error)
+	ListPaymentMethods(ctx context.Context, userID string) ([]*domain.PaymentMethod, error)
+	GetPaymentMethod(ctx context.Context, userID, id string) (*domain.PaymentMethod, error)
+	SetDefaultPaymentMethod(ctx context.Context, userID, id string) (*domain.PaymentMethod, error)
+	// DeletePaymentMethod removes a method; when it was the default, the
+	// newest remaining method takes its place.
+	DeletePaymentMethod(ctx context.Context, userID, id string) error
+
+	// GetWallet returns a user's store credit and usable gift cards.
+	GetWallet(ctx context.Context, userID string) (*domain.Wallet, error)
+	ListWalletTransactions(ctx context.Context, userID string, limit int64) ([]*domain.WalletTransaction, error)
+	// AddGiftCard adds a gift card to a user's wallet by its code.
+	AddGiftCard(ctx context.Context, userID, code string) (*domain.GiftCard, error)
+	// IssueGiftCard issues a gift card; a code is generated unless given.
+	IssueGiftCard(ctx context.Context, g *domain.GiftCard) (*domain.GiftCard, error)
+	ListGiftCards(ctx context.Context, userID string, limit int64) ([]*domain.GiftCard, error)
+	GetGiftCard(ctx context.Context, code string) (*domain.GiftCard, error)
+	// GrantStoreCredit adds store credit to a user's wallet, once per
+	// reference.
+	GrantStoreCredit(ctx context.Context, userID, reference string, amount money.Money, reason string) (*domain.Wallet, error)
+
+	// AnonymizeUser detaches a deleted account's payments and wallet from
+	// the user and deletes its saved payment methods.
+	AnonymizeUser(ctx context.Context, userID, anonymousID string) error
 }
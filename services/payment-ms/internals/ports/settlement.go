@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+	"payment-microservice/internals/domain"
+)
+
+// SettlementSource is where the payment provider drops its settlement
+// reports.
+type SettlementSource interface {
+	// List returns the names of the reports available, oldest first.
+	List(ctx context.Context) ([]string, error)
+	Read(ctx context.Context, name string) (*domain.SettlementReport, error)
+}
+
+// ReconciliationService matches settlement reports against payments.
+type ReconciliationService interface {
+	// Reconcile reconciles a report; a report reconciled before is
+	// returned as it was, reporting true.
+	Reconcile(ctx context.Context, report *domain.SettlementReport) (*domain.Reconciliation, bool, error)
+	// ReconcileSource reconciles the reports of source not reconciled
+	// yet; it runs periodically.
+	ReconcileSource(ctx context.Context, source SettlementSource) (int, error)
+	ListReconciliations(ctx context.Context, limit int64) ([]*domain.Reconciliation, error)
+	GetReconciliation(ctx context.Context, id string) (*domain.Reconciliation, error)
+}
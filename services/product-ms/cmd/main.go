@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"ecom-api/pkg/auth"
+	"ecom-api/pkg/money"
+	"ecom-api/pkg/softdelete"
 	"fmt"
 	"log"
 	"net"
@@ -62,7 +64,7 @@ func main() {
 	}
 
 	// MongoDB connection
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetRegistry(money.Registry(os.Getenv("DEFAULT_CURRENCY"))))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -78,18 +80,28 @@ func main() {
 
 	// Layers
 	repo := db.NewMongoProductRepository(dbConn)
-	service := application.NewProductService(repo)
+	rateRepo := db.NewMongoExchangeRateRepository(dbConn)
+	rateService := application.NewExchangeRateService(rateRepo)
+	service := application.NewProductService(repo, rateService)
+	stockService := application.NewStockService(repo, db.NewMongoReservationRepository(dbConn))
+
+	// purge of deleted products
+	purger, err := softdelete.PurgerFromEnv("products", service.PurgeDeletedProducts)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// HTTP setup
 	handler := httpAdapter.NewProductHandler(service)
+	rateHandler := httpAdapter.NewExchangeRateHandler(rateService)
 	httpServer := http.Server{
 		Addr:    httpPort,
-		Handler: httpAdapter.NewRouter(handler),
+		Handler: httpAdapter.NewRouter(handler, rateHandler),
 	}
 
 	// gRPC setup
 	grpcServer := grpc.NewServer()
-	productGrpc := grpcAdapter.NewProductGrpcServer(service)
+	productGrpc := grpcAdapter.NewProductGrpcServer(service, rateService, stockService)
 	pb.RegisterProductServiceServer(grpcServer, productGrpc)
 
 	// connect to user-ms
@@ -108,6 +120,8 @@ func main() {
 	}
 
 	g := new(errgroup.Group) // run both http and grpc concurrently
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
 
 	// HTTP server
 	g.Go(func() error {
@@ -121,6 +135,11 @@ func main() {
 		return grpcServer.Serve(lis)
 	})
 
+	// purge of deleted records
+	g.Go(func() error {
+		return purger.Run(workerCtx)
+	})
+
 	// graceful shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, os.Kill)
@@ -128,6 +147,7 @@ func main() {
 	go func() {
 		<-stop
 		fmt.Println("\nshutting down server...")
+		stopWorkers()
 
 		// shutdown http server
 		ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
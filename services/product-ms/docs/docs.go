@@ -23,6 +23,201 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/exchange-rates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the rate currently in force for every configured pair (requires JWT)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ExchangeRates"
+                ],
+                "summary": "List exchange rates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/ExchangeRate"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a rate for a currency pair; it supersedes the previous rate from its effective time (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ExchangeRates"
+                ],
+                "summary": "Set exchange rate",
+                "parameters": [
+                    {
+                        "description": "Exchange rate",
+                        "name": "rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ExchangeRateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/ExchangeRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/exchange-rates/{base}/{quote}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the rate in force for a pair, derived from the inverse pair if needed (requires JWT)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ExchangeRates"
+                ],
+                "summary": "Get exchange rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency",
+                        "name": "quote",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ExchangeRate"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/exchange-rates/{base}/{quote}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get every rate recorded for a pair, newest first (requires JWT)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ExchangeRates"
+                ],
+                "summary": "Exchange rate history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency",
+                        "name": "quote",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/ExchangeRate"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/products": {
             "get": {
                 "security": [
@@ -30,7 +225,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all products (requires JWT)",
+                "description": "Get all products (requires JWT), optionally priced in another currency",
                 "produces": [
                     "application/json"
                 ],
@@ -38,13 +233,30 @@ const docTemplate = `{
                     "Products"
                 ],
                 "summary": "List products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency to quote prices in",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Product"
+                                "$ref": "#/definitions/Product"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -83,7 +295,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.ProductCreateRequest"
+                            "$ref": "#/definitions/ProductCreateRequest"
                         }
                     }
                 ],
@@ -91,7 +303,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.Product"
+                            "$ref": "#/definitions/Product"
                         }
                     },
                     "400": {
@@ -122,7 +334,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Fetch a product by ID (requires JWT)",
+                "description": "Fetch a product by ID (requires JWT), optionally priced in another currency",
                 "produces": [
                     "application/json"
                 ],
@@ -137,13 +349,19 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency to quote the price in",
+                        "name": "currency",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Product"
+                            "$ref": "#/definitions/Product"
                         }
                     },
                     "404": {
@@ -154,6 +372,15 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             },
@@ -188,7 +415,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.ProductUpdateRequest"
+                            "$ref": "#/definitions/ProductUpdateRequest"
                         }
                     }
                 ],
@@ -196,7 +423,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Product"
+                            "$ref": "#/definitions/Product"
                         }
                     },
                     "400": {
@@ -225,7 +452,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete product by ID (requires JWT)",
+                "description": "Soft-delete product by ID (requires JWT); an admin can restore it until it is purged",
                 "produces": [
                     "application/json"
                 ],
@@ -252,6 +479,76 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restore a soft-deleted product (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Restore product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Product"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -266,65 +563,227 @@ const docTemplate = `{
         }
     },
     "definitions": {
-        "domain.Product": {
+        "ExchangeRate": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "description": "ISO 4217 code converted from",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "effective_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "quote": {
+                    "description": "ISO 4217 code converted to",
+                    "type": "string"
+                },
+                "rate": {
+                    "description": "units of Quote per unit of Base",
+                    "type": "number"
+                }
+            }
+        },
+        "ExchangeRateRequest": {
             "type": "object",
             "properties": {
+                "base": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "effective_at": {
+                    "description": "defaults to now",
+                    "type": "string"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 0.92
+                }
+            }
+        },
+        "Money": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "minor units, e.g. cents",
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "ISO 4217 code",
+                    "type": "string"
+                }
+            }
+        },
+        "PriceQuote": {
+            "type": "object",
+            "properties": {
+                "exchange_rate": {
+                    "description": "1 unless Source is converted",
+                    "type": "number"
+                },
+                "price": {
+                    "$ref": "#/definitions/Money"
+                },
+                "rate_at": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "Product": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "deleted_by": {
+                    "type": "string"
+                },
                 "description": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
+                "image_url": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
                 "price": {
-                    "type": "number"
+                    "$ref": "#/definitions/Money"
+                },
+                "prices": {
+                    "description": "per-currency list prices, override conversion",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Money"
+                    }
+                },
+                "quote": {
+                    "description": "set when a currency is requested",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/PriceQuote"
+                        }
+                    ]
+                },
+                "reserved": {
+                    "description": "held by open reservations, still part of Stock",
+                    "type": "integer"
+                },
+                "sku": {
+                    "type": "string"
                 },
                 "stock": {
                     "type": "integer"
+                },
+                "tax_category": {
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "shipping weight in kg",
+                    "type": "number"
                 }
             }
         },
-        "http.ProductCreateRequest": {
+        "ProductCreateRequest": {
             "type": "object",
             "properties": {
                 "description": {
                     "type": "string",
                     "example": "High-end gaming laptop"
                 },
+                "image_url": {
+                    "type": "string",
+                    "example": "https://cdn.example.com/laptop.jpg"
+                },
                 "name": {
                     "type": "string",
                     "example": "Laptop"
                 },
                 "price": {
-                    "type": "number",
-                    "example": 1299.99
+                    "$ref": "#/definitions/Money"
+                },
+                "prices": {
+                    "description": "optional per-currency list prices",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Money"
+                    }
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "LAP-15-BLK"
                 },
                 "stock": {
                     "type": "integer",
                     "example": 10
+                },
+                "tax_category": {
+                    "type": "string",
+                    "example": "standard"
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 2.5
                 }
             }
         },
-        "http.ProductUpdateRequest": {
+        "ProductUpdateRequest": {
             "type": "object",
             "properties": {
                 "description": {
                     "type": "string",
                     "example": "Updated description"
                 },
+                "image_url": {
+                    "type": "string",
+                    "example": "https://cdn.example.com/laptop.jpg"
+                },
                 "name": {
                     "type": "string",
                     "example": "Laptop"
                 },
                 "price": {
-                    "type": "number",
-                    "example": 1199.99
+                    "$ref": "#/definitions/Money"
+                },
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Money"
+                    }
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "LAP-15-BLK"
                 },
                 "stock": {
                     "type": "integer",
                     "example": 15
+                },
+                "tax_category": {
+                    "type": "string",
+                    "example": "standard"
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 2.5
                 }
             }
         }
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"product-microservice/internal/domain"
+	"product-microservice/internal/ports"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoExchangeRateRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoExchangeRateRepository(db *mongo.Database) ports.ExchangeRateRepository {
+	return &MongoExchangeRateRepository{collection: db.Collection("exchange_rates")}
+}
+
+func (r *MongoExchangeRateRepository) Create(ctx context.Context, rate *domain.ExchangeRate) (*domain.ExchangeRate, error) {
+	objID := primitive.NewObjectID()
+
+	_, err := r.collection.InsertOne(ctx, bson.M{
+		"_id":          objID,
+		"base":         rate.Base,
+		"quote":        rate.Quote,
+		"rate":         rate.Rate,
+		"effective_at": rate.EffectiveAt,
+		"created_at":   rate.CreatedAt,
+		"created_by":   rate.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rate.ID = objID.Hex()
+	return rate, nil
+}
+
+func (r *MongoExchangeRateRepository) Latest(ctx context.Context, base, quote string, at time.Time) (*domain.ExchangeRate, error) {
+	filter := bson.M{"base": base, "quote": quote, "effective_at": bson.M{"$lte": at}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "effective_at", Value: -1}, {Key: "created_at", Value: -1}})
+
+	var rate domain.ExchangeRate
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&rate)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrRateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *MongoExchangeRateRepository) History(ctx context.Context, base, quote string) ([]domain.ExchangeRate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "effective_at", Value: -1}, {Key: "created_at", Value: -1}})
+	return r.find(ctx, bson.M{"base": base, "quote": quote}, opts)
+}
+
+func (r *MongoExchangeRateRepository) FindAll(ctx context.Context) ([]domain.ExchangeRate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "effective_at", Value: -1}, {Key: "created_at", Value: -1}})
+	return r.find(ctx, bson.M{}, opts)
+}
+
+func (r *MongoExchangeRateRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]domain.ExchangeRate, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rates := []domain.ExchangeRate{}
+	for cursor.Next(ctx) {
+		var rate domain.ExchangeRate
+		if err := cursor.Decode(&rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, cursor.Err()
+}
@@ -6,6 +6,9 @@ import (
 	"product-microservice/internal/domain"
 	"product-microservice/internal/ports"
 
+	"ecom-api/pkg/softdelete"
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -29,9 +32,14 @@ func (r *MongoProductRepository) CreateProduct(ctx context.Context, p *domain.Pr
 	_, err := r.collection.InsertOne(ctx, bson.M{
 		"_id":         objID,
 		"name":        p.Name,
+		"sku":         p.SKU,
+		"image_url":   p.ImageURL,
 		"description": p.Description,
 		"price":       p.Price,
+		"prices":      p.Prices,
 		"stock":       p.Stock,
+		"tax_category": p.TaxCategory,
+		"weight":       p.Weight,
 	})
 	if err != nil {
 		return nil, err
@@ -47,7 +55,7 @@ func (r *MongoProductRepository) FindByID(ctx context.Context, id string) (*doma
 	}
 
 	var product domain.Product
-	 err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&product)
+	 err = r.collection.FindOne(ctx, softdelete.Active(bson.M{"_id": objectID})).Decode(&product)
 	if err != nil {
 		return  nil, err
 	}
@@ -58,7 +66,7 @@ func (r *MongoProductRepository) FindByID(ctx context.Context, id string) (*doma
 
 
 func (r *MongoProductRepository) FindAll(ctx context.Context) ([]domain.Product, error) {
-    cursor, err := r.collection.Find(ctx, bson.M{})
+    cursor, err := r.collection.Find(ctx, softdelete.Active(nil))
     if err != nil {
         return nil, err
     }
@@ -90,16 +98,24 @@ func (r *MongoProductRepository) Update(ctx context.Context, p *domain.Product)
 	update := bson.M{
 		"$set": bson.M{
 			"name":        p.Name,
+			"sku":         p.SKU,
+			"image_url":   p.ImageURL,
 			"description": p.Description,
 			"price":       p.Price,
+			"prices":      p.Prices,
 			"stock":       p.Stock,
+			"tax_category": p.TaxCategory,
+			"weight":       p.Weight,
 		},
 	}
 
-	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	res, err := r.collection.UpdateOne(ctx, softdelete.Active(bson.M{"_id": objectID}), update)
 	if err != nil {
 		return nil, err
 	}
+	if res.MatchedCount == 0 {
+		return nil, domain.ErrProductNotFound
+	}
 
 	// fetch updated product
 	var updated domain.Product
@@ -113,15 +129,97 @@ func (r *MongoProductRepository) Update(ctx context.Context, p *domain.Product)
 
 
 
-func (r *MongoProductRepository) Delete(ctx context.Context, id string) error {
+func (r *MongoProductRepository) Delete(ctx context.Context, id, deletedBy string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
     if err != nil {
 		return  fmt.Errorf("invalid id: %v", err)
 	}
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	res, err := r.collection.UpdateOne(ctx, softdelete.Active(bson.M{"_id": objectID}), softdelete.MarkDeleted(deletedBy, time.Now()))
 	if err != nil {
 		return   err
 	}
+	if res.MatchedCount == 0 {
+		return domain.ErrProductNotFound
+	}
 	return  nil
 }
 
+func (r *MongoProductRepository) Restore(ctx context.Context, id string) (*domain.Product, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %v", err)
+	}
+
+	res, err := r.collection.UpdateOne(ctx, softdelete.Deleted(bson.M{"_id": objectID}), softdelete.Restore())
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		return nil, domain.ErrProductNotFound
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *MongoProductRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return softdelete.Purge(ctx, r.collection, before)
+}
+
+
+func (r *MongoProductRepository) AdjustReserved(ctx context.Context, id string, delta int) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid id: %v", err)
+	}
+
+	reserved := bson.M{"$ifNull": bson.A{"$reserved", 0}}
+	filter := bson.M{"_id": objectID}
+	if delta > 0 {
+		// only reserve what is not already held
+		filter["$expr"] = bson.M{"$gte": bson.A{bson.M{"$subtract": bson.A{"$stock", reserved}}, delta}}
+	}
+	update := bson.A{bson.M{"$set": bson.M{
+		"reserved": bson.M{"$max": bson.A{0, bson.M{"$add": bson.A{reserved, delta}}}},
+	}}}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrInsufficientStock
+	}
+	return nil
+}
+
+func (r *MongoProductRepository) CommitReserved(ctx context.Context, id string, quantity int) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid id: %v", err)
+	}
+
+	update := bson.A{bson.M{"$set": bson.M{
+		"stock":    bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{"$stock", quantity}}}},
+		"reserved": bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{bson.M{"$ifNull": bson.A{"$reserved", 0}}, quantity}}}},
+	}}}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	return err
+}
+
+func (r *MongoProductRepository) Restock(ctx context.Context, id string, quantity int) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid id: %v", err)
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$inc": bson.M{"stock": quantity}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrProductNotFound
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"product-microservice/internal/domain"
+	"product-microservice/internal/ports"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoReservationRepository struct {
+	collection *mongo.Collection
+	restocks   *mongo.Collection
+}
+
+func NewMongoReservationRepository(db *mongo.Database) ports.ReservationRepository {
+	return &MongoReservationRepository{
+		collection: db.Collection("stock_reservations"),
+		restocks:   db.Collection("stock_restocks"),
+	}
+}
+
+func (r *MongoReservationRepository) Find(ctx context.Context, holder, productID string) (*domain.StockReservation, error) {
+	var res domain.StockReservation
+	err := r.collection.FindOne(ctx, bson.M{"holder": holder, "product_id": productID}).Decode(&res)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *MongoReservationRepository) FindByHolder(ctx context.Context, holder string) ([]domain.StockReservation, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"holder": holder})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var out []domain.StockReservation
+	for cursor.Next(ctx) {
+		var res domain.StockReservation
+		if err := cursor.Decode(&res); err != nil {
+			return nil, err
+		}
+		out = append(out, res)
+	}
+	return out, cursor.Err()
+}
+
+func (r *MongoReservationRepository) Save(ctx context.Context, res *domain.StockReservation) error {
+	filter := bson.M{"holder": res.Holder, "product_id": res.ProductID}
+	update := bson.M{
+		"$set":         bson.M{"quantity": res.Quantity, "updated_at": res.UpdatedAt},
+		"$setOnInsert": bson.M{"created_at": res.CreatedAt},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *MongoReservationRepository) Delete(ctx context.Context, holder, productID string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"holder": holder, "product_id": productID})
+	return err
+}
+
+func (r *MongoReservationRepository) RecordRestock(ctx context.Context, reference, productID string) (bool, error) {
+	_, err := r.restocks.InsertOne(ctx, bson.M{
+		"_id":        reference + "/" + productID,
+		"reference":  reference,
+		"product_id": productID,
+		"created_at": time.Now(),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *MongoReservationRepository) ForgetRestock(ctx context.Context, reference, productID string) error {
+	_, err := r.restocks.DeleteOne(ctx, bson.M{"_id": reference + "/" + productID})
+	return err
+}
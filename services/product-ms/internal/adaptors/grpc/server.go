@@ -2,27 +2,37 @@ package grpc
 
 import (
 	"context"
+	"ecom-api/pkg/money"
+	"ecom-api/pkg/money/moneypb"
 	//"product-microservice/adaptors/grpc/pb/user-microservice/services/product-ms/adaptors/grpc/pb"
 	"product-microservice/adaptors/grpc/pb/product-microservice/services/product-ms/adaptors/grpc/pb"
 	"product-microservice/internal/domain"
 	"product-microservice/internal/ports"
+	"time"
 )
 
 type ProductGrpcServer struct {
 	pb.UnimplementedProductServiceServer
 	service ports.ProductService
+	rates   ports.ExchangeRateService
+	stock   ports.StockService
 }
 
-func NewProductGrpcServer(s ports.ProductService) *ProductGrpcServer {
-	return  &ProductGrpcServer{service: s}
+func NewProductGrpcServer(s ports.ProductService, rates ports.ExchangeRateService, stock ports.StockService) *ProductGrpcServer {
+	return  &ProductGrpcServer{service: s, rates: rates, stock: stock}
 }
 
 func (s *ProductGrpcServer) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.CreateProductResponse, error) {
 	product := &domain.Product{
 		Name: req.GetProduct().GetName(),
+		SKU: req.GetProduct().GetSku(),
+		ImageURL: req.GetProduct().GetImageUrl(),
 		Description: req.GetProduct().GetDescription(),
-		Price: req.GetProduct().GetPrice(),
+		Price: money.FromProto(req.GetProduct().GetPrice()),
+		Prices: pricesFromProto(req.GetProduct().GetPrices()),
 		Stock: int(req.GetProduct().GetStock()),
+		TaxCategory: req.GetProduct().GetTaxCategory(),
+		Weight: req.GetProduct().GetWeight(),
 	}
 
 	p, err := s.service.CreateNewProduct(ctx, product)
@@ -31,31 +41,27 @@ func (s *ProductGrpcServer) CreateProduct(ctx context.Context, req *pb.CreatePro
 	}
 
 	 return &pb.CreateProductResponse{
-		Product: &pb.Product{
-			Id: p.ID,
-			Name: p.Name,
-			Description: p.Description,
-			Price: p.Price,
-			Stock: int32(p.Stock),
-		},
+		Product: toProto(p),
 	 }, nil
 }
 
 
+// GetProduct returns the product; with a currency the price is also quoted
+// in that currency from the price list or the exchange rate table.
 func (s *ProductGrpcServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.GetProductResponse, error) {
 	product, err := s.service.GetProduct(ctx, req.Id)
 	if err != nil {
 		return  nil, err
 	}
 
+	if req.GetCurrency() != "" {
+		if product.Quote, err = s.service.QuotePrice(ctx, product, req.GetCurrency()); err != nil {
+			return nil, err
+		}
+	}
+
 	return &pb.GetProductResponse{
-		Product: &pb.Product{
-			Id: product.ID,
-			Name: product.Name,
-			Description: product.Description,
-			Price: product.Price,
-			Stock: int32(product.Stock),
-		},
+		Product: toProto(product),
 	}, nil
 }
 
@@ -66,14 +72,8 @@ func (s *ProductGrpcServer) ListProducts(ctx context.Context, req *pb.ListProduc
 	}
 
 	var pbProducts []*pb.Product
-	for _, p := range products {
-		pbProducts = append(pbProducts, &pb.Product{
-			Id: p.ID,
-			Name: p.Name,
-			Description:  p.Description,
-			Price: p.Price,
-			Stock: int32(p.Stock),
-		})
+	for i := range products {
+		pbProducts = append(pbProducts, toProto(&products[i]))
 	}
 
 	return  &pb.ListProductsResponse{
@@ -86,9 +86,14 @@ func (s *ProductGrpcServer) UpdateProduct(ctx context.Context, req *pb.UpdatePro
 	product := &domain.Product{
 		ID:          req.Product.Id,
 		Name:        req.Product.Name,
+		SKU:         req.Product.Sku,
+		ImageURL:    req.Product.ImageUrl,
 		Description: req.Product.Description,
-		Price:       req.Product.Price,
+		Price:       money.FromProto(req.Product.Price),
+		Prices:      pricesFromProto(req.Product.Prices),
 		Stock:       int(req.Product.Stock),
+		TaxCategory: req.Product.TaxCategory,
+		Weight:      req.Product.Weight,
 	}
 
 	p, err := s.service.UpdateProduct(ctx, product)
@@ -97,18 +102,12 @@ func (s *ProductGrpcServer) UpdateProduct(ctx context.Context, req *pb.UpdatePro
 	}
 
 	return &pb.UpdateProductResponse{
-		Product: &pb.Product{
-			Id:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-			Stock:       int32(p.Stock),
-		},
+		Product: toProto(p),
 	}, nil
 }
 
 func (s *ProductGrpcServer) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
-	err := s.service.DeleteProduct(ctx, req.Id)
+	err := s.service.DeleteProduct(ctx, req.Id, "")
 	if err != nil {
 		return nil, err
 	}
@@ -116,4 +115,96 @@ func (s *ProductGrpcServer) DeleteProduct(ctx context.Context, req *pb.DeletePro
 	return &pb.DeleteProductResponse{
 		Success: true,
 	}, nil
-}
\ No newline at end of file
+}
+
+func (s *ProductGrpcServer) GetExchangeRate(ctx context.Context, req *pb.GetExchangeRateRequest) (*pb.GetExchangeRateResponse, error) {
+	rate, err := s.rates.GetRate(ctx, req.GetBase(), req.GetQuote())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetExchangeRateResponse{
+		Base:        rate.Base,
+		Quote:       rate.Quote,
+		Rate:        rate.Rate,
+		EffectiveAt: rate.EffectiveAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (s *ProductGrpcServer) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	res, available, err := s.stock.Reserve(ctx, req.GetHolder(), req.GetProductId(), int(req.GetQuantity()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReserveStockResponse{
+		Quantity:  int32(res.Quantity),
+		Available: int32(available),
+	}, nil
+}
+
+func (s *ProductGrpcServer) ReleaseStock(ctx context.Context, req *pb.ReleaseStockRequest) (*pb.ReleaseStockResponse, error) {
+	if err := s.stock.Release(ctx, req.GetHolder(), req.GetProductId()); err != nil {
+		return nil, err
+	}
+	return &pb.ReleaseStockResponse{Success: true}, nil
+}
+
+func (s *ProductGrpcServer) CommitStock(ctx context.Context, req *pb.CommitStockRequest) (*pb.CommitStockResponse, error) {
+	if err := s.stock.Commit(ctx, req.GetHolder()); err != nil {
+		return nil, err
+	}
+	return &pb.CommitStockResponse{Success: true}, nil
+}
+
+func (s *ProductGrpcServer) RestockItems(ctx context.Context, req *pb.RestockItemsRequest) (*pb.RestockItemsResponse, error) {
+	lines := make([]domain.StockLine, len(req.GetItems()))
+	for i, it := range req.GetItems() {
+		lines[i] = domain.StockLine{ProductID: it.GetProductId(), Quantity: int(it.GetQuantity())}
+	}
+
+	restocked, err := s.stock.Restock(ctx, req.GetReference(), lines)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RestockItemsResponse{Restocked: restocked}, nil
+}
+
+// helper to convert domain → proto
+func toProto(p *domain.Product) *pb.Product {
+	out := &pb.Product{
+		Id:          p.ID,
+		Name:        p.Name,
+		Sku:         p.SKU,
+		ImageUrl:    p.ImageURL,
+		Description: p.Description,
+		Price:       money.ToProto(p.Price),
+		Stock:       int32(p.Stock),
+		Reserved:    int32(p.Reserved),
+		TaxCategory: p.TaxCategory,
+		Weight:      p.Weight,
+	}
+	for _, m := range p.Prices {
+		out.Prices = append(out.Prices, money.ToProto(m))
+	}
+
+	if q := p.Quote; q != nil {
+		out.Quote = &pb.PriceQuote{
+			Price:        money.ToProto(q.Price),
+			Source:       q.Source,
+			ExchangeRate: q.ExchangeRate,
+		}
+		if q.RateAt != nil {
+			out.Quote.RateAt = q.RateAt.Format(time.RFC3339)
+		}
+	}
+	return out
+}
+
+func pricesFromProto(prices []*moneypb.Money) []money.Money {
+	var out []money.Money
+	for _, m := range prices {
+		out = append(out, money.FromProto(m))
+	}
+	return out
+}
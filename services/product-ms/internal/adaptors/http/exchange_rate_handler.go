@@ -0,0 +1,136 @@
+package http
+
+import (
+	"ecom-api/pkg/middleware"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"product-microservice/internal/domain"
+	"product-microservice/internal/ports"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+// ExchangeRateHandler handles the admin-managed exchange rate table
+type ExchangeRateHandler struct {
+	service ports.ExchangeRateService
+}
+
+func NewExchangeRateHandler(s ports.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: s}
+}
+
+type ExchangeRateRequest struct {
+	Base        string     `json:"base" example:"USD"`
+	Quote       string     `json:"quote" example:"EUR"`
+	Rate        float64    `json:"rate" example:"0.92"`
+	EffectiveAt *time.Time `json:"effective_at,omitempty"` // defaults to now
+}
+
+// @Summary      Set exchange rate
+// @Description  Add a rate for a currency pair; it supersedes the previous rate from its effective time (admin only)
+// @Tags         ExchangeRates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        rate  body      ExchangeRateRequest  true  "Exchange rate"
+// @Success      201  {object}  domain.ExchangeRate
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /exchange-rates [post]
+func (h *ExchangeRateHandler) SetRate(w http.ResponseWriter, r *http.Request) {
+	var req ExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	adminID, _ := middleware.FromContext(r.Context())
+	rate := &domain.ExchangeRate{
+		Base:      req.Base,
+		Quote:     req.Quote,
+		Rate:      req.Rate,
+		CreatedBy: adminID,
+	}
+	if req.EffectiveAt != nil {
+		rate.EffectiveAt = req.EffectiveAt.UTC()
+	}
+
+	created, err := h.service.SetRate(r.Context(), rate)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// @Summary      List exchange rates
+// @Description  Get the rate currently in force for every configured pair (requires JWT)
+// @Tags         ExchangeRates
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   domain.ExchangeRate
+// @Failure      500  {object}  map[string]string
+// @Router       /exchange-rates [get]
+func (h *ExchangeRateHandler) ListRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.ListRates(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rates)
+}
+
+// @Summary      Get exchange rate
+// @Description  Get the rate in force for a pair, derived from the inverse pair if needed (requires JWT)
+// @Tags         ExchangeRates
+// @Produce      json
+// @Security     BearerAuth
+// @Param        base   path      string  true  "Base currency"
+// @Param        quote  path      string  true  "Quote currency"
+// @Success      200  {object}  domain.ExchangeRate
+// @Failure      404  {object}  map[string]string
+// @Router       /exchange-rates/{base}/{quote} [get]
+func (h *ExchangeRateHandler) GetRate(w http.ResponseWriter, r *http.Request) {
+	rate, err := h.service.GetRate(r.Context(), chi.URLParam(r, "base"), chi.URLParam(r, "quote"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrRateNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rate)
+}
+
+// @Summary      Exchange rate history
+// @Description  Get every rate recorded for a pair, newest first (requires JWT)
+// @Tags         ExchangeRates
+// @Produce      json
+// @Security     BearerAuth
+// @Param        base   path      string  true  "Base currency"
+// @Param        quote  path      string  true  "Quote currency"
+// @Success      200  {array}   domain.ExchangeRate
+// @Failure      500  {object}  map[string]string
+// @Router       /exchange-rates/{base}/{quote}/history [get]
+func (h *ExchangeRateHandler) RateHistory(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.RateHistory(r.Context(), chi.URLParam(r, "base"), chi.URLParam(r, "quote"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rates)
+}
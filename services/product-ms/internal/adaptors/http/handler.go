@@ -1,7 +1,10 @@
 package http
 
 import (
+	appMiddleware "ecom-api/pkg/middleware"
+	"ecom-api/pkg/money"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"product-microservice/internal/domain"
 	"product-microservice/internal/ports"
@@ -21,16 +24,26 @@ func NewProductHandler(s ports.ProductService) *ProductHandler {
 // Request DTOs for Swagger
 type ProductCreateRequest struct {
 	Name        string  `json:"name" example:"Laptop"`
+	SKU         string  `json:"sku,omitempty" example:"LAP-15-BLK"`
+	ImageURL    string  `json:"image_url,omitempty" example:"https://cdn.example.com/laptop.jpg"`
 	Description string  `json:"description" example:"High-end gaming laptop"`
-	Price       float64 `json:"price" example:"1299.99"`
+	Price       money.Money `json:"price"`
+	Prices      []money.Money `json:"prices"` // optional per-currency list prices
 	Stock       int     `json:"stock" example:"10"`
+	TaxCategory string  `json:"tax_category" example:"standard"`
+	Weight      float64 `json:"weight" example:"2.5"`
 }
 
 type ProductUpdateRequest struct {
 	Name        string  `json:"name" example:"Laptop"`
+	SKU         string  `json:"sku,omitempty" example:"LAP-15-BLK"`
+	ImageURL    string  `json:"image_url,omitempty" example:"https://cdn.example.com/laptop.jpg"`
 	Description string  `json:"description" example:"Updated description"`
-	Price       float64 `json:"price" example:"1199.99"`
+	Price       money.Money `json:"price"`
+	Prices      []money.Money `json:"prices"`
 	Stock       int     `json:"stock" example:"15"`
+	TaxCategory string  `json:"tax_category" example:"standard"`
+	Weight      float64 `json:"weight" example:"2.5"`
 }
 
 // @Summary      Create product
@@ -57,9 +70,14 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	// Map request → domain model
 	p := domain.Product{
 		Name:        req.Name,
+		SKU:         req.SKU,
+		ImageURL:    req.ImageURL,
 		Description: req.Description,
 		Price:       req.Price,
+		Prices:      req.Prices,
 		Stock:       req.Stock,
+		TaxCategory: req.TaxCategory,
+		Weight:      req.Weight,
 	}
 
 	product, err := h.service.CreateNewProduct(r.Context(), &p)
@@ -79,7 +97,10 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 			"name":        product.Name,
 			"description": product.Description,
 			"price":       product.Price,
+			"prices":      product.Prices,
 			"stock":       product.Stock,
+			"tax_category": product.TaxCategory,
+			"weight":       product.Weight,
 		},
 	}
 
@@ -89,13 +110,15 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary      Get product by ID
-// @Description  Fetch a product by ID (requires JWT)
+// @Description  Fetch a product by ID (requires JWT), optionally priced in another currency
 // @Tags         Products
 // @Produce      json
 // @Security     BearerAuth
-// @Param        id   path      string  true  "Product ID"
+// @Param        id        path      string  true   "Product ID"
+// @Param        currency  query     string  false  "ISO 4217 currency to quote the price in"
 // @Success      200  {object}  domain.Product
 // @Failure      404  {object}  map[string]string
+// @Failure      422  {object}  map[string]string
 // @Router       /products/{id} [get]
 func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -106,15 +129,24 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if currency := r.URL.Query().Get("currency"); currency != "" {
+		if product.Quote, err = h.service.QuotePrice(r.Context(), product, currency); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
 	json.NewEncoder(w).Encode(product)
 }
 
 // @Summary      List products
-// @Description  Get all products (requires JWT)
+// @Description  Get all products (requires JWT), optionally priced in another currency
 // @Tags         Products
 // @Produce      json
 // @Security     BearerAuth
+// @Param        currency  query     string  false  "ISO 4217 currency to quote prices in"
 // @Success      200  {array}   domain.Product
+// @Failure      422  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /products [get]
 func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
@@ -124,6 +156,15 @@ func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if currency := r.URL.Query().Get("currency"); currency != "" {
+		for i := range products {
+			if products[i].Quote, err = h.service.QuotePrice(r.Context(), &products[i], currency); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		}
+	}
+
 	json.NewEncoder(w).Encode(products)
 }
 
@@ -151,9 +192,14 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	p := domain.Product{
 		ID:          id,
 		Name:        req.Name,
+		SKU:         req.SKU,
+		ImageURL:    req.ImageURL,
 		Description: req.Description,
 		Price:       req.Price,
+		Prices:      req.Prices,
 		Stock:       req.Stock,
+		TaxCategory: req.TaxCategory,
+		Weight:      req.Weight,
 	}
 
 	product, err := h.service.UpdateProduct(r.Context(), &p)
@@ -166,18 +212,24 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary      Delete product
-// @Description  Delete product by ID (requires JWT)
+// @Description  Soft-delete product by ID (requires JWT); an admin can restore it until it is purged
 // @Tags         Products
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Product ID"
 // @Success      200  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /products/{id} [delete]
 func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	userID, _ := appMiddleware.FromContext(r.Context())
 
-	if err := h.service.DeleteProduct(r.Context(), id); err != nil {
+	if err := h.service.DeleteProduct(r.Context(), id, userID); err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -186,3 +238,29 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"message": "Product deleted successfully"}`))
 }
+
+// @Summary      Restore product
+// @Description  Restore a soft-deleted product (admin only)
+// @Tags         Products
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Product ID"
+// @Success      200  {object}  domain.Product
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /products/{id}/restore [post]
+func (h *ProductHandler) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	product, err := h.service.RestoreProduct(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			http.Error(w, "no deleted product with this id", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
@@ -29,7 +29,7 @@ import (
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
-func NewRouter(handler *ProductHandler) http.Handler {
+func NewRouter(handler *ProductHandler, rateHandler *ExchangeRateHandler) http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(chiMiddleware.Logger)
@@ -53,6 +53,17 @@ func NewRouter(handler *ProductHandler) http.Handler {
 		r.Get("/{id}", handler.GetProduct)
 		r.Put("/{id}", handler.UpdateProduct)
 		r.Delete("/{id}", handler.DeleteProduct)
+		r.With(appMiddleware.AdminOnly).Post("/{id}/restore", handler.RestoreProduct)
+	})
+
+	// Exchange rate routes
+	r.Route("/exchange-rates", func(r chi.Router) {
+		r.Use(appMiddleware.AuthMiddleware)
+
+		r.Get("/", rateHandler.ListRates)
+		r.Get("/{base}/{quote}", rateHandler.GetRate)
+		r.Get("/{base}/{quote}/history", rateHandler.RateHistory)
+		r.With(appMiddleware.AdminOnly).Post("/", rateHandler.SetRate)
 	})
 
 	return r
@@ -0,0 +1,111 @@
+package application
+
+import (
+	"context"
+	"ecom-api/pkg/money"
+	"errors"
+	"fmt"
+	"product-microservice/internal/domain"
+	"product-microservice/internal/ports"
+	"strings"
+	"time"
+)
+
+type ExchangeRateServiceImplement struct {
+	repo ports.ExchangeRateRepository
+}
+
+func NewExchangeRateService(r ports.ExchangeRateRepository) ports.ExchangeRateService {
+	return &ExchangeRateServiceImplement{repo: r}
+}
+
+func (s *ExchangeRateServiceImplement) SetRate(ctx context.Context, r *domain.ExchangeRate) (*domain.ExchangeRate, error) {
+	r.Base = strings.ToUpper(strings.TrimSpace(r.Base))
+	r.Quote = strings.ToUpper(strings.TrimSpace(r.Quote))
+
+	if !money.ValidCurrency(r.Base) || !money.ValidCurrency(r.Quote) {
+		return nil, fmt.Errorf("base and quote must be ISO 4217 codes")
+	}
+	if r.Base == r.Quote {
+		return nil, fmt.Errorf("base and quote must differ")
+	}
+	if r.Rate <= 0 {
+		return nil, fmt.Errorf("rate must be greater than 0")
+	}
+
+	r.CreatedAt = time.Now().UTC()
+	if r.EffectiveAt.IsZero() {
+		r.EffectiveAt = r.CreatedAt
+	}
+
+	return s.repo.Create(ctx, r)
+}
+
+func (s *ExchangeRateServiceImplement) GetRate(ctx context.Context, base, quote string) (*domain.ExchangeRate, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+	quote = strings.ToUpper(strings.TrimSpace(quote))
+	now := time.Now().UTC()
+
+	if base == quote {
+		return &domain.ExchangeRate{Base: base, Quote: quote, Rate: 1, EffectiveAt: now}, nil
+	}
+
+	rate, err := s.repo.Latest(ctx, base, quote, now)
+	if err == nil {
+		return rate, nil
+	}
+	if !errors.Is(err, domain.ErrRateNotFound) {
+		return nil, err
+	}
+
+	// fall back to the inverse pair
+	inverse, err := s.repo.Latest(ctx, quote, base, now)
+	if err != nil {
+		if errors.Is(err, domain.ErrRateNotFound) {
+			return nil, fmt.Errorf("%w: %s/%s", domain.ErrRateNotFound, base, quote)
+		}
+		return nil, err
+	}
+
+	return &domain.ExchangeRate{
+		ID:          inverse.ID,
+		Base:        base,
+		Quote:       quote,
+		Rate:        1 / inverse.Rate,
+		EffectiveAt: inverse.EffectiveAt,
+		CreatedAt:   inverse.CreatedAt,
+		CreatedBy:   inverse.CreatedBy,
+	}, nil
+}
+
+// ListRates returns the rate currently in force for every configured pair.
+func (s *ExchangeRateServiceImplement) ListRates(ctx context.Context) ([]domain.ExchangeRate, error) {
+	all, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	seen := map[string]bool{}
+	current := []domain.ExchangeRate{}
+	for _, r := range all { // newest first
+		if r.EffectiveAt.After(now) || seen[r.Base+r.Quote] {
+			continue
+		}
+		seen[r.Base+r.Quote] = true
+		current = append(current, r)
+	}
+	return current, nil
+}
+
+func (s *ExchangeRateServiceImplement) RateHistory(ctx context.Context, base, quote string) ([]domain.ExchangeRate, error) {
+	return s.repo.History(ctx, strings.ToUpper(base), strings.ToUpper(quote))
+}
+
+func (s *ExchangeRateServiceImplement) Convert(ctx context.Context, m money.Money, currency string) (money.Money, *domain.ExchangeRate, error) {
+	rate, err := s.GetRate(ctx, m.Currency, currency)
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+	return m.Convert(currency, rate.Rate), rate, nil
+}
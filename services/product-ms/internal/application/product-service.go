@@ -2,17 +2,21 @@ package application
 
 import (
 	"context"
+	"ecom-api/pkg/money"
 	"fmt"
 	"product-microservice/internal/domain"
 	"product-microservice/internal/ports"
+	"strings"
+	"time"
 )
 
 type ProductServiceimplement struct {
 	repo ports.ProductRepository
+	rates ports.ExchangeRateService
 }
 
-func NewProductService(r ports.ProductRepository) ports.ProductService {
-  return  &ProductServiceimplement{repo: r}
+func NewProductService(r ports.ProductRepository, rates ports.ExchangeRateService) ports.ProductService {
+  return  &ProductServiceimplement{repo: r, rates: rates}
 }
 
 func (s *ProductServiceimplement) CreateNewProduct(ctx context.Context, p *domain.Product) (*domain.Product, error) {
@@ -20,12 +24,21 @@ func (s *ProductServiceimplement) CreateNewProduct(ctx context.Context, p *domai
 	if p.Name == "" {
 		return nil, fmt.Errorf("product name is required")
 	}
-	if p.Price <= 0 {
+	if !p.Price.IsPositive() {
 		return nil, fmt.Errorf("price must be greater than 0")
 	}
+	if !money.ValidCurrency(p.Price.Currency) {
+		return nil, fmt.Errorf("price currency must be an ISO 4217 code")
+	}
 	if p.Stock < 0 {
 		return nil, fmt.Errorf("stock cannot be negative")
 	}
+	if p.Weight < 0 {
+		return nil, fmt.Errorf("weight cannot be negative")
+	}
+	if err := validatePrices(p); err != nil {
+		return nil, err
+	}
 
 	// If validation passes → Save to DB
 	return s.repo.CreateProduct(ctx, p)
@@ -41,9 +54,66 @@ func (s *ProductServiceimplement) ListProducts(ctx context.Context) ([]domain.Pr
 }
 
 func (s *ProductServiceimplement) UpdateProduct(ctx context.Context, p *domain.Product) (*domain.Product, error) {
+	if err := validatePrices(p); err != nil {
+		return nil, err
+	}
 	return  s.repo.Update(ctx, p)
 }
 
-func (s *ProductServiceimplement) DeleteProduct(ctx context.Context, id string) error {
-	return  s.repo.Delete(ctx, id)
+// DeleteProduct soft-deletes a product; it is hidden until restored or purged.
+func (s *ProductServiceimplement) DeleteProduct(ctx context.Context, id, deletedBy string) error {
+	return  s.repo.Delete(ctx, id, deletedBy)
+}
+
+func (s *ProductServiceimplement) RestoreProduct(ctx context.Context, id string) (*domain.Product, error) {
+	return s.repo.Restore(ctx, id)
+}
+
+func (s *ProductServiceimplement) PurgeDeletedProducts(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.Purge(ctx, before)
+}
+
+func (s *ProductServiceimplement) QuotePrice(ctx context.Context, p *domain.Product, currency string) (*domain.PriceQuote, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" || currency == p.Price.Currency {
+		return &domain.PriceQuote{Price: p.Price, Source: domain.PriceSourceBase, ExchangeRate: 1}, nil
+	}
+	if !money.ValidCurrency(currency) {
+		return nil, fmt.Errorf("currency must be an ISO 4217 code")
+	}
+
+	if price, ok := p.ListPrice(currency); ok {
+		return &domain.PriceQuote{Price: price, Source: domain.PriceSourceList, ExchangeRate: 1}, nil
+	}
+
+	price, rate, err := s.rates.Convert(ctx, p.Price, currency)
+	if err != nil {
+		return nil, fmt.Errorf("no %s price for product %s: %w", currency, p.ID, err)
+	}
+	return &domain.PriceQuote{
+		Price:        price,
+		Source:       domain.PriceSourceConverted,
+		ExchangeRate: rate.Rate,
+		RateAt:       &rate.EffectiveAt,
+	}, nil
+}
+
+// validatePrices normalizes the price list and rejects invalid or duplicate entries.
+func validatePrices(p *domain.Product) error {
+	seen := map[string]bool{}
+	for i, m := range p.Prices {
+		m.Currency = strings.ToUpper(strings.TrimSpace(m.Currency))
+		if !money.ValidCurrency(m.Currency) {
+			return fmt.Errorf("price list currency must be an ISO 4217 code")
+		}
+		if !m.IsPositive() {
+			return fmt.Errorf("price list amount in %s must be greater than 0", m.Currency)
+		}
+		if seen[m.Currency] {
+			return fmt.Errorf("duplicate price list entry for %s", m.Currency)
+		}
+		seen[m.Currency] = true
+		p.Prices[i] = m
+	}
+	return nil
 }
@@ -0,0 +1,161 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"product-microservice/internal/domain"
+	"product-microservice/internal/ports"
+	"strings"
+	"time"
+)
+
+type StockServiceImplement struct {
+	products     ports.ProductRepository
+	reservations ports.ReservationRepository
+}
+
+func NewStockService(products ports.ProductRepository, reservations ports.ReservationRepository) ports.StockService {
+	return &StockServiceImplement{products: products, reservations: reservations}
+}
+
+func (s *StockServiceImplement) Reserve(ctx context.Context, holder, productID string, quantity int) (*domain.StockReservation, int, error) {
+	holder = strings.TrimSpace(holder)
+	if holder == "" || productID == "" {
+		return nil, 0, fmt.Errorf("holder and product id are required")
+	}
+	if quantity < 0 {
+		return nil, 0, fmt.Errorf("quantity cannot be negative")
+	}
+	if quantity == 0 {
+		return &domain.StockReservation{Holder: holder, ProductID: productID}, 0, s.Release(ctx, holder, productID)
+	}
+
+	existing, err := s.reservations.Find(ctx, holder, productID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load reservation: %w", err)
+	}
+
+	now := time.Now()
+	res := &domain.StockReservation{Holder: holder, ProductID: productID, Quantity: quantity, CreatedAt: now, UpdatedAt: now}
+	delta := quantity
+	if existing != nil {
+		delta -= existing.Quantity
+		res.CreatedAt = existing.CreatedAt
+	}
+
+	if delta != 0 {
+		if err := s.products.AdjustReserved(ctx, productID, delta); err != nil {
+			return nil, 0, fmt.Errorf("failed to reserve %d of product %s: %w", quantity, productID, err)
+		}
+	}
+	if err := s.reservations.Save(ctx, res); err != nil {
+		if delta != 0 {
+			if uerr := s.products.AdjustReserved(ctx, productID, -delta); uerr != nil {
+				log.Printf("failed to undo reservation of product %s: %v", productID, uerr)
+			}
+		}
+		return nil, 0, fmt.Errorf("failed to save reservation: %w", err)
+	}
+
+	product, err := s.products.FindByID(ctx, productID)
+	if err != nil {
+		return res, 0, nil
+	}
+	return res, product.Available(), nil
+}
+
+func (s *StockServiceImplement) Release(ctx context.Context, holder, productID string) error {
+	held, err := s.held(ctx, holder, productID)
+	if err != nil {
+		return err
+	}
+
+	for _, res := range held {
+		// drop the reservation first, so a retry never frees stock twice
+		if err := s.reservations.Delete(ctx, res.Holder, res.ProductID); err != nil {
+			return fmt.Errorf("failed to delete reservation: %w", err)
+		}
+		if err := s.products.AdjustReserved(ctx, res.ProductID, -res.Quantity); err != nil {
+			return fmt.Errorf("failed to release product %s: %w", res.ProductID, err)
+		}
+	}
+	return nil
+}
+
+func (s *StockServiceImplement) Commit(ctx context.Context, holder string) error {
+	held, err := s.held(ctx, holder, "")
+	if err != nil {
+		return err
+	}
+
+	for _, res := range held {
+		if err := s.reservations.Delete(ctx, res.Holder, res.ProductID); err != nil {
+			return fmt.Errorf("failed to delete reservation: %w", err)
+		}
+		if err := s.products.CommitReserved(ctx, res.ProductID, res.Quantity); err != nil {
+			return fmt.Errorf("failed to commit product %s: %w", res.ProductID, err)
+		}
+	}
+	return nil
+}
+
+func (s *StockServiceImplement) held(ctx context.Context, holder, productID string) ([]domain.StockReservation, error) {
+	if strings.TrimSpace(holder) == "" {
+		return nil, fmt.Errorf("holder is required")
+	}
+
+	if productID == "" {
+		held, err := s.reservations.FindByHolder(ctx, holder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load reservations: %w", err)
+		}
+		return held, nil
+	}
+
+	res, err := s.reservations.Find(ctx, holder, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reservation: %w", err)
+	}
+	if res == nil {
+		return nil, nil
+	}
+	return []domain.StockReservation{*res}, nil
+}
+
+func (s *StockServiceImplement) Restock(ctx context.Context, reference string, lines []domain.StockLine) (bool, error) {
+	if strings.TrimSpace(reference) == "" {
+		return false, fmt.Errorf("reference is required")
+	}
+
+	restocked := false
+	for _, line := range lines {
+		if line.Quantity <= 0 {
+			continue
+		}
+
+		// lines are recorded one by one so a retry after a partial failure
+		// adds only what is missing
+		fresh, err := s.reservations.RecordRestock(ctx, reference, line.ProductID)
+		if err != nil {
+			return restocked, fmt.Errorf("failed to record restock: %w", err)
+		}
+		if !fresh {
+			continue
+		}
+
+		if err := s.products.Restock(ctx, line.ProductID, line.Quantity); err != nil {
+			if errors.Is(err, domain.ErrProductNotFound) {
+				log.Printf("restock %s: product %s no longer exists", reference, line.ProductID)
+				continue
+			}
+			if ferr := s.reservations.ForgetRestock(ctx, reference, line.ProductID); ferr != nil {
+				log.Printf("failed to forget restock %s of %s: %v", reference, line.ProductID, ferr)
+			}
+			return restocked, fmt.Errorf("failed to restock product %s: %w", line.ProductID, err)
+		}
+		restocked = true
+	}
+	return restocked, nil
+}
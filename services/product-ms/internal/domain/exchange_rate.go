@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"ecom-api/pkg/money"
+	"errors"
+	"time"
+)
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// ExchangeRate is one entry of the admin-managed rate table. Entries are
+// never updated; a new entry for the same pair supersedes the previous one
+// from its EffectiveAt on, so older entries form the rate history.
+type ExchangeRate struct {
+	ID          string    `json:"id" bson:"_id,omitempty"`
+	Base        string    `json:"base" bson:"base"`   // ISO 4217 code converted from
+	Quote       string    `json:"quote" bson:"quote"` // ISO 4217 code converted to
+	Rate        float64   `json:"rate" bson:"rate"`   // units of Quote per unit of Base
+	EffectiveAt time.Time `json:"effective_at" bson:"effective_at"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	CreatedBy   string    `json:"created_by" bson:"created_by"`
+}
+
+// Price sources of a PriceQuote.
+const (
+	PriceSourceBase      = "base"      // the product's own price
+	PriceSourceList      = "list"      // an explicit price list entry
+	PriceSourceConverted = "converted" // the base price converted at ExchangeRate
+)
+
+// PriceQuote is a product price resolved for one currency.
+type PriceQuote struct {
+	Price        money.Money `json:"price"`
+	Source       string      `json:"source"`
+	ExchangeRate float64     `json:"exchange_rate"` // 1 unless Source is converted
+	RateAt       *time.Time  `json:"rate_at,omitempty"`
+}
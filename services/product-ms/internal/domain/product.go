@@ -1,9 +1,42 @@
 package domain
 
+import (
+	"ecom-api/pkg/money"
+	"time"
+)
+
 type Product struct {
 	ID   string  `json:"id" bson:"_id,omitempty"`
 	Name  string  `json:"name"`
+	SKU      string `json:"sku,omitempty" bson:"sku,omitempty"`
+	ImageURL string `json:"image_url,omitempty" bson:"image_url,omitempty"`
 	Description   string  `json:"description"`
-	Price   float64   `json:"price"`
+	Price   money.Money `json:"price"`
+	Prices  []money.Money `json:"prices" bson:"prices"` // per-currency list prices, override conversion
 	Stock   int     `json:"stock"`
-}
\ No newline at end of file
+	Reserved int    `json:"reserved" bson:"reserved"` // held by open reservations, still part of Stock
+	TaxCategory string `json:"tax_category" bson:"tax_category"`
+	Weight  float64   `json:"weight" bson:"weight"` // shipping weight in kg
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+
+	Quote *PriceQuote `json:"quote,omitempty" bson:"-"` // set when a currency is requested
+}
+
+// ListPrice returns the price list entry for currency, if any.
+func (p *Product) ListPrice(currency string) (money.Money, bool) {
+	for _, m := range p.Prices {
+		if m.Currency == currency {
+			return m, true
+		}
+	}
+	return money.Money{}, false
+}
+
+// Available returns the stock that is not held by a reservation.
+func (p *Product) Available() int {
+	if p.Reserved >= p.Stock {
+		return 0
+	}
+	return p.Stock - p.Reserved
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrProductNotFound   = errors.New("product not found")
+)
+
+// StockReservation holds units of a product for a holder such as a cart
+// ("cart:<user id>") until it is released or committed as a sale.
+type StockReservation struct {
+	ID        string    `json:"id" bson:"_id,omitempty"`
+	Holder    string    `json:"holder" bson:"holder"`
+	ProductID string    `json:"product_id" bson:"product_id"`
+	Quantity  int       `json:"quantity" bson:"quantity"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// StockLine is a quantity of one product.
+type StockLine struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
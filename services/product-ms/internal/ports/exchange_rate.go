@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+	"ecom-api/pkg/money"
+	"product-microservice/internal/domain"
+	"time"
+)
+
+type ExchangeRateRepository interface {
+	Create(ctx context.Context, r *domain.ExchangeRate) (*domain.ExchangeRate, error)
+	// Latest returns the newest rate for the pair effective at or before at.
+	Latest(ctx context.Context, base, quote string, at time.Time) (*domain.ExchangeRate, error)
+	History(ctx context.Context, base, quote string) ([]domain.ExchangeRate, error)
+	FindAll(ctx context.Context) ([]domain.ExchangeRate, error)
+}
+
+type ExchangeRateService interface {
+	SetRate(ctx context.Context, r *domain.ExchangeRate) (*domain.ExchangeRate, error)
+	// GetRate returns the rate in force now, using the inverse pair when only
+	// that one is configured.
+	GetRate(ctx context.Context, base, quote string) (*domain.ExchangeRate, error)
+	ListRates(ctx context.Context) ([]domain.ExchangeRate, error)
+	RateHistory(ctx context.Context, base, quote string) ([]domain.ExchangeRate, error)
+	Convert(ctx context.Context, m money.Money, currency string) (money.Money, *domain.ExchangeRate, error)
+}
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"product-microservice/internal/domain"
+	"time"
 )
 
 type ProductRepository interface {
@@ -10,5 +11,16 @@ type ProductRepository interface {
 	FindByID(ctx context.Context, id string) (*domain.Product, error)
 	FindAll(ctx context.Context) ([]domain.Product, error)
 	Update(ctx context.Context, p *domain.Product) (*domain.Product, error)
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id, deletedBy string) error
+	// Restore undeletes a soft-deleted product.
+	Restore(ctx context.Context, id string) (*domain.Product, error)
+	// Purge removes products deleted before before for good.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	// AdjustReserved changes the reserved count by delta. Positive deltas
+	// fail with domain.ErrInsufficientStock unless that much is available.
+	AdjustReserved(ctx context.Context, id string, delta int) error
+	// CommitReserved removes quantity reserved units from the stock.
+	CommitReserved(ctx context.Context, id string, quantity int) error
+	// Restock adds quantity units to the stock.
+	Restock(ctx context.Context, id string, quantity int) error
 }
\ No newline at end of file
@@ -0,0 +1,33 @@
+package ports
+
+import (
+	"context"
+	"product-microservice/internal/domain"
+)
+
+type ReservationRepository interface {
+	// Find returns the holder's reservation of a product, or nil.
+	Find(ctx context.Context, holder, productID string) (*domain.StockReservation, error)
+	FindByHolder(ctx context.Context, holder string) ([]domain.StockReservation, error)
+	Save(ctx context.Context, r *domain.StockReservation) error
+	Delete(ctx context.Context, holder, productID string) error
+
+	// RecordRestock marks a line of a restock reference as applied and
+	// reports false if it already was; ForgetRestock undoes it.
+	RecordRestock(ctx context.Context, reference, productID string) (bool, error)
+	ForgetRestock(ctx context.Context, reference, productID string) error
+}
+
+type StockService interface {
+	// Reserve sets the quantity the holder holds of a product, taking
+	// only the difference to an existing reservation from available stock.
+	Reserve(ctx context.Context, holder, productID string, quantity int) (*domain.StockReservation, int, error)
+	// Release drops the holder's reservation of a product, or all of the
+	// holder's reservations when productID is empty.
+	Release(ctx context.Context, holder, productID string) error
+	// Commit takes the holder's reserved units off the stock.
+	Commit(ctx context.Context, holder string) error
+	// Restock adds the lines back to stock once per reference and reports
+	// whether anything was added.
+	Restock(ctx context.Context, reference string, lines []domain.StockLine) (bool, error)
+}
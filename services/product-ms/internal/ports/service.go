@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"product-microservice/internal/domain"
+	"time"
 )
 
 type ProductService interface {
@@ -10,5 +11,10 @@ type ProductService interface {
 	GetProduct(ctx context.Context, id string) (*domain.Product, error)
 	ListProducts(ctx context.Context) ([]domain.Product, error)
 	UpdateProduct(ctx context.Context, p *domain.Product) (*domain.Product,error)
-	DeleteProduct(ctx context.Context, id string) error
+	DeleteProduct(ctx context.Context, id, deletedBy string) error
+	RestoreProduct(ctx context.Context, id string) (*domain.Product, error)
+	PurgeDeletedProducts(ctx context.Context, before time.Time) (int64, error)
+	// QuotePrice resolves the product price in currency from its price list,
+	// falling back to converting the base price at the current exchange rate.
+	QuotePrice(ctx context.Context, p *domain.Product, currency string) (*domain.PriceQuote, error)
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"ecom-api/pkg/auth"
+	"ecom-api/pkg/softdelete"
 	"fmt"
 	"log"
 	"net"
@@ -15,6 +16,7 @@ import (
 
 	grpcAdapter "user-microservice/internal/adaptors/grpc"
 	httpAdapter "user-microservice/internal/adaptors/http"
+	"user-microservice/internal/adaptors/mail"
 	"user-microservice/internal/application"
 
 	"github.com/joho/godotenv"
@@ -22,6 +24,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 // @title           User Microservice API
@@ -57,6 +60,8 @@ if err := godotenv.Load("../../../.env"); err != nil {
 	dbName := os.Getenv("MONGO_DB_NAME")
 	httpPort := os.Getenv("USER_HTTP_PORT")
 	grpcPort := os.Getenv("USER_GRPC_PORT")
+	orderMsAddr := os.Getenv("ORDER_MS_GRPC_ADDR")
+	paymentMsAddr := os.Getenv("PAYMENT_MS_GRPC_ADDR")
 
 	if mongoURI == "" || dbName == "" {
 		log.Fatal("Missing MONGO_URI or MONGO_DB_NAME in environment")
@@ -77,9 +82,38 @@ if err := godotenv.Load("../../../.env"); err != nil {
 
 	dbConn := client.Database(dbName)
 
+	// gRPC clients for account deletion
+	orderConn, err := grpc.Dial(orderMsAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to connect to order-ms at %s: %v", orderMsAddr, err)
+	}
+	defer orderConn.Close()
+
+	paymentConn, err := grpc.Dial(paymentMsAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to connect to payment-ms at %s: %v", paymentMsAddr, err)
+	}
+	defer paymentConn.Close()
+
+	// verification and password reset tokens
+	tokens := db.NewMongoTokenRepository(dbConn)
+	if err := tokens.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create token indexes: %v", err)
+	}
+	mailer, err := mail.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Layers
 	repo := db.NewMongoUserRepository(dbConn)
-	service := application.NewUserService(repo)
+	service := application.NewUserService(repo, tokens, grpcAdapter.NewOrderClient(orderConn), grpcAdapter.NewPaymentClient(paymentConn), mailer)
+
+	// purge of deleted users
+	purger, err := softdelete.PurgerFromEnv("users", service.PurgeDeletedUsers)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// HTTP setup
 	handler := httpAdapter.NewUserHandler(service)
@@ -99,6 +133,8 @@ if err := godotenv.Load("../../../.env"); err != nil {
 	}
 
 	g := new(errgroup.Group) // run both http and grpc concurrently
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
 
 	// HTTP server
 	g.Go(func() error {
@@ -112,6 +148,11 @@ if err := godotenv.Load("../../../.env"); err != nil {
 		return grpcServer.Serve(lis)
 	})
 
+	// purge of deleted records
+	g.Go(func() error {
+		return purger.Run(workerCtx)
+	})
+
 	// shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, os.Kill)
@@ -119,6 +160,7 @@ if err := godotenv.Load("../../../.env"); err != nil {
 	go func() {
 		<-stop
 		fmt.Println("\nshutting down server...")
+		stopWorkers()
 
 		// shutdown http server
 		ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"time"
+	"user-microservice/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoTokenRepository(db *mongo.Database) *MongoTokenRepository {
+	return &MongoTokenRepository{
+		collection: db.Collection("user_tokens"),
+	}
+}
+
+// EnsureIndexes creates the token lookup index and lets Mongo remove
+// tokens once they expire.
+func (r *MongoTokenRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetName("token_hash").SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}}, Options: options.Index().SetName("user_id_purpose")},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetName("expires_at").SetExpireAfterSeconds(0)},
+	})
+	return err
+}
+
+func (r *MongoTokenRepository) Create(token *domain.Token) error {
+	_, err := r.collection.InsertOne(context.Background(), token)
+	return err
+}
+
+// Consume checks and spends the token in one update, so two requests
+// racing with the same token cannot both use it.
+func (r *MongoTokenRepository) Consume(purpose, hash string, now time.Time) (*domain.Token, error) {
+	filter := bson.M{
+		"purpose":    purpose,
+		"token_hash": hash,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+
+	var t domain.Token
+	err := r.collection.FindOneAndUpdate(context.Background(), filter, bson.M{"$set": bson.M{"used_at": now}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&t)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *MongoTokenRepository) Revoke(userID, purpose string) error {
+	_, err := r.collection.DeleteMany(context.Background(), bson.M{"user_id": userID, "purpose": purpose, "used_at": bson.M{"$exists": false}})
+	return err
+}
+
+func (r *MongoTokenRepository) DeleteByUser(userID string) error {
+	_, err := r.collection.DeleteMany(context.Background(), bson.M{"user_id": userID})
+	return err
+}
@@ -8,9 +8,13 @@ import (
 	"user-microservice/internal/domain"
 	"user-microservice/internal/ports"
 
+	"ecom-api/pkg/softdelete"
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MongoUserRepository struct {
@@ -24,8 +28,14 @@ func NewMongoUserRepository(db *mongo.Database) ports.UserRepository {
 }
 
 func (r *MongoUserRepository) Create(user *domain.User) error {
-	_, err := r.collection.InsertOne(context.Background(), user)
-	return err
+	res, err := r.collection.InsertOne(context.Background(), user)
+	if err != nil {
+		return err
+	}
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		user.ID = oid.Hex()
+	}
+	return nil
 }
 
 func (r *MongoUserRepository) GetById(id string) (*domain.User, error) {
@@ -36,7 +46,10 @@ func (r *MongoUserRepository) GetById(id string) (*domain.User, error) {
 		return  nil, fmt.Errorf("invalid id: %v", err)
 	}
 
-	err = r.collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&u)
+	err = r.collection.FindOne(context.Background(), softdelete.Active(bson.M{"_id": objectID})).Decode(&u)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrUserNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +59,7 @@ func (r *MongoUserRepository) GetById(id string) (*domain.User, error) {
 
 
 func (r *MongoUserRepository) GetAll() ([]domain.User, error) {
-	cursor, err := r.collection.Find(context.Background(), bson.M{})
+	cursor, err := r.collection.Find(context.Background(), softdelete.Active(nil))
 	if err != nil {
 		return  nil, err
 	}
@@ -65,7 +78,7 @@ func (r *MongoUserRepository) Exists(id string) (bool, error) {
 		return  false, err
 	}
 
-	count, err := r.collection.CountDocuments(context.Background(), bson.M{"_id": objectID})
+	count, err := r.collection.CountDocuments(context.Background(), softdelete.Active(bson.M{"_id": objectID}))
 	if err != nil {
 		return  false, err
 	}
@@ -76,7 +89,7 @@ func (r *MongoUserRepository) Exists(id string) (bool, error) {
 
 func (r *MongoUserRepository) FindByEmail(email string) (*domain.User, error) {
 	var user domain.User
-	err := r.collection.FindOne(context.Background(), bson.M{"email": email}).Decode(&user)
+	err := r.collection.FindOne(context.Background(), softdelete.Active(bson.M{"email": email})).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 
@@ -88,3 +101,158 @@ func (r *MongoUserRepository) FindByEmail(email string) (*domain.User, error) {
 	return &user, nil
 }
 
+func (r *MongoUserRepository) Delete(id, deletedBy string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid id: %v", err)
+	}
+
+	res, err := r.collection.UpdateOne(context.Background(), softdelete.Active(bson.M{"_id": objectID}), softdelete.MarkDeleted(deletedBy, time.Now()))
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *MongoUserRepository) FindDeleted(id string) (*domain.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %v", err)
+	}
+
+	var u domain.User
+	err = r.collection.FindOne(context.Background(), softdelete.Deleted(bson.M{"_id": objectID})).Decode(&u)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *MongoUserRepository) Restore(id string) (*domain.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %v", err)
+	}
+
+	res, err := r.collection.UpdateOne(context.Background(), softdelete.Deleted(bson.M{"_id": objectID}), softdelete.Restore())
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		return nil, domain.ErrUserNotFound
+	}
+	return r.GetById(id)
+}
+
+func (r *MongoUserRepository) Purge(before time.Time) (int64, error) {
+	return softdelete.Purge(context.Background(), r.collection, before)
+}
+
+func (r *MongoUserRepository) UpdateName(id, name string) (*domain.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %v", err)
+	}
+
+	res, err := r.collection.UpdateOne(context.Background(), softdelete.Active(bson.M{"_id": objectID}), bson.M{"$set": bson.M{"name": name}})
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		return nil, domain.ErrUserNotFound
+	}
+	return r.GetById(id)
+}
+
+func (r *MongoUserRepository) UpdatePassword(id, hashedPassword string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid id: %v", err)
+	}
+
+	res, err := r.collection.UpdateOne(context.Background(), softdelete.Active(bson.M{"_id": objectID}), bson.M{"$set": bson.M{"password": hashedPassword}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// ChangeEmail also unsets the pending email change older versions kept on
+// the user document.
+func (r *MongoUserRepository) ChangeEmail(id, email string, at time.Time) (*domain.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %v", err)
+	}
+
+	update := bson.M{
+		"$set":   bson.M{"email": email, "email_verified_at": at},
+		"$unset": bson.M{"email_change": ""},
+	}
+	var u domain.User
+	err = r.collection.FindOneAndUpdate(context.Background(), softdelete.Active(bson.M{"_id": objectID}), update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&u)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *MongoUserRepository) SetAnonymizedAs(id, anonymousID string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid id: %v", err)
+	}
+
+	res, err := r.collection.UpdateOne(context.Background(), softdelete.Active(bson.M{"_id": objectID}), bson.M{"$set": bson.M{"anonymized_as": anonymousID}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *MongoUserRepository) Erase(id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid id: %v", err)
+	}
+
+	res, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *MongoUserRepository) MarkEmailVerified(id, email string, at time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid id: %v", err)
+	}
+
+	res, err := r.collection.UpdateOne(context.Background(), softdelete.Active(bson.M{"_id": objectID, "email": email}), bson.M{"$set": bson.M{"email_verified_at": at}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrInvalidToken
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"context"
+	"order-microservice/adaptors/grpc/pb/order-microservice/services/order-ms/adaptors/grpc/pb"
+	"user-microservice/internal/domain"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type OrderClient struct {
+	client pb.OrderServiceClient
+}
+
+func NewOrderClient(conn *grpc.ClientConn) *OrderClient {
+	return &OrderClient{
+		client: pb.NewOrderServiceClient(conn),
+	}
+}
+
+// AnonymizeUser moves the user's orders to anonymousID. order-ms refuses
+// with FailedPrecondition while an order is still being fulfilled.
+func (c *OrderClient) AnonymizeUser(ctx context.Context, userID, anonymousID string) error {
+	_, err := c.client.AnonymizeUser(ctx, &pb.AnonymizeUserRequest{UserId: userID, AnonymousId: anonymousID})
+	if status.Code(err) == codes.FailedPrecondition {
+		return domain.ErrOrdersInProgress
+	}
+	return err
+}
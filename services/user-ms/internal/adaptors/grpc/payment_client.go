@@ -0,0 +1,25 @@
+package grpc
+
+import (
+	"context"
+	"payment-microservice/adaptors/grpc/pb/payment-microservice/services/payment-ms/adaptors/grpc/pb"
+
+	"google.golang.org/grpc"
+)
+
+type PaymentClient struct {
+	client pb.PaymentServiceClient
+}
+
+func NewPaymentClient(conn *grpc.ClientConn) *PaymentClient {
+	return &PaymentClient{
+		client: pb.NewPaymentServiceClient(conn),
+	}
+}
+
+// AnonymizeUser moves the user's payments and wallet to anonymousID and
+// deletes their saved payment methods.
+func (c *PaymentClient) AnonymizeUser(ctx context.Context, userID, anonymousID string) error {
+	_, err := c.client.AnonymizeUser(ctx, &pb.AnonymizeUserRequest{UserId: userID, AnonymousId: anonymousID})
+	return err
+}
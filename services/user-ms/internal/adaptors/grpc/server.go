@@ -37,6 +37,7 @@ func (s *UserGrpcServer) RegisterUser(ctx context.Context, req *pb.CreateUserReq
 			Email: createdUser.Email,
 			Password: createdUser.Password,
 			CreatedAt: createdUser.CreateAt.String(),
+			EmailVerified: createdUser.EmailVerified(),
 		},
 	}, nil
 }
@@ -55,6 +56,7 @@ func (s *UserGrpcServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*
 			Email: user.Email,
 			Password: user.Password,
 			CreatedAt: user.CreateAt.String(),
+			EmailVerified: user.EmailVerified(),
 		},
 	}, nil
 }
@@ -73,6 +75,7 @@ func (s *UserGrpcServer) ListUsers(ctx context.Context, req *pb.ListUserRequest)
 			Email: u.Email,
 			Password: u.Password,
 			CreatedAt: u.CreateAt.String(),
+			EmailVerified: u.EmailVerified(),
 		})
 	}
 
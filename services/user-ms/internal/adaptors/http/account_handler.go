@@ -0,0 +1,217 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ecom-api/pkg/middleware"
+	"user-microservice/internal/domain"
+)
+
+type UpdateProfileRequest struct {
+	Name string `json:"name" example:"Jane Doe"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" example:"secret123"`
+	NewPassword     string `json:"new_password" example:"n3wsecret"`
+}
+
+type EmailChangeRequest struct {
+	Email    string `json:"email" example:"jane@example.com"`
+	Password string `json:"password" example:"secret123"`
+}
+
+type ConfirmEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" example:"secret123"`
+}
+
+// GetMe godoc
+// @Summary      Get the signed-in user
+// @Tags         Account
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  domain.User
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /users/me [get]
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	user.Password = ""
+	json.NewEncoder(w).Encode(user)
+}
+
+// UpdateMe godoc
+// @Summary      Update the signed-in user's profile
+// @Tags         Account
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        profile  body      UpdateProfileRequest  true  "Profile"
+// @Success      200      {object}  domain.User
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Router       /users/me [patch]
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	var req UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.service.UpdateProfile(r.Context(), userID, req.Name)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	user.Password = ""
+	json.NewEncoder(w).Encode(user)
+}
+
+// ChangePassword godoc
+// @Summary      Change the signed-in user's password
+// @Description  Requires the current password.
+// @Tags         Account
+// @Security     BearerAuth
+// @Accept       json
+// @Param        password  body  ChangePasswordRequest  true  "Current and new password"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /users/me/password [post]
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.ChangePassword(r.Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestEmailChange godoc
+// @Summary      Change the signed-in user's email
+// @Description  Mails a confirmation token to the new address; the email changes once the token is confirmed.
+// @Tags         Account
+// @Security     BearerAuth
+// @Accept       json
+// @Param        email  body  EmailChangeRequest  true  "New email and current password"
+// @Success      202  "Accepted"
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /users/me/email [post]
+func (h *UserHandler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	var req EmailChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RequestEmailChange(r.Context(), userID, req.Password, req.Email); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ConfirmEmailChange godoc
+// @Summary      Confirm an email change
+// @Description  Public endpoint; the token comes from the confirmation email.
+// @Tags         Account
+// @Accept       json
+// @Produce      json
+// @Param        token  body      ConfirmEmailRequest  true  "Confirmation token"
+// @Success      200    {object}  domain.User
+// @Failure      400    {object}  map[string]string
+// @Failure      409    {object}  map[string]string
+// @Router       /users/email/confirm [post]
+func (h *UserHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req ConfirmEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.service.ConfirmEmailChange(r.Context(), req.Token)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	user.Password = ""
+	json.NewEncoder(w).Encode(user)
+}
+
+// DeleteMe godoc
+// @Summary      Delete the signed-in user's account
+// @Description  Permanently deletes the account after anonymizing its orders and payments. Refused while an order is being fulfilled.
+// @Tags         Account
+// @Security     BearerAuth
+// @Accept       json
+// @Param        password  body  DeleteAccountRequest  true  "Current password"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /users/me [delete]
+func (h *UserHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteAccount(r.Context(), userID, req.Password); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidUser), errors.Is(err, domain.ErrInvalidToken):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrInvalidPassword):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domain.ErrEmailTaken), errors.Is(err, domain.ErrOrdersInProgress), errors.Is(err, domain.ErrAlreadyVerified):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -36,13 +36,30 @@ func NewRouter(handler *UserHandler) http.Handler {
 		r.Post("/register", handler.RegisterUser)
 		r.Post("/login", handler.Login)
 		r.Get("/{id}/exists", handler.ExistsUser)
+		r.Post("/email/confirm", handler.ConfirmEmailChange)
+		r.Post("/email/verify", handler.VerifyEmail)
+		r.Post("/password/forgot", handler.ForgotPassword)
+		r.Post("/password/reset", handler.ResetPassword)
 
 		// ✅ Protected routes (require JWT)
 		r.Group(func(protected chi.Router) {
 			protected.Use(middleware.AuthMiddleware)
 
 			protected.Get("/", handler.ListUsers)
+
+			// the signed-in user's own account
+			protected.Get("/me", handler.GetMe)
+			protected.Patch("/me", handler.UpdateMe)
+			protected.Delete("/me", handler.DeleteMe)
+			protected.Post("/me/password", handler.ChangePassword)
+			protected.Post("/me/email", handler.RequestEmailChange)
+			protected.Post("/me/email/verification", handler.SendVerificationEmail)
+
 			protected.Get("/{id}", handler.GetUser)
+
+			// admin only: soft delete and restore
+			protected.With(middleware.AdminOnly).Delete("/{id}", handler.DeleteUser)
+			protected.With(middleware.AdminOnly).Post("/{id}/restore", handler.RestoreUser)
 		})
 	})
 
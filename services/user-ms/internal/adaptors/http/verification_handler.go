@@ -0,0 +1,117 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"ecom-api/pkg/middleware"
+)
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" example:"john@example.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password" example:"n3wsecret"`
+}
+
+// SendVerificationEmail godoc
+// @Summary      Resend the email verification token
+// @Description  Mails a new token to the signed-in user's address; earlier tokens stop working.
+// @Tags         Account
+// @Security     BearerAuth
+// @Success      202  "Accepted"
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /users/me/email/verification [post]
+func (h *UserHandler) SendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.FromContext(r.Context())
+
+	if err := h.service.SendVerificationEmail(r.Context(), userID); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// VerifyEmail godoc
+// @Summary      Verify an email address
+// @Description  Public endpoint; the token comes from the verification email and works once.
+// @Tags         Account
+// @Accept       json
+// @Produce      json
+// @Param        token  body      VerifyEmailRequest  true  "Verification token"
+// @Success      200    {object}  domain.User
+// @Failure      400    {object}  map[string]string
+// @Router       /users/email/verify [post]
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.service.VerifyEmail(r.Context(), req.Token)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	user.Password = ""
+	json.NewEncoder(w).Encode(user)
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset
+// @Description  Public endpoint; mails a reset token if the email belongs to an account. Always answers 202.
+// @Tags         Account
+// @Accept       json
+// @Param        email  body  ForgotPasswordRequest  true  "Account email"
+// @Success      202  "Accepted"
+// @Failure      400  {object}  map[string]string
+// @Router       /users/password/forgot [post]
+func (h *UserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword godoc
+// @Summary      Reset a forgotten password
+// @Description  Public endpoint; the token comes from the reset email and works once.
+// @Tags         Account
+// @Accept       json
+// @Param        reset  body  ResetPasswordRequest  true  "Reset token and new password"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Router       /users/password/reset [post]
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,20 @@
+package mail
+
+import (
+	"context"
+	"log"
+	"user-microservice/internal/domain"
+)
+
+// LogMailer writes messages to the log instead of sending them, for
+// development setups without a mail server.
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg domain.Message) error {
+	log.Printf("mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,57 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"user-microservice/internal/domain"
+	"user-microservice/internal/ports"
+)
+
+// FromEnv builds the mailer named by MAILER:
+//
+//	log     write messages to the log (default)
+//	smtp    send through SMTP_HOST:SMTP_PORT, with SMTP_USERNAME and
+//	        SMTP_PASSWORD if set, from MAIL_FROM
+//	file    write each message to a .eml file in MAIL_DIR
+//	memory  keep messages in memory, for tests
+func FromEnv() (ports.Mailer, error) {
+	switch kind := os.Getenv("MAILER"); kind {
+	case "", "log":
+		return NewLogMailer(), nil
+	case "smtp":
+		host, port, from := os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"), os.Getenv("MAIL_FROM")
+		if host == "" || port == "" || from == "" {
+			return nil, fmt.Errorf("MAILER=smtp needs SMTP_HOST, SMTP_PORT and MAIL_FROM")
+		}
+		return NewSMTPMailer(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from), nil
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			return nil, fmt.Errorf("MAILER=file needs MAIL_DIR")
+		}
+		return NewFileMailer(dir, os.Getenv("MAIL_FROM"))
+	case "memory":
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown MAILER %q", kind)
+	}
+}
+
+// format renders msg as a plain text RFC 5322 message.
+func format(from string, msg domain.Message, at time.Time) []byte {
+	var b strings.Builder
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", at.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
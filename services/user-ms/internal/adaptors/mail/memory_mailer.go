@@ -0,0 +1,39 @@
+package mail
+
+import (
+	"context"
+	"sync"
+	"user-microservice/internal/domain"
+)
+
+// MemoryMailer keeps sent messages in memory so tests can read the
+// tokens they carry.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []domain.Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg domain.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first.
+func (m *MemoryMailer) Messages() []domain.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.Message(nil), m.messages...)
+}
+
+// Reset forgets all sent messages.
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
@@ -0,0 +1,160 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"user-microservice/internal/domain"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UpdateProfile changes the user's display name.
+func (s *UserServiceImplement) UpdateProfile(ctx context.Context, id, name string) (*domain.User, error) {
+	if err := s.validate.Var(name, "required,min=2"); err != nil {
+		return nil, fmt.Errorf("%w: name must be at least 2 characters", domain.ErrInvalidUser)
+	}
+	return s.repo.UpdateName(id, name)
+}
+
+// ChangePassword replaces the password once the current one is verified.
+func (s *UserServiceImplement) ChangePassword(ctx context.Context, id, current, next string) error {
+	if err := s.validate.Var(next, "required,min=6"); err != nil {
+		return fmt.Errorf("%w: password must be at least 6 characters", domain.ErrInvalidUser)
+	}
+	if _, err := s.verifyPassword(id, current); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(next), 10)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(id, string(hash)); err != nil {
+		return err
+	}
+	// a reset link mailed earlier must not undo the change, and an email
+	// change requested from a hijacked session must not go through
+	if err := s.tokens.Revoke(id, domain.TokenResetPassword); err != nil {
+		return err
+	}
+	return s.tokens.Revoke(id, domain.TokenChangeEmail)
+}
+
+// RequestEmailChange mails a confirmation token to the new address. The
+// email only changes once the token is confirmed; the current address is
+// told about the request so a hijacked session cannot move the account
+// silently.
+func (s *UserServiceImplement) RequestEmailChange(ctx context.Context, id, password, email string) error {
+	if err := s.validate.Var(email, "required,email"); err != nil {
+		return fmt.Errorf("%w: invalid email", domain.ErrInvalidUser)
+	}
+	user, err := s.verifyPassword(id, password)
+	if err != nil {
+		return err
+	}
+	if email == user.Email {
+		return fmt.Errorf("%w: %s is already the account email", domain.ErrInvalidUser, email)
+	}
+	if err := s.ensureEmailFree(email); err != nil {
+		return err
+	}
+
+	token, err := s.issueToken(user, domain.TokenChangeEmail, email, domain.EmailChangeTTL)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, domain.Message{
+		To:      email,
+		Subject: "Confirm your new email address",
+		Body:    fmt.Sprintf("Use this token to confirm %s as your account email: %s\nIt expires in %s.", email, token, domain.EmailChangeTTL),
+	}); err != nil {
+		return fmt.Errorf("failed to send confirmation email: %w", err)
+	}
+	if err := s.mailer.Send(ctx, domain.Message{
+		To:      user.Email,
+		Subject: "Email change requested",
+		Body:    fmt.Sprintf("A change of your account email to %s was requested. If this was not you, change your password.", email),
+	}); err != nil {
+		return fmt.Errorf("failed to send notice email: %w", err)
+	}
+	return nil
+}
+
+// ConfirmEmailChange moves the user to the address the token was mailed
+// to. Confirming proves the address is the user's, so it counts as
+// verified.
+func (s *UserServiceImplement) ConfirmEmailChange(ctx context.Context, token string) (*domain.User, error) {
+	if token == "" {
+		return nil, domain.ErrInvalidToken
+	}
+	t, err := s.tokens.Consume(domain.TokenChangeEmail, domain.HashToken(token), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	// the address may have been registered since the change was requested
+	if err := s.ensureEmailFree(t.Email); err != nil {
+		return nil, err
+	}
+	return s.repo.ChangeEmail(t.UserID, t.Email, time.Now())
+}
+
+// DeleteAccount removes the user for good. Their orders and payments are
+// kept for bookkeeping but moved to an anonymous id first, so no record
+// points at the deleted account. The anonymous id is stored before the
+// calls so a deletion that fails half way reuses it when retried.
+func (s *UserServiceImplement) DeleteAccount(ctx context.Context, id, password string) error {
+	user, err := s.verifyPassword(id, password)
+	if err != nil {
+		return err
+	}
+
+	anonymousID := user.AnonymizedAs
+	if anonymousID == "" {
+		if anonymousID, err = domain.NewAnonymousID(); err != nil {
+			return err
+		}
+		if err := s.repo.SetAnonymizedAs(id, anonymousID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.orders.AnonymizeUser(ctx, id, anonymousID); err != nil {
+		if errors.Is(err, domain.ErrOrdersInProgress) {
+			return err
+		}
+		return fmt.Errorf("failed to anonymize orders: %w", err)
+	}
+	if err := s.payments.AnonymizeUser(ctx, id, anonymousID); err != nil {
+		return fmt.Errorf("failed to anonymize payments: %w", err)
+	}
+
+	if err := s.tokens.DeleteByUser(id); err != nil {
+		return err
+	}
+	return s.repo.Erase(id)
+}
+
+func (s *UserServiceImplement) verifyPassword(id, password string) (*domain.User, error) {
+	user, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, domain.ErrInvalidPassword
+	}
+	return user, nil
+}
+
+func (s *UserServiceImplement) ensureEmailFree(email string) error {
+	existing, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return fmt.Errorf("failed to check existing user: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("%w: %s", domain.ErrEmailTaken, email)
+	}
+	return nil
+}
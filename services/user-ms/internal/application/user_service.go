@@ -1,8 +1,10 @@
 package application
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 	"user-microservice/internal/domain"
 	"user-microservice/internal/ports"
@@ -13,12 +15,20 @@ import (
 
 type UserServiceImplement struct {
 	repo ports.UserRepository
+	tokens   ports.TokenRepository
+	orders   ports.OrderClient
+	payments ports.PaymentClient
+	mailer   ports.Mailer
 	validate *validator.Validate
 }
 
-func NewUserService(repo ports.UserRepository) ports.UserService {
+func NewUserService(repo ports.UserRepository, tokens ports.TokenRepository, orders ports.OrderClient, payments ports.PaymentClient, mailer ports.Mailer) ports.UserService {
 	return  &UserServiceImplement{
 		repo: repo,
+		tokens:   tokens,
+		orders:   orders,
+		payments: payments,
+		mailer:   mailer,
 		validate: validator.New(),
 	}
 }
@@ -49,6 +59,11 @@ func (s *UserServiceImplement) Register(user *domain.User) (*domain.User, error)
 		return nil, err
 	}
 
+	// the account exists either way; the user can ask for another email
+	if err := s.sendVerification(context.Background(), user); err != nil {
+		log.Printf("failed to send verification email to user %s: %v", user.ID, err)
+	}
+
 	return user, nil
 }
 
@@ -69,6 +84,9 @@ func (s *UserServiceImplement) Authenticate(email, password string) (*domain.Use
 	if err != nil {
 		return  nil, err
 	}
+	if user == nil {
+		return nil, errors.New("invalid email or password")
+	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		return  nil, errors.New("invalid email or password")
@@ -76,3 +94,32 @@ func (s *UserServiceImplement) Authenticate(email, password string) (*domain.Use
 
 	return user, nil
 }
+
+// DeleteUser soft-deletes a user; the account cannot sign in until it is
+// restored and is purged after the retention period.
+func (s *UserServiceImplement) DeleteUser(id, deletedBy string) error {
+	return s.repo.Delete(id, deletedBy)
+}
+
+// RestoreUser undeletes a user unless the email was registered again
+// while the account was deleted.
+func (s *UserServiceImplement) RestoreUser(id string) (*domain.User, error) {
+	deleted, err := s.repo.FindDeleted(id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.FindByEmail(deleted.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing user: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: %s belongs to another account", domain.ErrEmailTaken, deleted.Email)
+	}
+
+	return s.repo.Restore(id)
+}
+
+func (s *UserServiceImplement) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.Purge(before)
+}
@@ -0,0 +1,117 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"user-microservice/internal/domain"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SendVerificationEmail mails the user a new verification token; tokens
+// sent before stop working.
+func (s *UserServiceImplement) SendVerificationEmail(ctx context.Context, id string) error {
+	user, err := s.repo.GetById(id)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified() {
+		return domain.ErrAlreadyVerified
+	}
+	return s.sendVerification(ctx, user)
+}
+
+// VerifyEmail marks the address the token was sent to as verified.
+func (s *UserServiceImplement) VerifyEmail(ctx context.Context, token string) (*domain.User, error) {
+	t, err := s.tokens.Consume(domain.TokenVerifyEmail, domain.HashToken(token), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.MarkEmailVerified(t.UserID, t.Email, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetById(t.UserID)
+}
+
+// RequestPasswordReset mails a reset token to the account with the given
+// email. It succeeds for unknown addresses too, so it cannot be used to
+// find out who has an account.
+func (s *UserServiceImplement) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.FindByEmail(strings.TrimSpace(email))
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	token, err := s.issueToken(user, domain.TokenResetPassword, user.Email, domain.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, domain.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Use this token to choose a new password: %s\nIt expires in %s. If you did not ask for a reset, ignore this email.", token, domain.PasswordResetTTL),
+	})
+}
+
+// ResetPassword sets a new password for the user the token was issued to
+// and revokes their other reset tokens.
+func (s *UserServiceImplement) ResetPassword(ctx context.Context, token, password string) error {
+	if err := s.validate.Var(password, "required,min=6"); err != nil {
+		return fmt.Errorf("%w: password must be at least 6 characters", domain.ErrInvalidUser)
+	}
+	t, err := s.tokens.Consume(domain.TokenResetPassword, domain.HashToken(token), time.Now())
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(t.UserID, string(hash)); err != nil {
+		return err
+	}
+	return s.tokens.Revoke(t.UserID, domain.TokenResetPassword)
+}
+
+func (s *UserServiceImplement) sendVerification(ctx context.Context, user *domain.User) error {
+	token, err := s.issueToken(user, domain.TokenVerifyEmail, user.Email, domain.VerifyEmailTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, domain.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Use this token to verify %s: %s\nIt expires in %s.", user.Email, token, domain.VerifyEmailTTL),
+	})
+}
+
+// issueToken replaces the user's unused tokens for purpose with a new one
+// to be mailed to email and returns the token.
+func (s *UserServiceImplement) issueToken(user *domain.User, purpose, email string, ttl time.Duration) (string, error) {
+	if err := s.tokens.Revoke(user.ID, purpose); err != nil {
+		return "", err
+	}
+
+	token, hash, err := domain.NewToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	if err := s.tokens.Create(&domain.Token{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     email,
+		Hash:      hash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
@@ -0,0 +1,8 @@
+package domain
+
+// Message is an email sent to a user.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// Token lifetimes.
+const (
+	EmailChangeTTL   = 24 * time.Hour
+	VerifyEmailTTL   = 48 * time.Hour
+	PasswordResetTTL = time.Hour
+)
+
+// Token purposes.
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+	TokenChangeEmail   = "change_email"
+)
+
+// Token is a single-use secret mailed to a user. Only its hash is stored,
+// so a leaked database does not hand out working tokens.
+type Token struct {
+	ID      string `bson:"_id,omitempty"`
+	UserID  string `bson:"user_id"`
+	Purpose string `bson:"purpose"`
+	// Email is the address the token was sent to; a verification token
+	// does not verify an address the user has since moved away from, and
+	// an email change token carries the new address.
+	Email     string     `bson:"email"`
+	Hash      string     `bson:"token_hash"`
+	ExpiresAt time.Time  `bson:"expires_at"`
+	UsedAt    *time.Time `bson:"used_at,omitempty"`
+	CreatedAt time.Time  `bson:"created_at"`
+}
+
+// NewToken returns a random token to mail to the user and the hash to
+// store in its place.
+func NewToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the stored form of a token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewAnonymousID returns the id a deleted user's records are moved to.
+func NewAnonymousID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "deleted-" + hex.EncodeToString(b), nil
+}
@@ -11,8 +11,29 @@ type User struct {
 	Email    string     `json:"email" validate:"required,email"`
 	Password string     `json:"password" validate:"required,min=6"`
 	CreateAt time.Time  `json:"created_at" bson:"created_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" bson:"email_verified_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	// AnonymizedAs is the id the user's orders and payments are moved to
+	// when the account is deleted.
+	AnonymizedAs string `json:"-" bson:"anonymized_as,omitempty"`
 }
 
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrEmailTaken   = errors.New("email is already registered")
+	ErrInvalidPassword  = errors.New("invalid password")
+	ErrInvalidToken     = errors.New("invalid or expired token")
+	ErrInvalidUser      = errors.New("invalid user data")
+	ErrAlreadyVerified  = errors.New("email is already verified")
+	ErrOrdersInProgress = errors.New("account has orders in progress")
+)
+
+
+// EmailVerified reports whether the user proved they own their email.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
 
 func  NewUser(id, name, email, hashedPassword string) (*User, error) {
 	if len(name) < 2 {
@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+	"user-microservice/internal/domain"
+)
+
+// Mailer delivers email to users.
+type Mailer interface {
+	Send(ctx context.Context, msg domain.Message) error
+}
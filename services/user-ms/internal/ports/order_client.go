@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// OrderClient is the part of order-ms user-ms calls into.
+type OrderClient interface {
+	// AnonymizeUser moves the user's orders to anonymousID. It fails with
+	// domain.ErrOrdersInProgress while an order is still being fulfilled.
+	AnonymizeUser(ctx context.Context, userID, anonymousID string) error
+}
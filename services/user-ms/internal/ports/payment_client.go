@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// PaymentClient is the part of payment-ms user-ms calls into.
+type PaymentClient interface {
+	// AnonymizeUser moves the user's payments to anonymousID and drops
+	// their saved payment methods.
+	AnonymizeUser(ctx context.Context, userID, anonymousID string) error
+}
@@ -1,6 +1,9 @@
 package ports
 
-import "user-microservice/internal/domain"
+import (
+	"time"
+	"user-microservice/internal/domain"
+)
 
 // Outbound port (persistent storage)
 type UserRepository interface {
@@ -9,5 +12,33 @@ type UserRepository interface {
 	GetAll() ([]domain.User, error)
 	Exists(id string) (bool, error)
 	FindByEmail(email string) (*domain.User, error)
+	Delete(id, deletedBy string) error
+	// FindDeleted returns a soft-deleted user.
+	FindDeleted(id string) (*domain.User, error)
+	Restore(id string) (*domain.User, error)
+	// Purge removes users deleted before before for good.
+	Purge(before time.Time) (int64, error)
+	UpdateName(id, name string) (*domain.User, error)
+	UpdatePassword(id, hashedPassword string) error
+	// ChangeEmail moves the user to a confirmed address, verified at at,
+	// and returns the updated user.
+	ChangeEmail(id, email string, at time.Time) (*domain.User, error)
+	SetAnonymizedAs(id, anonymousID string) error
+	// MarkEmailVerified fails with domain.ErrInvalidToken if the user's
+	// email is no longer email.
+	MarkEmailVerified(id, email string, at time.Time) error
+	// Erase removes a user for good, without the soft-delete retention.
+	Erase(id string) error
 }
 
+
+// TokenRepository stores the single-use tokens mailed to users.
+type TokenRepository interface {
+	Create(token *domain.Token) error
+	// Consume marks the unused, unexpired token with the given purpose and
+	// hash as used and returns it; any other token is domain.ErrInvalidToken.
+	Consume(purpose, hash string, now time.Time) (*domain.Token, error)
+	// Revoke drops the user's unused tokens for purpose.
+	Revoke(userID, purpose string) error
+	DeleteByUser(userID string) error
+}
@@ -1,6 +1,10 @@
 package ports
 
-import "user-microservice/internal/domain"
+import (
+	"context"
+	"time"
+	"user-microservice/internal/domain"
+)
 
 
 // Inbound port (use cases)
@@ -10,5 +14,17 @@ type UserService interface {
 	ListUsers() ([]domain.User, error)
 	Exists(id string) (bool, error)
 	Authenticate(email, password string)(*domain.User, error)
+	DeleteUser(id, deletedBy string) error
+	RestoreUser(id string) (*domain.User, error)
+	PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error)
+	UpdateProfile(ctx context.Context, id, name string) (*domain.User, error)
+	ChangePassword(ctx context.Context, id, current, next string) error
+	RequestEmailChange(ctx context.Context, id, password, email string) error
+	ConfirmEmailChange(ctx context.Context, token string) (*domain.User, error)
+	DeleteAccount(ctx context.Context, id, password string) error
+	SendVerificationEmail(ctx context.Context, id string) error
+	VerifyEmail(ctx context.Context, token string) (*domain.User, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
 }
 
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"ecom-api/pkg/auth"
+	"ecom-api/pkg/money"
+	"ecom-api/pkg/scheduler"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"webhook-microservice/internal/adaptors/db"
+	httpAdapter "webhook-microservice/internal/adaptors/http"
+	"webhook-microservice/internal/adaptors/sender"
+	"webhook-microservice/internal/application"
+	"webhook-microservice/internal/ports"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/errgroup"
+)
+
+// @title           Webhook Microservice API
+// @version         1.0
+// @description     Outbound merchant webhooks for the e-commerce system. Events of order-ms and payment-ms are delivered to registered endpoints, signed and retried.
+// @termsOfService  http://swagger.io/terms/
+
+// @contact.name   API Support
+// @contact.email  support@example.com
+
+// @license.name  Apache 2.0
+// @license.url   http://www.apache.org/licenses/LICENSE-2.0.html
+
+// @host      localhost:8086
+// @BasePath  /
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and JWT token.
+
+func main() {
+	// --- Load env ---
+	if err := godotenv.Load("../../../.env"); err != nil {
+		log.Println("Warning: No .env file found, falling back to system environment")
+	}
+
+	auth.InitJWT()
+
+	mongoURI := os.Getenv("MONGO_URI")
+	dbName := os.Getenv("MONGO_DB_NAME")
+	httpPort := os.Getenv("WEBHOOK_HTTP_PORT")
+
+	if mongoURI == "" || dbName == "" || httpPort == "" {
+		log.Fatal("❌ Missing required environment variables")
+	}
+
+	// --- MongoDB ---
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetRegistry(money.Registry(os.Getenv("DEFAULT_CURRENCY"))))
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		log.Fatal(err)
+	}
+	defer client.Disconnect(ctx)
+
+	dbConn := client.Database(dbName)
+
+	// --- event sources ---
+	sources, err := parseSources(client, os.Getenv("WEBHOOK_SOURCES"), dbName)
+	if err != nil {
+		log.Fatalf("invalid WEBHOOK_SOURCES: %v", err)
+	}
+
+	// --- delivery ---
+	dispatch := application.DefaultDispatchConfig
+	if v := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); v != "" {
+		if dispatch.MaxAttempts, err = strconv.Atoi(v); err != nil || dispatch.MaxAttempts <= 0 {
+			log.Fatalf("invalid WEBHOOK_MAX_ATTEMPTS: %q", v)
+		}
+	}
+	if v := os.Getenv("WEBHOOK_RETRY_BASE"); v != "" {
+		if dispatch.RetryBase, err = time.ParseDuration(v); err != nil || dispatch.RetryBase <= 0 {
+			log.Fatalf("invalid WEBHOOK_RETRY_BASE: %q", v)
+		}
+	}
+	timeout := 10 * time.Second
+	if v := os.Getenv("WEBHOOK_TIMEOUT"); v != "" {
+		if timeout, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid WEBHOOK_TIMEOUT: %v", err)
+		}
+	}
+	relayInterval := 10 * time.Second
+	if v := os.Getenv("WEBHOOK_RELAY_INTERVAL"); v != "" {
+		if relayInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid WEBHOOK_RELAY_INTERVAL: %v", err)
+		}
+	}
+	dispatchInterval := 10 * time.Second
+	if v := os.Getenv("WEBHOOK_DISPATCH_INTERVAL"); v != "" {
+		if dispatchInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid WEBHOOK_DISPATCH_INTERVAL: %v", err)
+		}
+	}
+
+	// --- wiring ---
+	subscriptions := db.NewMongoSubscriptionRepo(dbConn)
+	deliveries := db.NewMongoDeliveryRepo(dbConn)
+	if err := deliveries.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to create delivery indexes: %v", err)
+	}
+	service := application.NewWebhookService(subscriptions, deliveries, db.NewMongoCursorRepo(dbConn), sources, sender.NewHTTPSender(timeout), dispatch)
+
+	jobs, err := scheduler.FromEnv(dbConn, "webhook-ms")
+	if err != nil {
+		log.Fatal(err)
+	}
+	jobs.Every("relay-events", relayInterval, func(ctx context.Context) error {
+		_, err := service.RelayEvents(ctx)
+		return err
+	})
+	jobs.Every("dispatch-deliveries", dispatchInterval, func(ctx context.Context) error {
+		_, err := service.DispatchDue(ctx)
+		return err
+	})
+
+	// HTTP server
+	handler := httpAdapter.NewWebhookHandler(service)
+	httpServer := &http.Server{
+		Addr:    httpPort,
+		Handler: httpAdapter.NewRouter(handler),
+	}
+
+	g := new(errgroup.Group)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// HTTP
+	g.Go(func() error {
+		fmt.Println("✅ Webhook HTTP server running on", httpPort)
+		return httpServer.ListenAndServe()
+	})
+
+	// relay and dispatch, run by one replica at a time
+	g.Go(func() error {
+		return jobs.Run(workerCtx)
+	})
+
+	// --- Graceful shutdown ---
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, os.Kill)
+
+	go func() {
+		<-stop
+		fmt.Println("\n🛑 Shutting down Webhook service...")
+		stopWorkers()
+
+		ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(ctxShutdown); err != nil {
+			log.Printf("HTTP shutdown error: %v\n", err)
+		}
+	}()
+
+	// wait for error or shutdown
+	if err := g.Wait(); err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
+
+// parseSources reads the services whose outbox is relayed, as a
+// comma-separated list of name[=database] such as
+// "order-ms=orderdb,payment-ms=paymentdb". A source without a database
+// shares this service's one.
+func parseSources(client *mongo.Client, v, defaultDB string) ([]ports.EventSource, error) {
+	if strings.TrimSpace(v) == "" {
+		v = "order-ms,payment-ms"
+	}
+
+	var sources []ports.EventSource
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, database, found := strings.Cut(part, "=")
+		name, database = strings.TrimSpace(name), strings.TrimSpace(database)
+		if name == "" || (found && database == "") {
+			return nil, fmt.Errorf("source %q must be name or name=database", part)
+		}
+		if database == "" {
+			database = defaultDB
+		}
+		sources = append(sources, db.NewMongoEventSource(client.Database(database), name))
+	}
+	return sources, nil
+}
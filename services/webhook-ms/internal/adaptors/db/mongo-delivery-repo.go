@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"webhook-microservice/internal/domain"
+	"webhook-microservice/internal/ports"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxLoggedAttempts caps the attempt log kept on a delivery; older
+// attempts are dropped.
+const maxLoggedAttempts = 25
+
+type MongoDeliveryRepo struct {
+	col *mongo.Collection
+}
+
+func NewMongoDeliveryRepo(db *mongo.Database) ports.DeliveryRepository {
+	return &MongoDeliveryRepo{
+		col: db.Collection("deliveries"),
+	}
+}
+
+func (r *MongoDeliveryRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "subscription_id", Value: 1}, {Key: "event_id", Value: 1}},
+			Options: options.Index().SetName("subscription_event").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+			Options: options.Index().SetName("status_next_attempt_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "subscription_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("subscription_created_at"),
+		},
+	})
+	return err
+}
+
+func (r *MongoDeliveryRepo) Create(ctx context.Context, d *domain.Delivery) (bool, error) {
+	res, err := r.col.InsertOne(ctx, d)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if id, ok := res.InsertedID.(primitive.ObjectID); ok {
+		d.ID = id.Hex()
+	}
+	return true, nil
+}
+
+func (r *MongoDeliveryRepo) FindByID(ctx context.Context, id string) (*domain.Delivery, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid id", domain.ErrDeliveryNotFound)
+	}
+
+	var d domain.Delivery
+	err = r.col.FindOne(ctx, bson.M{"_id": objID}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *MongoDeliveryRepo) List(ctx context.Context, subscriptionID, status string, limit int64) ([]*domain.Delivery, error) {
+	filter := bson.M{}
+	if subscriptionID != "" {
+		filter["subscription_id"] = subscriptionID
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"payload": 0})
+	return r.find(ctx, filter, opts)
+}
+
+func (r *MongoDeliveryRepo) FindDue(ctx context.Context, now time.Time, limit int64) ([]*domain.Delivery, error) {
+	filter := bson.M{
+		"status":          domain.DeliveryPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"log": 0})
+	return r.find(ctx, filter, opts)
+}
+
+func (r *MongoDeliveryRepo) RecordAttempt(ctx context.Context, id string, attempt domain.DeliveryAttempt, status string, next *time.Time) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid id", domain.ErrDeliveryNotFound)
+	}
+
+	set := bson.M{"status": status}
+	unset := bson.M{}
+	if next != nil {
+		set["next_attempt_at"] = *next
+	} else {
+		unset["next_attempt_at"] = ""
+	}
+	if failure := attempt.Failure(); failure != "" {
+		set["last_error"] = failure
+	} else {
+		unset["last_error"] = ""
+	}
+	if status == domain.DeliverySucceeded && attempt.OK() {
+		set["delivered_at"] = attempt.At
+	}
+
+	update := bson.M{
+		"$set": set,
+		"$inc": bson.M{"attempts": 1},
+		"$push": bson.M{"log": bson.M{
+			"$each":  []domain.DeliveryAttempt{attempt},
+			"$slice": -maxLoggedAttempts,
+		}},
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	res, err := r.col.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrDeliveryNotFound
+	}
+	return nil
+}
+
+func (r *MongoDeliveryRepo) Postpone(ctx context.Context, id string, next time.Time) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid id", domain.ErrDeliveryNotFound)
+	}
+	_, err = r.col.UpdateOne(ctx,
+		bson.M{"_id": objID, "status": domain.DeliveryPending},
+		bson.M{"$set": bson.M{"next_attempt_at": next}},
+	)
+	return err
+}
+
+func (r *MongoDeliveryRepo) DeleteBySubscription(ctx context.Context, subscriptionID string) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"subscription_id": subscriptionID})
+	return err
+}
+
+func (r *MongoDeliveryRepo) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*domain.Delivery, error) {
+	cursor, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := []*domain.Delivery{}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"ecom-api/pkg/events"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"webhook-microservice/internal/domain"
+	"webhook-microservice/internal/ports"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoEventSource reads the events a service published to its outbox.
+// Services may share a database, so events are also filtered by source.
+type MongoEventSource struct {
+	col  *mongo.Collection
+	name string
+}
+
+func NewMongoEventSource(db *mongo.Database, service string) ports.EventSource {
+	return &MongoEventSource{
+		col:  db.Collection(events.OutboxCollection),
+		name: service,
+	}
+}
+
+func (s *MongoEventSource) Name() string { return s.name }
+
+type outboxEvent struct {
+	ID         string        `bson:"_id"`
+	Type       string        `bson:"type"`
+	Source     string        `bson:"source"`
+	Subject    string        `bson:"subject"`
+	OccurredAt time.Time     `bson:"occurred_at"`
+	Data       bson.RawValue `bson:"data"`
+}
+
+func (s *MongoEventSource) ReadAfter(ctx context.Context, after string, until time.Time, limit int64) ([]*domain.Event, error) {
+	// IDs are ObjectID hex strings, which sort by creation time
+	id := bson.M{"$lt": primitive.NewObjectIDFromTimestamp(until).Hex()}
+	if after != "" {
+		id["$gt"] = after
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := s.col.Find(ctx, bson.M{"source": s.name, "_id": id}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	out := []*domain.Event{}
+	for cursor.Next(ctx) {
+		var e outboxEvent
+		if err := cursor.Decode(&e); err != nil {
+			return nil, err
+		}
+		data, err := dataJSON(e.Data)
+		if err != nil {
+			return nil, fmt.Errorf("event %s: %w", e.ID, err)
+		}
+		out = append(out, &domain.Event{
+			ID:         e.ID,
+			Type:       e.Type,
+			Source:     e.Source,
+			Subject:    e.Subject,
+			OccurredAt: e.OccurredAt,
+			Data:       data,
+		})
+	}
+	return out, cursor.Err()
+}
+
+// dataJSON converts an event's payload to JSON. Payload structs carry the
+// same bson and json field names, so documents come out as the emitting
+// service would have encoded them to JSON.
+func dataJSON(v bson.RawValue) (json.RawMessage, error) {
+	if v.Type != bsontype.EmbeddedDocument {
+		var x interface{}
+		if v.Type != 0 && v.Type != bsontype.Null {
+			if err := v.Unmarshal(&x); err != nil {
+				return nil, err
+			}
+		}
+		return json.Marshal(x)
+	}
+
+	dec, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(v.Document()))
+	if err != nil {
+		return nil, err
+	}
+	dec.DefaultDocumentM()
+
+	var m bson.M
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// MongoCursorRepo keeps the relay's position in each source.
+type MongoCursorRepo struct {
+	col *mongo.Collection
+}
+
+func NewMongoCursorRepo(db *mongo.Database) ports.CursorRepository {
+	return &MongoCursorRepo{
+		col: db.Collection("relay_cursors"),
+	}
+}
+
+func (r *MongoCursorRepo) Get(ctx context.Context, source string) (string, error) {
+	var doc struct {
+		EventID string `bson:"event_id"`
+	}
+	err := r.col.FindOne(ctx, bson.M{"_id": source}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+	return doc.EventID, err
+}
+
+func (r *MongoCursorRepo) Save(ctx context.Context, source, eventID string) error {
+	_, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": source},
+		bson.M{"$set": bson.M{"event_id": eventID, "updated_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
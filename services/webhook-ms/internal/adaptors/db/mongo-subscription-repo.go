@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"webhook-microservice/internal/domain"
+	"webhook-microservice/internal/ports"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoSubscriptionRepo struct {
+	col *mongo.Collection
+}
+
+func NewMongoSubscriptionRepo(db *mongo.Database) ports.SubscriptionRepository {
+	return &MongoSubscriptionRepo{
+		col: db.Collection("subscriptions"),
+	}
+}
+
+func (r *MongoSubscriptionRepo) Create(ctx context.Context, s *domain.Subscription) (*domain.Subscription, error) {
+	objID := primitive.NewObjectID()
+
+	_, err := r.col.InsertOne(ctx, bson.M{
+		"_id":         objID,
+		"url":         s.URL,
+		"event_types": s.EventTypes,
+		"description": s.Description,
+		"secret":      s.Secret,
+		"active":      s.Active,
+		"created_at":  s.CreatedAt,
+		"updated_at":  s.UpdatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.ID = objID.Hex()
+	return s, nil
+}
+
+func (r *MongoSubscriptionRepo) FindByID(ctx context.Context, id string) (*domain.Subscription, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid id", domain.ErrSubscriptionNotFound)
+	}
+
+	var s domain.Subscription
+	err = r.col.FindOne(ctx, bson.M{"_id": objID}).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *MongoSubscriptionRepo) List(ctx context.Context) ([]*domain.Subscription, error) {
+	return r.find(ctx, bson.M{})
+}
+
+func (r *MongoSubscriptionRepo) FindActive(ctx context.Context) ([]*domain.Subscription, error) {
+	return r.find(ctx, bson.M{"active": true})
+}
+
+func (r *MongoSubscriptionRepo) Update(ctx context.Context, s *domain.Subscription) error {
+	objID, err := primitive.ObjectIDFromHex(s.ID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid id", domain.ErrSubscriptionNotFound)
+	}
+
+	res, err := r.col.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{
+		"$set": bson.M{
+			"url":         s.URL,
+			"event_types": s.EventTypes,
+			"description": s.Description,
+			"active":      s.Active,
+			"updated_at":  s.UpdatedAt,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *MongoSubscriptionRepo) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid id", domain.ErrSubscriptionNotFound)
+	}
+	_, err = r.col.DeleteOne(ctx, bson.M{"_id": objID})
+	return err
+}
+
+func (r *MongoSubscriptionRepo) find(ctx context.Context, filter bson.M) ([]*domain.Subscription, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	subs := []*domain.Subscription{}
+	if err := cursor.All(ctx, &subs); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
@@ -0,0 +1,268 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"webhook-microservice/internal/domain"
+	"webhook-microservice/internal/ports"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhookHandler struct {
+	service ports.WebhookService
+}
+
+func NewWebhookHandler(service ports.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+type CreateSubscriptionRequest struct {
+	URL         string   `json:"url" example:"https://merchant.example.com/hooks"`
+	EventTypes  []string `json:"event_types" example:"order.*,payment.completed"`
+	Description string   `json:"description,omitempty" example:"Order sync"`
+}
+
+// UpdateSubscriptionRequest changes the fields that are present.
+type UpdateSubscriptionRequest struct {
+	URL         *string  `json:"url,omitempty"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// SubscriptionWithSecret is a new subscription together with its signing
+// secret, which is not shown again.
+type SubscriptionWithSecret struct {
+	domain.Subscription
+	Secret string `json:"secret" example:"whsec_..."`
+}
+
+// @Summary      Create Subscription
+// @Description  Register a merchant endpoint for event types such as order.created, a family such as order.* or * for all (admin only). Deliveries are posted as JSON with a Webhook-Signature header "t=<unix time>,v1=<hex HMAC-SHA256 of t.body>" made with the returned secret, which is only shown here.
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        subscription  body      CreateSubscriptionRequest  true  "Subscription"
+// @Success      201  {object}  SubscriptionWithSecret
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/subscriptions [post]
+func (h *WebhookHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var req CreateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	sub, err := h.service.CreateSubscription(r.Context(), req.URL, req.EventTypes, req.Description)
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, SubscriptionWithSecret{Subscription: *sub, Secret: sub.Secret})
+}
+
+// @Summary      List Subscriptions
+// @Description  List the registered merchant endpoints (admin only)
+// @Tags         Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   domain.Subscription
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/subscriptions [get]
+func (h *WebhookHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.service.ListSubscriptions(r.Context())
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, subs)
+}
+
+// @Summary      Get Subscription
+// @Description  Get a merchant endpoint (admin only)
+// @Tags         Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Subscription ID"
+// @Success      200  {object}  domain.Subscription
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /webhooks/subscriptions/{id} [get]
+func (h *WebhookHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, err := h.service.GetSubscription(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+// @Summary      Update Subscription
+// @Description  Change the URL, event types or description of a merchant endpoint, or pause it with active=false (admin only). Deliveries of a paused endpoint wait until it is active again.
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id            path      string                     true  "Subscription ID"
+// @Param        subscription  body      UpdateSubscriptionRequest  true  "Changes"
+// @Success      200  {object}  domain.Subscription
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /webhooks/subscriptions/{id} [patch]
+func (h *WebhookHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	var req UpdateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	sub, err := h.service.UpdateSubscription(r.Context(), chi.URLParam(r, "id"), ports.SubscriptionChanges{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		Active:      req.Active,
+	})
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+// @Summary      Delete Subscription
+// @Description  Remove a merchant endpoint together with its deliveries (admin only)
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Subscription ID"
+// @Success      204
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /webhooks/subscriptions/{id} [delete]
+func (h *WebhookHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteSubscription(r.Context(), chi.URLParam(r, "id")); err != nil {
+		webhookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary      List Deliveries
+// @Description  List deliveries with their attempt log, newest first, without payloads (admin only). status=dead is the dead-letter view of deliveries that ran out of attempts.
+// @Tags         Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        subscription_id  query  string  false  "Only deliveries to this subscription"
+// @Param        status           query  string  false  "pending, succeeded or dead"
+// @Param        limit            query  int     false  "Maximum number of deliveries (default 50)"
+// @Success      200  {array}   domain.Delivery
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	h.listDeliveries(w, r, r.URL.Query().Get("status"))
+}
+
+// @Summary      Dead Letters
+// @Description  List the deliveries that ran out of attempts, newest first (admin only). Redeliver them once the endpoint is fixed.
+// @Tags         Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        subscription_id  query  string  false  "Only deliveries to this subscription"
+// @Param        limit            query  int     false  "Maximum number of deliveries (default 50)"
+// @Success      200  {array}   domain.Delivery
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/deliveries/dead [get]
+func (h *WebhookHandler) ListDeadDeliveries(w http.ResponseWriter, r *http.Request) {
+	h.listDeliveries(w, r, domain.DeliveryDead)
+}
+
+func (h *WebhookHandler) listDeliveries(w http.ResponseWriter, r *http.Request, status string) {
+	limit := int64(50)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = min(n, 500)
+	}
+
+	deliveries, err := h.service.ListDeliveries(r.Context(), r.URL.Query().Get("subscription_id"), status, limit)
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// @Summary      Get Delivery
+// @Description  Get a delivery with its payload and attempt log (admin only)
+// @Tags         Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Delivery ID"
+// @Success      200  {object}  domain.Delivery
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /webhooks/deliveries/{id} [get]
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	d, err := h.service.GetDelivery(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// @Summary      Redeliver
+// @Description  Post a delivery to its endpoint again right away, whatever its status (admin only). A successful attempt marks it succeeded; a failed one is logged and leaves it as it was.
+// @Tags         Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Delivery ID"
+// @Success      200  {object}  domain.Delivery
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/deliveries/{id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	d, err := h.service.Redeliver(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		webhookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+func webhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSubscriptionNotFound), errors.Is(err, domain.ErrDeliveryNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidSubscription), errors.Is(err, domain.ErrInvalidDeliveryStatus):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"ecom-api/pkg/middleware"
+	"net/http"
+
+	_ "webhook-microservice/docs"
+
+	"github.com/go-chi/chi/v5"
+	httpSwagger "github.com/swaggo/http-swagger"
+)
+
+// NewRouter sets up routes for webhook-ms. Everything is admin only.
+func NewRouter(handler *WebhookHandler) http.Handler {
+	r := chi.NewRouter()
+
+	// Swagger UI
+	r.Get("/swagger/*", httpSwagger.WrapHandler)
+
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware, middleware.AdminOnly)
+
+		r.Post("/subscriptions", handler.CreateSubscription)
+		r.Get("/subscriptions", handler.ListSubscriptions)
+		r.Get("/subscriptions/{id}", handler.GetSubscription)
+		r.Patch("/subscriptions/{id}", handler.UpdateSubscription)
+		r.Delete("/subscriptions/{id}", handler.DeleteSubscription)
+
+		r.Get("/deliveries", handler.ListDeliveries)
+		r.Get("/deliveries/dead", handler.ListDeadDeliveries)
+		r.Get("/deliveries/{id}", handler.GetDelivery)
+		r.Post("/deliveries/{id}/redeliver", handler.Redeliver)
+	})
+
+	return r
+}
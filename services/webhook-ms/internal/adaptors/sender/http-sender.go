@@ -0,0 +1,51 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"webhook-microservice/internal/ports"
+)
+
+// maxLoggedResponse is how much of a response body is kept in the log.
+const maxLoggedResponse = 2048
+
+// HTTPSender posts webhooks with a bounded timeout. Redirects are not
+// followed, so an endpoint is always the URL that was registered.
+type HTTPSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(timeout time.Duration) ports.Sender {
+	return &HTTPSender{
+		client: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (s *HTTPSender) Post(ctx context.Context, url string, header map[string]string, body []byte) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("User-Agent", "ecom-webhooks/1.0")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+
+	response, _ := io.ReadAll(io.LimitReader(res.Body, maxLoggedResponse))
+	return res.StatusCode, string(response), nil
+}
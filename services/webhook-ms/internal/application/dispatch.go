@@ -0,0 +1,167 @@
+package application
+
+import (
+	"context"
+	"ecom-api/pkg/webhook"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"webhook-microservice/internal/domain"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	dispatchBatchSize   = 100
+	dispatchConcurrency = 8
+)
+
+// Headers sent with every delivery next to the signature. The event ID
+// stays the same across retries, so endpoints can deduplicate on it.
+const (
+	EventIDHeader   = "Webhook-Id"
+	EventTypeHeader = "Webhook-Event"
+)
+
+func (s *WebhookServiceImplement) ListDeliveries(ctx context.Context, subscriptionID, status string, limit int64) ([]*domain.Delivery, error) {
+	switch status {
+	case "", domain.DeliveryPending, domain.DeliverySucceeded, domain.DeliveryDead:
+	default:
+		return nil, fmt.Errorf("%w: %q, want pending, succeeded or dead", domain.ErrInvalidDeliveryStatus, status)
+	}
+	return s.deliveries.List(ctx, subscriptionID, status, limit)
+}
+
+func (s *WebhookServiceImplement) GetDelivery(ctx context.Context, id string) (*domain.Delivery, error) {
+	return s.deliveries.FindByID(ctx, id)
+}
+
+// Redeliver makes a manual attempt. A successful one settles the delivery;
+// a failed one is logged and leaves its status and retry schedule alone.
+func (s *WebhookServiceImplement) Redeliver(ctx context.Context, id string) (*domain.Delivery, error) {
+	d, err := s.deliveries.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.subscriptions.FindByID(ctx, d.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := s.send(ctx, sub, d, true)
+	status, next := d.Status, d.NextAttemptAt
+	if attempt.OK() {
+		status, next = domain.DeliverySucceeded, nil
+	}
+	if err := s.deliveries.RecordAttempt(ctx, d.ID, attempt, status, next); err != nil {
+		return nil, fmt.Errorf("failed to record attempt of delivery %s: %w", d.ID, err)
+	}
+	return s.deliveries.FindByID(ctx, id)
+}
+
+func (s *WebhookServiceImplement) DispatchDue(ctx context.Context) (int, error) {
+	due, err := s.deliveries.FindDue(ctx, time.Now(), dispatchBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find due deliveries: %w", err)
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	subs, err := s.subscriptions.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+	byID := make(map[string]*domain.Subscription, len(subs))
+	for _, sub := range subs {
+		byID[sub.ID] = sub
+	}
+
+	var succeeded atomic.Int64
+	g := new(errgroup.Group)
+	g.SetLimit(dispatchConcurrency)
+	for _, d := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			if s.dispatchOne(ctx, byID[d.SubscriptionID], d) {
+				succeeded.Add(1)
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	log.Printf("webhooks: attempted %d deliveries, %d succeeded", len(due), succeeded.Load())
+	return int(succeeded.Load()), nil
+}
+
+// dispatchOne makes the next scheduled attempt of a delivery and reports
+// whether it succeeded. Deliveries of paused subscriptions wait without
+// using up attempts.
+func (s *WebhookServiceImplement) dispatchOne(ctx context.Context, sub *domain.Subscription, d *domain.Delivery) bool {
+	var attempt domain.DeliveryAttempt
+	switch {
+	case sub == nil:
+		attempt = domain.DeliveryAttempt{At: time.Now(), Error: "subscription was deleted"}
+	case !sub.Active:
+		if err := s.deliveries.Postpone(ctx, d.ID, time.Now().Add(s.dispatch.MaxRetryDelay)); err != nil {
+			log.Printf("webhooks: failed to postpone delivery %s: %v", d.ID, err)
+		}
+		return false
+	default:
+		attempt = s.send(ctx, sub, d, false)
+	}
+
+	status, next := s.outcome(d.Attempts+1, &attempt, sub == nil)
+	if err := s.deliveries.RecordAttempt(ctx, d.ID, attempt, status, next); err != nil {
+		log.Printf("webhooks: failed to record attempt of delivery %s: %v", d.ID, err)
+	}
+	return attempt.OK()
+}
+
+// outcome returns the status of a delivery after its attempts-th attempt,
+// and when a pending one is due again.
+func (s *WebhookServiceImplement) outcome(attempts int, attempt *domain.DeliveryAttempt, giveUp bool) (string, *time.Time) {
+	switch {
+	case attempt.OK():
+		return domain.DeliverySucceeded, nil
+	case giveUp || attempts >= s.dispatch.MaxAttempts:
+		return domain.DeliveryDead, nil
+	}
+
+	delay := s.dispatch.RetryBase
+	for i := 1; i < attempts && delay < s.dispatch.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	next := time.Now().Add(min(delay, s.dispatch.MaxRetryDelay))
+	return domain.DeliveryPending, &next
+}
+
+// send posts a delivery's payload, signed with the subscription's secret.
+func (s *WebhookServiceImplement) send(ctx context.Context, sub *domain.Subscription, d *domain.Delivery, manual bool) domain.DeliveryAttempt {
+	body := []byte(d.Payload)
+	start := time.Now()
+	header := map[string]string{
+		"Content-Type":          "application/json",
+		webhook.SignatureHeader: webhook.Sign(sub.Secret, start, body),
+		EventIDHeader:           d.EventID,
+		EventTypeHeader:         d.EventType,
+	}
+
+	code, response, err := s.sender.Post(ctx, sub.URL, header, body)
+	attempt := domain.DeliveryAttempt{
+		At:         start,
+		StatusCode: code,
+		Response:   response,
+		DurationMS: time.Since(start).Milliseconds(),
+		Manual:     manual,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	return attempt
+}
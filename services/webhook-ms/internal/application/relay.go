@@ -0,0 +1,124 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"webhook-microservice/internal/domain"
+	"webhook-microservice/internal/ports"
+)
+
+const (
+	relayBatchSize = 500
+	// relayLag keeps the relay behind the newest events. Event IDs are
+	// taken before the insert, so an event can land after one with a later
+	// ID; waiting a little keeps the cursor from skipping it.
+	relayLag = 10 * time.Second
+)
+
+func (s *WebhookServiceImplement) RelayEvents(ctx context.Context) (int, error) {
+	subs, err := s.subscriptions.FindActive(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+
+	until := time.Now().Add(-relayLag)
+	created := 0
+	for _, source := range s.sources {
+		n, err := s.relaySource(ctx, source, subs, until)
+		created += n
+		if err != nil {
+			return created, err
+		}
+	}
+	if created > 0 {
+		log.Printf("webhooks: queued %d deliveries", created)
+	}
+	return created, nil
+}
+
+// relaySource moves a source's cursor over the events published before
+// until, creating deliveries on the way. The cursor is saved after every
+// batch and before an error is returned, so a failed run resumes where it
+// stopped; deliveries created twice are ignored.
+func (s *WebhookServiceImplement) relaySource(ctx context.Context, source ports.EventSource, subs []*domain.Subscription, until time.Time) (int, error) {
+	cursor, err := s.cursors.Get(ctx, source.Name())
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cursor of %s: %w", source.Name(), err)
+	}
+
+	created := 0
+	for ctx.Err() == nil {
+		events, err := source.ReadAfter(ctx, cursor, until, relayBatchSize)
+		if err != nil {
+			return created, fmt.Errorf("failed to read events of %s: %w", source.Name(), err)
+		}
+
+		last := cursor
+		for _, e := range events {
+			n, err := s.fanOut(ctx, e, subs)
+			created += n
+			if err != nil {
+				s.saveCursor(ctx, source.Name(), cursor, last)
+				return created, err
+			}
+			last = e.ID
+		}
+		s.saveCursor(ctx, source.Name(), cursor, last)
+		cursor = last
+
+		if len(events) < relayBatchSize {
+			break
+		}
+	}
+	return created, nil
+}
+
+func (s *WebhookServiceImplement) saveCursor(ctx context.Context, source, from, to string) {
+	if to == from {
+		return
+	}
+	if err := s.cursors.Save(ctx, source, to); err != nil {
+		log.Printf("webhooks: failed to save cursor of %s: %v", source, err)
+	}
+}
+
+// fanOut creates a delivery of e for every subscription that wants it.
+// Subscriptions only get events that happened after they were created.
+func (s *WebhookServiceImplement) fanOut(ctx context.Context, e *domain.Event, subs []*domain.Subscription) (int, error) {
+	var payload []byte
+	created := 0
+	for _, sub := range subs {
+		if !sub.Matches(e.Type) || sub.CreatedAt.After(e.OccurredAt) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(e); err != nil {
+				return created, fmt.Errorf("failed to encode event %s: %w", e.ID, err)
+			}
+		}
+
+		now := time.Now()
+		ok, err := s.deliveries.Create(ctx, &domain.Delivery{
+			SubscriptionID: sub.ID,
+			EventID:        e.ID,
+			EventType:      e.Type,
+			Payload:        string(payload),
+			Status:         domain.DeliveryPending,
+			NextAttemptAt:  &now,
+			Log:            []domain.DeliveryAttempt{},
+			CreatedAt:      now,
+		})
+		if err != nil {
+			return created, fmt.Errorf("failed to queue event %s for subscription %s: %w", e.ID, sub.ID, err)
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,42 @@
+package application
+
+import (
+	"time"
+
+	"webhook-microservice/internal/ports"
+)
+
+// DispatchConfig controls how often and how fast failed deliveries are
+// retried.
+type DispatchConfig struct {
+	MaxAttempts   int           // attempts before a delivery is dead
+	RetryBase     time.Duration // delay after the first failed attempt; doubles with each one after
+	MaxRetryDelay time.Duration
+}
+
+// DefaultDispatchConfig spreads eight attempts over about an hour.
+var DefaultDispatchConfig = DispatchConfig{
+	MaxAttempts:   8,
+	RetryBase:     30 * time.Second,
+	MaxRetryDelay: 6 * time.Hour,
+}
+
+type WebhookServiceImplement struct {
+	subscriptions ports.SubscriptionRepository
+	deliveries    ports.DeliveryRepository
+	cursors       ports.CursorRepository
+	sources       []ports.EventSource
+	sender        ports.Sender
+	dispatch      DispatchConfig
+}
+
+func NewWebhookService(subscriptions ports.SubscriptionRepository, deliveries ports.DeliveryRepository, cursors ports.CursorRepository, sources []ports.EventSource, sender ports.Sender, dispatch DispatchConfig) ports.WebhookService {
+	return &WebhookServiceImplement{
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+		cursors:       cursors,
+		sources:       sources,
+		sender:        sender,
+		dispatch:      dispatch,
+	}
+}
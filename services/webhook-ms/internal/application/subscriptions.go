@@ -0,0 +1,142 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"webhook-microservice/internal/domain"
+	"webhook-microservice/internal/ports"
+)
+
+const maxDescription = 500
+
+func (s *WebhookServiceImplement) CreateSubscription(ctx context.Context, endpoint string, eventTypes []string, description string) (*domain.Subscription, error) {
+	endpoint, err := validateEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	eventTypes, err = validateEventTypes(eventTypes)
+	if err != nil {
+		return nil, err
+	}
+	description, err = validateDescription(description)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return s.subscriptions.Create(ctx, &domain.Subscription{
+		URL:         endpoint,
+		EventTypes:  eventTypes,
+		Description: description,
+		Secret:      secret,
+		Active:      true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+func (s *WebhookServiceImplement) ListSubscriptions(ctx context.Context) ([]*domain.Subscription, error) {
+	return s.subscriptions.List(ctx)
+}
+
+func (s *WebhookServiceImplement) GetSubscription(ctx context.Context, id string) (*domain.Subscription, error) {
+	return s.subscriptions.FindByID(ctx, id)
+}
+
+func (s *WebhookServiceImplement) UpdateSubscription(ctx context.Context, id string, changes ports.SubscriptionChanges) (*domain.Subscription, error) {
+	sub, err := s.subscriptions.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.URL != nil {
+		if sub.URL, err = validateEndpoint(*changes.URL); err != nil {
+			return nil, err
+		}
+	}
+	if changes.EventTypes != nil {
+		if sub.EventTypes, err = validateEventTypes(changes.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+	if changes.Description != nil {
+		if sub.Description, err = validateDescription(*changes.Description); err != nil {
+			return nil, err
+		}
+	}
+	if changes.Active != nil {
+		sub.Active = *changes.Active
+	}
+
+	sub.UpdatedAt = time.Now()
+	if err := s.subscriptions.Update(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *WebhookServiceImplement) DeleteSubscription(ctx context.Context, id string) error {
+	if _, err := s.subscriptions.FindByID(ctx, id); err != nil {
+		return err
+	}
+	if err := s.subscriptions.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := s.deliveries.DeleteBySubscription(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete deliveries of subscription %s: %w", id, err)
+	}
+	return nil
+}
+
+func validateEndpoint(endpoint string) (string, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("%w: url must be an absolute http or https URL", domain.ErrInvalidSubscription)
+	}
+	return endpoint, nil
+}
+
+func validateEventTypes(eventTypes []string) ([]string, error) {
+	var out []string
+	for _, t := range eventTypes {
+		t = strings.TrimSpace(t)
+		if !domain.ValidEventType(t) {
+			return nil, fmt.Errorf("%w: unknown event type %q, want one of %s, a family such as order.* or *", domain.ErrInvalidSubscription, t, strings.Join(domain.EventTypes, ", "))
+		}
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: at least one event type is required", domain.ErrInvalidSubscription)
+	}
+	return out, nil
+}
+
+func validateDescription(description string) (string, error) {
+	description = strings.TrimSpace(description)
+	if len(description) > maxDescription {
+		return "", fmt.Errorf("%w: description must be at most %d characters", domain.ErrInvalidSubscription, maxDescription)
+	}
+	return description, nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrDeliveryNotFound      = errors.New("webhook delivery not found")
+	ErrInvalidDeliveryStatus = errors.New("invalid delivery status")
+)
+
+// States of a delivery
+const (
+	DeliveryPending   = "pending"   // waiting for its next attempt
+	DeliverySucceeded = "succeeded" // the endpoint answered 2xx
+	DeliveryDead      = "dead"      // out of attempts; only redelivered by hand
+)
+
+// Event is a domain event read from the outbox of a source service.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Source     string          `json:"source"`
+	Subject    string          `json:"subject"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Delivery is an event on its way to one subscription, with a log of the
+// attempts made to deliver it. A subscription gets at most one delivery
+// per event.
+type Delivery struct {
+	ID             string            `json:"id" bson:"_id,omitempty"`
+	SubscriptionID string            `json:"subscription_id" bson:"subscription_id"`
+	EventID        string            `json:"event_id" bson:"event_id"`
+	EventType      string            `json:"event_type" bson:"event_type"`
+	Payload        string            `json:"payload,omitempty" bson:"payload"` // body as posted to the endpoint
+	Status         string            `json:"status" bson:"status"`             // pending, succeeded or dead
+	Attempts       int               `json:"attempts" bson:"attempts"`
+	NextAttemptAt  *time.Time        `json:"next_attempt_at,omitempty" bson:"next_attempt_at,omitempty"`
+	LastError      string            `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	Log            []DeliveryAttempt `json:"log" bson:"log"` // latest attempts, oldest first
+	CreatedAt      time.Time         `json:"created_at" bson:"created_at"`
+	DeliveredAt    *time.Time        `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
+
+// DeliveryAttempt records one POST to the endpoint and what came back.
+type DeliveryAttempt struct {
+	At         time.Time `json:"at" bson:"at"`
+	StatusCode int       `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	Response   string    `json:"response,omitempty" bson:"response,omitempty"` // start of the response body
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms" bson:"duration_ms"`
+	Manual     bool      `json:"manual,omitempty" bson:"manual,omitempty"` // redelivered by an admin
+}
+
+// OK reports whether the endpoint accepted the delivery.
+func (a *DeliveryAttempt) OK() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// Failure describes why the attempt failed, or is empty if it did not.
+func (a *DeliveryAttempt) Failure() string {
+	switch {
+	case a.Error != "":
+		return a.Error
+	case !a.OK():
+		return fmt.Sprintf("endpoint answered %d", a.StatusCode)
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrInvalidSubscription  = errors.New("invalid webhook subscription")
+)
+
+// EventTypes are the events merchants can subscribe to.
+var EventTypes = []string{
+	"order.created",
+	"order.status_changed",
+	"shipment.created",
+	"shipment.updated",
+	"payment.completed",
+	"payment.failed",
+	"payment.voided",
+	"payment.refunded",
+}
+
+// Subscription is a merchant endpoint registered to receive events. Its
+// event types are exact types, a family such as "order.*", or "*" for all
+// events.
+type Subscription struct {
+	ID          string    `json:"id" bson:"_id,omitempty"`
+	URL         string    `json:"url" bson:"url"`
+	EventTypes  []string  `json:"event_types" bson:"event_types"`
+	Description string    `json:"description,omitempty" bson:"description,omitempty"`
+	Secret      string    `json:"-" bson:"secret"` // signs deliveries; only shown when the subscription is created
+	Active      bool      `json:"active" bson:"active"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// Matches reports whether the subscription wants events of eventType.
+func (s *Subscription) Matches(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == "*" || t == eventType {
+			return true
+		}
+		if family, ok := strings.CutSuffix(t, ".*"); ok && strings.HasPrefix(eventType, family+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidEventType reports whether t names a known event type, a family of
+// them, or all of them.
+func ValidEventType(t string) bool {
+	if t == "*" {
+		return true
+	}
+	family, wildcard := strings.CutSuffix(t, ".*")
+	for _, known := range EventTypes {
+		if known == t || (wildcard && strings.HasPrefix(known, family+".")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"webhook-microservice/internal/domain"
+)
+
+type SubscriptionRepository interface {
+	Create(ctx context.Context, s *domain.Subscription) (*domain.Subscription, error)
+	FindByID(ctx context.Context, id string) (*domain.Subscription, error)
+	List(ctx context.Context) ([]*domain.Subscription, error)
+	FindActive(ctx context.Context) ([]*domain.Subscription, error)
+	Update(ctx context.Context, s *domain.Subscription) error
+	Delete(ctx context.Context, id string) error
+}
+
+type DeliveryRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Create stores a delivery and reports false when the subscription
+	// already has one for the event.
+	Create(ctx context.Context, d *domain.Delivery) (bool, error)
+	FindByID(ctx context.Context, id string) (*domain.Delivery, error)
+	// List returns deliveries newest first, without their payload. Empty
+	// filters match all.
+	List(ctx context.Context, subscriptionID, status string, limit int64) ([]*domain.Delivery, error)
+	FindDue(ctx context.Context, now time.Time, limit int64) ([]*domain.Delivery, error)
+	// RecordAttempt logs an attempt and moves the delivery to status, due
+	// again at next if it is still pending.
+	RecordAttempt(ctx context.Context, id string, attempt domain.DeliveryAttempt, status string, next *time.Time) error
+	// Postpone moves a pending delivery's next attempt without counting one.
+	Postpone(ctx context.Context, id string, next time.Time) error
+	DeleteBySubscription(ctx context.Context, subscriptionID string) error
+}
+
+// EventSource reads the outbox of one service in publication order.
+type EventSource interface {
+	Name() string
+	// ReadAfter returns up to limit events published after the event with
+	// ID after and before until.
+	ReadAfter(ctx context.Context, after string, until time.Time, limit int64) ([]*domain.Event, error)
+}
+
+// CursorRepository remembers the last event relayed from each source.
+type CursorRepository interface {
+	Get(ctx context.Context, source string) (string, error)
+	Save(ctx context.Context, source, eventID string) error
+}
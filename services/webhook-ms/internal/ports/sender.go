@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// Sender posts a webhook body to a merchant endpoint. It returns the
+// status code and the start of the response body; err is set when no
+// response came back.
+type Sender interface {
+	Post(ctx context.Context, url string, header map[string]string, body []byte) (int, string, error)
+}
@@ -0,0 +1,39 @@
+package ports
+
+import (
+	"context"
+
+	"webhook-microservice/internal/domain"
+)
+
+// SubscriptionChanges holds the fields of a subscription to change; nil
+// fields are left as they are.
+type SubscriptionChanges struct {
+	URL         *string
+	EventTypes  []string
+	Description *string
+	Active      *bool
+}
+
+type WebhookService interface {
+	// CreateSubscription registers an endpoint and generates the secret
+	// its deliveries are signed with.
+	CreateSubscription(ctx context.Context, url string, eventTypes []string, description string) (*domain.Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]*domain.Subscription, error)
+	GetSubscription(ctx context.Context, id string) (*domain.Subscription, error)
+	UpdateSubscription(ctx context.Context, id string, changes SubscriptionChanges) (*domain.Subscription, error)
+	// DeleteSubscription removes a subscription with its deliveries.
+	DeleteSubscription(ctx context.Context, id string) error
+
+	ListDeliveries(ctx context.Context, subscriptionID, status string, limit int64) ([]*domain.Delivery, error)
+	GetDelivery(ctx context.Context, id string) (*domain.Delivery, error)
+	// Redeliver posts a delivery again right away, whatever its status.
+	Redeliver(ctx context.Context, id string) (*domain.Delivery, error)
+
+	// RelayEvents fans new source events out into deliveries and returns
+	// how many deliveries it created.
+	RelayEvents(ctx context.Context) (int, error)
+	// DispatchDue attempts the deliveries that are due and returns how
+	// many of them succeeded.
+	DispatchDue(ctx context.Context) (int, error)
+}